@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS table_operation_log_encounter_id_idx;
+DROP TABLE IF EXISTS public.table_operation_log;
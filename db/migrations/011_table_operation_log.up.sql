@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS public.table_operation_log
+(
+    session_id TEXT PRIMARY KEY,
+    encounter_id TEXT NOT NULL,
+    admin_id BIGINT NOT NULL
+        REFERENCES public.user(id),
+    initial_data JSONB NOT NULL,
+    operations JSONB NOT NULL DEFAULT '[]'::jsonb,
+    started_at TIMESTAMPTZ NOT NULL,
+    finished_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX table_operation_log_encounter_id_idx ON public.table_operation_log (encounter_id);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS public.encounter_versions
+(
+    encounter_id UUID NOT NULL
+        REFERENCES public.encounter_store(uuid) ON DELETE CASCADE,
+    version INT NOT NULL
+        CHECK(version > 0),
+    data BYTEA NOT NULL,
+    author_id BIGINT NOT NULL
+        REFERENCES public.user(id),
+    source TEXT NOT NULL
+        CHECK(source IN ('rest', 'table', 'restore', 'migration')),
+    restored_from INT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (encounter_id, version)
+);
+
+INSERT INTO public.encounter_versions (encounter_id, version, data, author_id, source)
+SELECT uuid, 1, data, user_id, 'rest'
+FROM public.encounter_store
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS encounter_deleted_at_idx;
+
+ALTER TABLE public.encounter_store
+DROP COLUMN IF EXISTS deleted_at;
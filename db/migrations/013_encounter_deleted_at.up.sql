@@ -0,0 +1,10 @@
+ALTER TABLE public.encounter_store
+ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+
+UPDATE public.encounter_store
+SET deleted_at = now()
+WHERE is_deleted AND deleted_at IS NULL;
+
+CREATE INDEX encounter_deleted_at_idx
+ON public.encounter_store (deleted_at)
+WHERE is_deleted;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS encounter_share_links_encounter_id_idx;
+DROP TABLE IF EXISTS public.encounter_share_links;
+
+DROP INDEX IF EXISTS encounter_shares_user_id_idx;
+DROP TABLE IF EXISTS public.encounter_shares;
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS public.encounter_shares
+(
+    encounter_id UUID NOT NULL
+        REFERENCES public.encounter_store(uuid) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL
+        REFERENCES public.user(id) ON DELETE CASCADE,
+    role TEXT NOT NULL
+        CHECK(role IN ('viewer', 'editor', 'co-owner')),
+    granted_by BIGINT NOT NULL
+        REFERENCES public.user(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (encounter_id, user_id)
+);
+
+CREATE INDEX encounter_shares_user_id_idx
+ON public.encounter_shares (user_id);
+
+CREATE TABLE IF NOT EXISTS public.encounter_share_links
+(
+    token TEXT PRIMARY KEY,
+    encounter_id UUID NOT NULL
+        REFERENCES public.encounter_store(uuid) ON DELETE CASCADE,
+    created_by BIGINT NOT NULL
+        REFERENCES public.user(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX encounter_share_links_encounter_id_idx
+ON public.encounter_share_links (encounter_id);
@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS encounter_store_tags_idx;
+DROP INDEX IF EXISTS encounter_store_folder_id_idx;
+
+ALTER TABLE public.encounter_store
+    DROP CONSTRAINT IF EXISTS encounter_store_folder_fkey;
+
+ALTER TABLE public.encounter_store
+    DROP COLUMN IF EXISTS folder_id,
+    DROP COLUMN IF EXISTS tags,
+    DROP COLUMN IF EXISTS is_template,
+    DROP COLUMN IF EXISTS updated_at,
+    DROP COLUMN IF EXISTS created_at;
+
+DROP TABLE IF EXISTS public.encounter_folders;
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS public.encounter_folders
+(
+    id BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    user_id BIGINT NOT NULL
+        REFERENCES public.user(id) ON DELETE CASCADE,
+    name TEXT NOT NULL
+        CHECK(name <> '')
+        CONSTRAINT max_len_folder_name CHECK(LENGTH(name) <= 60),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (user_id, name),
+    UNIQUE (id, user_id)
+);
+
+-- Настоящая дата создания раньше нигде не хранилась, поэтому существующие энкаунтеры получают время миграции
+ALTER TABLE public.encounter_store
+    ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ADD COLUMN IF NOT EXISTS is_template BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}',
+    ADD COLUMN IF NOT EXISTS folder_id BIGINT;
+
+-- Папка должна принадлежать владельцу энкаунтера, при удалении папки энкаунтер остаётся без неё
+ALTER TABLE public.encounter_store
+    ADD CONSTRAINT encounter_store_folder_fkey
+        FOREIGN KEY (folder_id, user_id) REFERENCES public.encounter_folders (id, user_id)
+        ON DELETE SET NULL (folder_id);
+
+CREATE INDEX encounter_store_folder_id_idx
+ON public.encounter_store (folder_id);
+
+CREATE INDEX encounter_store_tags_idx
+ON public.encounter_store USING GIN (tags);
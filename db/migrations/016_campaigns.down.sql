@@ -0,0 +1,14 @@
+DROP INDEX IF EXISTS campaign_characters_character_id_idx;
+DROP TABLE IF EXISTS public.campaign_characters;
+
+DROP INDEX IF EXISTS campaign_maps_map_id_idx;
+DROP TABLE IF EXISTS public.campaign_maps;
+
+DROP INDEX IF EXISTS campaign_encounters_encounter_id_idx;
+DROP TABLE IF EXISTS public.campaign_encounters;
+
+DROP INDEX IF EXISTS campaign_members_user_id_idx;
+DROP TABLE IF EXISTS public.campaign_members;
+
+DROP INDEX IF EXISTS campaigns_user_id_idx;
+DROP TABLE IF EXISTS public.campaigns;
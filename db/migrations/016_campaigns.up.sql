@@ -0,0 +1,72 @@
+CREATE TABLE IF NOT EXISTS public.campaigns
+(
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id BIGINT NOT NULL
+        REFERENCES public.user(id) ON DELETE CASCADE,
+    name TEXT NOT NULL
+        CHECK(name <> '')
+        CONSTRAINT max_len_campaign_name CHECK(LENGTH(name) <= 60),
+    description TEXT NOT NULL DEFAULT ''
+        CONSTRAINT max_len_campaign_description CHECK(LENGTH(description) <= 2000),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX campaigns_user_id_idx ON public.campaigns (user_id);
+
+CREATE TABLE IF NOT EXISTS public.campaign_members
+(
+    campaign_id UUID NOT NULL
+        REFERENCES public.campaigns(id) ON DELETE CASCADE,
+    user_id BIGINT NOT NULL
+        REFERENCES public.user(id) ON DELETE CASCADE,
+    added_by BIGINT NOT NULL
+        REFERENCES public.user(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (campaign_id, user_id)
+);
+
+CREATE INDEX campaign_members_user_id_idx ON public.campaign_members (user_id);
+
+CREATE TABLE IF NOT EXISTS public.campaign_encounters
+(
+    campaign_id UUID NOT NULL
+        REFERENCES public.campaigns(id) ON DELETE CASCADE,
+    encounter_id UUID NOT NULL
+        REFERENCES public.encounter_store(uuid) ON DELETE CASCADE,
+    added_by BIGINT NOT NULL
+        REFERENCES public.user(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (campaign_id, encounter_id)
+);
+
+CREATE INDEX campaign_encounters_encounter_id_idx ON public.campaign_encounters (encounter_id);
+
+CREATE TABLE IF NOT EXISTS public.campaign_maps
+(
+    campaign_id UUID NOT NULL
+        REFERENCES public.campaigns(id) ON DELETE CASCADE,
+    map_id UUID NOT NULL
+        REFERENCES public.maps(id) ON DELETE CASCADE,
+    added_by BIGINT NOT NULL
+        REFERENCES public.user(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (campaign_id, map_id)
+);
+
+CREATE INDEX campaign_maps_map_id_idx ON public.campaign_maps (map_id);
+
+-- Персонажи хранятся в MongoDB, поэтому внешнего ключа на них нет
+CREATE TABLE IF NOT EXISTS public.campaign_characters
+(
+    campaign_id UUID NOT NULL
+        REFERENCES public.campaigns(id) ON DELETE CASCADE,
+    character_id TEXT NOT NULL
+        CHECK(character_id <> ''),
+    added_by BIGINT NOT NULL
+        REFERENCES public.user(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (campaign_id, character_id)
+);
+
+CREATE INDEX campaign_characters_character_id_idx ON public.campaign_characters (character_id);
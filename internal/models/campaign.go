@@ -0,0 +1,83 @@
+package models
+
+import "time"
+
+// CampaignRole — роль пользователя в кампании
+type CampaignRole string
+
+const (
+	MasterCampaignRole CampaignRole = "master" // Создатель кампании
+	PlayerCampaignRole CampaignRole = "player"
+)
+
+type Campaign struct {
+	ID          string       `json:"id"`
+	UserID      int          `json:"userID"`
+	Name        string       `json:"name"`
+	Description string       `json:"description"`
+	Role        CampaignRole `json:"role,omitempty"` // Роль текущего пользователя, заполняется в списке кампаний
+	CreatedAt   time.Time    `json:"createdAt"`
+	UpdatedAt   time.Time    `json:"updatedAt"`
+}
+
+type CampaignsList []*Campaign
+
+type CampaignReq struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// CampaignMember — игрок, добавленный в кампанию мастером
+type CampaignMember struct {
+	UserID      int       `json:"userID"`
+	DisplayName string    `json:"displayName"`
+	AddedBy     int       `json:"addedBy"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+type CampaignMembersList []*CampaignMember
+
+type CampaignEncounter struct {
+	ID        string    `json:"id"`
+	UserID    int       `json:"userID"`
+	Name      string    `json:"name"`
+	AddedBy   int       `json:"addedBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type CampaignMap struct {
+	ID        string    `json:"id"`
+	UserID    int       `json:"userID"`
+	Name      string    `json:"name"`
+	AddedBy   int       `json:"addedBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CampaignCharacter — персонаж кампании. Имя подгружается из хранилища персонажей
+type CampaignCharacter struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name,omitempty"`
+	AddedBy   int       `json:"addedBy"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// CampaignSession — активная сессия стола по одному из энкаунтеров кампании
+type CampaignSession struct {
+	SessionID       string    `json:"sessionID"`
+	EncounterID     string    `json:"encounterID"`
+	EncounterName   string    `json:"encounterName"`
+	AdminName       string    `json:"adminName"`
+	ParticipantsNum int       `json:"participantsNum"`
+	StartedAt       time.Time `json:"startedAt"`
+	Paused          bool      `json:"paused"`
+}
+
+// CampaignDashboard собирает на одной странице всё, что привязано к кампании
+type CampaignDashboard struct {
+	Campaign   *Campaign            `json:"campaign"`
+	Members    CampaignMembersList  `json:"members"`
+	Encounters []*CampaignEncounter `json:"encounters"`
+	Maps       []*CampaignMap       `json:"maps"`
+	Characters []*CampaignCharacter `json:"characters"`
+	Sessions   []*CampaignSession   `json:"sessions"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// ChatRequest — сообщение в чат сессии. To — получатель шёпота, 0 для сообщения всем.
+// Формулы в тексте вида [[1d20+5]] бросаются сервером
+type ChatRequest struct {
+	Text string `json:"text"`
+	To   int    `json:"to,omitempty"`
+}
+
+type ChatMsg struct {
+	ID        string       `json:"id"`
+	UserID    int          `json:"userID"`
+	Name      string       `json:"name"`
+	To        int          `json:"to,omitempty"`
+	ToName    string       `json:"toName,omitempty"`
+	Text      string       `json:"text"`
+	Rolls     []InlineRoll `json:"rolls,omitempty"`
+	Timestamp time.Time    `json:"timestamp"`
+}
+
+// InlineRoll — результат броска формулы из текста сообщения
+type InlineRoll struct {
+	Formula string    `json:"formula"`
+	Result  *DiceRoll `json:"result"`
+}
+
+type ChatHistoryMsg struct {
+	Messages []ChatMsg `json:"messages"`
+}
@@ -0,0 +1,24 @@
+package models
+
+// DiceRoll — результат броска по формуле вроде "3к8+5" или "2d20kh1"
+type DiceRoll struct {
+	Formula string     `json:"formula"`
+	Total   int        `json:"total"`
+	Terms   []DiceTerm `json:"terms"`
+}
+
+// DiceTerm — слагаемое формулы: группа костей или константа
+type DiceTerm struct {
+	Sign     int         `json:"sign"`
+	Dice     DiceType    `json:"dice,omitempty"`
+	Count    int         `json:"count,omitempty"`
+	Constant int         `json:"constant,omitempty"`
+	Rolls    []DieResult `json:"rolls,omitempty"`
+	Subtotal int         `json:"subtotal"`
+}
+
+type DieResult struct {
+	Value    int  `json:"value"`
+	Dropped  bool `json:"dropped,omitempty"`  // Отброшена модификатором kh/kl/dh/dl
+	Exploded bool `json:"exploded,omitempty"` // Выпал максимум, брошена дополнительная кость
+}
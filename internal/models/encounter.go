@@ -1,29 +1,99 @@
 package models
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 type Encounter struct {
-	UserID int             `json:"userID"`
-	Name   string          `json:"name"`
-	Data   json.RawMessage `json:"data"`
-	UUID   string          `json:"id"`
+	UserID     int             `json:"userID"`
+	Name       string          `json:"name"`
+	Data       json.RawMessage `json:"data"`
+	UUID       string          `json:"id"`
+	FolderID   *int            `json:"folderID,omitempty"`
+	Tags       []string        `json:"tags,omitempty"`
+	IsTemplate bool            `json:"isTemplate,omitempty"`
+	CreatedAt  *time.Time      `json:"createdAt,omitempty"`
+	UpdatedAt  *time.Time      `json:"updatedAt,omitempty"`
 }
 
 type EncounterInList struct {
-	UserID int    `json:"userID"`
-	Name   string `json:"name"`
-	UUID   string `json:"id"`
+	UserID     int       `json:"userID"`
+	Name       string    `json:"name"`
+	UUID       string    `json:"id"`
+	FolderID   *int      `json:"folderID,omitempty"`
+	Tags       []string  `json:"tags"`
+	IsTemplate bool      `json:"isTemplate"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
 }
 
 type EncountersList []*EncounterInList
 
+// DeletedEncounter — энкаунтер в корзине
+type DeletedEncounter struct {
+	UserID    int       `json:"userID"`
+	Name      string    `json:"name"`
+	UUID      string    `json:"id"`
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+type DeletedEncountersList []*DeletedEncounter
+
 type SaveEncounterReq struct {
-	Name string          `json:"name"`
-	Data json.RawMessage `json:"data"`
+	Name       string          `json:"name"`
+	Data       json.RawMessage `json:"data"`
+	FolderID   *int            `json:"folderID,omitempty"`
+	Tags       []string        `json:"tags,omitempty"`
+	IsTemplate bool            `json:"isTemplate,omitempty"`
 }
 
 type GetEncountersListReq struct {
-	Start  int          `json:"start"`
-	Size   int          `json:"size"`
-	Search SearchParams `json:"search"`
+	Start  int                   `json:"start"`
+	Size   int                   `json:"size"`
+	Search SearchParams          `json:"search"`
+	Filter EncounterFilterParams `json:"filter"`
+	Order  Order                 `json:"order"`
+}
+
+// EncounterFilterParams — фильтры списка энкаунтеров. Пустые поля не ограничивают выборку
+type EncounterFilterParams struct {
+	FolderID *int   `json:"folderID,omitempty"`
+	Tag      string `json:"tag,omitempty"`
+	Template *bool  `json:"template,omitempty"` // true — только шаблоны, false — только обычные энкаунтеры
+}
+
+// Поля, по которым можно сортировать список энкаунтеров
+const (
+	EncounterOrderByName      = "name"
+	EncounterOrderByCreatedAt = "createdAt"
+	EncounterOrderByUpdatedAt = "updatedAt"
+)
+
+// CopyEncounterReq — параметры копии энкаунтера или энкаунтера из шаблона. Пустое имя
+// заменяется именем исходного энкаунтера
+type CopyEncounterReq struct {
+	Name     string `json:"name,omitempty"`
+	FolderID *int   `json:"folderID,omitempty"`
+}
+
+// EncounterOrganizationReq заменяет папку, теги и признак шаблона у энкаунтера
+type EncounterOrganizationReq struct {
+	FolderID   *int     `json:"folderID,omitempty"`
+	Tags       []string `json:"tags"`
+	IsTemplate bool     `json:"isTemplate"`
+}
+
+// EncounterFolder — пользовательская папка для энкаунтеров, например кампания
+type EncounterFolder struct {
+	ID        int       `json:"id"`
+	UserID    int       `json:"userID"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+type EncounterFoldersList []*EncounterFolder
+
+type EncounterFolderReq struct {
+	Name string `json:"name"`
 }
@@ -0,0 +1,66 @@
+package models
+
+// EncounterSchemaVersion — текущая версия формата данных энкаунтера. Данные без schemaVersion
+// считаются версией 0 и обновляются при чтении
+const EncounterSchemaVersion = 1
+
+type Condition string
+
+const (
+	Blinded       Condition = "blinded"
+	Charmed       Condition = "charmed"
+	Deafened      Condition = "deafened"
+	Frightened    Condition = "frightened"
+	Grappled      Condition = "grappled"
+	Incapacitated Condition = "incapacitated"
+	Invisible     Condition = "invisible"
+	Paralyzed     Condition = "paralyzed"
+	Petrified     Condition = "petrified"
+	Poisoned      Condition = "poisoned"
+	Prone         Condition = "prone"
+	Restrained    Condition = "restrained"
+	Stunned       Condition = "stunned"
+	Unconscious   Condition = "unconscious"
+)
+
+func (c Condition) IsValid() bool {
+	switch c {
+	case Blinded, Charmed, Deafened, Frightened, Grappled, Incapacitated, Invisible, Paralyzed, Petrified,
+		Poisoned, Prone, Restrained, Stunned, Unconscious:
+		return true
+	default:
+		return false
+	}
+}
+
+type GridPosition struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// EncounterParticipant — участник боя. Для существ SourceID — engName из бестиария, для персонажей —
+// ID персонажа. Initiative и Position не заданы, пока участник не бросил инициативу или не выставлен на карту
+type EncounterParticipant struct {
+	ID              string        `json:"id"`
+	Kind            CombatantKind `json:"kind"`
+	SourceID        string        `json:"sourceID,omitempty"`
+	Name            string        `json:"name"`
+	OwnerID         int           `json:"ownerID,omitempty"`
+	MaxHP           int           `json:"maxHP"`
+	CurrentHP       int           `json:"currentHP"`
+	TempHP          int           `json:"tempHP"`
+	Conditions      []Condition   `json:"conditions,omitempty"`
+	ExhaustionLevel int           `json:"exhaustionLevel,omitempty"`
+	Initiative      *int          `json:"initiative,omitempty"`
+	Position        *GridPosition `json:"position,omitempty"`
+	Notes           string        `json:"notes,omitempty"`
+	DMOnly          bool          `json:"_dmOnly,omitempty"` // Участник скрыт от игроков
+}
+
+// EncounterContent — содержимое Encounter.Data. Hidden — пути, скрытые от игроков за столом
+type EncounterContent struct {
+	SchemaVersion int                    `json:"schemaVersion"`
+	Participants  []EncounterParticipant `json:"participants"`
+	Notes         string                 `json:"notes,omitempty"`
+	Hidden        []string               `json:"_hidden,omitempty"`
+}
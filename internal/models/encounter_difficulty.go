@@ -0,0 +1,73 @@
+package models
+
+type Difficulty string
+
+const (
+	TrivialDifficulty Difficulty = "trivial"
+	EasyDifficulty    Difficulty = "easy"
+	MediumDifficulty  Difficulty = "medium"
+	HardDifficulty    Difficulty = "hard"
+	DeadlyDifficulty  Difficulty = "deadly"
+)
+
+type DifficultyThresholds struct {
+	Easy   int `json:"easy"`
+	Medium int `json:"medium"`
+	Hard   int `json:"hard"`
+	Deadly int `json:"deadly"`
+}
+
+// DifficultyReq — состав боя для расчёта сложности. Участники сохранённого энкаунтера и перечисленные
+// в запросе суммируются, PartyLevels позволяет указать уровни персонажей без карточек
+type DifficultyReq struct {
+	EncounterID string   `json:"encounterID,omitempty"`
+	Creatures   []string `json:"creatures,omitempty"`  // engName существ из бестиария
+	Characters  []string `json:"characters,omitempty"` // ID персонажей
+	PartyLevels []int    `json:"partyLevels,omitempty"`
+}
+
+// EncounterDifficulty — сложность боя по правилам DMG: опыт монстров умножается на множитель,
+// зависящий от их числа и размера группы, и сравнивается с суммой порогов персонажей
+type EncounterDifficulty struct {
+	TotalXP      int                  `json:"totalXP"`
+	AdjustedXP   int                  `json:"adjustedXP"`
+	Multiplier   float64              `json:"multiplier"`
+	MonsterCount int                  `json:"monsterCount"`
+	PartyLevels  []int                `json:"partyLevels"`
+	Thresholds   DifficultyThresholds `json:"thresholds"`
+	Difficulty   Difficulty           `json:"difficulty"`
+}
+
+// GenerateEncounterReq — параметры случайного энкаунтера. Environment дополняет Filter.Environment,
+// уровни персонажей из Characters добавляются к PartyLevels
+type GenerateEncounterReq struct {
+	Difficulty  Difficulty   `json:"difficulty"`
+	PartyLevels []int        `json:"partyLevels,omitempty"`
+	Characters  []string     `json:"characters,omitempty"`
+	Environment []string     `json:"environment,omitempty"`
+	Filter      FilterParams `json:"filter"`
+	Count       int          `json:"count,omitempty"`       // Число вариантов, по умолчанию 3
+	MaxMonsters int          `json:"maxMonsters,omitempty"` // Монстров в группе, по умолчанию 10
+}
+
+type GeneratedCreature struct {
+	EngName         string `json:"engName"`
+	Name            Name   `json:"name"`
+	ChallengeRating string `json:"challengeRating"`
+	Experience      int    `json:"experience"`
+	Count           int    `json:"count"`
+}
+
+// EncounterCandidate — подобранная группа монстров. Encounter можно сразу сохранить как новый энкаунтер
+type EncounterCandidate struct {
+	Creatures  []GeneratedCreature  `json:"creatures"`
+	Difficulty *EncounterDifficulty `json:"difficulty"`
+	Encounter  SaveEncounterReq     `json:"encounter"`
+}
+
+type GeneratedEncounters struct {
+	Difficulty Difficulty            `json:"difficulty"`
+	MinXP      int                   `json:"minXP"`
+	MaxXP      int                   `json:"maxXP"` // Скорректированный опыт группы меньше MaxXP
+	Candidates []*EncounterCandidate `json:"candidates"`
+}
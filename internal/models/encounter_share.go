@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// EncounterRole — уровень доступа к энкаунтеру. Каждая следующая роль включает права предыдущих
+type EncounterRole string
+
+const (
+	ViewerEncounterRole  EncounterRole = "viewer"
+	EditorEncounterRole  EncounterRole = "editor"
+	CoOwnerEncounterRole EncounterRole = "co-owner"
+	OwnerEncounterRole   EncounterRole = "owner" // Есть только у создателя энкаунтера, не выдаётся
+)
+
+var encounterRoleRanks = map[EncounterRole]int{
+	ViewerEncounterRole:  1,
+	EditorEncounterRole:  2,
+	CoOwnerEncounterRole: 3,
+	OwnerEncounterRole:   4,
+}
+
+// IsGrantable сообщает, можно ли выдать роль другому пользователю
+func (r EncounterRole) IsGrantable() bool {
+	return r == ViewerEncounterRole || r == EditorEncounterRole || r == CoOwnerEncounterRole
+}
+
+// Includes сообщает, достаточно ли роли для действия, которому нужна роль required
+func (r EncounterRole) Includes(required EncounterRole) bool {
+	rank, ok := encounterRoleRanks[r]
+
+	return ok && rank >= encounterRoleRanks[required]
+}
+
+// GrantedRolesIncluding возвращает выдаваемые роли, которых достаточно для роли required
+func GrantedRolesIncluding(required EncounterRole) []EncounterRole {
+	roles := make([]EncounterRole, 0, 3)
+
+	for _, role := range []EncounterRole{ViewerEncounterRole, EditorEncounterRole, CoOwnerEncounterRole} {
+		if role.Includes(required) {
+			roles = append(roles, role)
+		}
+	}
+
+	return roles
+}
+
+// EncounterShare — доступ к энкаунтеру, выданный другому пользователю
+type EncounterShare struct {
+	EncounterID string        `json:"encounterID"`
+	UserID      int           `json:"userID"`
+	DisplayName string        `json:"displayName"`
+	Role        EncounterRole `json:"role"`
+	GrantedBy   int           `json:"grantedBy"`
+	CreatedAt   time.Time     `json:"createdAt"`
+}
+
+type EncounterSharesList []*EncounterShare
+
+type ShareEncounterReq struct {
+	Role EncounterRole `json:"role"`
+}
+
+// SharedEncounter — чужой энкаунтер, к которому у пользователя есть доступ
+type SharedEncounter struct {
+	UserID int           `json:"userID"`
+	Name   string        `json:"name"`
+	UUID   string        `json:"id"`
+	Role   EncounterRole `json:"role"`
+}
+
+type SharedEncountersList []*SharedEncounter
+
+// CreateShareLinkReq — параметры ссылки на просмотр энкаунтера. Нулевой срок — бессрочная ссылка
+type CreateShareLinkReq struct {
+	ExpiresIn int `json:"expiresIn,omitempty"` // Срок действия в секундах
+}
+
+// EncounterShareLink — ссылка, по которой любой вошедший пользователь может посмотреть энкаунтер
+type EncounterShareLink struct {
+	Token       string     `json:"token"`
+	EncounterID string     `json:"encounterID"`
+	CreatedBy   int        `json:"createdBy"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	ExpiresAt   *time.Time `json:"expiresAt,omitempty"`
+}
+
+type EncounterShareLinksList []*EncounterShareLink
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type EncounterVersionSource string
+
+const (
+	RESTVersionSource    EncounterVersionSource = "rest"
+	TableVersionSource   EncounterVersionSource = "table"
+	RestoreVersionSource EncounterVersionSource = "restore"
+)
+
+// EncounterChange — кто и откуда изменил данные энкаунтера
+type EncounterChange struct {
+	AuthorID     int
+	Source       EncounterVersionSource
+	RestoredFrom int // Версия, из которой восстановлены данные, 0 — не восстановление
+}
+
+// EncounterVersion — данные энкаунтера после изменения. Data заполняется только при запросе
+// конкретной версии
+type EncounterVersion struct {
+	EncounterID  string                 `json:"encounterID"`
+	Version      int                    `json:"version"`
+	AuthorID     int                    `json:"authorID"`
+	Source       EncounterVersionSource `json:"source"`
+	RestoredFrom int                    `json:"restoredFrom,omitempty"`
+	CreatedAt    time.Time              `json:"createdAt"`
+	Data         json.RawMessage        `json:"data,omitempty"`
+}
+
+type EncounterVersionsList []*EncounterVersion
+
+// EncounterVersionsDiff — JSON Patch (RFC 6902), который переводит версию From в версию To
+type EncounterVersionsDiff struct {
+	From  int             `json:"from"`
+	To    int             `json:"to"`
+	Patch json.RawMessage `json:"patch"`
+}
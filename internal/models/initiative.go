@@ -0,0 +1,60 @@
+package models
+
+type CombatantKind string
+
+const (
+	CreatureCombatant  CombatantKind = "creature"  // Существо из бестиария, SourceID — engName
+	CharacterCombatant CombatantKind = "character" // Персонаж игрока, SourceID — ID персонажа
+	CustomCombatant    CombatantKind = "custom"    // Участник боя без карточки
+)
+
+type Combatant struct {
+	ID          string        `json:"id"`
+	Name        string        `json:"name"`
+	Kind        CombatantKind `json:"kind"`
+	SourceID    string        `json:"sourceID,omitempty"`
+	OwnerID     int           `json:"ownerID,omitempty"` // Игрок, управляющий участником
+	DexModifier int           `json:"dexModifier"`
+	Initiative  int           `json:"initiative"`
+	Roll        *DiceRoll     `json:"roll,omitempty"`
+	Delayed     bool          `json:"delayed,omitempty"` // Отложил ход и пропускается до возвращения в очередь
+	Ready       bool          `json:"ready,omitempty"`   // Подготовил действие
+	DMOnly      bool          `json:"_dmOnly,omitempty"` // Виден только ведущему, как объекты энкаунтера с _dmOnly
+}
+
+// InitiativeState — порядок ходов. Turn — индекс текущего участника в Combatants, Round начинается с 1
+// после броска инициативы, 0 означает, что бой ещё не начат
+type InitiativeState struct {
+	Combatants []Combatant `json:"combatants"`
+	Round      int         `json:"round"`
+	Turn       int         `json:"turn"`
+	CurrentID  string      `json:"currentID,omitempty"`
+}
+
+type InitiativeAction string
+
+const (
+	AddCombatant    InitiativeAction = "add"
+	RemoveCombatant InitiativeAction = "remove"
+	SetInitiative   InitiativeAction = "set"
+	RollInitiative  InitiativeAction = "rollAll"
+	NextTurn        InitiativeAction = "next"
+	PrevTurn        InitiativeAction = "prev"
+	DelayTurn       InitiativeAction = "delay"
+	ResumeTurn      InitiativeAction = "resume"
+	ReadyAction     InitiativeAction = "ready"
+)
+
+type InitiativeRequest struct {
+	Action     InitiativeAction `json:"action"`
+	ID         string           `json:"id,omitempty"`
+	Combatant  *Combatant       `json:"combatant,omitempty"`
+	Initiative int              `json:"initiative,omitempty"`
+}
+
+// TurnMsg рассылается при смене хода
+type TurnMsg struct {
+	Round     int        `json:"round"`
+	CurrentID string     `json:"currentID"`
+	Combatant *Combatant `json:"combatant,omitempty"`
+}
@@ -0,0 +1,51 @@
+package models
+
+import "time"
+
+// CreateInviteRequest — параметры приглашения в сессию. Нулевые значения означают срок действия
+// по умолчанию и неограниченное число использований
+type CreateInviteRequest struct {
+	ExpiresIn   int    `json:"expiresIn,omitempty"` // Срок действия в секундах
+	MaxUses     int    `json:"maxUses,omitempty"`
+	Role        Role   `json:"role,omitempty"`        // Роль, с которой подключится приглашённый
+	CharacterID string `json:"characterID,omitempty"` // Персонаж, закреплённый за приглашённым
+}
+
+type InviteResponse struct {
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// InviteClaims — содержимое подписанного приглашения
+type InviteClaims struct {
+	ID          string `json:"id"`
+	SessionID   string `json:"sid"`
+	ExpiresAt   int64  `json:"exp"` // Unix-время
+	MaxUses     int    `json:"max,omitempty"`
+	Role        Role   `json:"role,omitempty"`
+	CharacterID string `json:"chr,omitempty"`
+}
+
+type JoinRequestStatus string
+
+const (
+	JoinPending   JoinRequestStatus = "pending"
+	JoinCancelled JoinRequestStatus = "cancelled" // Пользователь отключился, не дождавшись решения
+	JoinAccepted  JoinRequestStatus = "accepted"
+	JoinDenied    JoinRequestStatus = "denied"
+)
+
+// JoinRequestMsg сообщает ведущему о пользователе, который подключился без приглашения и ждёт
+// разрешения. Ожидающий пользователь получает то же сообщение со своим статусом
+type JoinRequestMsg struct {
+	UserID int               `json:"userID"`
+	Name   string            `json:"name"`
+	Role   Role              `json:"role"`
+	Status JoinRequestStatus `json:"status"`
+}
+
+// JoinDecisionRequest — ответ ведущего на запрос подключения
+type JoinDecisionRequest struct {
+	UserID int  `json:"userID"`
+	Accept bool `json:"accept"`
+}
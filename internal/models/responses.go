@@ -1,5 +1,7 @@
 package models
 
+import "encoding/json"
+
 type ErrResponse struct {
 	Status string `json:"status"`
 }
@@ -7,16 +9,47 @@ type ErrResponse struct {
 type WSErrResponse struct {
 	Type  string `json:"type"`
 	Error string `json:"error"`
+	Seq   int64  `json:"seq,omitempty"`
 }
 
 type WSResponse struct {
 	Type WSMsgType `json:"type"`
 	Data any       `json:"data"`
+	Seq  int64     `json:"seq,omitempty"` // Номер сообщения игровой сессии для возобновления после переподключения
+}
+
+// WSRequest — команда от клиента. Сообщения другого вида считаются патчем энкаунтера
+type WSRequest struct {
+	Type WSMsgType       `json:"type"`
+	Data json.RawMessage `json:"data"`
 }
 
 type WSMsgType string
 
 const (
 	BattleInfo       WSMsgType = "battleInfo"
+	BattleDelta      WSMsgType = "battleDelta"
+	Resync           WSMsgType = "resync"
 	ParticipantsInfo WSMsgType = "participantsInfo"
+
+	Patch    WSMsgType = "patch"
+	Undo     WSMsgType = "undo"
+	Conflict WSMsgType = "conflict"
+
+	Permissions WSMsgType = "permissions"
+	Roll        WSMsgType = "roll"
+
+	Initiative WSMsgType = "initiative"
+	Turn       WSMsgType = "turn"
+
+	Resume WSMsgType = "resume"
+
+	Chat        WSMsgType = "chat"
+	ChatHistory WSMsgType = "chatHistory"
+
+	SessionControl WSMsgType = "sessionControl"
+	SessionState   WSMsgType = "sessionState"
+
+	JoinRequest  WSMsgType = "joinRequest"
+	JoinDecision WSMsgType = "joinDecision"
 )
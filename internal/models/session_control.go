@@ -0,0 +1,38 @@
+package models
+
+type SessionAction string
+
+const (
+	EndSession      SessionAction = "end"
+	PauseSession    SessionAction = "pause"
+	ResumeSession   SessionAction = "resume"
+	KickParticipant SessionAction = "kick"
+	BanParticipant  SessionAction = "ban"
+	TransferAdmin   SessionAction = "transfer"
+)
+
+// SessionControlRequest — команда ведущего, управляющая сессией. UserID — участник, к которому
+// применяется команда kick, ban или transfer
+type SessionControlRequest struct {
+	Action SessionAction `json:"action"`
+	UserID int           `json:"userID,omitempty"`
+}
+
+// TargetsParticipant сообщает, что команда применяется к участнику сессии
+func (r *SessionControlRequest) TargetsParticipant() bool {
+	switch r.Action {
+	case KickParticipant, BanParticipant, TransferAdmin:
+		return true
+	default:
+		return false
+	}
+}
+
+// SessionStateMsg сообщает участникам о состоянии сессии: при подключении и после каждой команды ведущего
+type SessionStateMsg struct {
+	Action    SessionAction `json:"action,omitempty"` // Команда, изменившая состояние, пустая при подключении
+	UserID    int           `json:"userID,omitempty"` // Участник, к которому применена команда
+	AdminID   int           `json:"adminID"`
+	AdminName string        `json:"adminName"`
+	Paused    bool          `json:"paused"`
+}
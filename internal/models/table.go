@@ -1,9 +1,13 @@
 package models
 
-import "encoding/json"
+import (
+	"encoding/json"
+	"time"
+)
 
 type CreateTableRequest struct {
 	EncounterID string `json:"encounterID"`
+	MaxPlayers  int    `json:"maxPlayers,omitempty"` // 0 — значение по умолчанию
 }
 
 type CreateTableResponse struct {
@@ -14,19 +18,46 @@ type TableData struct {
 	AdminName     string          `json:"adminName"`
 	EncounterName string          `json:"encounterName"`
 	EncounterData json.RawMessage `json:"encounterData"`
+	Revision      int             `json:"revision"`
+	MaxPlayers    int             `json:"maxPlayers"`
+	Paused        bool            `json:"paused"`
 }
 
 type Role string
 
 const (
-	Admin  Role = "admin"
-	Player Role = "player"
+	Admin     Role = "admin"
+	Player    Role = "player"
+	Spectator Role = "spectator" // Только наблюдает за игрой, не занимает место игрока
 )
 
+// ConnectionParams — параметры подключения к игровой сессии. LastSeq — номер последнего полученного
+// сообщения при переподключении, 0 для нового подключения
+type ConnectionParams struct {
+	Role    Role
+	LastSeq int64
+	Invite  *InviteClaims // Проверенное приглашение, nil — подключение с одобрения ведущего
+}
+
+// ResumeMsg завершает переподключение: пропущенные сообщения либо повторены, либо вместо них
+// отправлено текущее состояние сессии
+type ResumeMsg struct {
+	LastSeq  int64 `json:"lastSeq"`
+	Snapshot bool  `json:"snapshot"`
+}
+
 type Participant struct {
-	ID   int    `json:"id"`
-	Name string `json:"name"`
-	Role Role   `json:"role"`
+	ID          int    `json:"id"`
+	Name        string `json:"name"`
+	Role        Role   `json:"role"`
+	CharacterID string `json:"characterID,omitempty"` // Персонаж из приглашения
+}
+
+// MemberGrant — роль и персонаж, с которыми участник был впервые допущен в сессию. При повторных
+// подключениях используются они, а не параметры подключения
+type MemberGrant struct {
+	Role        Role   `json:"role"`
+	CharacterID string `json:"characterID,omitempty"`
 }
 
 type ParticipantStatus string
@@ -34,6 +65,7 @@ type ParticipantStatus string
 const (
 	Connected    ParticipantStatus = "connected"
 	Disconnected ParticipantStatus = "disconnected"
+	RoleChanged  ParticipantStatus = "roleChanged"
 )
 
 type ParticipantsInfoMsg struct {
@@ -45,4 +77,147 @@ type ParticipantsInfoMsg struct {
 
 type EncounterData struct {
 	EncounterData json.RawMessage `json:"encounterData"`
+	Revision      int             `json:"revision"`
+}
+
+// EncounterDelta — изменения энкаунтера в формате JSON Patch относительно ревизии BaseRevision.
+// Клиент, у которого другая ревизия, запрашивает полные данные сообщением resync
+type EncounterDelta struct {
+	Patch        json.RawMessage `json:"patch"`
+	BaseRevision int             `json:"baseRevision"`
+	Revision     int             `json:"revision"`
+}
+
+// TableSessionSnapshot хранит состояние игровой сессии, достаточное для её восстановления после рестарта
+type TableSessionSnapshot struct {
+	SessionID     string          `json:"sessionID"`
+	EncounterID   string          `json:"encounterID"`
+	EncounterName string          `json:"encounterName"`
+	EncounterData json.RawMessage `json:"encounterData"`
+	AdminID       int             `json:"adminID"`
+	AdminName     string          `json:"adminName"`
+	StartedAt     time.Time       `json:"startedAt"`
+	SavedAt       time.Time       `json:"savedAt"`
+
+	InitialData json.RawMessage  `json:"initialData,omitempty"`
+	Operations  []TableOperation `json:"operations,omitempty"`
+	Permissions map[int][]string `json:"permissions,omitempty"`
+	Rolls       []RollMsg        `json:"rolls,omitempty"`
+	Chat        []ChatMsg        `json:"chat,omitempty"`
+	Initiative  *InitiativeState `json:"initiative,omitempty"`
+	MaxPlayers  int              `json:"maxPlayers,omitempty"`
+	Seq         int64            `json:"seq,omitempty"` // Номер последнего отправленного сообщения
+	Paused      bool             `json:"paused,omitempty"`
+	Banned      []int            `json:"banned,omitempty"` // Участники, которым запрещено подключаться
+
+	Members         []int               `json:"members,omitempty"`    // Пользователи, подключавшиеся к сессии, кроме ведущего
+	Grants          map[int]MemberGrant `json:"grants,omitempty"`     // Ключ - UserID участника из Members
+	InviteUses      map[string]int      `json:"inviteUses,omitempty"` // Ключ - ID приглашения
+	ParticipantsNum int                 `json:"participantsNum,omitempty"`
+	LastActivityAt  time.Time           `json:"lastActivityAt,omitempty"`
+}
+
+// TableSessionSummary — сведения об активной сессии для списка сессий пользователя
+type TableSessionSummary struct {
+	SessionID       string    `json:"sessionID"`
+	EncounterID     string    `json:"encounterID"`
+	EncounterName   string    `json:"encounterName"`
+	AdminName       string    `json:"adminName"`
+	Role            Role      `json:"role"` // Роль пользователя в сессии
+	ParticipantsNum int       `json:"participantsNum"`
+	StartedAt       time.Time `json:"startedAt"`
+	Paused          bool      `json:"paused"`
+
+	// IdleSecondsLeft — время до завершения сессии без активности, не задано у сессии на паузе
+	IdleSecondsLeft *int `json:"idleSecondsLeft"`
+}
+
+type TableOperationKind string
+
+const (
+	PatchOperation TableOperationKind = "patch"
+	UndoOperation  TableOperationKind = "undo"
+)
+
+// TableOperation — запись в журнале изменений сессии. Операция patch хранит применённый патч,
+// операция undo — номера отменённых ею операций
+type TableOperation struct {
+	Seq       int                `json:"seq"`
+	AuthorID  int                `json:"authorID"`
+	Timestamp time.Time          `json:"timestamp"`
+	Kind      TableOperationKind `json:"kind"`
+	Patch     json.RawMessage    `json:"patch,omitempty"`
+	Format    PatchFormat        `json:"format,omitempty"` // Формат Patch
+	Undone    bool               `json:"undone,omitempty"`
+	Reverts   []int              `json:"reverts,omitempty"`
+}
+
+// TableOperationLog позволяет воспроизвести бой: InitialData с последовательно применёнными
+// неотменёнными патчами даёт текущее состояние энкаунтера. Долгая сессия держит в памяти только
+// последние операции, более старые хранятся в table_operation_log и попадают в журнал оттуда
+type TableOperationLog struct {
+	SessionID   string           `json:"sessionID"`
+	EncounterID string           `json:"encounterID"`
+	AdminID     int              `json:"adminID"`
+	InitialData json.RawMessage  `json:"initialData"`
+	Operations  []TableOperation `json:"operations"`
+	StartedAt   time.Time        `json:"startedAt"`
+	FinishedAt  *time.Time       `json:"finishedAt,omitempty"`
+}
+
+// PatchFormat — формат патча энкаунтера
+type PatchFormat string
+
+const (
+	LegacyPatch PatchFormat = ""            // Собственный формат merger.Merge
+	JSONPatch   PatchFormat = "json-patch"  // RFC 6902
+	MergePatch  PatchFormat = "merge-patch" // RFC 7386
+)
+
+func (f PatchFormat) IsValid() bool {
+	switch f {
+	case LegacyPatch, JSONPatch, MergePatch:
+		return true
+	default:
+		return false
+	}
+}
+
+// PatchRequest — патч с ревизией, относительно которой он был сделан. Если с тех пор
+// изменились те же пути, патч отклоняется сообщением ConflictMsg
+type PatchRequest struct {
+	BaseRevision int             `json:"baseRevision"`
+	Format       PatchFormat     `json:"format,omitempty"`
+	Patch        json.RawMessage `json:"patch"`
+}
+
+type ConflictMsg struct {
+	BaseRevision    int      `json:"baseRevision"`
+	CurrentRevision int      `json:"currentRevision"`
+	Paths           []string `json:"paths"`
+}
+
+// PermissionsMsg задаёт пути энкаунтера, которые может изменять участник. WritablePaths == nil
+// снимает ограничения, пустой список запрещает любые изменения
+type PermissionsMsg struct {
+	UserID        int      `json:"userID"`
+	WritablePaths []string `json:"writablePaths"`
+}
+
+type RollRequest struct {
+	Formula string `json:"formula"`
+	Label   string `json:"label,omitempty"`
+}
+
+// RollMsg — бросок, выполненный сервером. Хранится в истории бросков сессии
+type RollMsg struct {
+	UserID    int       `json:"userID"`
+	Name      string    `json:"name"`
+	Label     string    `json:"label,omitempty"`
+	Result    *DiceRoll `json:"result"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+type UndoRequest struct {
+	Count int `json:"count"`
 }
@@ -0,0 +1,9 @@
+package apperrors
+
+import "errors"
+
+var (
+	CampaignNotFoundErr      = errors.New("campaign not found")
+	InvalidCampaignErr       = errors.New("invalid campaign name or description")
+	InvalidCampaignMemberErr = errors.New("invalid campaign member")
+)
@@ -3,5 +3,18 @@ package apperrors
 import "errors"
 
 var (
-	PermissionDeniedError = errors.New("permission denied")
+	PermissionDeniedError       = errors.New("permission denied")
+	InvalidEncounterDataErr     = errors.New("encounter data does not match the schema")
+	InvalidDifficultyErr        = errors.New("invalid difficulty request")
+	InvalidGenerateErr          = errors.New("invalid encounter generation request")
+	EncounterNotFoundErr        = errors.New("encounter not found")
+	EncounterVersionNotFoundErr = errors.New("encounter version not found")
+	EncounterNotInTrashErr      = errors.New("encounter not found in trash")
+	InvalidShareErr             = errors.New("invalid encounter share")
+	ShareLinkNotFoundErr        = errors.New("share link not found")
+	InvalidEncounterOrderErr    = errors.New("invalid encounters order")
+	InvalidEncounterTagsErr     = errors.New("invalid encounter tags")
+	InvalidEncounterFolderErr   = errors.New("invalid encounter folder")
+	EncounterNotTemplateErr     = errors.New("encounter is not a template")
+	InvalidTrashPurgeErr        = errors.New("trash retention and purge interval must be positive")
 )
@@ -3,7 +3,33 @@ package apperrors
 import "errors"
 
 var (
-	TableNotFoundErr     = errors.New("table not found")
-	PlayersNumErr        = errors.New("max players number had already reached")
-	UserAlreadyExistsErr = errors.New("user already exists")
+	TableNotFoundErr       = errors.New("table not found")
+	PlayersNumErr          = errors.New("max players number had already reached")
+	UserAlreadyExistsErr   = errors.New("user already exists")
+	InvalidUndoErr         = errors.New("invalid undo request")
+	InvalidPatchErr        = errors.New("invalid patch request")
+	InvalidPermissionsErr  = errors.New("invalid permissions request")
+	InvalidRollErr         = errors.New("invalid dice formula")
+	InvalidInitiativeErr   = errors.New("invalid initiative request")
+	InvalidMaxPlayersErr   = errors.New("invalid max players number")
+	SpectatorReadOnlyErr   = errors.New("spectators cannot change the table")
+	InvalidChatErr         = errors.New("invalid chat message")
+	InvalidControlErr      = errors.New("invalid session control request")
+	BannedErr              = errors.New("user is banned from session")
+	EncounterSessionErr    = errors.New("encounter already has a running session")
+	InvalidInviteErr       = errors.New("invalid invite")
+	InvalidInviteParamsErr = errors.New("invalid invite params")
+	InviteExpiredErr       = errors.New("invite expired")
+	InviteExhaustedErr     = errors.New("invite has no uses left")
+	JoinDeniedErr          = errors.New("join request denied")
+	JoinPendingErr         = errors.New("user is not admitted to session")
+
+	SaveTableSnapshotErr   = errors.New("something went wrong while saving table session snapshot")
+	GetTableSnapshotErr    = errors.New("something went wrong while getting table session snapshot")
+	RemoveTableSnapshotErr = errors.New("something went wrong while removing table session snapshot")
+
+	PublishTableMsgErr = errors.New("something went wrong while publishing table message")
+	SlowConsumerErr    = errors.New("websocket client is too slow, connection closed")
+	SubscribeTableErr  = errors.New("something went wrong while subscribing to table channel")
+	TableLockErr       = errors.New("something went wrong while locking table session")
 )
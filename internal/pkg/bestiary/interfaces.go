@@ -12,6 +12,7 @@ type BestiaryRepository interface {
 	GetCreaturesList(ctx context.Context, size, start int, order []models.Order, filter models.FilterParams,
 		search models.SearchParams) ([]*models.BestiaryCreature, error)
 	GetCreatureByEngName(ctx context.Context, engName string, isUserCollection bool) (*models.Creature, error)
+	SampleCreatures(ctx context.Context, size int, filter models.FilterParams) ([]*models.BestiaryCreature, error)
 
 	GetUserCreaturesList(ctx context.Context, size, start int, order []models.Order, filter models.FilterParams,
 		search models.SearchParams, userID int) ([]*models.BestiaryCreature, error)
@@ -87,6 +87,43 @@ func (s *bestiaryStorage) GetUserCreaturesList(ctx context.Context, size, start
 	return s.getCreaturesList(ctx, filters, findOptions, true)
 }
 
+// SampleCreatures возвращает до size случайных существ, подходящих под фильтр
+func (s *bestiaryStorage) SampleCreatures(ctx context.Context, size int,
+	filter models.FilterParams) ([]*models.BestiaryCreature, error) {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	collection := s.db.Collection("creatures")
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: buildTypesFilters(filter)}},
+		{{Key: "$sample", Value: bson.M{"size": size}}},
+	}
+
+	cursor, err := dbcall.DBCall[*mongo.Cursor](fnName, s.metrics, func() (*mongo.Cursor, error) {
+		return collection.Aggregate(ctx, pipeline)
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"size": size})
+		return nil, apperrors.FindMongoDataErr
+	}
+	defer cursor.Close(ctx)
+
+	var creatures []*models.BestiaryCreature
+
+	for cursor.Next(ctx) {
+		var creature models.BestiaryCreature
+
+		if err := cursor.Decode(&creature); err != nil {
+			l.RepoError(err, nil)
+			return nil, apperrors.DecodeMongoDataErr
+		}
+
+		creatures = append(creatures, &creature)
+	}
+
+	return creatures, nil
+}
+
 func (s *bestiaryStorage) GetCreatureByEngName(ctx context.Context, url string,
 	isUserCollection bool) (*models.Creature, error) {
 	l := logger.FromContext(ctx)
@@ -0,0 +1,390 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	campaigninterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/campaign"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/delivery/responses"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+const defaultCampaignsPage = 20
+
+type CampaignHandler struct {
+	usecases   campaigninterfaces.CampaignUsecases
+	ctxUserKey string
+}
+
+func NewCampaignHandler(usecases campaigninterfaces.CampaignUsecases, ctxUserKey string) *CampaignHandler {
+	return &CampaignHandler{
+		usecases:   usecases,
+		ctxUserKey: ctxUserKey,
+	}
+}
+
+func (h *CampaignHandler) GetCampaigns(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+
+	start, startErr := queryInt(r, "start", 0)
+	size, sizeErr := queryInt(r, "size", defaultCampaignsPage)
+
+	if err := errors.Join(startErr, sizeErr); err != nil {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrSizeOrPosition, err, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrSizeOrPosition)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	list, err := h.usecases.GetCampaigns(ctx, size, start, userID)
+	if err != nil {
+		h.sendCampaignError(w, r, err, map[string]any{"size": size, "start": start, "user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, list)
+}
+
+func (h *CampaignHandler) CreateCampaign(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+
+	var reqData models.CampaignReq
+
+	err := json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrBadJSON, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrBadJSON)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	campaign, err := h.usecases.CreateCampaign(ctx, &reqData, userID)
+	if err != nil {
+		h.sendCampaignError(w, r, err, map[string]any{"name": reqData.Name, "user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, campaign)
+}
+
+func (h *CampaignHandler) GetCampaign(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, ok := h.campaignID(w, r)
+	if !ok {
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	campaign, err := h.usecases.GetCampaign(ctx, id, userID)
+	if err != nil {
+		h.sendCampaignError(w, r, err, map[string]any{"id": id, "user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, campaign)
+}
+
+func (h *CampaignHandler) UpdateCampaign(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+
+	id, ok := h.campaignID(w, r)
+	if !ok {
+		return
+	}
+
+	var reqData models.CampaignReq
+
+	err := json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrBadJSON, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrBadJSON)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	err = h.usecases.UpdateCampaign(ctx, id, &reqData, userID)
+	if err != nil {
+		h.sendCampaignError(w, r, err, map[string]any{"id": id, "name": reqData.Name, "user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, nil)
+}
+
+func (h *CampaignHandler) RemoveCampaign(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, ok := h.campaignID(w, r)
+	if !ok {
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	err := h.usecases.RemoveCampaign(ctx, id, userID)
+	if err != nil {
+		h.sendCampaignError(w, r, err, map[string]any{"id": id, "user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, nil)
+}
+
+func (h *CampaignHandler) GetCampaignDashboard(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, ok := h.campaignID(w, r)
+	if !ok {
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	dashboard, err := h.usecases.GetCampaignDashboard(ctx, id, userID)
+	if err != nil {
+		h.sendCampaignError(w, r, err, map[string]any{"id": id, "user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, dashboard)
+}
+
+func (h *CampaignHandler) GetCampaignMembers(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, ok := h.campaignID(w, r)
+	if !ok {
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	list, err := h.usecases.GetCampaignMembers(ctx, id, userID)
+	if err != nil {
+		h.sendCampaignError(w, r, err, map[string]any{"id": id, "user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, list)
+}
+
+func (h *CampaignHandler) AddCampaignMember(w http.ResponseWriter, r *http.Request) {
+	h.handleMember(w, r, h.usecases.AddCampaignMember)
+}
+
+func (h *CampaignHandler) RemoveCampaignMember(w http.ResponseWriter, r *http.Request) {
+	h.handleMember(w, r, h.usecases.RemoveCampaignMember)
+}
+
+func (h *CampaignHandler) GetCampaignEncounter(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+
+	id, ok := h.campaignID(w, r)
+	if !ok {
+		return
+	}
+
+	itemID, ok := mux.Vars(r)["itemID"]
+	if !ok || itemID == "" {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	encounter, err := h.usecases.GetCampaignEncounter(ctx, id, itemID, userID)
+	if err != nil {
+		h.sendCampaignError(w, r, err, map[string]any{"id": id, "item_id": itemID, "user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, encounter)
+}
+
+func (h *CampaignHandler) AttachEncounter(w http.ResponseWriter, r *http.Request) {
+	h.handleAttachment(w, r, h.usecases.AttachEncounter)
+}
+
+func (h *CampaignHandler) DetachEncounter(w http.ResponseWriter, r *http.Request) {
+	h.handleAttachment(w, r, h.usecases.DetachEncounter)
+}
+
+func (h *CampaignHandler) AttachMap(w http.ResponseWriter, r *http.Request) {
+	h.handleAttachment(w, r, h.usecases.AttachMap)
+}
+
+func (h *CampaignHandler) DetachMap(w http.ResponseWriter, r *http.Request) {
+	h.handleAttachment(w, r, h.usecases.DetachMap)
+}
+
+func (h *CampaignHandler) AttachCharacter(w http.ResponseWriter, r *http.Request) {
+	h.handleAttachment(w, r, h.usecases.AttachCharacter)
+}
+
+func (h *CampaignHandler) DetachCharacter(w http.ResponseWriter, r *http.Request) {
+	h.handleAttachment(w, r, h.usecases.DetachCharacter)
+}
+
+// handleMember обрабатывает добавление и исключение игрока по его ID из пути
+func (h *CampaignHandler) handleMember(w http.ResponseWriter, r *http.Request,
+	action func(ctx context.Context, id string, targetID, userID int) error) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+
+	id, ok := h.campaignID(w, r)
+	if !ok {
+		return
+	}
+
+	targetID, err := strconv.Atoi(mux.Vars(r)["userID"])
+	if err != nil {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, err, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	err = action(ctx, id, targetID, userID)
+	if err != nil {
+		h.sendCampaignError(w, r, err, map[string]any{"id": id, "target_id": targetID, "user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, nil)
+}
+
+// handleAttachment обрабатывает привязку и отвязку энкаунтеров, карт и персонажей по ID из пути
+func (h *CampaignHandler) handleAttachment(w http.ResponseWriter, r *http.Request,
+	action func(ctx context.Context, id, itemID string, userID int) error) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+
+	id, ok := h.campaignID(w, r)
+	if !ok {
+		return
+	}
+
+	itemID, ok := mux.Vars(r)["itemID"]
+	if !ok || itemID == "" {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	err := action(ctx, id, itemID, userID)
+	if err != nil {
+		h.sendCampaignError(w, r, err, map[string]any{"id": id, "item_id": itemID, "user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, nil)
+}
+
+// campaignID достаёт ID кампании из пути и отвечает ошибкой, если это не UUID
+func (h *CampaignHandler) campaignID(w http.ResponseWriter, r *http.Request) (string, bool) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+
+	id := mux.Vars(r)["id"]
+	if _, err := uuid.Parse(id); err != nil {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, err, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return "", false
+	}
+
+	return id, true
+}
+
+func (h *CampaignHandler) sendCampaignError(w http.ResponseWriter, r *http.Request, err error, data any) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+
+	var code int
+	var status string
+
+	switch {
+	case errors.Is(err, apperrors.PermissionDeniedError):
+		code = responses.StatusForbidden
+		status = responses.ErrForbidden
+	case errors.Is(err, apperrors.StartPosSizeError):
+		code = responses.StatusBadRequest
+		status = responses.ErrSizeOrPosition
+	case errors.Is(err, apperrors.InvalidCampaignErr):
+		code = responses.StatusBadRequest
+		status = responses.ErrWrongCampaign
+	case errors.Is(err, apperrors.CampaignNotFoundErr):
+		code = responses.StatusBadRequest
+		status = responses.ErrCampaignNotFound
+	case errors.Is(err, apperrors.InvalidCampaignMemberErr):
+		code = responses.StatusBadRequest
+		status = responses.ErrWrongCampaignMember
+	case errors.Is(err, apperrors.InvalidIDErr):
+		code = responses.StatusBadRequest
+		status = responses.ErrInvalidID
+	case errors.Is(err, apperrors.EncounterNotFoundErr):
+		code = responses.StatusBadRequest
+		status = responses.ErrEncounterNotFound
+	default:
+		code = responses.StatusInternalServerError
+		status = responses.ErrInternalServer
+	}
+
+	l.DeliveryError(ctx, code, status, err, data)
+	responses.SendErrResponse(w, code, status)
+}
+
+func queryInt(r *http.Request, key string, fallback int) (int, error) {
+	raw := r.URL.Query().Get(key)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	return strconv.Atoi(raw)
+}
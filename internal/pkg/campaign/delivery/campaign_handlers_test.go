@@ -0,0 +1,270 @@
+package delivery_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/campaign/delivery"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/delivery/responses"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/testhelpers"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+)
+
+const campaignID = "00000000-0000-0000-0000-00000000000c"
+
+// --- fake usecase ---
+
+type fakeCampaignUsecases struct {
+	err       error
+	dashboard *models.CampaignDashboard
+	targetID  int
+	itemID    string
+}
+
+func (f *fakeCampaignUsecases) GetCampaigns(_ context.Context, _, _, _ int) (*models.CampaignsList, error) {
+	return &models.CampaignsList{}, f.err
+}
+
+func (f *fakeCampaignUsecases) GetCampaign(_ context.Context, _ string, _ int) (*models.Campaign, error) {
+	return &models.Campaign{}, f.err
+}
+
+func (f *fakeCampaignUsecases) CreateCampaign(_ context.Context, req *models.CampaignReq,
+	userID int) (*models.Campaign, error) {
+	return &models.Campaign{UserID: userID, Name: req.Name}, f.err
+}
+
+func (f *fakeCampaignUsecases) UpdateCampaign(_ context.Context, _ string, _ *models.CampaignReq, _ int) error {
+	return f.err
+}
+
+func (f *fakeCampaignUsecases) RemoveCampaign(_ context.Context, _ string, _ int) error {
+	return f.err
+}
+
+func (f *fakeCampaignUsecases) GetCampaignDashboard(_ context.Context, _ string,
+	_ int) (*models.CampaignDashboard, error) {
+	return f.dashboard, f.err
+}
+
+func (f *fakeCampaignUsecases) GetCampaignMembers(_ context.Context, _ string,
+	_ int) (*models.CampaignMembersList, error) {
+	return &models.CampaignMembersList{}, f.err
+}
+
+func (f *fakeCampaignUsecases) AddCampaignMember(_ context.Context, _ string, targetID, _ int) error {
+	f.targetID = targetID
+	return f.err
+}
+
+func (f *fakeCampaignUsecases) RemoveCampaignMember(_ context.Context, _ string, targetID, _ int) error {
+	f.targetID = targetID
+	return f.err
+}
+
+func (f *fakeCampaignUsecases) GetCampaignEncounter(_ context.Context, _, itemID string,
+	_ int) (*models.Encounter, error) {
+	f.itemID = itemID
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	return &models.Encounter{UUID: itemID}, nil
+}
+
+func (f *fakeCampaignUsecases) AttachEncounter(_ context.Context, _, itemID string, _ int) error {
+	f.itemID = itemID
+	return f.err
+}
+
+func (f *fakeCampaignUsecases) DetachEncounter(_ context.Context, _, itemID string, _ int) error {
+	f.itemID = itemID
+	return f.err
+}
+
+func (f *fakeCampaignUsecases) AttachMap(_ context.Context, _, itemID string, _ int) error {
+	f.itemID = itemID
+	return f.err
+}
+
+func (f *fakeCampaignUsecases) DetachMap(_ context.Context, _, itemID string, _ int) error {
+	f.itemID = itemID
+	return f.err
+}
+
+func (f *fakeCampaignUsecases) AttachCharacter(_ context.Context, _, itemID string, _ int) error {
+	f.itemID = itemID
+	return f.err
+}
+
+func (f *fakeCampaignUsecases) DetachCharacter(_ context.Context, _, itemID string, _ int) error {
+	f.itemID = itemID
+	return f.err
+}
+
+// --- helpers ---
+
+const ctxUserKey = "test-user-key"
+
+func withUser(r *http.Request, key string, user *models.User) *http.Request {
+	ctx := context.WithValue(r.Context(), key, user)
+	return r.WithContext(ctx)
+}
+
+func TestCreateCampaign_InvalidCampaign_Returns400(t *testing.T) {
+	t.Parallel()
+
+	handler := delivery.NewCampaignHandler(&fakeCampaignUsecases{err: apperrors.InvalidCampaignErr}, ctxUserKey)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/campaign", bytes.NewBufferString(`{"name":""}`))
+	req = withUser(req, ctxUserKey, &models.User{ID: 1, DisplayName: "Tester"})
+
+	rr := httptest.NewRecorder()
+	handler.CreateCampaign(rr, req)
+
+	assert.Equal(t, responses.StatusBadRequest, rr.Code)
+	assert.Equal(t, responses.ErrWrongCampaign, testhelpers.DecodeErrorResponse(t, rr.Body))
+}
+
+func TestGetCampaignDashboard_InvalidID_Returns400(t *testing.T) {
+	t.Parallel()
+
+	handler := delivery.NewCampaignHandler(&fakeCampaignUsecases{}, ctxUserKey)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/campaign/not-a-uuid/dashboard", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "not-a-uuid"})
+	req = withUser(req, ctxUserKey, &models.User{ID: 1, DisplayName: "Tester"})
+
+	rr := httptest.NewRecorder()
+	handler.GetCampaignDashboard(rr, req)
+
+	assert.Equal(t, responses.StatusBadRequest, rr.Code)
+	assert.Equal(t, responses.ErrInvalidID, testhelpers.DecodeErrorResponse(t, rr.Body))
+}
+
+func TestGetCampaignDashboard_HappyPath_Returns200(t *testing.T) {
+	t.Parallel()
+
+	dashboard := &models.CampaignDashboard{
+		Campaign: &models.Campaign{ID: campaignID, Name: "Curse of Strahd"},
+		Sessions: []*models.CampaignSession{{SessionID: "session-1", EncounterID: "enc-1"}},
+	}
+	handler := delivery.NewCampaignHandler(&fakeCampaignUsecases{dashboard: dashboard}, ctxUserKey)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/campaign/"+campaignID+"/dashboard", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": campaignID})
+	req = withUser(req, ctxUserKey, &models.User{ID: 2, DisplayName: "Player"})
+
+	rr := httptest.NewRecorder()
+	handler.GetCampaignDashboard(rr, req)
+
+	assert.Equal(t, responses.StatusOk, rr.Code)
+
+	var got models.CampaignDashboard
+	assert.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+	assert.Equal(t, "Curse of Strahd", got.Campaign.Name)
+	assert.Equal(t, "session-1", got.Sessions[0].SessionID)
+}
+
+func TestAddCampaignMember_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   int
+		wantStatus string
+	}{
+		{"invalid member", apperrors.InvalidCampaignMemberErr, responses.StatusBadRequest,
+			responses.ErrWrongCampaignMember},
+		{"permission denied", apperrors.PermissionDeniedError, responses.StatusForbidden, responses.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fake := &fakeCampaignUsecases{err: tt.err}
+			handler := delivery.NewCampaignHandler(fake, ctxUserKey)
+
+			req := httptest.NewRequest(http.MethodPut, "/api/campaign/"+campaignID+"/members/2", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": campaignID, "userID": "2"})
+			req = withUser(req, ctxUserKey, &models.User{ID: 1, DisplayName: "Tester"})
+
+			rr := httptest.NewRecorder()
+			handler.AddCampaignMember(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Equal(t, tt.wantStatus, testhelpers.DecodeErrorResponse(t, rr.Body))
+			assert.Equal(t, 2, fake.targetID)
+		})
+	}
+}
+
+func TestAttachCharacter_HappyPath_Returns200(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeCampaignUsecases{}
+	handler := delivery.NewCampaignHandler(fake, ctxUserKey)
+
+	req := httptest.NewRequest(http.MethodPut, "/api/campaign/"+campaignID+"/characters/char-1", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": campaignID, "itemID": "char-1"})
+	req = withUser(req, ctxUserKey, &models.User{ID: 2, DisplayName: "Player"})
+
+	rr := httptest.NewRecorder()
+	handler.AttachCharacter(rr, req)
+
+	assert.Equal(t, responses.StatusOk, rr.Code)
+	assert.Equal(t, "char-1", fake.itemID)
+}
+
+func TestGetCampaignEncounter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   int
+		wantStatus string
+	}{
+		{"attached encounter is returned", nil, responses.StatusOk, ""},
+		{"not attached encounter", apperrors.EncounterNotFoundErr, responses.StatusBadRequest,
+			responses.ErrEncounterNotFound},
+		{"not a campaign member", apperrors.PermissionDeniedError, responses.StatusForbidden, responses.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fake := &fakeCampaignUsecases{err: tt.err}
+			handler := delivery.NewCampaignHandler(fake, ctxUserKey)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/campaign/"+campaignID+"/encounters/enc-1", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": campaignID, "itemID": "enc-1"})
+			req = withUser(req, ctxUserKey, &models.User{ID: 2, DisplayName: "Player"})
+
+			rr := httptest.NewRecorder()
+			handler.GetCampaignEncounter(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Equal(t, "enc-1", fake.itemID)
+
+			if tt.err != nil {
+				assert.Equal(t, tt.wantStatus, testhelpers.DecodeErrorResponse(t, rr.Body))
+				return
+			}
+
+			var got models.Encounter
+			assert.NoError(t, json.NewDecoder(rr.Body).Decode(&got))
+			assert.Equal(t, "enc-1", got.UUID)
+		})
+	}
+}
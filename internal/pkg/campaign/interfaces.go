@@ -0,0 +1,54 @@
+package campaign
+
+//go:generate mockgen -source=interfaces.go -destination=mocks/mock_campaign.go -package=mocks
+
+import (
+	"context"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+)
+
+type CampaignRepository interface {
+	GetCampaigns(ctx context.Context, size, start, userID int) (*models.CampaignsList, error)
+	GetCampaignByID(ctx context.Context, id string) (*models.Campaign, error)
+	CreateCampaign(ctx context.Context, campaign *models.Campaign) error
+	UpdateCampaign(ctx context.Context, id string, req *models.CampaignReq) error
+	RemoveCampaign(ctx context.Context, id string) error
+	GetCampaignRole(ctx context.Context, id string, userID int) (models.CampaignRole, error)
+
+	GetCampaignMembers(ctx context.Context, id string) (*models.CampaignMembersList, error)
+	AddCampaignMember(ctx context.Context, id string, userID, addedBy int) error
+	RemoveCampaignMember(ctx context.Context, id string, userID int) error
+
+	GetCampaignEncounters(ctx context.Context, id string) ([]*models.CampaignEncounter, error)
+	AttachEncounter(ctx context.Context, id, encounterID string, addedBy int) error
+	DetachEncounter(ctx context.Context, id, encounterID string) error
+	GetCampaignMaps(ctx context.Context, id string) ([]*models.CampaignMap, error)
+	AttachMap(ctx context.Context, id, mapID string, addedBy int) error
+	DetachMap(ctx context.Context, id, mapID string) error
+	GetCampaignCharacters(ctx context.Context, id string) ([]*models.CampaignCharacter, error)
+	AttachCharacter(ctx context.Context, id, characterID string, addedBy int) error
+	DetachCharacter(ctx context.Context, id, characterID string) error
+	HasCharacterAccess(ctx context.Context, characterID string, userID int) bool
+}
+
+type CampaignUsecases interface {
+	GetCampaigns(ctx context.Context, size, start, userID int) (*models.CampaignsList, error)
+	GetCampaign(ctx context.Context, id string, userID int) (*models.Campaign, error)
+	CreateCampaign(ctx context.Context, req *models.CampaignReq, userID int) (*models.Campaign, error)
+	UpdateCampaign(ctx context.Context, id string, req *models.CampaignReq, userID int) error
+	RemoveCampaign(ctx context.Context, id string, userID int) error
+	GetCampaignDashboard(ctx context.Context, id string, userID int) (*models.CampaignDashboard, error)
+
+	GetCampaignMembers(ctx context.Context, id string, userID int) (*models.CampaignMembersList, error)
+	AddCampaignMember(ctx context.Context, id string, targetID, userID int) error
+	RemoveCampaignMember(ctx context.Context, id string, targetID, userID int) error
+
+	GetCampaignEncounter(ctx context.Context, id, encounterID string, userID int) (*models.Encounter, error)
+	AttachEncounter(ctx context.Context, id, encounterID string, userID int) error
+	DetachEncounter(ctx context.Context, id, encounterID string, userID int) error
+	AttachMap(ctx context.Context, id, mapID string, userID int) error
+	DetachMap(ctx context.Context, id, mapID string, userID int) error
+	AttachCharacter(ctx context.Context, id, characterID string, userID int) error
+	DetachCharacter(ctx context.Context, id, characterID string, userID int) error
+}
@@ -0,0 +1,142 @@
+package repository
+
+const (
+	GetCampaignsQuery = `
+		SELECT c.id, c.user_id, c.name, c.description,
+			CASE WHEN c.user_id = $1 THEN 'master' ELSE 'player' END,
+			c.created_at, c.updated_at
+		FROM public.campaigns c
+		WHERE c.user_id = $1 OR EXISTS(
+			SELECT 1
+			FROM public.campaign_members m
+			WHERE m.campaign_id = c.id AND m.user_id = $1
+		)
+		ORDER BY c.updated_at DESC, c.id
+		LIMIT $2 OFFSET $3;
+	`
+
+	GetCampaignByIDQuery = `
+		SELECT id, user_id, name, description, created_at, updated_at
+		FROM public.campaigns
+		WHERE id = $1;
+	`
+
+	CreateCampaignQuery = `
+		INSERT INTO public.campaigns (user_id, name, description)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at, updated_at;
+	`
+
+	UpdateCampaignQuery = `
+		UPDATE public.campaigns
+		SET name = $2, description = $3, updated_at = now()
+		WHERE id = $1;
+	`
+
+	RemoveCampaignQuery = `
+		DELETE FROM public.campaigns
+		WHERE id = $1;
+	`
+
+	GetCampaignRoleQuery = `
+		SELECT CASE WHEN c.user_id = $2 THEN 'master' ELSE 'player' END
+		FROM public.campaigns c
+		WHERE c.id = $1 AND (
+			c.user_id = $2 OR EXISTS(
+				SELECT 1
+				FROM public.campaign_members m
+				WHERE m.campaign_id = c.id AND m.user_id = $2
+			)
+		);
+	`
+
+	GetCampaignMembersQuery = `
+		SELECT m.user_id, u.display_name, m.added_by, m.created_at
+		FROM public.campaign_members m
+		JOIN public."user" u ON u.id = m.user_id
+		WHERE m.campaign_id = $1
+		ORDER BY m.created_at;
+	`
+
+	AddCampaignMemberQuery = `
+		INSERT INTO public.campaign_members (campaign_id, user_id, added_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (campaign_id, user_id) DO NOTHING;
+	`
+
+	RemoveCampaignMemberQuery = `
+		DELETE FROM public.campaign_members
+		WHERE campaign_id = $1 AND user_id = $2;
+	`
+
+	GetCampaignEncountersQuery = `
+		SELECT e.uuid, e.user_id, e.name, ce.added_by, ce.created_at
+		FROM public.campaign_encounters ce
+		JOIN public.encounter_store e ON e.uuid = ce.encounter_id
+		WHERE ce.campaign_id = $1 AND NOT(e.is_deleted)
+		ORDER BY ce.created_at;
+	`
+
+	AttachEncounterQuery = `
+		INSERT INTO public.campaign_encounters (campaign_id, encounter_id, added_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (campaign_id, encounter_id) DO NOTHING;
+	`
+
+	DetachEncounterQuery = `
+		DELETE FROM public.campaign_encounters
+		WHERE campaign_id = $1 AND encounter_id = $2;
+	`
+
+	GetCampaignMapsQuery = `
+		SELECT mp.id, mp.user_id, mp.name, cm.added_by, cm.created_at
+		FROM public.campaign_maps cm
+		JOIN public.maps mp ON mp.id = cm.map_id
+		WHERE cm.campaign_id = $1
+		ORDER BY cm.created_at;
+	`
+
+	AttachMapQuery = `
+		INSERT INTO public.campaign_maps (campaign_id, map_id, added_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (campaign_id, map_id) DO NOTHING;
+	`
+
+	DetachMapQuery = `
+		DELETE FROM public.campaign_maps
+		WHERE campaign_id = $1 AND map_id = $2;
+	`
+
+	GetCampaignCharactersQuery = `
+		SELECT character_id, added_by, created_at
+		FROM public.campaign_characters
+		WHERE campaign_id = $1
+		ORDER BY created_at;
+	`
+
+	AttachCharacterQuery = `
+		INSERT INTO public.campaign_characters (campaign_id, character_id, added_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (campaign_id, character_id) DO NOTHING;
+	`
+
+	DetachCharacterQuery = `
+		DELETE FROM public.campaign_characters
+		WHERE campaign_id = $1 AND character_id = $2;
+	`
+
+	HasCharacterAccessQuery = `
+		SELECT EXISTS(
+			SELECT 1
+			FROM public.campaign_characters cc
+			JOIN public.campaigns c ON c.id = cc.campaign_id
+			WHERE cc.character_id = $1 AND (
+				c.user_id = $2 OR EXISTS(
+					SELECT 1
+					FROM public.campaign_members m
+					WHERE m.campaign_id = c.id AND m.user_id = $2
+				)
+			)
+		);
+	`
+)
@@ -0,0 +1,361 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	campaigninterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/campaign"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	mymetrics "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/metrics"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/repository/dbcall"
+	serverrepo "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/repository/dbinit"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const foreignKeyViolation = "23503"
+
+type campaignStorage struct {
+	pool    serverrepo.PostgresPool
+	metrics mymetrics.DBMetrics
+}
+
+func NewCampaignStorage(pool serverrepo.PostgresPool, metrics mymetrics.DBMetrics) campaigninterfaces.CampaignRepository {
+	return &campaignStorage{
+		pool:    pool,
+		metrics: metrics,
+	}
+}
+
+// GetCampaigns возвращает кампании, которые пользователь ведёт или в которых играет
+func (s *campaignStorage) GetCampaigns(ctx context.Context, size, start, userID int) (*models.CampaignsList, error) {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	rows, err := dbcall.DBCall[pgx.Rows](fnName, s.metrics, func() (pgx.Rows, error) {
+		return s.pool.Query(ctx, GetCampaignsQuery, userID, size, start)
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"size": size, "start": start, "userID": userID})
+		return nil, apperrors.QueryError
+	}
+	defer rows.Close()
+
+	list := make(models.CampaignsList, 0)
+
+	for rows.Next() {
+		var campaign models.Campaign
+
+		if err := rows.Scan(&campaign.ID, &campaign.UserID, &campaign.Name, &campaign.Description, &campaign.Role,
+			&campaign.CreatedAt, &campaign.UpdatedAt); err != nil {
+			l.RepoError(err, map[string]any{"size": size, "start": start, "userID": userID})
+			return nil, apperrors.ScanError
+		}
+
+		list = append(list, &campaign)
+	}
+
+	return &list, nil
+}
+
+func (s *campaignStorage) GetCampaignByID(ctx context.Context, id string) (*models.Campaign, error) {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	var campaign models.Campaign
+
+	_, err := dbcall.DBCall[*models.Campaign](fnName, s.metrics, func() (*models.Campaign, error) {
+		line := s.pool.QueryRow(ctx, GetCampaignByIDQuery, id)
+		if err := line.Scan(&campaign.ID, &campaign.UserID, &campaign.Name, &campaign.Description,
+			&campaign.CreatedAt, &campaign.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		return &campaign, nil
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			l.RepoWarn(err, map[string]any{"id": id})
+			return nil, apperrors.CampaignNotFoundErr
+		}
+
+		l.RepoError(err, map[string]any{"id": id})
+		return nil, apperrors.ScanError
+	}
+
+	return &campaign, nil
+}
+
+func (s *campaignStorage) CreateCampaign(ctx context.Context, campaign *models.Campaign) error {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	_, err := dbcall.DBCall[*models.Campaign](fnName, s.metrics, func() (*models.Campaign, error) {
+		line := s.pool.QueryRow(ctx, CreateCampaignQuery, campaign.UserID, campaign.Name, campaign.Description)
+		if err := line.Scan(&campaign.ID, &campaign.CreatedAt, &campaign.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		return campaign, nil
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"userID": campaign.UserID, "name": campaign.Name})
+		return apperrors.TxError
+	}
+
+	return nil
+}
+
+func (s *campaignStorage) UpdateCampaign(ctx context.Context, id string, req *models.CampaignReq) error {
+	return s.exec(ctx, utils.GetFunctionName(), UpdateCampaignQuery, map[string]any{"id": id, "name": req.Name},
+		id, req.Name, req.Description)
+}
+
+// RemoveCampaign удаляет кампанию вместе с составом и привязками. Сами энкаунтеры, карты и персонажи остаются
+func (s *campaignStorage) RemoveCampaign(ctx context.Context, id string) error {
+	return s.exec(ctx, utils.GetFunctionName(), RemoveCampaignQuery, map[string]any{"id": id}, id)
+}
+
+// GetCampaignRole возвращает роль пользователя в кампании или пустую роль, если он в ней не участвует
+func (s *campaignStorage) GetCampaignRole(ctx context.Context, id string, userID int) (models.CampaignRole, error) {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	var role models.CampaignRole
+
+	_, err := dbcall.DBCall[models.CampaignRole](fnName, s.metrics, func() (models.CampaignRole, error) {
+		line := s.pool.QueryRow(ctx, GetCampaignRoleQuery, id, userID)
+		if err := line.Scan(&role); err != nil {
+			return "", err
+		}
+
+		return role, nil
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return "", nil
+		}
+
+		l.RepoError(err, map[string]any{"id": id, "userID": userID})
+		return "", apperrors.ScanError
+	}
+
+	return role, nil
+}
+
+func (s *campaignStorage) GetCampaignMembers(ctx context.Context, id string) (*models.CampaignMembersList, error) {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	rows, err := dbcall.DBCall[pgx.Rows](fnName, s.metrics, func() (pgx.Rows, error) {
+		return s.pool.Query(ctx, GetCampaignMembersQuery, id)
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"id": id})
+		return nil, apperrors.QueryError
+	}
+	defer rows.Close()
+
+	list := make(models.CampaignMembersList, 0)
+
+	for rows.Next() {
+		var member models.CampaignMember
+
+		if err := rows.Scan(&member.UserID, &member.DisplayName, &member.AddedBy, &member.CreatedAt); err != nil {
+			l.RepoError(err, map[string]any{"id": id})
+			return nil, apperrors.ScanError
+		}
+
+		list = append(list, &member)
+	}
+
+	return &list, nil
+}
+
+func (s *campaignStorage) AddCampaignMember(ctx context.Context, id string, userID, addedBy int) error {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	err := dbcall.ErrOnlyDBCall(fnName, s.metrics, func() error {
+		_, err := s.pool.Exec(ctx, AddCampaignMemberQuery, id, userID, addedBy)
+
+		return err
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			l.RepoWarn(err, map[string]any{"id": id, "userID": userID})
+			return apperrors.InvalidCampaignMemberErr
+		}
+
+		l.RepoError(err, map[string]any{"id": id, "userID": userID})
+		return apperrors.TxError
+	}
+
+	return nil
+}
+
+func (s *campaignStorage) RemoveCampaignMember(ctx context.Context, id string, userID int) error {
+	return s.exec(ctx, utils.GetFunctionName(), RemoveCampaignMemberQuery, map[string]any{"id": id, "userID": userID},
+		id, userID)
+}
+
+func (s *campaignStorage) GetCampaignEncounters(ctx context.Context,
+	id string) ([]*models.CampaignEncounter, error) {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	rows, err := dbcall.DBCall[pgx.Rows](fnName, s.metrics, func() (pgx.Rows, error) {
+		return s.pool.Query(ctx, GetCampaignEncountersQuery, id)
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"id": id})
+		return nil, apperrors.QueryError
+	}
+	defer rows.Close()
+
+	list := make([]*models.CampaignEncounter, 0)
+
+	for rows.Next() {
+		var encounter models.CampaignEncounter
+
+		if err := rows.Scan(&encounter.ID, &encounter.UserID, &encounter.Name, &encounter.AddedBy,
+			&encounter.CreatedAt); err != nil {
+			l.RepoError(err, map[string]any{"id": id})
+			return nil, apperrors.ScanError
+		}
+
+		list = append(list, &encounter)
+	}
+
+	return list, nil
+}
+
+func (s *campaignStorage) AttachEncounter(ctx context.Context, id, encounterID string, addedBy int) error {
+	return s.exec(ctx, utils.GetFunctionName(), AttachEncounterQuery,
+		map[string]any{"id": id, "encounterID": encounterID, "userID": addedBy}, id, encounterID, addedBy)
+}
+
+func (s *campaignStorage) DetachEncounter(ctx context.Context, id, encounterID string) error {
+	return s.exec(ctx, utils.GetFunctionName(), DetachEncounterQuery,
+		map[string]any{"id": id, "encounterID": encounterID}, id, encounterID)
+}
+
+func (s *campaignStorage) GetCampaignMaps(ctx context.Context, id string) ([]*models.CampaignMap, error) {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	rows, err := dbcall.DBCall[pgx.Rows](fnName, s.metrics, func() (pgx.Rows, error) {
+		return s.pool.Query(ctx, GetCampaignMapsQuery, id)
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"id": id})
+		return nil, apperrors.QueryError
+	}
+	defer rows.Close()
+
+	list := make([]*models.CampaignMap, 0)
+
+	for rows.Next() {
+		var campaignMap models.CampaignMap
+
+		if err := rows.Scan(&campaignMap.ID, &campaignMap.UserID, &campaignMap.Name, &campaignMap.AddedBy,
+			&campaignMap.CreatedAt); err != nil {
+			l.RepoError(err, map[string]any{"id": id})
+			return nil, apperrors.ScanError
+		}
+
+		list = append(list, &campaignMap)
+	}
+
+	return list, nil
+}
+
+func (s *campaignStorage) AttachMap(ctx context.Context, id, mapID string, addedBy int) error {
+	return s.exec(ctx, utils.GetFunctionName(), AttachMapQuery,
+		map[string]any{"id": id, "mapID": mapID, "userID": addedBy}, id, mapID, addedBy)
+}
+
+func (s *campaignStorage) DetachMap(ctx context.Context, id, mapID string) error {
+	return s.exec(ctx, utils.GetFunctionName(), DetachMapQuery, map[string]any{"id": id, "mapID": mapID},
+		id, mapID)
+}
+
+func (s *campaignStorage) GetCampaignCharacters(ctx context.Context,
+	id string) ([]*models.CampaignCharacter, error) {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	rows, err := dbcall.DBCall[pgx.Rows](fnName, s.metrics, func() (pgx.Rows, error) {
+		return s.pool.Query(ctx, GetCampaignCharactersQuery, id)
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"id": id})
+		return nil, apperrors.QueryError
+	}
+	defer rows.Close()
+
+	list := make([]*models.CampaignCharacter, 0)
+
+	for rows.Next() {
+		var character models.CampaignCharacter
+
+		if err := rows.Scan(&character.ID, &character.AddedBy, &character.CreatedAt); err != nil {
+			l.RepoError(err, map[string]any{"id": id})
+			return nil, apperrors.ScanError
+		}
+
+		list = append(list, &character)
+	}
+
+	return list, nil
+}
+
+func (s *campaignStorage) AttachCharacter(ctx context.Context, id, characterID string, addedBy int) error {
+	return s.exec(ctx, utils.GetFunctionName(), AttachCharacterQuery,
+		map[string]any{"id": id, "characterID": characterID, "userID": addedBy}, id, characterID, addedBy)
+}
+
+func (s *campaignStorage) DetachCharacter(ctx context.Context, id, characterID string) error {
+	return s.exec(ctx, utils.GetFunctionName(), DetachCharacterQuery,
+		map[string]any{"id": id, "characterID": characterID}, id, characterID)
+}
+
+// HasCharacterAccess проверяет, что персонаж привязан к кампании, которую пользователь ведёт или в которой играет
+func (s *campaignStorage) HasCharacterAccess(ctx context.Context, characterID string, userID int) bool {
+	fnName := utils.GetFunctionName()
+
+	var hasAccess bool
+
+	hasAccess, _ = dbcall.DBCall[bool](fnName, s.metrics, func() (bool, error) {
+		line := s.pool.QueryRow(ctx, HasCharacterAccessQuery, characterID, userID)
+		if err := line.Scan(&hasAccess); err != nil {
+			return false, nil
+		}
+
+		return hasAccess, nil
+	})
+
+	return hasAccess
+}
+
+// exec выполняет запрос на изменение без возвращаемых строк
+func (s *campaignStorage) exec(ctx context.Context, fnName, query string, logData map[string]any,
+	args ...any) error {
+	l := logger.FromContext(ctx)
+
+	err := dbcall.ErrOnlyDBCall(fnName, s.metrics, func() error {
+		_, err := s.pool.Exec(ctx, query, args...)
+
+		return err
+	})
+	if err != nil {
+		l.RepoError(err, logData)
+		return apperrors.TxError
+	}
+
+	return nil
+}
@@ -0,0 +1,153 @@
+package usecases
+
+import (
+	"context"
+	"slices"
+	"strconv"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/schema"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils/redactor"
+)
+
+// AttachEncounter привязывает к кампании энкаунтер. Привязать можно только свой энкаунтер,
+// после этого игроки кампании могут просматривать его без скрытых от игроков данных
+func (uc *campaignUsecases) AttachEncounter(ctx context.Context, id, encounterID string, userID int) error {
+	l := logger.FromContext(ctx)
+
+	if _, err := uc.checkRole(ctx, id, userID, models.MasterCampaignRole); err != nil {
+		return err
+	}
+
+	if !uc.encounterRepo.CheckPermission(ctx, encounterID, userID, models.OwnerEncounterRole) {
+		l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"id": id, "encounterID": encounterID})
+		return apperrors.PermissionDeniedError
+	}
+
+	return uc.repo.AttachEncounter(ctx, id, encounterID, userID)
+}
+
+// GetCampaignEncounter возвращает привязанный к кампании энкаунтер в том виде, в каком его видят
+// игроки: без скрытых полей и объектов с _dmOnly. Полный энкаунтер доступен только по правам на него
+func (uc *campaignUsecases) GetCampaignEncounter(ctx context.Context, id, encounterID string,
+	userID int) (*models.Encounter, error) {
+	l := logger.FromContext(ctx)
+
+	if _, err := uc.checkRole(ctx, id, userID, models.PlayerCampaignRole); err != nil {
+		return nil, err
+	}
+
+	encounters, err := uc.repo.GetCampaignEncounters(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	attached := slices.ContainsFunc(encounters, func(encounter *models.CampaignEncounter) bool {
+		return encounter.ID == encounterID
+	})
+	if !attached {
+		l.UsecasesWarn(apperrors.EncounterNotFoundErr, userID, map[string]any{"id": id, "encounterID": encounterID})
+		return nil, apperrors.EncounterNotFoundErr
+	}
+
+	encounter, err := uc.encounterRepo.GetEncounterByID(ctx, encounterID)
+	if err != nil {
+		return nil, err
+	}
+
+	data, _, err := schema.Migrate(encounter.Data)
+	if err != nil {
+		l.UsecasesWarn(err, userID, map[string]any{"id": id, "encounterID": encounterID})
+		data = encounter.Data
+	}
+
+	redacted, err := redactor.Redact(data)
+	if err != nil {
+		l.UsecasesError(err, userID, map[string]any{"id": id, "encounterID": encounterID})
+		return nil, err
+	}
+
+	encounter.Data = redacted
+
+	return encounter, nil
+}
+
+func (uc *campaignUsecases) DetachEncounter(ctx context.Context, id, encounterID string, userID int) error {
+	if _, err := uc.checkRole(ctx, id, userID, models.MasterCampaignRole); err != nil {
+		return err
+	}
+
+	return uc.repo.DetachEncounter(ctx, id, encounterID)
+}
+
+// AttachMap привязывает к кампании карту мастера, игроки получают к ней доступ на просмотр
+func (uc *campaignUsecases) AttachMap(ctx context.Context, id, mapID string, userID int) error {
+	l := logger.FromContext(ctx)
+
+	if _, err := uc.checkRole(ctx, id, userID, models.MasterCampaignRole); err != nil {
+		return err
+	}
+
+	if !uc.mapsRepo.CheckPermission(ctx, mapID, userID) {
+		l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"id": id, "mapID": mapID})
+		return apperrors.PermissionDeniedError
+	}
+
+	return uc.repo.AttachMap(ctx, id, mapID, userID)
+}
+
+func (uc *campaignUsecases) DetachMap(ctx context.Context, id, mapID string, userID int) error {
+	if _, err := uc.checkRole(ctx, id, userID, models.MasterCampaignRole); err != nil {
+		return err
+	}
+
+	return uc.repo.DetachMap(ctx, id, mapID)
+}
+
+// AttachCharacter привязывает к кампании персонажа. Своих персонажей привязывают и мастер, и игроки
+func (uc *campaignUsecases) AttachCharacter(ctx context.Context, id, characterID string, userID int) error {
+	if _, err := uc.checkRole(ctx, id, userID, models.PlayerCampaignRole); err != nil {
+		return err
+	}
+
+	if err := uc.checkCharacterOwner(ctx, id, characterID, userID); err != nil {
+		return err
+	}
+
+	return uc.repo.AttachCharacter(ctx, id, characterID, userID)
+}
+
+// DetachCharacter отвязывает персонажа. Мастер может отвязать любого, игрок — только своего
+func (uc *campaignUsecases) DetachCharacter(ctx context.Context, id, characterID string, userID int) error {
+	role, err := uc.checkRole(ctx, id, userID, models.PlayerCampaignRole)
+	if err != nil {
+		return err
+	}
+
+	if role != models.MasterCampaignRole {
+		if err := uc.checkCharacterOwner(ctx, id, characterID, userID); err != nil {
+			return err
+		}
+	}
+
+	return uc.repo.DetachCharacter(ctx, id, characterID)
+}
+
+func (uc *campaignUsecases) checkCharacterOwner(ctx context.Context, id, characterID string, userID int) error {
+	l := logger.FromContext(ctx)
+
+	character, err := uc.characterRepo.GetCharacterByMongoId(ctx, characterID)
+	if err != nil {
+		l.UsecasesWarn(err, userID, map[string]any{"id": id, "characterID": characterID})
+		return err
+	}
+
+	if character == nil || character.UserID != strconv.Itoa(userID) {
+		l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"id": id, "characterID": characterID})
+		return apperrors.PermissionDeniedError
+	}
+
+	return nil
+}
@@ -0,0 +1,248 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	campaigninterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/campaign"
+	characterinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/character"
+	encounterinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	mapsinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/maps"
+	tableinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table"
+)
+
+const (
+	maxCampaignName        = 60
+	maxCampaignDescription = 2000
+	maxCampaignsPage       = 100
+)
+
+type campaignUsecases struct {
+	repo          campaigninterfaces.CampaignRepository
+	encounterRepo encounterinterfaces.EncounterRepository
+	mapsRepo      mapsinterfaces.MapsRepository
+	characterRepo characterinterfaces.CharacterRepository
+	tableManager  tableinterfaces.TableManager
+}
+
+func NewCampaignUsecases(repo campaigninterfaces.CampaignRepository,
+	encounterRepo encounterinterfaces.EncounterRepository,
+	mapsRepo mapsinterfaces.MapsRepository,
+	characterRepo characterinterfaces.CharacterRepository,
+	tableManager tableinterfaces.TableManager) campaigninterfaces.CampaignUsecases {
+	return &campaignUsecases{
+		repo:          repo,
+		encounterRepo: encounterRepo,
+		mapsRepo:      mapsRepo,
+		characterRepo: characterRepo,
+		tableManager:  tableManager,
+	}
+}
+
+// GetCampaigns возвращает кампании, которые пользователь ведёт или в которых играет
+func (uc *campaignUsecases) GetCampaigns(ctx context.Context, size, start,
+	userID int) (*models.CampaignsList, error) {
+	l := logger.FromContext(ctx)
+
+	if start < 0 || size <= 0 {
+		l.UsecasesWarn(apperrors.StartPosSizeError, userID, map[string]any{"start": start, "size": size})
+		return nil, apperrors.StartPosSizeError
+	}
+
+	return uc.repo.GetCampaigns(ctx, min(size, maxCampaignsPage), start, userID)
+}
+
+func (uc *campaignUsecases) GetCampaign(ctx context.Context, id string, userID int) (*models.Campaign, error) {
+	role, err := uc.checkRole(ctx, id, userID, models.PlayerCampaignRole)
+	if err != nil {
+		return nil, err
+	}
+
+	campaign, err := uc.repo.GetCampaignByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	campaign.Role = role
+
+	return campaign, nil
+}
+
+func (uc *campaignUsecases) CreateCampaign(ctx context.Context, req *models.CampaignReq,
+	userID int) (*models.Campaign, error) {
+	l := logger.FromContext(ctx)
+
+	req, err := normalizeCampaignReq(req)
+	if err != nil {
+		l.UsecasesWarn(err, userID, map[string]any{"name": req.Name})
+		return nil, err
+	}
+
+	campaign := &models.Campaign{
+		UserID:      userID,
+		Name:        req.Name,
+		Description: req.Description,
+		Role:        models.MasterCampaignRole,
+	}
+
+	if err := uc.repo.CreateCampaign(ctx, campaign); err != nil {
+		return nil, err
+	}
+
+	return campaign, nil
+}
+
+func (uc *campaignUsecases) UpdateCampaign(ctx context.Context, id string, req *models.CampaignReq,
+	userID int) error {
+	l := logger.FromContext(ctx)
+
+	if _, err := uc.checkRole(ctx, id, userID, models.MasterCampaignRole); err != nil {
+		return err
+	}
+
+	req, err := normalizeCampaignReq(req)
+	if err != nil {
+		l.UsecasesWarn(err, userID, map[string]any{"id": id, "name": req.Name})
+		return err
+	}
+
+	return uc.repo.UpdateCampaign(ctx, id, req)
+}
+
+func (uc *campaignUsecases) RemoveCampaign(ctx context.Context, id string, userID int) error {
+	if _, err := uc.checkRole(ctx, id, userID, models.MasterCampaignRole); err != nil {
+		return err
+	}
+
+	return uc.repo.RemoveCampaign(ctx, id)
+}
+
+// GetCampaignDashboard собирает кампанию, её состав, привязанные энкаунтеры, карты, персонажей
+// и активные сессии стола по энкаунтерам кампании
+func (uc *campaignUsecases) GetCampaignDashboard(ctx context.Context, id string,
+	userID int) (*models.CampaignDashboard, error) {
+	l := logger.FromContext(ctx)
+
+	campaign, err := uc.GetCampaign(ctx, id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := uc.repo.GetCampaignMembers(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	encounters, err := uc.repo.GetCampaignEncounters(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	maps, err := uc.repo.GetCampaignMaps(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	characters, err := uc.repo.GetCampaignCharacters(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Персонаж, удалённый из хранилища или недоступный, остаётся в списке без имени
+	for _, character := range characters {
+		full, err := uc.characterRepo.GetCharacterByMongoId(ctx, character.ID)
+		if err != nil {
+			l.UsecasesWarn(err, userID, map[string]any{"id": id, "characterID": character.ID})
+			continue
+		}
+
+		if full == nil {
+			continue
+		}
+
+		character.Name = full.Data.Name.Value
+	}
+
+	return &models.CampaignDashboard{
+		Campaign:   campaign,
+		Members:    *members,
+		Encounters: encounters,
+		Maps:       maps,
+		Characters: characters,
+		Sessions:   uc.campaignSessions(ctx, encounters, userID),
+	}, nil
+}
+
+// campaignSessions ищет активные сессии стола по энкаунтерам кампании. Ошибка хранилища сессий
+// не мешает показать остальную кампанию
+func (uc *campaignUsecases) campaignSessions(ctx context.Context, encounters []*models.CampaignEncounter,
+	userID int) []*models.CampaignSession {
+	l := logger.FromContext(ctx)
+
+	sessions := make([]*models.CampaignSession, 0)
+	if len(encounters) == 0 {
+		return sessions
+	}
+
+	ids := make([]string, 0, len(encounters))
+	for _, encounter := range encounters {
+		ids = append(ids, encounter.ID)
+	}
+
+	snapshots, err := uc.tableManager.FindEncounterSessions(ctx, ids)
+	if err != nil {
+		l.UsecasesWarn(err, userID, map[string]any{"encounters": ids})
+		return sessions
+	}
+
+	for _, snapshot := range snapshots {
+		sessions = append(sessions, &models.CampaignSession{
+			SessionID:       snapshot.SessionID,
+			EncounterID:     snapshot.EncounterID,
+			EncounterName:   snapshot.EncounterName,
+			AdminName:       snapshot.AdminName,
+			ParticipantsNum: snapshot.ParticipantsNum,
+			StartedAt:       snapshot.StartedAt,
+			Paused:          snapshot.Paused,
+		})
+	}
+
+	return sessions
+}
+
+// checkRole возвращает роль пользователя в кампании. Мастер проходит любую проверку,
+// игроку доступно только то, что требует роли player
+func (uc *campaignUsecases) checkRole(ctx context.Context, id string, userID int,
+	required models.CampaignRole) (models.CampaignRole, error) {
+	l := logger.FromContext(ctx)
+
+	role, err := uc.repo.GetCampaignRole(ctx, id, userID)
+	if err != nil {
+		return "", err
+	}
+
+	if role == "" || (required == models.MasterCampaignRole && role != models.MasterCampaignRole) {
+		l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"id": id, "role": role})
+		return "", apperrors.PermissionDeniedError
+	}
+
+	return role, nil
+}
+
+func normalizeCampaignReq(req *models.CampaignReq) (*models.CampaignReq, error) {
+	normalized := &models.CampaignReq{
+		Name:        strings.TrimSpace(req.Name),
+		Description: strings.TrimSpace(req.Description),
+	}
+
+	if normalized.Name == "" || utf8.RuneCountInString(normalized.Name) > maxCampaignName ||
+		utf8.RuneCountInString(normalized.Description) > maxCampaignDescription {
+		return req, apperrors.InvalidCampaignErr
+	}
+
+	return normalized, nil
+}
@@ -0,0 +1,383 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/campaign/mocks"
+	charactermocks "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/character/mocks"
+	encountermocks "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/mocks"
+	mapsmocks "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/maps/mocks"
+	tablemocks "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+const campaignID = "00000000-0000-0000-0000-00000000000c"
+
+type campaignMocks struct {
+	repo          *mocks.MockCampaignRepository
+	encounterRepo *encountermocks.MockEncounterRepository
+	mapsRepo      *mapsmocks.MockMapsRepository
+	characterRepo *charactermocks.MockCharacterRepository
+	tableManager  *tablemocks.MockTableManager
+}
+
+func newCampaignMocks(ctrl *gomock.Controller) *campaignMocks {
+	return &campaignMocks{
+		repo:          mocks.NewMockCampaignRepository(ctrl),
+		encounterRepo: encountermocks.NewMockEncounterRepository(ctrl),
+		mapsRepo:      mapsmocks.NewMockMapsRepository(ctrl),
+		characterRepo: charactermocks.NewMockCharacterRepository(ctrl),
+		tableManager:  tablemocks.NewMockTableManager(ctrl),
+	}
+}
+
+func (m *campaignMocks) usecases() *campaignUsecases {
+	return NewCampaignUsecases(m.repo, m.encounterRepo, m.mapsRepo, m.characterRepo,
+		m.tableManager).(*campaignUsecases)
+}
+
+func TestCreateCampaign(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		req     *models.CampaignReq
+		setup   func(m *campaignMocks)
+		wantErr error
+	}{
+		{
+			name:    "blank name returns InvalidCampaignErr",
+			req:     &models.CampaignReq{Name: "   "},
+			setup:   func(_ *campaignMocks) {},
+			wantErr: apperrors.InvalidCampaignErr,
+		},
+		{
+			name:    "too long description returns InvalidCampaignErr",
+			req:     &models.CampaignReq{Name: "Curse of Strahd", Description: strings.Repeat("я", 2001)},
+			setup:   func(_ *campaignMocks) {},
+			wantErr: apperrors.InvalidCampaignErr,
+		},
+		{
+			name: "trimmed campaign is stored",
+			req:  &models.CampaignReq{Name: "  Curse of Strahd ", Description: " Barovia "},
+			setup: func(m *campaignMocks) {
+				m.repo.EXPECT().CreateCampaign(gomock.Any(), &models.Campaign{
+					UserID:      1,
+					Name:        "Curse of Strahd",
+					Description: "Barovia",
+					Role:        models.MasterCampaignRole,
+				}).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			m := newCampaignMocks(ctrl)
+			tt.setup(m)
+
+			_, err := m.usecases().CreateCampaign(context.Background(), tt.req, 1)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestUpdateCampaign_PlayerCannotEdit(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	m := newCampaignMocks(ctrl)
+	m.repo.EXPECT().GetCampaignRole(gomock.Any(), campaignID, 2).Return(models.PlayerCampaignRole, nil)
+
+	err := m.usecases().UpdateCampaign(context.Background(), campaignID, &models.CampaignReq{Name: "New"}, 2)
+	assert.ErrorIs(t, err, apperrors.PermissionDeniedError)
+}
+
+func TestRemoveCampaignMember(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		targetID int
+		userID   int
+		setup    func(m *campaignMocks)
+		wantErr  error
+	}{
+		{
+			name:     "stranger cannot see the campaign",
+			targetID: 3,
+			userID:   3,
+			setup: func(m *campaignMocks) {
+				m.repo.EXPECT().GetCampaignRole(gomock.Any(), campaignID, 3).Return(models.CampaignRole(""), nil)
+			},
+			wantErr: apperrors.PermissionDeniedError,
+		},
+		{
+			name:     "player cannot remove another player",
+			targetID: 3,
+			userID:   2,
+			setup: func(m *campaignMocks) {
+				m.repo.EXPECT().GetCampaignRole(gomock.Any(), campaignID, 2).Return(models.PlayerCampaignRole, nil)
+			},
+			wantErr: apperrors.PermissionDeniedError,
+		},
+		{
+			name:     "player can leave the campaign",
+			targetID: 2,
+			userID:   2,
+			setup: func(m *campaignMocks) {
+				m.repo.EXPECT().GetCampaignRole(gomock.Any(), campaignID, 2).Return(models.PlayerCampaignRole, nil)
+				m.repo.EXPECT().RemoveCampaignMember(gomock.Any(), campaignID, 2).Return(nil)
+			},
+		},
+		{
+			name:     "master cannot leave own campaign",
+			targetID: 1,
+			userID:   1,
+			setup: func(m *campaignMocks) {
+				m.repo.EXPECT().GetCampaignRole(gomock.Any(), campaignID, 1).Return(models.MasterCampaignRole, nil)
+			},
+			wantErr: apperrors.InvalidCampaignMemberErr,
+		},
+		{
+			name:     "master removes a player",
+			targetID: 2,
+			userID:   1,
+			setup: func(m *campaignMocks) {
+				m.repo.EXPECT().GetCampaignRole(gomock.Any(), campaignID, 1).Return(models.MasterCampaignRole, nil)
+				m.repo.EXPECT().RemoveCampaignMember(gomock.Any(), campaignID, 2).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			m := newCampaignMocks(ctrl)
+			tt.setup(m)
+
+			err := m.usecases().RemoveCampaignMember(context.Background(), campaignID, tt.targetID, tt.userID)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestAttachEncounter_RequiresOwnEncounter(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	m := newCampaignMocks(ctrl)
+	m.repo.EXPECT().GetCampaignRole(gomock.Any(), campaignID, 1).Return(models.MasterCampaignRole, nil)
+	m.encounterRepo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.OwnerEncounterRole).Return(false)
+
+	err := m.usecases().AttachEncounter(context.Background(), campaignID, "enc-1", 1)
+	assert.ErrorIs(t, err, apperrors.PermissionDeniedError)
+}
+
+func TestGetCampaignEncounter(t *testing.T) {
+	t.Parallel()
+
+	attached := []*models.CampaignEncounter{{ID: "enc-1", UserID: 1, Name: "Crypt"}}
+	data := `{"schemaVersion":1,"_hidden":["notes"],"notes":"the priest is a vampire",` +
+		`"participants":[{"name":"Ally"},{"name":"Assassin","_dmOnly":true}]}`
+
+	tests := []struct {
+		name        string
+		encounterID string
+		setup       func(m *campaignMocks)
+		want        string
+		wantErr     error
+	}{
+		{
+			name:        "player reads attached encounter without hidden data",
+			encounterID: "enc-1",
+			setup: func(m *campaignMocks) {
+				m.repo.EXPECT().GetCampaignRole(gomock.Any(), campaignID, 2).Return(models.PlayerCampaignRole, nil)
+				m.repo.EXPECT().GetCampaignEncounters(gomock.Any(), campaignID).Return(attached, nil)
+				m.encounterRepo.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").
+					Return(&models.Encounter{UUID: "enc-1", Data: json.RawMessage(data)}, nil)
+			},
+			want: `{"schemaVersion":1,"participants":[{"name":"Ally"},null]}`,
+		},
+		{
+			name:        "encounter not attached to the campaign",
+			encounterID: "enc-2",
+			setup: func(m *campaignMocks) {
+				m.repo.EXPECT().GetCampaignRole(gomock.Any(), campaignID, 2).Return(models.PlayerCampaignRole, nil)
+				m.repo.EXPECT().GetCampaignEncounters(gomock.Any(), campaignID).Return(attached, nil)
+			},
+			wantErr: apperrors.EncounterNotFoundErr,
+		},
+		{
+			name:        "not a campaign member",
+			encounterID: "enc-1",
+			setup: func(m *campaignMocks) {
+				m.repo.EXPECT().GetCampaignRole(gomock.Any(), campaignID, 2).Return(models.CampaignRole(""), nil)
+			},
+			wantErr: apperrors.PermissionDeniedError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			m := newCampaignMocks(ctrl)
+			tt.setup(m)
+
+			got, err := m.usecases().GetCampaignEncounter(context.Background(), campaignID, tt.encounterID, 2)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, got)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.want, string(got.Data))
+		})
+	}
+}
+
+func TestAttachMap_StoresAttachment(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	m := newCampaignMocks(ctrl)
+	m.repo.EXPECT().GetCampaignRole(gomock.Any(), campaignID, 1).Return(models.MasterCampaignRole, nil)
+	m.mapsRepo.EXPECT().CheckPermission(gomock.Any(), "map-1", 1).Return(true)
+	m.repo.EXPECT().AttachMap(gomock.Any(), campaignID, "map-1", 1).Return(nil)
+
+	assert.NoError(t, m.usecases().AttachMap(context.Background(), campaignID, "map-1", 1))
+}
+
+func TestAttachCharacter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		setup   func(m *campaignMocks)
+		wantErr error
+	}{
+		{
+			name: "missing character returns PermissionDeniedError",
+			setup: func(m *campaignMocks) {
+				m.repo.EXPECT().GetCampaignRole(gomock.Any(), campaignID, 2).Return(models.PlayerCampaignRole, nil)
+				m.characterRepo.EXPECT().GetCharacterByMongoId(gomock.Any(), "char-1").Return(nil, nil)
+			},
+			wantErr: apperrors.PermissionDeniedError,
+		},
+		{
+			name: "public character cannot be attached",
+			setup: func(m *campaignMocks) {
+				m.repo.EXPECT().GetCampaignRole(gomock.Any(), campaignID, 2).Return(models.PlayerCampaignRole, nil)
+				m.characterRepo.EXPECT().GetCharacterByMongoId(gomock.Any(), "char-1").
+					Return(&models.Character{UserID: "*"}, nil)
+			},
+			wantErr: apperrors.PermissionDeniedError,
+		},
+		{
+			name: "player attaches own character",
+			setup: func(m *campaignMocks) {
+				m.repo.EXPECT().GetCampaignRole(gomock.Any(), campaignID, 2).Return(models.PlayerCampaignRole, nil)
+				m.characterRepo.EXPECT().GetCharacterByMongoId(gomock.Any(), "char-1").
+					Return(&models.Character{UserID: "2"}, nil)
+				m.repo.EXPECT().AttachCharacter(gomock.Any(), campaignID, "char-1", 2).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			m := newCampaignMocks(ctrl)
+			tt.setup(m)
+
+			err := m.usecases().AttachCharacter(context.Background(), campaignID, "char-1", 2)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestDetachCharacter_MasterSkipsOwnerCheck(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	m := newCampaignMocks(ctrl)
+	m.repo.EXPECT().GetCampaignRole(gomock.Any(), campaignID, 1).Return(models.MasterCampaignRole, nil)
+	m.repo.EXPECT().DetachCharacter(gomock.Any(), campaignID, "char-1").Return(nil)
+
+	assert.NoError(t, m.usecases().DetachCharacter(context.Background(), campaignID, "char-1", 1))
+}
+
+func TestGetCampaignDashboard(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	m := newCampaignMocks(ctrl)
+
+	character := &models.Character{UserID: "2"}
+	character.Data.Name.Value = "Ireena"
+
+	m.repo.EXPECT().GetCampaignRole(gomock.Any(), campaignID, 2).Return(models.PlayerCampaignRole, nil)
+	m.repo.EXPECT().GetCampaignByID(gomock.Any(), campaignID).
+		Return(&models.Campaign{ID: campaignID, UserID: 1, Name: "Curse of Strahd"}, nil)
+	m.repo.EXPECT().GetCampaignMembers(gomock.Any(), campaignID).
+		Return(&models.CampaignMembersList{{UserID: 2, DisplayName: "Player"}}, nil)
+	m.repo.EXPECT().GetCampaignEncounters(gomock.Any(), campaignID).
+		Return([]*models.CampaignEncounter{{ID: "enc-1"}, {ID: "enc-2"}}, nil)
+	m.repo.EXPECT().GetCampaignMaps(gomock.Any(), campaignID).Return([]*models.CampaignMap{{ID: "map-1"}}, nil)
+	m.repo.EXPECT().GetCampaignCharacters(gomock.Any(), campaignID).
+		Return([]*models.CampaignCharacter{{ID: "char-1"}, {ID: "char-2"}}, nil)
+	m.characterRepo.EXPECT().GetCharacterByMongoId(gomock.Any(), "char-1").Return(character, nil)
+	m.characterRepo.EXPECT().GetCharacterByMongoId(gomock.Any(), "char-2").Return(nil, apperrors.InvalidIDErr)
+	m.tableManager.EXPECT().FindEncounterSessions(gomock.Any(), []string{"enc-1", "enc-2"}).
+		Return([]*models.TableSessionSnapshot{{SessionID: "session-1", EncounterID: "enc-2"}}, nil)
+
+	dashboard, err := m.usecases().GetCampaignDashboard(context.Background(), campaignID, 2)
+	assert.NoError(t, err)
+
+	assert.Equal(t, models.PlayerCampaignRole, dashboard.Campaign.Role)
+	assert.Len(t, dashboard.Members, 1)
+	assert.Len(t, dashboard.Encounters, 2)
+	assert.Len(t, dashboard.Maps, 1)
+	assert.Equal(t, "Ireena", dashboard.Characters[0].Name)
+	assert.Empty(t, dashboard.Characters[1].Name)
+	assert.Equal(t, []*models.CampaignSession{{SessionID: "session-1", EncounterID: "enc-2"}}, dashboard.Sessions)
+}
+
+func TestGetCampaignDashboard_SessionStoreErrorKeepsDashboard(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	m := newCampaignMocks(ctrl)
+
+	m.repo.EXPECT().GetCampaignRole(gomock.Any(), campaignID, 1).Return(models.MasterCampaignRole, nil)
+	m.repo.EXPECT().GetCampaignByID(gomock.Any(), campaignID).Return(&models.Campaign{ID: campaignID}, nil)
+	m.repo.EXPECT().GetCampaignMembers(gomock.Any(), campaignID).Return(&models.CampaignMembersList{}, nil)
+	m.repo.EXPECT().GetCampaignEncounters(gomock.Any(), campaignID).
+		Return([]*models.CampaignEncounter{{ID: "enc-1"}}, nil)
+	m.repo.EXPECT().GetCampaignMaps(gomock.Any(), campaignID).Return([]*models.CampaignMap{}, nil)
+	m.repo.EXPECT().GetCampaignCharacters(gomock.Any(), campaignID).Return([]*models.CampaignCharacter{}, nil)
+	m.tableManager.EXPECT().FindEncounterSessions(gomock.Any(), []string{"enc-1"}).
+		Return(nil, errors.New("redis down"))
+
+	dashboard, err := m.usecases().GetCampaignDashboard(context.Background(), campaignID, 1)
+	assert.NoError(t, err)
+	assert.Empty(t, dashboard.Sessions)
+}
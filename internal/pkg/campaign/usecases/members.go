@@ -0,0 +1,57 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+)
+
+func (uc *campaignUsecases) GetCampaignMembers(ctx context.Context, id string,
+	userID int) (*models.CampaignMembersList, error) {
+	if _, err := uc.checkRole(ctx, id, userID, models.PlayerCampaignRole); err != nil {
+		return nil, err
+	}
+
+	return uc.repo.GetCampaignMembers(ctx, id)
+}
+
+// AddCampaignMember добавляет игрока в кампанию. Состав меняет только мастер
+func (uc *campaignUsecases) AddCampaignMember(ctx context.Context, id string, targetID, userID int) error {
+	l := logger.FromContext(ctx)
+
+	if _, err := uc.checkRole(ctx, id, userID, models.MasterCampaignRole); err != nil {
+		return err
+	}
+
+	if targetID == userID {
+		l.UsecasesWarn(apperrors.InvalidCampaignMemberErr, userID, map[string]any{"id": id})
+		return apperrors.InvalidCampaignMemberErr
+	}
+
+	return uc.repo.AddCampaignMember(ctx, id, targetID, userID)
+}
+
+// RemoveCampaignMember исключает игрока из кампании. Игрок может и сам покинуть кампанию
+func (uc *campaignUsecases) RemoveCampaignMember(ctx context.Context, id string, targetID, userID int) error {
+	l := logger.FromContext(ctx)
+
+	required := models.MasterCampaignRole
+	if targetID == userID {
+		required = models.PlayerCampaignRole
+	}
+
+	role, err := uc.checkRole(ctx, id, userID, required)
+	if err != nil {
+		return err
+	}
+
+	// Мастер не может покинуть свою кампанию, её можно только удалить
+	if role == models.MasterCampaignRole && targetID == userID {
+		l.UsecasesWarn(apperrors.InvalidCampaignMemberErr, userID, map[string]any{"id": id})
+		return apperrors.InvalidCampaignMemberErr
+	}
+
+	return uc.repo.RemoveCampaignMember(ctx, id, targetID)
+}
@@ -10,16 +10,20 @@ import (
 
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	campaigninterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/campaign"
 	characterinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/character"
 )
 
 type characterUsecases struct {
-	repo characterinterfaces.CharacterRepository
+	repo         characterinterfaces.CharacterRepository
+	campaignRepo campaigninterfaces.CampaignRepository
 }
 
-func NewCharacterUsecases(repo characterinterfaces.CharacterRepository) characterinterfaces.CharacterUsecases {
+func NewCharacterUsecases(repo characterinterfaces.CharacterRepository,
+	campaignRepo campaigninterfaces.CampaignRepository) characterinterfaces.CharacterUsecases {
 	return &characterUsecases{
-		repo: repo,
+		repo:         repo,
+		campaignRepo: campaignRepo,
 	}
 }
 
@@ -75,7 +79,14 @@ func (uc *characterUsecases) GetCharacterByMongoId(ctx context.Context, id strin
 		return nil, err
 	}
 
-	if character.UserID != "*" && character.UserID != strconv.Itoa(userID) {
+	if character == nil {
+		l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"id": id})
+		return nil, apperrors.PermissionDeniedError
+	}
+
+	// Персонажей, привязанных к кампании, видят её мастер и игроки
+	if character.UserID != "*" && character.UserID != strconv.Itoa(userID) &&
+		!uc.campaignRepo.HasCharacterAccess(ctx, id, userID) {
 		l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"id": id})
 		return nil, apperrors.PermissionDeniedError
 	}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	campaignmocks "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/campaign/mocks"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/character/mocks"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -94,7 +95,7 @@ func TestGetCharactersList(t *testing.T) {
 			repo := mocks.NewMockCharacterRepository(ctrl)
 			tt.setup(repo)
 
-			uc := NewCharacterUsecases(repo)
+			uc := NewCharacterUsecases(repo, nil)
 			result, err := uc.GetCharactersList(context.Background(), tt.size, tt.start, 1, models.SearchParams{})
 
 			if tt.wantErr != nil {
@@ -124,7 +125,7 @@ func TestGetCharacterByMongoId(t *testing.T) {
 		name    string
 		id      string
 		userID  int
-		setup   func(repo *mocks.MockCharacterRepository)
+		setup   func(repo *mocks.MockCharacterRepository, campaignRepo *campaignmocks.MockCampaignRepository)
 		wantErr error
 		wantNil bool
 	}{
@@ -132,7 +133,7 @@ func TestGetCharacterByMongoId(t *testing.T) {
 			name:    "empty id returns InvalidInputError",
 			id:      "",
 			userID:  1,
-			setup:   func(_ *mocks.MockCharacterRepository) {},
+			setup:   func(_ *mocks.MockCharacterRepository, _ *campaignmocks.MockCampaignRepository) {},
 			wantErr: apperrors.InvalidInputError,
 			wantNil: true,
 		},
@@ -140,7 +141,7 @@ func TestGetCharacterByMongoId(t *testing.T) {
 			name:   "happy path returns own character",
 			id:     "abc123",
 			userID: 1,
-			setup: func(repo *mocks.MockCharacterRepository) {
+			setup: func(repo *mocks.MockCharacterRepository, campaignRepo *campaignmocks.MockCampaignRepository) {
 				repo.EXPECT().GetCharacterByMongoId(gomock.Any(), "abc123").Return(ownedChar, nil)
 			},
 		},
@@ -148,7 +149,7 @@ func TestGetCharacterByMongoId(t *testing.T) {
 			name:   "public character accessible by any user",
 			id:     "abc123",
 			userID: 42,
-			setup: func(repo *mocks.MockCharacterRepository) {
+			setup: func(repo *mocks.MockCharacterRepository, campaignRepo *campaignmocks.MockCampaignRepository) {
 				repo.EXPECT().GetCharacterByMongoId(gomock.Any(), "abc123").Return(publicChar, nil)
 			},
 		},
@@ -156,8 +157,28 @@ func TestGetCharacterByMongoId(t *testing.T) {
 			name:   "other user's character returns PermissionDeniedError",
 			id:     "abc123",
 			userID: 1,
-			setup: func(repo *mocks.MockCharacterRepository) {
+			setup: func(repo *mocks.MockCharacterRepository, campaignRepo *campaignmocks.MockCampaignRepository) {
+				repo.EXPECT().GetCharacterByMongoId(gomock.Any(), "abc123").Return(otherUserChar, nil)
+				campaignRepo.EXPECT().HasCharacterAccess(gomock.Any(), "abc123", 1).Return(false)
+			},
+			wantErr: apperrors.PermissionDeniedError,
+			wantNil: true,
+		},
+		{
+			name:   "other user's character in a shared campaign is accessible",
+			id:     "abc123",
+			userID: 1,
+			setup: func(repo *mocks.MockCharacterRepository, campaignRepo *campaignmocks.MockCampaignRepository) {
 				repo.EXPECT().GetCharacterByMongoId(gomock.Any(), "abc123").Return(otherUserChar, nil)
+				campaignRepo.EXPECT().HasCharacterAccess(gomock.Any(), "abc123", 1).Return(true)
+			},
+		},
+		{
+			name:   "missing character returns PermissionDeniedError",
+			id:     "abc123",
+			userID: 1,
+			setup: func(repo *mocks.MockCharacterRepository, _ *campaignmocks.MockCampaignRepository) {
+				repo.EXPECT().GetCharacterByMongoId(gomock.Any(), "abc123").Return(nil, nil)
 			},
 			wantErr: apperrors.PermissionDeniedError,
 			wantNil: true,
@@ -166,7 +187,7 @@ func TestGetCharacterByMongoId(t *testing.T) {
 			name:   "repo error is propagated",
 			id:     "abc123",
 			userID: 1,
-			setup: func(repo *mocks.MockCharacterRepository) {
+			setup: func(repo *mocks.MockCharacterRepository, campaignRepo *campaignmocks.MockCampaignRepository) {
 				repo.EXPECT().GetCharacterByMongoId(gomock.Any(), "abc123").Return(nil, repoErr)
 			},
 			wantErr: repoErr,
@@ -180,9 +201,10 @@ func TestGetCharacterByMongoId(t *testing.T) {
 
 			ctrl := gomock.NewController(t)
 			repo := mocks.NewMockCharacterRepository(ctrl)
-			tt.setup(repo)
+			campaignRepo := campaignmocks.NewMockCampaignRepository(ctrl)
+			tt.setup(repo, campaignRepo)
 
-			uc := NewCharacterUsecases(repo)
+			uc := NewCharacterUsecases(repo, campaignRepo)
 			result, err := uc.GetCharacterByMongoId(context.Background(), tt.id, tt.userID)
 
 			if tt.wantErr != nil {
@@ -250,7 +272,7 @@ func TestAddCharacter(t *testing.T) {
 			repo := mocks.NewMockCharacterRepository(ctrl)
 			tt.setup(repo)
 
-			uc := NewCharacterUsecases(repo)
+			uc := NewCharacterUsecases(repo, nil)
 			err := uc.AddCharacter(context.Background(), newFakeFile(tt.fileData), 1)
 
 			if tt.wantErr != nil {
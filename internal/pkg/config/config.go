@@ -106,6 +106,29 @@ type SessionConfig struct {
 	Duration time.Duration `yaml:"duration" env:"SESSION_DURATION" env-default:"720h"`
 }
 
+type TableConfig struct {
+	// Store выбирает хранилище снапшотов игровых сессий: "redis" или "memory"
+	Store            string        `yaml:"store" env:"TABLE_STORE" env-default:"redis"`
+	SnapshotInterval time.Duration `yaml:"snapshot_interval" env:"TABLE_SNAPSHOT_INTERVAL" env-default:"30s"`
+	SnapshotTTL      time.Duration `yaml:"snapshot_ttl" env:"TABLE_SNAPSHOT_TTL" env-default:"24h"`
+	// Broker выбирает шину сообщений между репликами: "redis" или "memory" для одной реплики
+	Broker  string        `yaml:"broker" env:"TABLE_BROKER" env-default:"redis"`
+	LockTTL time.Duration `yaml:"lock_ttl" env:"TABLE_LOCK_TTL" env-default:"30s"`
+	// ReconnectGrace — сколько место участника сохраняется после обрыва соединения
+	ReconnectGrace time.Duration `yaml:"reconnect_grace" env:"TABLE_RECONNECT_GRACE" env-default:"10s"`
+	// DeltaBroadcasts включает рассылку изменений энкаунтера разницей. Если выключено, после каждого
+	// изменения участники получают полные данные
+	DeltaBroadcasts bool `yaml:"delta_broadcasts" env:"TABLE_DELTA_BROADCASTS" env-default:"true"`
+	// InviteSecret подписывает приглашения в сессии и должен совпадать на всех репликах
+	InviteSecret string `env:"TABLE_INVITE_SECRET"`
+}
+
+type EncounterConfig struct {
+	// TrashRetention — сколько удалённые энкаунтеры хранятся в корзине до окончательного удаления
+	TrashRetention     time.Duration `yaml:"trash_retention" env:"ENCOUNTER_TRASH_RETENTION" env-default:"720h"`
+	TrashPurgeInterval time.Duration `yaml:"trash_purge_interval" env:"ENCOUNTER_TRASH_PURGE_INTERVAL" env-default:"1h"`
+}
+
 type LoggerConfig struct {
 	// Deprecated: Key is no longer used. The logger context key is now a typed
 	// struct (logger.loggerCtxKey) and does not need external configuration.
@@ -116,8 +139,10 @@ type LoggerConfig struct {
 }
 
 type Config struct {
-	Server  ServerConfig  `yaml:"server"`
-	Session SessionConfig `yaml:"session"`
+	Server    ServerConfig    `yaml:"server"`
+	Session   SessionConfig   `yaml:"session"`
+	Table     TableConfig     `yaml:"table"`
+	Encounter EncounterConfig `yaml:"encounter"`
 
 	Mongo    MongoConfig
 	Postgres PostgresConfig
@@ -10,6 +10,7 @@ import (
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/delivery/responses"
 	"github.com/gorilla/mux"
 	"io"
+	"mime"
 	"net/http"
 )
 
@@ -42,7 +43,8 @@ func (h *EncounterHandler) GetEncountersList(w http.ResponseWriter, r *http.Requ
 	user := ctx.Value(h.ctxUserKey).(*models.User)
 	userID := user.ID
 
-	list, err := h.usecases.GetEncountersList(ctx, reqData.Size, reqData.Start, userID, &reqData.Search)
+	list, err := h.usecases.GetEncountersList(ctx, reqData.Size, reqData.Start, userID, &reqData.Filter,
+		&reqData.Order, &reqData.Search)
 	if err != nil {
 		var code int
 		var status string
@@ -51,6 +53,9 @@ func (h *EncounterHandler) GetEncountersList(w http.ResponseWriter, r *http.Requ
 		case errors.Is(err, apperrors.StartPosSizeError):
 			code = responses.StatusBadRequest
 			status = responses.ErrSizeOrPosition
+		case errors.Is(err, apperrors.InvalidEncounterOrderErr):
+			code = responses.StatusBadRequest
+			status = responses.ErrWrongOrder
 		default:
 			code = responses.StatusInternalServerError
 			status = responses.ErrInternalServer
@@ -130,6 +135,15 @@ func (h *EncounterHandler) SaveEncounter(w http.ResponseWriter, r *http.Request)
 		case errors.Is(err, apperrors.InvalidInputError):
 			code = responses.StatusBadRequest
 			status = responses.ErrWrongEncounterName
+		case errors.Is(err, apperrors.InvalidEncounterDataErr):
+			code = responses.StatusBadRequest
+			status = responses.ErrWrongEncounterData
+		case errors.Is(err, apperrors.InvalidEncounterTagsErr):
+			code = responses.StatusBadRequest
+			status = responses.ErrWrongTags
+		case errors.Is(err, apperrors.InvalidEncounterFolderErr):
+			code = responses.StatusBadRequest
+			status = responses.ErrWrongFolder
 		default:
 			code = responses.StatusInternalServerError
 			status = responses.ErrInternalServer
@@ -168,7 +182,7 @@ func (h *EncounterHandler) UpdateEncounter(w http.ResponseWriter, r *http.Reques
 	user := ctx.Value(h.ctxUserKey).(*models.User)
 	userID := user.ID
 
-	err = h.usecases.UpdateEncounter(ctx, data, id, userID)
+	err = h.usecases.UpdateEncounter(ctx, data, patchFormat(r), id, userID)
 	if err != nil {
 		var code int
 		var status string
@@ -177,6 +191,15 @@ func (h *EncounterHandler) UpdateEncounter(w http.ResponseWriter, r *http.Reques
 		case errors.Is(err, apperrors.PermissionDeniedError):
 			code = responses.StatusForbidden
 			status = responses.ErrForbidden
+		case errors.Is(err, apperrors.InvalidPatchErr):
+			code = responses.StatusBadRequest
+			status = responses.ErrWrongPatch
+		case errors.Is(err, apperrors.InvalidEncounterDataErr):
+			code = responses.StatusBadRequest
+			status = responses.ErrWrongEncounterData
+		case errors.Is(err, apperrors.EncounterNotFoundErr):
+			code = responses.StatusBadRequest
+			status = responses.ErrEncounterNotFound
 		default:
 			code = responses.StatusInternalServerError
 			status = responses.ErrInternalServer
@@ -191,6 +214,21 @@ func (h *EncounterHandler) UpdateEncounter(w http.ResponseWriter, r *http.Reques
 	responses.SendOkResponse(w, nil)
 }
 
+// patchFormat выбирает формат патча по Content-Type. С любым другим типом тело запроса
+// заменяет данные энкаунтера целиком
+func patchFormat(r *http.Request) models.PatchFormat {
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+
+	switch mediaType {
+	case "application/json-patch+json":
+		return models.JSONPatch
+	case "application/merge-patch+json":
+		return models.MergePatch
+	default:
+		return models.LegacyPatch
+	}
+}
+
 func (h *EncounterHandler) RemoveEncounter(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	l := logger.FromContext(ctx)
@@ -229,3 +267,89 @@ func (h *EncounterHandler) RemoveEncounter(w http.ResponseWriter, r *http.Reques
 
 	responses.SendOkResponse(w, nil)
 }
+
+func (h *EncounterHandler) GetDifficulty(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+
+	var reqData models.DifficultyReq
+
+	err := json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrBadJSON, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrBadJSON)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	result, err := h.usecases.GetDifficulty(ctx, &reqData, userID)
+	if err != nil {
+		var code int
+		var status string
+
+		switch {
+		case errors.Is(err, apperrors.PermissionDeniedError):
+			code = responses.StatusForbidden
+			status = responses.ErrForbidden
+		case errors.Is(err, apperrors.InvalidDifficultyErr):
+			code = responses.StatusBadRequest
+			status = responses.ErrWrongDifficulty
+		case errors.Is(err, apperrors.InvalidEncounterDataErr):
+			code = responses.StatusBadRequest
+			status = responses.ErrWrongEncounterData
+		default:
+			code = responses.StatusInternalServerError
+			status = responses.ErrInternalServer
+		}
+
+		l.DeliveryError(ctx, code, status, err, reqData)
+		responses.SendErrResponse(w, code, status)
+
+		return
+	}
+
+	responses.SendOkResponse(w, result)
+}
+
+func (h *EncounterHandler) GenerateEncounters(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+
+	var reqData models.GenerateEncounterReq
+
+	err := json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrBadJSON, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrBadJSON)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	result, err := h.usecases.GenerateEncounters(ctx, &reqData, userID)
+	if err != nil {
+		var code int
+		var status string
+
+		switch {
+		case errors.Is(err, apperrors.InvalidGenerateErr):
+			code = responses.StatusBadRequest
+			status = responses.ErrWrongGenerate
+		default:
+			code = responses.StatusInternalServerError
+			status = responses.ErrInternalServer
+		}
+
+		l.DeliveryError(ctx, code, status, err, reqData)
+		responses.SendErrResponse(w, code, status)
+
+		return
+	}
+
+	responses.SendOkResponse(w, result)
+}
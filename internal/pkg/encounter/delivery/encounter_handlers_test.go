@@ -7,24 +7,45 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/delivery"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/delivery/responses"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/testhelpers"
+	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
 )
 
 // --- fake usecase ---
 
 type fakeEncounterUsecases struct {
-	saveErr error
+	saveErr       error
+	updateErr     error
+	format        models.PatchFormat
+	difficulty    *models.EncounterDifficulty
+	difficultyErr error
+	generated     *models.GeneratedEncounters
+	generateErr   error
+	versionErr    error
+	restored      int
+	trashErr      error
+	purged        string
+	shareErr      error
+	sharedRole    models.EncounterRole
+	sharedWith    int
+	listOrder     *models.Order
+	listErr       error
+	copyErr       error
+	copyReq       *models.CopyEncounterReq
+	folderErr     error
 }
 
-func (f *fakeEncounterUsecases) GetEncountersList(_ context.Context, _, _, _ int,
-	_ *models.SearchParams) (*models.EncountersList, error) {
-	return nil, nil
+func (f *fakeEncounterUsecases) GetEncountersList(_ context.Context, _, _, _ int, _ *models.EncounterFilterParams,
+	order *models.Order, _ *models.SearchParams) (*models.EncountersList, error) {
+	f.listOrder = order
+	return &models.EncountersList{}, f.listErr
 }
 
 func (f *fakeEncounterUsecases) GetEncounterByID(_ context.Context, _ string, _ int) (*models.Encounter, error) {
@@ -35,14 +56,165 @@ func (f *fakeEncounterUsecases) SaveEncounter(_ context.Context, _ *models.SaveE
 	return f.saveErr
 }
 
-func (f *fakeEncounterUsecases) UpdateEncounter(_ context.Context, _ []byte, _ string, _ int) error {
-	return nil
+func (f *fakeEncounterUsecases) UpdateEncounter(_ context.Context, _ []byte, format models.PatchFormat, _ string,
+	_ int) error {
+	f.format = format
+	return f.updateErr
 }
 
 func (f *fakeEncounterUsecases) RemoveEncounter(_ context.Context, _ string, _ int) error {
 	return nil
 }
 
+func (f *fakeEncounterUsecases) GetDifficulty(_ context.Context, _ *models.DifficultyReq,
+	_ int) (*models.EncounterDifficulty, error) {
+	return f.difficulty, f.difficultyErr
+}
+
+func (f *fakeEncounterUsecases) GenerateEncounters(_ context.Context, _ *models.GenerateEncounterReq,
+	_ int) (*models.GeneratedEncounters, error) {
+	return f.generated, f.generateErr
+}
+
+func (f *fakeEncounterUsecases) GetEncounterVersions(_ context.Context, _ string, _, _,
+	_ int) (*models.EncounterVersionsList, error) {
+	return &models.EncounterVersionsList{}, f.versionErr
+}
+
+func (f *fakeEncounterUsecases) GetEncounterVersion(_ context.Context, id string, version,
+	_ int) (*models.EncounterVersion, error) {
+	if f.versionErr != nil {
+		return nil, f.versionErr
+	}
+
+	return &models.EncounterVersion{EncounterID: id, Version: version, Data: []byte(`{}`)}, nil
+}
+
+func (f *fakeEncounterUsecases) DiffEncounterVersions(_ context.Context, _ string, from, to,
+	_ int) (*models.EncounterVersionsDiff, error) {
+	if f.versionErr != nil {
+		return nil, f.versionErr
+	}
+
+	return &models.EncounterVersionsDiff{From: from, To: to, Patch: []byte(`[]`)}, nil
+}
+
+func (f *fakeEncounterUsecases) GetDeletedEncounters(_ context.Context, _, _,
+	_ int) (*models.DeletedEncountersList, error) {
+	return &models.DeletedEncountersList{}, f.trashErr
+}
+
+func (f *fakeEncounterUsecases) RestoreEncounter(_ context.Context, _ string, _ int) error {
+	return f.trashErr
+}
+
+func (f *fakeEncounterUsecases) PurgeEncounter(_ context.Context, id string, _ int) error {
+	f.purged = id
+	return f.trashErr
+}
+
+func (f *fakeEncounterUsecases) RunTrashPurge(_ context.Context, _, _ time.Duration) {}
+
+func (f *fakeEncounterUsecases) RestoreEncounterVersion(_ context.Context, _ string, version, _ int) error {
+	f.restored = version
+	return f.versionErr
+}
+
+func (f *fakeEncounterUsecases) GetSharedEncounters(_ context.Context, _, _,
+	_ int) (*models.SharedEncountersList, error) {
+	return &models.SharedEncountersList{}, f.shareErr
+}
+
+func (f *fakeEncounterUsecases) GetEncounterShares(_ context.Context, _ string,
+	_ int) (*models.EncounterSharesList, error) {
+	return &models.EncounterSharesList{}, f.shareErr
+}
+
+func (f *fakeEncounterUsecases) ShareEncounter(_ context.Context, _ string, targetID int,
+	role models.EncounterRole, _ int) error {
+	f.sharedWith = targetID
+	f.sharedRole = role
+	return f.shareErr
+}
+
+func (f *fakeEncounterUsecases) RevokeEncounterShare(_ context.Context, _ string, _, _ int) error {
+	return f.shareErr
+}
+
+func (f *fakeEncounterUsecases) CreateShareLink(_ context.Context, id string, _ *models.CreateShareLinkReq,
+	userID int) (*models.EncounterShareLink, error) {
+	if f.shareErr != nil {
+		return nil, f.shareErr
+	}
+
+	return &models.EncounterShareLink{Token: "token", EncounterID: id, CreatedBy: userID}, nil
+}
+
+func (f *fakeEncounterUsecases) GetShareLinks(_ context.Context, _ string,
+	_ int) (*models.EncounterShareLinksList, error) {
+	return &models.EncounterShareLinksList{}, f.shareErr
+}
+
+func (f *fakeEncounterUsecases) RevokeShareLink(_ context.Context, _, _ string, _ int) error {
+	return f.shareErr
+}
+
+func (f *fakeEncounterUsecases) GetEncounterByShareLink(_ context.Context, _ string,
+	_ int) (*models.Encounter, error) {
+	if f.shareErr != nil {
+		return nil, f.shareErr
+	}
+
+	return &models.Encounter{UUID: "enc-1"}, nil
+}
+
+func (f *fakeEncounterUsecases) DuplicateEncounter(_ context.Context, id string, req *models.CopyEncounterReq,
+	_ int) (*models.Encounter, error) {
+	f.copyReq = req
+	if f.copyErr != nil {
+		return nil, f.copyErr
+	}
+
+	return &models.Encounter{UUID: "copy-of-" + id, Name: req.Name}, nil
+}
+
+func (f *fakeEncounterUsecases) InstantiateTemplate(_ context.Context, id string, req *models.CopyEncounterReq,
+	_ int) (*models.Encounter, error) {
+	f.copyReq = req
+	if f.copyErr != nil {
+		return nil, f.copyErr
+	}
+
+	return &models.Encounter{UUID: "instance-of-" + id, Name: req.Name}, nil
+}
+
+func (f *fakeEncounterUsecases) UpdateEncounterOrganization(_ context.Context, _ string,
+	_ *models.EncounterOrganizationReq, _ int) error {
+	return f.folderErr
+}
+
+func (f *fakeEncounterUsecases) GetEncounterFolders(_ context.Context, _ int) (*models.EncounterFoldersList, error) {
+	return &models.EncounterFoldersList{}, f.folderErr
+}
+
+func (f *fakeEncounterUsecases) CreateEncounterFolder(_ context.Context, req *models.EncounterFolderReq,
+	userID int) (*models.EncounterFolder, error) {
+	if f.folderErr != nil {
+		return nil, f.folderErr
+	}
+
+	return &models.EncounterFolder{ID: 1, UserID: userID, Name: req.Name}, nil
+}
+
+func (f *fakeEncounterUsecases) RenameEncounterFolder(_ context.Context, _ int, _ *models.EncounterFolderReq,
+	_ int) error {
+	return f.folderErr
+}
+
+func (f *fakeEncounterUsecases) RemoveEncounterFolder(_ context.Context, _, _ int) error {
+	return f.folderErr
+}
+
 // ctxUserKey must match the key used by the handler to extract the user from context.
 const ctxUserKey = "test-user-key"
 
@@ -93,3 +265,511 @@ func TestSaveEncounter_BadJSON_Returns400(t *testing.T) {
 	assert.Equal(t, responses.StatusBadRequest, rr.Code)
 	assert.Equal(t, responses.ErrBadJSON, testhelpers.DecodeErrorResponse(t, rr.Body))
 }
+
+func TestUpdateEncounter_ContentTypeSelectsFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		contentType string
+		want        models.PatchFormat
+	}{
+		{"plain JSON replaces data", "application/json", models.LegacyPatch},
+		{"no content type replaces data", "", models.LegacyPatch},
+		{"json patch", "application/json-patch+json", models.JSONPatch},
+		{"merge patch with charset", "application/merge-patch+json; charset=utf-8", models.MergePatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fake := &fakeEncounterUsecases{}
+			handler := delivery.NewEncounterHandler(fake, ctxUserKey)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/encounter/enc-1", bytes.NewReader([]byte(`{}`)))
+			req.Header.Set("Content-Type", tt.contentType)
+			req = mux.SetURLVars(req, map[string]string{"id": "enc-1"})
+			req = withUser(req, ctxUserKey, &models.User{ID: 1, DisplayName: "Tester"})
+
+			rr := httptest.NewRecorder()
+			handler.UpdateEncounter(rr, req)
+
+			assert.Equal(t, responses.StatusOk, rr.Code)
+			assert.Equal(t, tt.want, fake.format)
+		})
+	}
+}
+
+func TestUpdateEncounter_InvalidPatch_Returns400(t *testing.T) {
+	t.Parallel()
+
+	handler := delivery.NewEncounterHandler(
+		&fakeEncounterUsecases{updateErr: apperrors.InvalidPatchErr},
+		ctxUserKey,
+	)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/encounter/enc-1",
+		bytes.NewReader([]byte(`[{"op":"remove","path":"/missing"}]`)))
+	req.Header.Set("Content-Type", "application/json-patch+json")
+	req = mux.SetURLVars(req, map[string]string{"id": "enc-1"})
+	req = withUser(req, ctxUserKey, &models.User{ID: 1, DisplayName: "Tester"})
+
+	rr := httptest.NewRecorder()
+	handler.UpdateEncounter(rr, req)
+
+	assert.Equal(t, responses.StatusBadRequest, rr.Code)
+	assert.Equal(t, responses.ErrWrongPatch, testhelpers.DecodeErrorResponse(t, rr.Body))
+}
+
+func TestSaveEncounter_InvalidData_Returns400(t *testing.T) {
+	t.Parallel()
+
+	handler := delivery.NewEncounterHandler(
+		&fakeEncounterUsecases{saveErr: apperrors.InvalidEncounterDataErr},
+		ctxUserKey,
+	)
+
+	body := testhelpers.MustJSON(t, models.SaveEncounterReq{
+		Name: "Battle",
+		Data: []byte(`{"schemaVersion":1,"participants":[{"kind":"dragon"}]}`),
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/api/encounter", bytes.NewReader(body))
+	req = withUser(req, ctxUserKey, &models.User{ID: 1, DisplayName: "Tester"})
+
+	rr := httptest.NewRecorder()
+	handler.SaveEncounter(rr, req)
+
+	assert.Equal(t, responses.StatusBadRequest, rr.Code)
+	assert.Equal(t, responses.ErrWrongEncounterData, testhelpers.DecodeErrorResponse(t, rr.Body))
+}
+
+func TestGetDifficulty_HappyPath_Returns200(t *testing.T) {
+	t.Parallel()
+
+	handler := delivery.NewEncounterHandler(
+		&fakeEncounterUsecases{difficulty: &models.EncounterDifficulty{Difficulty: models.HardDifficulty}},
+		ctxUserKey,
+	)
+
+	body := testhelpers.MustJSON(t, models.DifficultyReq{Creatures: []string{"goblin"}, PartyLevels: []int{1}})
+	req := httptest.NewRequest(http.MethodPost, "/api/encounter/difficulty", bytes.NewReader(body))
+	req = withUser(req, ctxUserKey, &models.User{ID: 1, DisplayName: "Tester"})
+
+	rr := httptest.NewRecorder()
+	handler.GetDifficulty(rr, req)
+
+	assert.Equal(t, responses.StatusOk, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"difficulty":"hard"`)
+}
+
+func TestGetDifficulty_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantCode   int
+		wantStatus string
+	}{
+		{"bad json", `{bad`, nil, responses.StatusBadRequest, responses.ErrBadJSON},
+		{"invalid request", `{}`, apperrors.InvalidDifficultyErr, responses.StatusBadRequest,
+			responses.ErrWrongDifficulty},
+		{"foreign encounter", `{"encounterID":"enc-1"}`, apperrors.PermissionDeniedError, responses.StatusForbidden,
+			responses.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := delivery.NewEncounterHandler(&fakeEncounterUsecases{difficultyErr: tt.err}, ctxUserKey)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/encounter/difficulty", bytes.NewReader([]byte(tt.body)))
+			req = withUser(req, ctxUserKey, &models.User{ID: 1, DisplayName: "Tester"})
+
+			rr := httptest.NewRecorder()
+			handler.GetDifficulty(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Equal(t, tt.wantStatus, testhelpers.DecodeErrorResponse(t, rr.Body))
+		})
+	}
+}
+
+func TestGenerateEncounters_HappyPath_Returns200(t *testing.T) {
+	t.Parallel()
+
+	handler := delivery.NewEncounterHandler(&fakeEncounterUsecases{generated: &models.GeneratedEncounters{
+		Difficulty: models.MediumDifficulty,
+		Candidates: []*models.EncounterCandidate{{Encounter: models.SaveEncounterReq{Name: "Goblin ×2"}}},
+	}}, ctxUserKey)
+
+	body := testhelpers.MustJSON(t, models.GenerateEncounterReq{Difficulty: models.MediumDifficulty,
+		PartyLevels: []int{1, 1}, Environment: []string{"forest"}})
+	req := httptest.NewRequest(http.MethodPost, "/api/encounter/generate", bytes.NewReader(body))
+	req = withUser(req, ctxUserKey, &models.User{ID: 1, DisplayName: "Tester"})
+
+	rr := httptest.NewRecorder()
+	handler.GenerateEncounters(rr, req)
+
+	assert.Equal(t, responses.StatusOk, rr.Code)
+	assert.Contains(t, rr.Body.String(), `"name":"Goblin ×2"`)
+}
+
+func TestGenerateEncounters_InvalidRequest_Returns400(t *testing.T) {
+	t.Parallel()
+
+	handler := delivery.NewEncounterHandler(&fakeEncounterUsecases{generateErr: apperrors.InvalidGenerateErr},
+		ctxUserKey)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/encounter/generate",
+		bytes.NewReader([]byte(`{"difficulty":"trivial"}`)))
+	req = withUser(req, ctxUserKey, &models.User{ID: 1, DisplayName: "Tester"})
+
+	rr := httptest.NewRecorder()
+	handler.GenerateEncounters(rr, req)
+
+	assert.Equal(t, responses.StatusBadRequest, rr.Code)
+	assert.Equal(t, responses.ErrWrongGenerate, testhelpers.DecodeErrorResponse(t, rr.Body))
+}
+
+func TestDiffEncounterVersions_HappyPath_Returns200(t *testing.T) {
+	t.Parallel()
+
+	handler := delivery.NewEncounterHandler(&fakeEncounterUsecases{}, ctxUserKey)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/encounter/enc-1/versions/diff?from=1&to=3", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "enc-1"})
+	req = withUser(req, ctxUserKey, &models.User{ID: 1, DisplayName: "Tester"})
+
+	rr := httptest.NewRecorder()
+	handler.DiffEncounterVersions(rr, req)
+
+	assert.Equal(t, responses.StatusOk, rr.Code)
+	assert.JSONEq(t, `{"from":1,"to":3,"patch":[]}`, rr.Body.String())
+}
+
+func TestRestoreEncounterVersion_HappyPath_Returns200(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeEncounterUsecases{}
+	handler := delivery.NewEncounterHandler(fake, ctxUserKey)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/encounter/enc-1/versions/2/restore", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "enc-1", "version": "2"})
+	req = withUser(req, ctxUserKey, &models.User{ID: 1, DisplayName: "Tester"})
+
+	rr := httptest.NewRecorder()
+	handler.RestoreEncounterVersion(rr, req)
+
+	assert.Equal(t, responses.StatusOk, rr.Code)
+	assert.Equal(t, 2, fake.restored)
+}
+
+func TestEncounterVersions_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		target     string
+		vars       map[string]string
+		err        error
+		handle     func(h *delivery.EncounterHandler) http.HandlerFunc
+		wantCode   int
+		wantStatus string
+	}{
+		{
+			name:       "unknown version",
+			target:     "/api/encounter/enc-1/versions/9",
+			vars:       map[string]string{"id": "enc-1", "version": "9"},
+			err:        apperrors.EncounterVersionNotFoundErr,
+			handle:     func(h *delivery.EncounterHandler) http.HandlerFunc { return h.GetEncounterVersion },
+			wantCode:   responses.StatusBadRequest,
+			wantStatus: responses.ErrWrongVersion,
+		},
+		{
+			name:       "diff without versions",
+			target:     "/api/encounter/enc-1/versions/diff?from=1",
+			vars:       map[string]string{"id": "enc-1"},
+			handle:     func(h *delivery.EncounterHandler) http.HandlerFunc { return h.DiffEncounterVersions },
+			wantCode:   responses.StatusBadRequest,
+			wantStatus: responses.ErrWrongVersion,
+		},
+		{
+			name:       "list with bad size",
+			target:     "/api/encounter/enc-1/versions?size=many",
+			vars:       map[string]string{"id": "enc-1"},
+			handle:     func(h *delivery.EncounterHandler) http.HandlerFunc { return h.GetEncounterVersions },
+			wantCode:   responses.StatusBadRequest,
+			wantStatus: responses.ErrSizeOrPosition,
+		},
+		{
+			name:       "restore of foreign encounter",
+			target:     "/api/encounter/enc-1/versions/1/restore",
+			vars:       map[string]string{"id": "enc-1", "version": "1"},
+			err:        apperrors.PermissionDeniedError,
+			handle:     func(h *delivery.EncounterHandler) http.HandlerFunc { return h.RestoreEncounterVersion },
+			wantCode:   responses.StatusForbidden,
+			wantStatus: responses.ErrForbidden,
+		},
+		{
+			name:       "restore of invalid data",
+			target:     "/api/encounter/enc-1/versions/1/restore",
+			vars:       map[string]string{"id": "enc-1", "version": "1"},
+			err:        apperrors.InvalidEncounterDataErr,
+			handle:     func(h *delivery.EncounterHandler) http.HandlerFunc { return h.RestoreEncounterVersion },
+			wantCode:   responses.StatusBadRequest,
+			wantStatus: responses.ErrWrongEncounterData,
+		},
+		{
+			name:       "restore of trashed encounter",
+			target:     "/api/encounter/enc-1/versions/1/restore",
+			vars:       map[string]string{"id": "enc-1", "version": "1"},
+			err:        apperrors.EncounterNotFoundErr,
+			handle:     func(h *delivery.EncounterHandler) http.HandlerFunc { return h.RestoreEncounterVersion },
+			wantCode:   responses.StatusBadRequest,
+			wantStatus: responses.ErrEncounterNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := delivery.NewEncounterHandler(&fakeEncounterUsecases{versionErr: tt.err}, ctxUserKey)
+
+			req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+			req = mux.SetURLVars(req, tt.vars)
+			req = withUser(req, ctxUserKey, &models.User{ID: 1, DisplayName: "Tester"})
+
+			rr := httptest.NewRecorder()
+			tt.handle(handler)(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Equal(t, tt.wantStatus, testhelpers.DecodeErrorResponse(t, rr.Body))
+		})
+	}
+}
+
+func TestPurgeEncounter_HappyPath_Returns200(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeEncounterUsecases{}
+	handler := delivery.NewEncounterHandler(fake, ctxUserKey)
+
+	req := httptest.NewRequest(http.MethodDelete, "/api/encounter/trash/enc-1", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "enc-1"})
+	req = withUser(req, ctxUserKey, &models.User{ID: 1, DisplayName: "Tester"})
+
+	rr := httptest.NewRecorder()
+	handler.PurgeEncounter(rr, req)
+
+	assert.Equal(t, responses.StatusOk, rr.Code)
+	assert.Equal(t, "enc-1", fake.purged)
+}
+
+func TestRestoreEncounter_NotInTrash_Returns400(t *testing.T) {
+	t.Parallel()
+
+	handler := delivery.NewEncounterHandler(&fakeEncounterUsecases{trashErr: apperrors.EncounterNotInTrashErr},
+		ctxUserKey)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/encounter/trash/enc-1/restore", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "enc-1"})
+	req = withUser(req, ctxUserKey, &models.User{ID: 1, DisplayName: "Tester"})
+
+	rr := httptest.NewRecorder()
+	handler.RestoreEncounter(rr, req)
+
+	assert.Equal(t, responses.StatusBadRequest, rr.Code)
+	assert.Equal(t, responses.ErrNotInTrash, testhelpers.DecodeErrorResponse(t, rr.Body))
+}
+
+func TestGetDeletedEncounters_BadStart_Returns400(t *testing.T) {
+	t.Parallel()
+
+	handler := delivery.NewEncounterHandler(&fakeEncounterUsecases{}, ctxUserKey)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/encounter/trash?start=first", nil)
+	req = withUser(req, ctxUserKey, &models.User{ID: 1, DisplayName: "Tester"})
+
+	rr := httptest.NewRecorder()
+	handler.GetDeletedEncounters(rr, req)
+
+	assert.Equal(t, responses.StatusBadRequest, rr.Code)
+	assert.Equal(t, responses.ErrSizeOrPosition, testhelpers.DecodeErrorResponse(t, rr.Body))
+}
+
+func TestShareEncounter_HappyPath_Returns200(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeEncounterUsecases{}
+	handler := delivery.NewEncounterHandler(fake, ctxUserKey)
+
+	body := bytes.NewBufferString(`{"role":"editor"}`)
+	req := httptest.NewRequest(http.MethodPut, "/api/encounter/enc-1/shares/2", body)
+	req = mux.SetURLVars(req, map[string]string{"id": "enc-1", "userID": "2"})
+	req = withUser(req, ctxUserKey, &models.User{ID: 1, DisplayName: "Tester"})
+
+	rr := httptest.NewRecorder()
+	handler.ShareEncounter(rr, req)
+
+	assert.Equal(t, responses.StatusOk, rr.Code)
+	assert.Equal(t, 2, fake.sharedWith)
+	assert.Equal(t, models.EditorEncounterRole, fake.sharedRole)
+}
+
+func TestShareEncounter_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   int
+		wantStatus string
+	}{
+		{"invalid share", apperrors.InvalidShareErr, responses.StatusBadRequest, responses.ErrWrongShare},
+		{"permission denied", apperrors.PermissionDeniedError, responses.StatusForbidden, responses.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := delivery.NewEncounterHandler(&fakeEncounterUsecases{shareErr: tt.err}, ctxUserKey)
+
+			body := bytes.NewBufferString(`{"role":"owner"}`)
+			req := httptest.NewRequest(http.MethodPut, "/api/encounter/enc-1/shares/2", body)
+			req = mux.SetURLVars(req, map[string]string{"id": "enc-1", "userID": "2"})
+			req = withUser(req, ctxUserKey, &models.User{ID: 1, DisplayName: "Tester"})
+
+			rr := httptest.NewRecorder()
+			handler.ShareEncounter(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Equal(t, tt.wantStatus, testhelpers.DecodeErrorResponse(t, rr.Body))
+		})
+	}
+}
+
+func TestGetEncounterByShareLink_Expired_Returns400(t *testing.T) {
+	t.Parallel()
+
+	handler := delivery.NewEncounterHandler(&fakeEncounterUsecases{shareErr: apperrors.ShareLinkNotFoundErr},
+		ctxUserKey)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/encounter/link/token", nil)
+	req = mux.SetURLVars(req, map[string]string{"token": "token"})
+	req = withUser(req, ctxUserKey, &models.User{ID: 2, DisplayName: "Guest"})
+
+	rr := httptest.NewRecorder()
+	handler.GetEncounterByShareLink(rr, req)
+
+	assert.Equal(t, responses.StatusBadRequest, rr.Code)
+	assert.Equal(t, responses.ErrWrongShareLink, testhelpers.DecodeErrorResponse(t, rr.Body))
+}
+
+func TestGetEncountersList_PassesOrder(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeEncounterUsecases{}
+	handler := delivery.NewEncounterHandler(fake, ctxUserKey)
+
+	body := bytes.NewBufferString(`{"start":0,"size":10,"order":{"field":"name","direction":"desc"}}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/encounter/list", body)
+	req = withUser(req, ctxUserKey, &models.User{ID: 1, DisplayName: "Tester"})
+
+	rr := httptest.NewRecorder()
+	handler.GetEncountersList(rr, req)
+
+	assert.Equal(t, responses.StatusOk, rr.Code)
+	assert.Equal(t, &models.Order{Field: "name", Direction: "desc"}, fake.listOrder)
+}
+
+func TestGetEncountersList_WrongOrder_Returns400(t *testing.T) {
+	t.Parallel()
+
+	handler := delivery.NewEncounterHandler(&fakeEncounterUsecases{listErr: apperrors.InvalidEncounterOrderErr},
+		ctxUserKey)
+
+	body := bytes.NewBufferString(`{"start":0,"size":10,"order":{"field":"data"}}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/encounter/list", body)
+	req = withUser(req, ctxUserKey, &models.User{ID: 1, DisplayName: "Tester"})
+
+	rr := httptest.NewRecorder()
+	handler.GetEncountersList(rr, req)
+
+	assert.Equal(t, responses.StatusBadRequest, rr.Code)
+	assert.Equal(t, responses.ErrWrongOrder, testhelpers.DecodeErrorResponse(t, rr.Body))
+}
+
+func TestDuplicateEncounter_EmptyBody_Returns200(t *testing.T) {
+	t.Parallel()
+
+	fake := &fakeEncounterUsecases{}
+	handler := delivery.NewEncounterHandler(fake, ctxUserKey)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/encounter/enc-1/duplicate", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "enc-1"})
+	req = withUser(req, ctxUserKey, &models.User{ID: 1, DisplayName: "Tester"})
+
+	rr := httptest.NewRecorder()
+	handler.DuplicateEncounter(rr, req)
+
+	assert.Equal(t, responses.StatusOk, rr.Code)
+	assert.Equal(t, &models.CopyEncounterReq{}, fake.copyReq)
+}
+
+func TestInstantiateTemplate_NotTemplate_Returns400(t *testing.T) {
+	t.Parallel()
+
+	handler := delivery.NewEncounterHandler(&fakeEncounterUsecases{copyErr: apperrors.EncounterNotTemplateErr},
+		ctxUserKey)
+
+	body := bytes.NewBufferString(`{"name":"Ambush at the bridge"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/encounter/enc-1/instantiate", body)
+	req = mux.SetURLVars(req, map[string]string{"id": "enc-1"})
+	req = withUser(req, ctxUserKey, &models.User{ID: 1, DisplayName: "Tester"})
+
+	rr := httptest.NewRecorder()
+	handler.InstantiateTemplate(rr, req)
+
+	assert.Equal(t, responses.StatusBadRequest, rr.Code)
+	assert.Equal(t, responses.ErrNotTemplate, testhelpers.DecodeErrorResponse(t, rr.Body))
+}
+
+func TestRenameEncounterFolder_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		folderID   string
+		err        error
+		wantStatus string
+	}{
+		{"invalid folder ID", "first", nil, responses.ErrInvalidID},
+		{"unknown folder", "5", apperrors.InvalidEncounterFolderErr, responses.ErrWrongFolder},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := delivery.NewEncounterHandler(&fakeEncounterUsecases{folderErr: tt.err}, ctxUserKey)
+
+			body := bytes.NewBufferString(`{"name":"Curse of Strahd"}`)
+			req := httptest.NewRequest(http.MethodPut, "/api/encounter/folders/"+tt.folderID, body)
+			req = mux.SetURLVars(req, map[string]string{"folderID": tt.folderID})
+			req = withUser(req, ctxUserKey, &models.User{ID: 1, DisplayName: "Tester"})
+
+			rr := httptest.NewRecorder()
+			handler.RenameEncounterFolder(rr, req)
+
+			assert.Equal(t, responses.StatusBadRequest, rr.Code)
+			assert.Equal(t, tt.wantStatus, testhelpers.DecodeErrorResponse(t, rr.Body))
+		})
+	}
+}
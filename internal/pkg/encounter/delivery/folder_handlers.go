@@ -0,0 +1,181 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/delivery/responses"
+	"github.com/gorilla/mux"
+)
+
+func (h *EncounterHandler) UpdateEncounterOrganization(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+	vars := mux.Vars(r)
+
+	id, ok := vars["id"]
+	if !ok || id == "" {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return
+	}
+
+	var reqData models.EncounterOrganizationReq
+
+	err := json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrBadJSON, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrBadJSON)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	err = h.usecases.UpdateEncounterOrganization(ctx, id, &reqData, userID)
+	if err != nil {
+		h.sendFolderError(w, r, err, map[string]any{"id": id, "user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, nil)
+}
+
+func (h *EncounterHandler) GetEncounterFolders(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	list, err := h.usecases.GetEncounterFolders(ctx, userID)
+	if err != nil {
+		h.sendFolderError(w, r, err, map[string]any{"user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, list)
+}
+
+func (h *EncounterHandler) CreateEncounterFolder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+
+	var reqData models.EncounterFolderReq
+
+	err := json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrBadJSON, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrBadJSON)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	folder, err := h.usecases.CreateEncounterFolder(ctx, &reqData, userID)
+	if err != nil {
+		h.sendFolderError(w, r, err, map[string]any{"name": reqData.Name, "user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, folder)
+}
+
+func (h *EncounterHandler) RenameEncounterFolder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+	vars := mux.Vars(r)
+
+	id, err := strconv.Atoi(vars["folderID"])
+	if err != nil {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, err, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return
+	}
+
+	var reqData models.EncounterFolderReq
+
+	err = json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrBadJSON, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrBadJSON)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	err = h.usecases.RenameEncounterFolder(ctx, id, &reqData, userID)
+	if err != nil {
+		h.sendFolderError(w, r, err, map[string]any{"id": id, "name": reqData.Name, "user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, nil)
+}
+
+func (h *EncounterHandler) RemoveEncounterFolder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+	vars := mux.Vars(r)
+
+	id, err := strconv.Atoi(vars["folderID"])
+	if err != nil {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, err, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	err = h.usecases.RemoveEncounterFolder(ctx, id, userID)
+	if err != nil {
+		h.sendFolderError(w, r, err, map[string]any{"id": id, "user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, nil)
+}
+
+func (h *EncounterHandler) sendFolderError(w http.ResponseWriter, r *http.Request, err error, data any) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+
+	var code int
+	var status string
+
+	switch {
+	case errors.Is(err, apperrors.PermissionDeniedError):
+		code = responses.StatusForbidden
+		status = responses.ErrForbidden
+	case errors.Is(err, apperrors.InvalidEncounterFolderErr):
+		code = responses.StatusBadRequest
+		status = responses.ErrWrongFolder
+	case errors.Is(err, apperrors.InvalidEncounterTagsErr):
+		code = responses.StatusBadRequest
+		status = responses.ErrWrongTags
+	default:
+		code = responses.StatusInternalServerError
+		status = responses.ErrInternalServer
+	}
+
+	l.DeliveryError(ctx, code, status, err, data)
+	responses.SendErrResponse(w, code, status)
+}
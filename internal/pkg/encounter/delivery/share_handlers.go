@@ -0,0 +1,293 @@
+package delivery
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/delivery/responses"
+	"github.com/gorilla/mux"
+)
+
+const defaultSharedPage = 20
+
+func (h *EncounterHandler) GetSharedEncounters(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+
+	start, startErr := queryInt(r, "start", 0)
+	size, sizeErr := queryInt(r, "size", defaultSharedPage)
+
+	if err := errors.Join(startErr, sizeErr); err != nil {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrSizeOrPosition, err, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrSizeOrPosition)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	list, err := h.usecases.GetSharedEncounters(ctx, size, start, userID)
+	if err != nil {
+		h.sendShareError(w, r, err, map[string]any{"size": size, "start": start, "user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, list)
+}
+
+func (h *EncounterHandler) GetEncounterShares(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+	vars := mux.Vars(r)
+
+	id, ok := vars["id"]
+	if !ok || id == "" {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	list, err := h.usecases.GetEncounterShares(ctx, id, userID)
+	if err != nil {
+		h.sendShareError(w, r, err, map[string]any{"id": id, "user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, list)
+}
+
+func (h *EncounterHandler) ShareEncounter(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+	vars := mux.Vars(r)
+
+	id, ok := vars["id"]
+	if !ok || id == "" {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return
+	}
+
+	targetID, err := strconv.Atoi(vars["userID"])
+	if err != nil {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, err, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return
+	}
+
+	var reqData models.ShareEncounterReq
+
+	err = json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrBadJSON, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrBadJSON)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	err = h.usecases.ShareEncounter(ctx, id, targetID, reqData.Role, userID)
+	if err != nil {
+		h.sendShareError(w, r, err, map[string]any{"id": id, "target_id": targetID, "role": reqData.Role,
+			"user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, nil)
+}
+
+func (h *EncounterHandler) RevokeEncounterShare(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+	vars := mux.Vars(r)
+
+	id, ok := vars["id"]
+	if !ok || id == "" {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return
+	}
+
+	targetID, err := strconv.Atoi(vars["userID"])
+	if err != nil {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, err, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	err = h.usecases.RevokeEncounterShare(ctx, id, targetID, userID)
+	if err != nil {
+		h.sendShareError(w, r, err, map[string]any{"id": id, "target_id": targetID, "user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, nil)
+}
+
+func (h *EncounterHandler) CreateShareLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+	vars := mux.Vars(r)
+
+	id, ok := vars["id"]
+	if !ok || id == "" {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return
+	}
+
+	var reqData models.CreateShareLinkReq
+
+	err := json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrBadJSON, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrBadJSON)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	link, err := h.usecases.CreateShareLink(ctx, id, &reqData, userID)
+	if err != nil {
+		h.sendShareError(w, r, err, map[string]any{"id": id, "expires_in": reqData.ExpiresIn, "user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, link)
+}
+
+func (h *EncounterHandler) GetShareLinks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+	vars := mux.Vars(r)
+
+	id, ok := vars["id"]
+	if !ok || id == "" {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	list, err := h.usecases.GetShareLinks(ctx, id, userID)
+	if err != nil {
+		h.sendShareError(w, r, err, map[string]any{"id": id, "user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, list)
+}
+
+func (h *EncounterHandler) RevokeShareLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+	vars := mux.Vars(r)
+
+	id, ok := vars["id"]
+	if !ok || id == "" {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return
+	}
+
+	token := vars["token"]
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	err := h.usecases.RevokeShareLink(ctx, id, token, userID)
+	if err != nil {
+		h.sendShareError(w, r, err, map[string]any{"id": id, "user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, nil)
+}
+
+func (h *EncounterHandler) GetEncounterByShareLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+	vars := mux.Vars(r)
+
+	token, ok := vars["token"]
+	if !ok || token == "" {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrWrongShareLink, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrWrongShareLink)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	encounter, err := h.usecases.GetEncounterByShareLink(ctx, token, userID)
+	if err != nil {
+		h.sendShareError(w, r, err, map[string]any{"user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, encounter)
+}
+
+func (h *EncounterHandler) sendShareError(w http.ResponseWriter, r *http.Request, err error, data any) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+
+	var code int
+	var status string
+
+	switch {
+	case errors.Is(err, apperrors.PermissionDeniedError):
+		code = responses.StatusForbidden
+		status = responses.ErrForbidden
+	case errors.Is(err, apperrors.StartPosSizeError):
+		code = responses.StatusBadRequest
+		status = responses.ErrSizeOrPosition
+	case errors.Is(err, apperrors.InvalidShareErr):
+		code = responses.StatusBadRequest
+		status = responses.ErrWrongShare
+	case errors.Is(err, apperrors.ShareLinkNotFoundErr):
+		code = responses.StatusBadRequest
+		status = responses.ErrWrongShareLink
+	default:
+		code = responses.StatusInternalServerError
+		status = responses.ErrInternalServer
+	}
+
+	l.DeliveryError(ctx, code, status, err, data)
+	responses.SendErrResponse(w, code, status)
+}
@@ -0,0 +1,86 @@
+package delivery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/delivery/responses"
+	"github.com/gorilla/mux"
+)
+
+func (h *EncounterHandler) DuplicateEncounter(w http.ResponseWriter, r *http.Request) {
+	h.handleCopy(w, r, h.usecases.DuplicateEncounter)
+}
+
+func (h *EncounterHandler) InstantiateTemplate(w http.ResponseWriter, r *http.Request) {
+	h.handleCopy(w, r, h.usecases.InstantiateTemplate)
+}
+
+// handleCopy обрабатывает создание энкаунтера из существующего. Тело запроса необязательно
+func (h *EncounterHandler) handleCopy(w http.ResponseWriter, r *http.Request,
+	action func(ctx context.Context, id string, req *models.CopyEncounterReq, userID int) (*models.Encounter, error)) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+	vars := mux.Vars(r)
+
+	id, ok := vars["id"]
+	if !ok || id == "" {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return
+	}
+
+	var reqData models.CopyEncounterReq
+
+	err := json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil && !errors.Is(err, io.EOF) {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrBadJSON, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrBadJSON)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	encounter, err := action(ctx, id, &reqData, userID)
+	if err != nil {
+		var code int
+		var status string
+
+		switch {
+		case errors.Is(err, apperrors.PermissionDeniedError):
+			code = responses.StatusForbidden
+			status = responses.ErrForbidden
+		case errors.Is(err, apperrors.EncounterNotTemplateErr):
+			code = responses.StatusBadRequest
+			status = responses.ErrNotTemplate
+		case errors.Is(err, apperrors.InvalidInputError):
+			code = responses.StatusBadRequest
+			status = responses.ErrWrongEncounterName
+		case errors.Is(err, apperrors.InvalidEncounterDataErr):
+			code = responses.StatusBadRequest
+			status = responses.ErrWrongEncounterData
+		case errors.Is(err, apperrors.InvalidEncounterFolderErr):
+			code = responses.StatusBadRequest
+			status = responses.ErrWrongFolder
+		default:
+			code = responses.StatusInternalServerError
+			status = responses.ErrInternalServer
+		}
+
+		l.DeliveryError(ctx, code, status, err, map[string]any{"id": id, "user_id": userID})
+		responses.SendErrResponse(w, code, status)
+
+		return
+	}
+
+	responses.SendOkResponse(w, encounter)
+}
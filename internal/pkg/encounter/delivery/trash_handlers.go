@@ -0,0 +1,103 @@
+package delivery
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/delivery/responses"
+	"github.com/gorilla/mux"
+)
+
+const defaultTrashPage = 20
+
+func (h *EncounterHandler) GetDeletedEncounters(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+
+	start, startErr := queryInt(r, "start", 0)
+	size, sizeErr := queryInt(r, "size", defaultTrashPage)
+
+	if err := errors.Join(startErr, sizeErr); err != nil {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrSizeOrPosition, err, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrSizeOrPosition)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	list, err := h.usecases.GetDeletedEncounters(ctx, size, start, userID)
+	if err != nil {
+		var code int
+		var status string
+
+		switch {
+		case errors.Is(err, apperrors.StartPosSizeError):
+			code = responses.StatusBadRequest
+			status = responses.ErrSizeOrPosition
+		default:
+			code = responses.StatusInternalServerError
+			status = responses.ErrInternalServer
+		}
+
+		l.DeliveryError(ctx, code, status, err, map[string]any{"size": size, "start": start, "user_id": userID})
+		responses.SendErrResponse(w, code, status)
+
+		return
+	}
+
+	responses.SendOkResponse(w, list)
+}
+
+func (h *EncounterHandler) RestoreEncounter(w http.ResponseWriter, r *http.Request) {
+	h.handleTrashItem(w, r, h.usecases.RestoreEncounter)
+}
+
+func (h *EncounterHandler) PurgeEncounter(w http.ResponseWriter, r *http.Request) {
+	h.handleTrashItem(w, r, h.usecases.PurgeEncounter)
+}
+
+func (h *EncounterHandler) handleTrashItem(w http.ResponseWriter, r *http.Request,
+	action func(ctx context.Context, id string, userID int) error) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+	vars := mux.Vars(r)
+
+	id, ok := vars["id"]
+	if !ok || id == "" {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	err := action(ctx, id, userID)
+	if err != nil {
+		var code int
+		var status string
+
+		switch {
+		case errors.Is(err, apperrors.EncounterNotInTrashErr):
+			code = responses.StatusBadRequest
+			status = responses.ErrNotInTrash
+		default:
+			code = responses.StatusInternalServerError
+			status = responses.ErrInternalServer
+		}
+
+		l.DeliveryError(ctx, code, status, err, map[string]any{"id": id, "user_id": userID})
+		responses.SendErrResponse(w, code, status)
+
+		return
+	}
+
+	responses.SendOkResponse(w, nil)
+}
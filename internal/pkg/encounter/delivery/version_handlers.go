@@ -0,0 +1,203 @@
+package delivery
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/delivery/responses"
+	"github.com/gorilla/mux"
+)
+
+const defaultVersionsPage = 20
+
+func (h *EncounterHandler) GetEncounterVersions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+	vars := mux.Vars(r)
+
+	id, ok := vars["id"]
+	if !ok || id == "" {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return
+	}
+
+	start, err := queryInt(r, "start", 0)
+	if err != nil {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrSizeOrPosition, err, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrSizeOrPosition)
+
+		return
+	}
+
+	size, err := queryInt(r, "size", defaultVersionsPage)
+	if err != nil {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrSizeOrPosition, err, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrSizeOrPosition)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	list, err := h.usecases.GetEncounterVersions(ctx, id, size, start, userID)
+	if err != nil {
+		h.sendVersionError(w, r, err, map[string]any{"id": id, "user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, list)
+}
+
+func (h *EncounterHandler) GetEncounterVersion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+	vars := mux.Vars(r)
+
+	id, ok := vars["id"]
+	if !ok || id == "" {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return
+	}
+
+	version, err := strconv.Atoi(vars["version"])
+	if err != nil || version <= 0 {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrWrongVersion, err, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrWrongVersion)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	encounterVersion, err := h.usecases.GetEncounterVersion(ctx, id, version, userID)
+	if err != nil {
+		h.sendVersionError(w, r, err, map[string]any{"id": id, "version": version, "user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, encounterVersion)
+}
+
+func (h *EncounterHandler) DiffEncounterVersions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+	vars := mux.Vars(r)
+
+	id, ok := vars["id"]
+	if !ok || id == "" {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return
+	}
+
+	from, fromErr := queryInt(r, "from", 0)
+	to, toErr := queryInt(r, "to", 0)
+
+	if err := errors.Join(fromErr, toErr); err != nil || from <= 0 || to <= 0 {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrWrongVersion, err, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrWrongVersion)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	diff, err := h.usecases.DiffEncounterVersions(ctx, id, from, to, userID)
+	if err != nil {
+		h.sendVersionError(w, r, err, map[string]any{"id": id, "from": from, "to": to, "user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, diff)
+}
+
+func (h *EncounterHandler) RestoreEncounterVersion(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+	vars := mux.Vars(r)
+
+	id, ok := vars["id"]
+	if !ok || id == "" {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return
+	}
+
+	version, err := strconv.Atoi(vars["version"])
+	if err != nil || version <= 0 {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrWrongVersion, err, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrWrongVersion)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+	userID := user.ID
+
+	err = h.usecases.RestoreEncounterVersion(ctx, id, version, userID)
+	if err != nil {
+		h.sendVersionError(w, r, err, map[string]any{"id": id, "version": version, "user_id": userID})
+
+		return
+	}
+
+	responses.SendOkResponse(w, nil)
+}
+
+func (h *EncounterHandler) sendVersionError(w http.ResponseWriter, r *http.Request, err error, data any) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+
+	var code int
+	var status string
+
+	switch {
+	case errors.Is(err, apperrors.PermissionDeniedError):
+		code = responses.StatusForbidden
+		status = responses.ErrForbidden
+	case errors.Is(err, apperrors.StartPosSizeError):
+		code = responses.StatusBadRequest
+		status = responses.ErrSizeOrPosition
+	case errors.Is(err, apperrors.EncounterVersionNotFoundErr):
+		code = responses.StatusBadRequest
+		status = responses.ErrWrongVersion
+	case errors.Is(err, apperrors.EncounterNotFoundErr):
+		code = responses.StatusBadRequest
+		status = responses.ErrEncounterNotFound
+	case errors.Is(err, apperrors.InvalidEncounterDataErr):
+		code = responses.StatusBadRequest
+		status = responses.ErrWrongEncounterData
+	default:
+		code = responses.StatusInternalServerError
+		status = responses.ErrInternalServer
+	}
+
+	l.DeliveryError(ctx, code, status, err, data)
+	responses.SendErrResponse(w, code, status)
+}
+
+// queryInt читает целочисленный параметр запроса, если он не задан — возвращает значение по умолчанию
+func queryInt(r *http.Request, key string, fallback int) (int, error) {
+	raw := r.URL.Query().Get(key)
+	if raw == "" {
+		return fallback, nil
+	}
+
+	return strconv.Atoi(raw)
+}
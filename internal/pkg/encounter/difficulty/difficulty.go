@@ -0,0 +1,195 @@
+package difficulty
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+)
+
+const (
+	MinLevel = 1
+	MaxLevel = 20
+)
+
+var (
+	ErrEmptyParty             = errors.New("party is empty")
+	ErrInvalidLevel           = errors.New("character level must be between 1 and 20")
+	ErrUnknownChallengeRating = errors.New("unknown challenge rating")
+)
+
+// thresholds — пороги опыта на одного персонажа по уровням 1–20 (DMG, глава 3)
+var thresholds = [MaxLevel]models.DifficultyThresholds{
+	{Easy: 25, Medium: 50, Hard: 75, Deadly: 100},
+	{Easy: 50, Medium: 100, Hard: 150, Deadly: 200},
+	{Easy: 75, Medium: 150, Hard: 225, Deadly: 400},
+	{Easy: 125, Medium: 250, Hard: 375, Deadly: 500},
+	{Easy: 250, Medium: 500, Hard: 750, Deadly: 1100},
+	{Easy: 300, Medium: 600, Hard: 900, Deadly: 1400},
+	{Easy: 350, Medium: 750, Hard: 1100, Deadly: 1700},
+	{Easy: 450, Medium: 900, Hard: 1400, Deadly: 2100},
+	{Easy: 550, Medium: 1100, Hard: 1600, Deadly: 2400},
+	{Easy: 600, Medium: 1200, Hard: 1900, Deadly: 2800},
+	{Easy: 800, Medium: 1600, Hard: 2400, Deadly: 3600},
+	{Easy: 1000, Medium: 2000, Hard: 3000, Deadly: 4500},
+	{Easy: 1100, Medium: 2200, Hard: 3400, Deadly: 5100},
+	{Easy: 1250, Medium: 2500, Hard: 3800, Deadly: 5700},
+	{Easy: 1400, Medium: 2800, Hard: 4300, Deadly: 6400},
+	{Easy: 1600, Medium: 3200, Hard: 4800, Deadly: 7200},
+	{Easy: 2000, Medium: 3900, Hard: 5900, Deadly: 8800},
+	{Easy: 2100, Medium: 4200, Hard: 6300, Deadly: 9500},
+	{Easy: 2400, Medium: 4900, Hard: 7300, Deadly: 10900},
+	{Easy: 2800, Medium: 5700, Hard: 8500, Deadly: 12700},
+}
+
+// multipliers — множители опыта по числу монстров. Первый и последний используются только при
+// поправке на размер группы
+var multipliers = []float64{0.5, 1, 1.5, 2, 2.5, 3, 4, 5}
+
+var challengeXP = map[string]int{
+	"0": 10, "1/8": 25, "1/4": 50, "1/2": 100,
+	"1": 200, "2": 450, "3": 700, "4": 1100, "5": 1800,
+	"6": 2300, "7": 2900, "8": 3900, "9": 5000, "10": 5900,
+	"11": 7200, "12": 8400, "13": 10000, "14": 11500, "15": 13000,
+	"16": 15000, "17": 18000, "18": 20000, "19": 22000, "20": 25000,
+	"21": 33000, "22": 41000, "23": 50000, "24": 62000, "25": 75000,
+	"26": 90000, "27": 105000, "28": 120000, "29": 135000, "30": 155000,
+}
+
+// ChallengeXP возвращает опыт за существо с указанным показателем опасности
+func ChallengeXP(challengeRating string) (int, error) {
+	xp, ok := challengeXP[strings.TrimSpace(challengeRating)]
+	if !ok {
+		return 0, ErrUnknownChallengeRating
+	}
+
+	return xp, nil
+}
+
+// ChallengeRatingsBelow возвращает показатели опасности, опыт за которые меньше maxXP, по возрастанию
+func ChallengeRatingsBelow(maxXP int) []string {
+	ratings := make([]string, 0, len(challengeXP))
+	for rating, xp := range challengeXP {
+		if xp < maxXP {
+			ratings = append(ratings, rating)
+		}
+	}
+
+	slices.SortFunc(ratings, func(a, b string) int {
+		return challengeXP[a] - challengeXP[b]
+	})
+
+	return ratings
+}
+
+// CreatureXP возвращает опыт за существо. Если опыт не указан в карточке, он берётся по показателю
+// опасности
+func CreatureXP(creature *models.Creature) (int, error) {
+	if creature.Experience > 0 {
+		return creature.Experience, nil
+	}
+
+	return ChallengeXP(creature.ChallengeRating)
+}
+
+// PartyThresholds суммирует пороги сложности всех персонажей группы
+func PartyThresholds(levels []int) (models.DifficultyThresholds, error) {
+	var sum models.DifficultyThresholds
+
+	if len(levels) == 0 {
+		return sum, ErrEmptyParty
+	}
+
+	for _, level := range levels {
+		if level < MinLevel || level > MaxLevel {
+			return models.DifficultyThresholds{}, ErrInvalidLevel
+		}
+
+		t := thresholds[level-1]
+		sum.Easy += t.Easy
+		sum.Medium += t.Medium
+		sum.Hard += t.Hard
+		sum.Deadly += t.Deadly
+	}
+
+	return sum, nil
+}
+
+// Multiplier возвращает множитель опыта для числа монстров. Группе меньше трёх персонажей
+// назначается следующий множитель, группе от шести персонажей — предыдущий
+func Multiplier(monsterCount, partySize int) float64 {
+	if monsterCount <= 0 {
+		return 1
+	}
+
+	var idx int
+
+	switch {
+	case monsterCount == 1:
+		idx = 1
+	case monsterCount == 2:
+		idx = 2
+	case monsterCount <= 6:
+		idx = 3
+	case monsterCount <= 10:
+		idx = 4
+	case monsterCount <= 14:
+		idx = 5
+	default:
+		idx = 6
+	}
+
+	switch {
+	case partySize < 3:
+		idx++
+	case partySize >= 6:
+		idx--
+	}
+
+	return multipliers[idx]
+}
+
+// Rate определяет сложность по скорректированному опыту
+func Rate(adjustedXP int, t models.DifficultyThresholds) models.Difficulty {
+	switch {
+	case adjustedXP >= t.Deadly:
+		return models.DeadlyDifficulty
+	case adjustedXP >= t.Hard:
+		return models.HardDifficulty
+	case adjustedXP >= t.Medium:
+		return models.MediumDifficulty
+	case adjustedXP >= t.Easy:
+		return models.EasyDifficulty
+	default:
+		return models.TrivialDifficulty
+	}
+}
+
+// Calculate считает сложность боя с монстрами, за которых дают creatureXP опыта, против группы
+// персонажей указанных уровней
+func Calculate(creatureXP []int, partyLevels []int) (*models.EncounterDifficulty, error) {
+	t, err := PartyThresholds(partyLevels)
+	if err != nil {
+		return nil, err
+	}
+
+	total := 0
+	for _, xp := range creatureXP {
+		total += xp
+	}
+
+	multiplier := Multiplier(len(creatureXP), len(partyLevels))
+	adjusted := int(math.Round(float64(total) * multiplier))
+
+	return &models.EncounterDifficulty{
+		TotalXP:      total,
+		AdjustedXP:   adjusted,
+		Multiplier:   multiplier,
+		MonsterCount: len(creatureXP),
+		PartyLevels:  slices.Clone(partyLevels),
+		Thresholds:   t,
+		Difficulty:   Rate(adjusted, t),
+	}, nil
+}
@@ -0,0 +1,142 @@
+package difficulty_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/difficulty"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMultiplier(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		monsterCount int
+		partySize    int
+		want         float64
+	}{
+		{"single monster", 1, 4, 1},
+		{"pair", 2, 4, 1.5},
+		{"small group", 6, 4, 2},
+		{"group", 7, 4, 2.5},
+		{"large group", 14, 4, 3},
+		{"horde", 15, 4, 4},
+		{"small party uses next multiplier", 1, 2, 1.5},
+		{"small party facing horde", 20, 1, 5},
+		{"large party uses previous multiplier", 1, 6, 0.5},
+		{"large party facing group", 3, 7, 1.5},
+		{"no monsters", 0, 4, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, difficulty.Multiplier(tt.monsterCount, tt.partySize))
+		})
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		creatureXP  []int
+		partyLevels []int
+		want        *models.EncounterDifficulty
+		wantErr     error
+	}{
+		{
+			// DMG example: four level 3 characters against a bugbear and three hobgoblins
+			name:        "dmg example is hard",
+			creatureXP:  []int{200, 100, 100, 100},
+			partyLevels: []int{3, 3, 3, 3},
+			want: &models.EncounterDifficulty{
+				TotalXP:      500,
+				AdjustedXP:   1000,
+				Multiplier:   2,
+				MonsterCount: 4,
+				PartyLevels:  []int{3, 3, 3, 3},
+				Thresholds:   models.DifficultyThresholds{Easy: 300, Medium: 600, Hard: 900, Deadly: 1600},
+				Difficulty:   models.HardDifficulty,
+			},
+		},
+		{
+			name:        "mixed levels are summed",
+			creatureXP:  []int{1800},
+			partyLevels: []int{1, 5},
+			want: &models.EncounterDifficulty{
+				TotalXP:      1800,
+				AdjustedXP:   2700,
+				Multiplier:   1.5,
+				MonsterCount: 1,
+				PartyLevels:  []int{1, 5},
+				Thresholds:   models.DifficultyThresholds{Easy: 275, Medium: 550, Hard: 825, Deadly: 1200},
+				Difficulty:   models.DeadlyDifficulty,
+			},
+		},
+		{
+			name:        "no monsters is trivial",
+			partyLevels: []int{1, 1, 1},
+			want: &models.EncounterDifficulty{
+				Multiplier:  1,
+				PartyLevels: []int{1, 1, 1},
+				Thresholds:  models.DifficultyThresholds{Easy: 75, Medium: 150, Hard: 225, Deadly: 300},
+				Difficulty:  models.TrivialDifficulty,
+			},
+		},
+		{
+			name:       "empty party",
+			creatureXP: []int{100},
+			wantErr:    difficulty.ErrEmptyParty,
+		},
+		{
+			name:        "level out of range",
+			creatureXP:  []int{100},
+			partyLevels: []int{3, 21},
+			wantErr:     difficulty.ErrInvalidLevel,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := difficulty.Calculate(tt.creatureXP, tt.partyLevels)
+
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "expected %v, got %v", tt.wantErr, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, result)
+		})
+	}
+}
+
+func TestCreatureXP(t *testing.T) {
+	t.Parallel()
+
+	xp, err := difficulty.CreatureXP(&models.Creature{ChallengeRating: "1/4", Experience: 60})
+	assert.NoError(t, err)
+	assert.Equal(t, 60, xp)
+
+	xp, err = difficulty.CreatureXP(&models.Creature{ChallengeRating: " 1/4 "})
+	assert.NoError(t, err)
+	assert.Equal(t, 50, xp)
+
+	_, err = difficulty.CreatureXP(&models.Creature{ChallengeRating: "—"})
+	assert.ErrorIs(t, err, difficulty.ErrUnknownChallengeRating)
+}
+
+func TestChallengeRatingsBelow(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"0", "1/8", "1/4", "1/2"}, difficulty.ChallengeRatingsBelow(200))
+	assert.Empty(t, difficulty.ChallengeRatingsBelow(10))
+}
@@ -0,0 +1,211 @@
+package generator
+
+import (
+	"errors"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/difficulty"
+)
+
+const (
+	maxAttempts = 200
+	maxKinds    = 3 // Больше трёх видов существ в одной группе DMG не рекомендует
+)
+
+var (
+	ErrInvalidTarget = errors.New("target difficulty must be easy, medium, hard or deadly")
+	ErrNoGroups      = errors.New("no monster group fits the XP budget")
+)
+
+// RNG возвращает случайное число от 0 до n-1
+type RNG func(n int) int
+
+// DefaultRNG — генератор по умолчанию. Подбор групп не требует криптостойкости
+func DefaultRNG(n int) int {
+	return rand.IntN(n)
+}
+
+// Option — существо, которое может попасть в группу
+type Option struct {
+	ID string
+	XP int
+}
+
+type Pick struct {
+	ID    string
+	Count int
+}
+
+type Group struct {
+	Picks      []Pick
+	Difficulty *models.EncounterDifficulty
+}
+
+// Budget возвращает границы скорректированного опыта [low, high) для сложности. Верхняя граница
+// смертельного боя отстоит от порога на столько же, на сколько порог смертельного боя от сложного
+func Budget(t models.DifficultyThresholds, target models.Difficulty) (int, int, error) {
+	switch target {
+	case models.EasyDifficulty:
+		return t.Easy, t.Medium, nil
+	case models.MediumDifficulty:
+		return t.Medium, t.Hard, nil
+	case models.HardDifficulty:
+		return t.Hard, t.Deadly, nil
+	case models.DeadlyDifficulty:
+		return t.Deadly, 2*t.Deadly - t.Hard, nil
+	default:
+		return 0, 0, ErrInvalidTarget
+	}
+}
+
+// Generate подбирает до count разных групп монстров, скорректированный опыт которых попадает в бюджет
+// сложности target для группы персонажей partyLevels. Каждая попытка выбирает от одного до трёх
+// видов существ и добавляет монстров, пока опыт не достигнет нижней границы, не переходя верхнюю
+func Generate(options []Option, partyLevels []int, target models.Difficulty, count, maxMonsters int,
+	rng RNG) ([]Group, error) {
+	thresholds, err := difficulty.PartyThresholds(partyLevels)
+	if err != nil {
+		return nil, err
+	}
+
+	low, high, err := Budget(thresholds, target)
+	if err != nil {
+		return nil, err
+	}
+
+	usable := make([]Option, 0, len(options))
+	for _, option := range options {
+		duplicate := slices.ContainsFunc(usable, func(o Option) bool { return o.ID == option.ID })
+		if !duplicate && option.XP > 0 && adjustedXP(option.XP, 1, len(partyLevels)) < high {
+			usable = append(usable, option)
+		}
+	}
+
+	if len(usable) == 0 {
+		return nil, ErrNoGroups
+	}
+
+	groups := make([]Group, 0, count)
+	seen := make(map[string]struct{})
+
+	for attempt := 0; attempt < maxAttempts && len(groups) < count; attempt++ {
+		picks, ok := pack(usable, len(partyLevels), low, high, maxMonsters, rng)
+		if !ok {
+			continue
+		}
+
+		key := signature(picks)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+
+		seen[key] = struct{}{}
+
+		xp := make([]int, 0, maxMonsters)
+		for _, pick := range picks {
+			option := usable[slices.IndexFunc(usable, func(o Option) bool { return o.ID == pick.ID })]
+			for i := 0; i < pick.Count; i++ {
+				xp = append(xp, option.XP)
+			}
+		}
+
+		result, err := difficulty.Calculate(xp, partyLevels)
+		if err != nil {
+			return nil, err
+		}
+
+		groups = append(groups, Group{Picks: picks, Difficulty: result})
+	}
+
+	if len(groups) == 0 {
+		return nil, ErrNoGroups
+	}
+
+	return groups, nil
+}
+
+func pack(options []Option, partySize, low, high, maxMonsters int, rng RNG) ([]Pick, bool) {
+	kinds := 1 + rng(min(maxKinds, len(options)))
+
+	// Частичное перемешивание Фишера — Йетса выбирает kinds разных существ
+	idx := make([]int, len(options))
+	for i := range idx {
+		idx[i] = i
+	}
+
+	for i := 0; i < kinds; i++ {
+		j := i + rng(len(idx)-i)
+		idx[i], idx[j] = idx[j], idx[i]
+	}
+
+	chosen := idx[:kinds]
+	counts := make([]int, kinds)
+	total, monsters := 0, 0
+
+	fits := func(i int) bool {
+		return monsters < maxMonsters && adjustedXP(total+options[chosen[i]].XP, monsters+1, partySize) < high
+	}
+
+	add := func(i int) {
+		counts[i]++
+		total += options[chosen[i]].XP
+		monsters++
+	}
+
+	for i := range chosen {
+		if !fits(i) {
+			return nil, false
+		}
+
+		add(i)
+	}
+
+	for adjustedXP(total, monsters, partySize) < low {
+		candidates := make([]int, 0, kinds)
+		for i := range chosen {
+			if fits(i) {
+				candidates = append(candidates, i)
+			}
+		}
+
+		if len(candidates) == 0 {
+			return nil, false
+		}
+
+		add(candidates[rng(len(candidates))])
+	}
+
+	picks := make([]Pick, 0, kinds)
+	for i, optionIdx := range chosen {
+		picks = append(picks, Pick{ID: options[optionIdx].ID, Count: counts[i]})
+	}
+
+	slices.SortFunc(picks, func(a, b Pick) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+
+	return picks, true
+}
+
+// adjustedXP считает скорректированный опыт так же, как difficulty.Calculate
+func adjustedXP(total, monsters, partySize int) int {
+	return int(math.Round(float64(total) * difficulty.Multiplier(monsters, partySize)))
+}
+
+func signature(picks []Pick) string {
+	var b strings.Builder
+
+	for _, pick := range picks {
+		b.WriteString(pick.ID)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(pick.Count))
+		b.WriteByte(';')
+	}
+
+	return b.String()
+}
@@ -0,0 +1,102 @@
+package generator_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/difficulty"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/generator"
+	"github.com/stretchr/testify/assert"
+)
+
+func firstRNG(_ int) int { return 0 }
+
+func TestGenerate_SingleOption(t *testing.T) {
+	t.Parallel()
+
+	options := []generator.Option{{ID: "goblin", XP: 50}, {ID: "goblin", XP: 50}}
+
+	groups, err := generator.Generate(options, []int{1, 1, 1, 1}, models.EasyDifficulty, 3, 10, firstRNG)
+
+	assert.NoError(t, err)
+	assert.Len(t, groups, 1)
+	assert.Equal(t, []generator.Pick{{ID: "goblin", Count: 2}}, groups[0].Picks)
+	assert.Equal(t, 150, groups[0].Difficulty.AdjustedXP)
+	assert.Equal(t, models.EasyDifficulty, groups[0].Difficulty.Difficulty)
+}
+
+func TestGenerate_GroupsMatchTarget(t *testing.T) {
+	t.Parallel()
+
+	options := []generator.Option{
+		{ID: "kobold", XP: 25}, {ID: "goblin", XP: 50}, {ID: "orc", XP: 100}, {ID: "bugbear", XP: 200},
+		{ID: "ogre", XP: 450}, {ID: "troll", XP: 1800}, {ID: "dragon", XP: 50000},
+	}
+	party := []int{3, 3, 4, 4}
+
+	for _, target := range []models.Difficulty{models.EasyDifficulty, models.MediumDifficulty,
+		models.HardDifficulty, models.DeadlyDifficulty} {
+		groups, err := generator.Generate(options, party, target, 5, 8, generator.DefaultRNG)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, groups)
+		assert.LessOrEqual(t, len(groups), 5)
+
+		for _, group := range groups {
+			assert.Equal(t, target, group.Difficulty.Difficulty)
+			assert.LessOrEqual(t, group.Difficulty.MonsterCount, 8)
+			assert.LessOrEqual(t, len(group.Picks), 3)
+
+			for _, pick := range group.Picks {
+				assert.NotEqual(t, "dragon", pick.ID)
+				assert.Positive(t, pick.Count)
+			}
+		}
+	}
+}
+
+func TestGenerate_Errors(t *testing.T) {
+	t.Parallel()
+
+	options := []generator.Option{{ID: "goblin", XP: 50}}
+
+	tests := []struct {
+		name    string
+		options []generator.Option
+		party   []int
+		target  models.Difficulty
+		wantErr error
+	}{
+		{"trivial target", options, []int{1}, models.TrivialDifficulty, generator.ErrInvalidTarget},
+		{"unknown target", options, []int{1}, "impossible", generator.ErrInvalidTarget},
+		{"empty party", options, nil, models.HardDifficulty, difficulty.ErrEmptyParty},
+		{"monsters are too strong", []generator.Option{{ID: "dragon", XP: 50000}}, []int{1},
+			models.HardDifficulty, generator.ErrNoGroups},
+		{"budget cannot be reached", options, []int{1, 1, 1, 1}, models.MediumDifficulty, generator.ErrNoGroups},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			groups, err := generator.Generate(tt.options, tt.party, tt.target, 3, 10, firstRNG)
+
+			assert.True(t, errors.Is(err, tt.wantErr), "expected %v, got %v", tt.wantErr, err)
+			assert.Nil(t, groups)
+		})
+	}
+}
+
+func TestBudget(t *testing.T) {
+	t.Parallel()
+
+	thresholds := models.DifficultyThresholds{Easy: 100, Medium: 200, Hard: 300, Deadly: 400}
+
+	low, high, err := generator.Budget(thresholds, models.MediumDifficulty)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{200, 300}, []int{low, high})
+
+	low, high, err = generator.Budget(thresholds, models.DeadlyDifficulty)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{400, 500}, []int{low, high})
+}
@@ -4,26 +4,87 @@ package encounter
 
 import (
 	"context"
+	"time"
 
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
 )
 
 type EncounterRepository interface {
-	GetEncountersListWithSearch(ctx context.Context, size, start, userID int,
-		search *models.SearchParams) (*models.EncountersList, error)
-	GetEncountersList(ctx context.Context, size, start, userID int) (*models.EncountersList, error)
+	GetEncountersListWithSearch(ctx context.Context, size, start, userID int, filter *models.EncounterFilterParams,
+		order *models.Order, search *models.SearchParams) (*models.EncountersList, error)
+	GetEncountersList(ctx context.Context, size, start, userID int, filter *models.EncounterFilterParams,
+		order *models.Order) (*models.EncountersList, error)
 	GetEncounterByID(ctx context.Context, id string) (*models.Encounter, error)
 	SaveEncounter(ctx context.Context, encounter *models.SaveEncounterReq, id string, userID int) error
-	UpdateEncounter(ctx context.Context, data []byte, id string) error
+	UpdateEncounter(ctx context.Context, data []byte, id string, change *models.EncounterChange) error
 	RemoveEncounter(ctx context.Context, id string) error
-	CheckPermission(ctx context.Context, id string, userID int) bool
+	CheckPermission(ctx context.Context, id string, userID int, role models.EncounterRole) bool
+
+	GetEncounterVersions(ctx context.Context, id string, size, start int) (*models.EncounterVersionsList, error)
+	GetEncounterVersion(ctx context.Context, id string, version int) (*models.EncounterVersion, error)
+
+	GetDeletedEncounters(ctx context.Context, size, start, userID int) (*models.DeletedEncountersList, error)
+	RestoreEncounter(ctx context.Context, id string, userID int) error
+	PurgeEncounter(ctx context.Context, id string, userID int) error
+	PurgeDeletedEncounters(ctx context.Context, before time.Time) (int64, error)
+
+	GetSharedEncounters(ctx context.Context, size, start, userID int) (*models.SharedEncountersList, error)
+	GetEncounterShares(ctx context.Context, id string) (*models.EncounterSharesList, error)
+	ShareEncounter(ctx context.Context, share *models.EncounterShare) error
+	RevokeEncounterShare(ctx context.Context, id string, userID int) error
+	CreateShareLink(ctx context.Context, link *models.EncounterShareLink) error
+	GetShareLinks(ctx context.Context, id string) (*models.EncounterShareLinksList, error)
+	RevokeShareLink(ctx context.Context, id, token string) error
+	GetEncounterByShareLink(ctx context.Context, token string) (*models.Encounter, error)
+
+	UpdateEncounterOrganization(ctx context.Context, id string, req *models.EncounterOrganizationReq) error
+	GetEncounterFolders(ctx context.Context, userID int) (*models.EncounterFoldersList, error)
+	CreateEncounterFolder(ctx context.Context, folder *models.EncounterFolder) error
+	RenameEncounterFolder(ctx context.Context, id, userID int, name string) error
+	RemoveEncounterFolder(ctx context.Context, id, userID int) error
 }
 
 type EncounterUsecases interface {
-	GetEncountersList(ctx context.Context, size, start, userID int,
-		search *models.SearchParams) (*models.EncountersList, error)
+	GetEncountersList(ctx context.Context, size, start, userID int, filter *models.EncounterFilterParams,
+		order *models.Order, search *models.SearchParams) (*models.EncountersList, error)
 	GetEncounterByID(ctx context.Context, id string, userID int) (*models.Encounter, error)
 	SaveEncounter(ctx context.Context, encounter *models.SaveEncounterReq, userID int) error
-	UpdateEncounter(ctx context.Context, data []byte, id string, userID int) error
+	UpdateEncounter(ctx context.Context, data []byte, format models.PatchFormat, id string, userID int) error
 	RemoveEncounter(ctx context.Context, id string, userID int) error
+
+	GetDifficulty(ctx context.Context, req *models.DifficultyReq, userID int) (*models.EncounterDifficulty, error)
+	GenerateEncounters(ctx context.Context, req *models.GenerateEncounterReq,
+		userID int) (*models.GeneratedEncounters, error)
+
+	GetEncounterVersions(ctx context.Context, id string, size, start, userID int) (*models.EncounterVersionsList, error)
+	GetEncounterVersion(ctx context.Context, id string, version, userID int) (*models.EncounterVersion, error)
+	DiffEncounterVersions(ctx context.Context, id string, from, to, userID int) (*models.EncounterVersionsDiff, error)
+	RestoreEncounterVersion(ctx context.Context, id string, version, userID int) error
+
+	GetDeletedEncounters(ctx context.Context, size, start, userID int) (*models.DeletedEncountersList, error)
+	RestoreEncounter(ctx context.Context, id string, userID int) error
+	PurgeEncounter(ctx context.Context, id string, userID int) error
+	RunTrashPurge(ctx context.Context, retention, interval time.Duration)
+
+	GetSharedEncounters(ctx context.Context, size, start, userID int) (*models.SharedEncountersList, error)
+	GetEncounterShares(ctx context.Context, id string, userID int) (*models.EncounterSharesList, error)
+	ShareEncounter(ctx context.Context, id string, targetID int, role models.EncounterRole, userID int) error
+	RevokeEncounterShare(ctx context.Context, id string, targetID, userID int) error
+	CreateShareLink(ctx context.Context, id string, req *models.CreateShareLinkReq,
+		userID int) (*models.EncounterShareLink, error)
+	GetShareLinks(ctx context.Context, id string, userID int) (*models.EncounterShareLinksList, error)
+	RevokeShareLink(ctx context.Context, id, token string, userID int) error
+	GetEncounterByShareLink(ctx context.Context, token string, userID int) (*models.Encounter, error)
+
+	DuplicateEncounter(ctx context.Context, id string, req *models.CopyEncounterReq,
+		userID int) (*models.Encounter, error)
+	InstantiateTemplate(ctx context.Context, id string, req *models.CopyEncounterReq,
+		userID int) (*models.Encounter, error)
+	UpdateEncounterOrganization(ctx context.Context, id string, req *models.EncounterOrganizationReq,
+		userID int) error
+	GetEncounterFolders(ctx context.Context, userID int) (*models.EncounterFoldersList, error)
+	CreateEncounterFolder(ctx context.Context, req *models.EncounterFolderReq,
+		userID int) (*models.EncounterFolder, error)
+	RenameEncounterFolder(ctx context.Context, id int, req *models.EncounterFolderReq, userID int) error
+	RemoveEncounterFolder(ctx context.Context, id, userID int) error
 }
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/repository/dbcall"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const uniqueViolation = "23505"
+
+// UpdateEncounterOrganization заменяет папку, теги и признак шаблона. Папка должна принадлежать
+// владельцу энкаунтера, это проверяет внешний ключ
+func (s *encounterStorage) UpdateEncounterOrganization(ctx context.Context, id string,
+	req *models.EncounterOrganizationReq) error {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	err := dbcall.ErrOnlyDBCall(fnName, s.metrics, func() error {
+		_, err := s.pool.Exec(ctx, UpdateEncounterOrganizationQuery, id, req.FolderID, req.Tags, req.IsTemplate)
+
+		return err
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			l.RepoWarn(err, map[string]any{"id": id, "folder_id": req.FolderID})
+			return apperrors.InvalidEncounterFolderErr
+		}
+
+		l.RepoError(err, map[string]any{"id": id})
+		return apperrors.TxError
+	}
+
+	return nil
+}
+
+func (s *encounterStorage) GetEncounterFolders(ctx context.Context, userID int) (*models.EncounterFoldersList, error) {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	rows, err := dbcall.DBCall[pgx.Rows](fnName, s.metrics, func() (pgx.Rows, error) {
+		return s.pool.Query(ctx, GetEncounterFoldersQuery, userID)
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"userID": userID})
+		return nil, apperrors.QueryError
+	}
+	defer rows.Close()
+
+	list := make(models.EncounterFoldersList, 0)
+
+	for rows.Next() {
+		var folder models.EncounterFolder
+
+		if err := rows.Scan(&folder.ID, &folder.UserID, &folder.Name, &folder.CreatedAt); err != nil {
+			l.RepoError(err, map[string]any{"userID": userID})
+			return nil, apperrors.ScanError
+		}
+
+		list = append(list, &folder)
+	}
+
+	return &list, nil
+}
+
+func (s *encounterStorage) CreateEncounterFolder(ctx context.Context, folder *models.EncounterFolder) error {
+	fnName := utils.GetFunctionName()
+
+	_, err := dbcall.DBCall[*models.EncounterFolder](fnName, s.metrics, func() (*models.EncounterFolder, error) {
+		line := s.pool.QueryRow(ctx, CreateEncounterFolderQuery, folder.UserID, folder.Name)
+		if err := line.Scan(&folder.ID, &folder.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		return folder, nil
+	})
+	if err != nil {
+		return folderError(ctx, err, map[string]any{"userID": folder.UserID, "name": folder.Name})
+	}
+
+	return nil
+}
+
+func (s *encounterStorage) RenameEncounterFolder(ctx context.Context, id, userID int, name string) error {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	var found bool
+
+	err := dbcall.ErrOnlyDBCall(fnName, s.metrics, func() error {
+		tag, err := s.pool.Exec(ctx, RenameEncounterFolderQuery, id, userID, name)
+		if err != nil {
+			return err
+		}
+
+		found = tag.RowsAffected() > 0
+
+		return nil
+	})
+	if err != nil {
+		return folderError(ctx, err, map[string]any{"id": id, "userID": userID, "name": name})
+	}
+
+	if !found {
+		l.RepoWarn(apperrors.InvalidEncounterFolderErr, map[string]any{"id": id, "userID": userID})
+		return apperrors.InvalidEncounterFolderErr
+	}
+
+	return nil
+}
+
+// RemoveEncounterFolder удаляет папку. Энкаунтеры из неё остаются без папки
+func (s *encounterStorage) RemoveEncounterFolder(ctx context.Context, id, userID int) error {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	var found bool
+
+	err := dbcall.ErrOnlyDBCall(fnName, s.metrics, func() error {
+		tag, err := s.pool.Exec(ctx, RemoveEncounterFolderQuery, id, userID)
+		if err != nil {
+			return err
+		}
+
+		found = tag.RowsAffected() > 0
+
+		return nil
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"id": id, "userID": userID})
+		return apperrors.TxError
+	}
+
+	if !found {
+		l.RepoWarn(apperrors.InvalidEncounterFolderErr, map[string]any{"id": id, "userID": userID})
+		return apperrors.InvalidEncounterFolderErr
+	}
+
+	return nil
+}
+
+// folderError переводит нарушение уникальности имени папки в InvalidEncounterFolderErr
+func folderError(ctx context.Context, err error, data map[string]any) error {
+	l := logger.FromContext(ctx)
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		l.RepoWarn(err, data)
+		return apperrors.InvalidEncounterFolderErr
+	}
+
+	l.RepoError(err, data)
+
+	return apperrors.TxError
+}
@@ -12,13 +12,14 @@ import (
 	"github.com/jackc/pgx/v5"
 )
 
-func (s *encounterStorage) GetEncountersList(ctx context.Context,
-	size, start, userID int) (*models.EncountersList, error) {
+func (s *encounterStorage) GetEncountersList(ctx context.Context, size, start, userID int,
+	filter *models.EncounterFilterParams, order *models.Order) (*models.EncountersList, error) {
 	l := logger.FromContext(ctx)
 	fnName := utils.GetFunctionName()
 
 	rows, err := dbcall.DBCall[pgx.Rows](fnName, s.metrics, func() (pgx.Rows, error) {
-		return s.pool.Query(ctx, GetEncountersListQuery, userID, size, start)
+		return s.pool.Query(ctx, GetEncountersListQuery, userID, filter.FolderID, filter.Tag, filter.Template,
+			order.Field, order.Direction, size, start)
 	})
 	if err != nil && errors.Is(err, pgx.ErrNoRows) {
 		l.RepoWarn(err, map[string]any{"size": size, "start": start, "userID": userID})
@@ -34,7 +35,7 @@ func (s *encounterStorage) GetEncountersList(ctx context.Context,
 	for rows.Next() {
 		var encounter models.EncounterInList
 
-		if err := rows.Scan(&encounter.UserID, &encounter.Name, &encounter.UUID); err != nil {
+		if err := scanEncounterInList(rows, &encounter); err != nil {
 			l.RepoError(err, map[string]any{"size": size, "start": start, "userID": userID})
 			return nil, apperrors.ScanError
 		}
@@ -46,6 +47,7 @@ func (s *encounterStorage) GetEncountersList(ctx context.Context,
 }
 
 func (s *encounterStorage) GetEncountersListWithSearch(ctx context.Context, size, start, userID int,
+	filter *models.EncounterFilterParams, order *models.Order,
 	search *models.SearchParams) (*models.EncountersList, error) {
 	l := logger.FromContext(ctx)
 	fnName := utils.GetFunctionName()
@@ -53,7 +55,8 @@ func (s *encounterStorage) GetEncountersListWithSearch(ctx context.Context, size
 	searchValue := fmt.Sprintf("%s:*", search.Value)
 
 	rows, err := dbcall.DBCall[pgx.Rows](fnName, s.metrics, func() (pgx.Rows, error) {
-		return s.pool.Query(ctx, GetEncountersListWithSearchQuery, userID, searchValue, size, start)
+		return s.pool.Query(ctx, GetEncountersListWithSearchQuery, userID, filter.FolderID, filter.Tag,
+			filter.Template, order.Field, order.Direction, size, start, searchValue)
 	})
 	if err != nil && errors.Is(err, pgx.ErrNoRows) {
 		l.RepoWarn(err, map[string]any{"size": size, "start": start, "userID": userID, "search": searchValue})
@@ -69,7 +72,7 @@ func (s *encounterStorage) GetEncountersListWithSearch(ctx context.Context, size
 	for rows.Next() {
 		var encounter models.EncounterInList
 
-		if err := rows.Scan(&encounter.UserID, &encounter.Name, &encounter.UUID); err != nil {
+		if err := scanEncounterInList(rows, &encounter); err != nil {
 			l.RepoError(err, map[string]any{"size": size, "start": start, "userID": userID, "search": searchValue})
 			return nil, apperrors.ScanError
 		}
@@ -80,6 +83,11 @@ func (s *encounterStorage) GetEncountersListWithSearch(ctx context.Context, size
 	return &list, nil
 }
 
+func scanEncounterInList(rows pgx.Rows, encounter *models.EncounterInList) error {
+	return rows.Scan(&encounter.UserID, &encounter.Name, &encounter.UUID, &encounter.FolderID, &encounter.Tags,
+		&encounter.IsTemplate, &encounter.CreatedAt, &encounter.UpdatedAt)
+}
+
 func (s *encounterStorage) GetEncounterByID(ctx context.Context, id string) (*models.Encounter, error) {
 	l := logger.FromContext(ctx)
 	fnName := utils.GetFunctionName()
@@ -88,8 +96,9 @@ func (s *encounterStorage) GetEncounterByID(ctx context.Context, id string) (*mo
 
 	_, err := dbcall.DBCall[*models.Encounter](fnName, s.metrics, func() (*models.Encounter, error) {
 		line := s.pool.QueryRow(ctx, GetEncounterByIDQuery, id)
-		if err := line.Scan(&encounter.UserID, &encounter.Name, &encounter.Data,
-			&encounter.UUID); err != nil {
+		if err := line.Scan(&encounter.UserID, &encounter.Name, &encounter.Data, &encounter.UUID,
+			&encounter.FolderID, &encounter.Tags, &encounter.IsTemplate, &encounter.CreatedAt,
+			&encounter.UpdatedAt); err != nil {
 			return nil, err
 		}
 
@@ -102,3 +111,62 @@ func (s *encounterStorage) GetEncounterByID(ctx context.Context, id string) (*mo
 
 	return &encounter, nil
 }
+
+func (s *encounterStorage) GetEncounterVersions(ctx context.Context, id string,
+	size, start int) (*models.EncounterVersionsList, error) {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	rows, err := dbcall.DBCall[pgx.Rows](fnName, s.metrics, func() (pgx.Rows, error) {
+		return s.pool.Query(ctx, GetEncounterVersionsQuery, id, size, start)
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"id": id, "size": size, "start": start})
+		return nil, apperrors.QueryError
+	}
+	defer rows.Close()
+
+	list := make(models.EncounterVersionsList, 0)
+
+	for rows.Next() {
+		var version models.EncounterVersion
+
+		if err := rows.Scan(&version.EncounterID, &version.Version, &version.AuthorID, &version.Source,
+			&version.RestoredFrom, &version.CreatedAt); err != nil {
+			l.RepoError(err, map[string]any{"id": id, "size": size, "start": start})
+			return nil, apperrors.ScanError
+		}
+
+		list = append(list, &version)
+	}
+
+	return &list, nil
+}
+
+func (s *encounterStorage) GetEncounterVersion(ctx context.Context, id string,
+	version int) (*models.EncounterVersion, error) {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	var encounterVersion models.EncounterVersion
+
+	_, err := dbcall.DBCall[*models.EncounterVersion](fnName, s.metrics, func() (*models.EncounterVersion, error) {
+		line := s.pool.QueryRow(ctx, GetEncounterVersionQuery, id, version)
+		if err := line.Scan(&encounterVersion.EncounterID, &encounterVersion.Version, &encounterVersion.AuthorID,
+			&encounterVersion.Source, &encounterVersion.RestoredFrom, &encounterVersion.CreatedAt,
+			&encounterVersion.Data); err != nil {
+			return nil, err
+		}
+
+		return &encounterVersion, nil
+	})
+	if err != nil && errors.Is(err, pgx.ErrNoRows) {
+		l.RepoWarn(err, map[string]any{"id": id, "version": version})
+		return nil, apperrors.EncounterVersionNotFoundErr
+	} else if err != nil {
+		l.RepoError(err, map[string]any{"id": id, "version": version})
+		return nil, apperrors.ScanError
+	}
+
+	return &encounterVersion, nil
+}
@@ -8,8 +8,10 @@ import (
 	"encoding/json"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/repository"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/testhelpers"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -27,12 +29,82 @@ const (
 
 		CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
 
+		CREATE TABLE IF NOT EXISTS public.encounter_folders (
+			id BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+			user_id BIGINT NOT NULL REFERENCES public."user"(id) ON DELETE CASCADE,
+			name TEXT NOT NULL CHECK(name <> ''),
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			UNIQUE (user_id, name),
+			UNIQUE (id, user_id)
+		);
+
 		CREATE TABLE IF NOT EXISTS public.encounter_store (
 			user_id BIGINT NOT NULL REFERENCES public."user"(id),
 			name TEXT NOT NULL CHECK(name <> '') CONSTRAINT max_len_name CHECK(LENGTH(name) <= 60),
 			data BYTEA NOT NULL,
 			is_deleted BOOLEAN DEFAULT FALSE,
-			uuid UUID NOT NULL PRIMARY KEY
+			deleted_at TIMESTAMPTZ,
+			uuid UUID NOT NULL PRIMARY KEY,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			is_template BOOLEAN NOT NULL DEFAULT FALSE,
+			tags TEXT[] NOT NULL DEFAULT '{}',
+			folder_id BIGINT,
+			FOREIGN KEY (folder_id, user_id) REFERENCES public.encounter_folders (id, user_id)
+				ON DELETE SET NULL (folder_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS public.encounter_versions (
+			encounter_id UUID NOT NULL REFERENCES public.encounter_store(uuid) ON DELETE CASCADE,
+			version INT NOT NULL CHECK(version > 0),
+			data BYTEA NOT NULL,
+			author_id BIGINT NOT NULL REFERENCES public."user"(id),
+			source TEXT NOT NULL,
+			restored_from INT,
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			PRIMARY KEY (encounter_id, version)
+		);
+
+		CREATE TABLE IF NOT EXISTS public.encounter_shares (
+			encounter_id UUID NOT NULL REFERENCES public.encounter_store(uuid) ON DELETE CASCADE,
+			user_id BIGINT NOT NULL REFERENCES public."user"(id) ON DELETE CASCADE,
+			role TEXT NOT NULL CHECK(role IN ('viewer', 'editor', 'co-owner')),
+			granted_by BIGINT NOT NULL REFERENCES public."user"(id),
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			PRIMARY KEY (encounter_id, user_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS public.encounter_share_links (
+			token TEXT PRIMARY KEY,
+			encounter_id UUID NOT NULL REFERENCES public.encounter_store(uuid) ON DELETE CASCADE,
+			created_by BIGINT NOT NULL REFERENCES public."user"(id),
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			expires_at TIMESTAMPTZ
+		);
+
+		CREATE TABLE IF NOT EXISTS public.campaigns (
+			id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+			user_id BIGINT NOT NULL REFERENCES public."user"(id) ON DELETE CASCADE,
+			name TEXT NOT NULL CHECK(name <> ''),
+			description TEXT NOT NULL DEFAULT '',
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		);
+
+		CREATE TABLE IF NOT EXISTS public.campaign_members (
+			campaign_id UUID NOT NULL REFERENCES public.campaigns(id) ON DELETE CASCADE,
+			user_id BIGINT NOT NULL REFERENCES public."user"(id) ON DELETE CASCADE,
+			added_by BIGINT NOT NULL REFERENCES public."user"(id),
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			PRIMARY KEY (campaign_id, user_id)
+		);
+
+		CREATE TABLE IF NOT EXISTS public.campaign_encounters (
+			campaign_id UUID NOT NULL REFERENCES public.campaigns(id) ON DELETE CASCADE,
+			encounter_id UUID NOT NULL REFERENCES public.encounter_store(uuid) ON DELETE CASCADE,
+			added_by BIGINT NOT NULL REFERENCES public."user"(id),
+			created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+			PRIMARY KEY (campaign_id, encounter_id)
 		);
 	`
 
@@ -42,13 +114,34 @@ const (
 		RETURNING id;
 	`
 
+	seedCampaignSQL = `
+		WITH player AS (
+			INSERT INTO public."user" (display_name) VALUES ('Integration Tester') RETURNING id
+		), campaign AS (
+			INSERT INTO public.campaigns (user_id, name) VALUES ($1, 'Integration Campaign') RETURNING id
+		), member AS (
+			INSERT INTO public.campaign_members (campaign_id, user_id, added_by)
+			SELECT campaign.id, player.id, $1 FROM campaign, player
+		)
+		INSERT INTO public.campaign_encounters (campaign_id, encounter_id, added_by)
+		SELECT campaign.id, $2, $1 FROM campaign
+		RETURNING (SELECT id FROM player);
+	`
+
 	cleanupSQL = `
 		DELETE FROM public.encounter_store WHERE name = 'Integration Smoke Test';
 		DELETE FROM public."user" WHERE display_name = 'Integration Tester';
 	`
 
 	teardownSQL = `
+		DROP TABLE IF EXISTS public.campaign_encounters;
+		DROP TABLE IF EXISTS public.campaign_members;
+		DROP TABLE IF EXISTS public.campaigns;
+		DROP TABLE IF EXISTS public.encounter_share_links;
+		DROP TABLE IF EXISTS public.encounter_shares;
+		DROP TABLE IF EXISTS public.encounter_versions;
 		DROP TABLE IF EXISTS public.encounter_store;
+		DROP TABLE IF EXISTS public.encounter_folders;
 		DROP TABLE IF EXISTS public."user";
 	`
 )
@@ -90,10 +183,17 @@ func TestEncounterRepo_SaveAndGetByID(t *testing.T) {
 
 	repo := repository.NewEncounterStorage(pool, testhelpers.NoopDBMetrics())
 
+	folder := &models.EncounterFolder{UserID: userID, Name: "Integration Campaign"}
+	assert.NoError(t, repo.CreateEncounterFolder(ctx, folder))
+	assert.ErrorIs(t, repo.CreateEncounterFolder(ctx, &models.EncounterFolder{UserID: userID, Name: folder.Name}),
+		apperrors.InvalidEncounterFolderErr)
+
 	encounterUUID := "00000000-0000-0000-0000-000000000001"
 	saveReq := &models.SaveEncounterReq{
-		Name: "Integration Smoke Test",
-		Data: json.RawMessage(`{"monsters":[]}`),
+		Name:     "Integration Smoke Test",
+		Data:     json.RawMessage(`{"monsters":[]}`),
+		FolderID: &folder.ID,
+		Tags:     []string{"goblins"},
 	}
 
 	// Save.
@@ -107,4 +207,105 @@ func TestEncounterRepo_SaveAndGetByID(t *testing.T) {
 	assert.Equal(t, saveReq.Name, got.Name)
 	assert.Equal(t, encounterUUID, got.UUID)
 	assert.Equal(t, userID, got.UserID)
+	assert.Equal(t, &folder.ID, got.FolderID)
+
+	// Lists are filtered by folder and tag.
+	order := &models.Order{Field: models.EncounterOrderByName, Direction: "asc"}
+
+	list, err := repo.GetEncountersList(ctx, 10, 0, userID, &models.EncounterFilterParams{Tag: "goblins"}, order)
+	assert.NoError(t, err)
+	assert.Len(t, *list, 1)
+
+	list, err = repo.GetEncountersList(ctx, 10, 0, userID, &models.EncounterFilterParams{Tag: "dragons"}, order)
+	assert.NoError(t, err)
+	assert.Empty(t, *list)
+
+	// Removing the folder keeps the encounter.
+	assert.NoError(t, repo.RemoveEncounterFolder(ctx, folder.ID, userID))
+
+	got, err = repo.GetEncounterByID(ctx, encounterUUID)
+	assert.NoError(t, err)
+	assert.Nil(t, got.FolderID)
+
+	// The owner passes any role check, other users need a share.
+	assert.True(t, repo.CheckPermission(ctx, encounterUUID, userID, models.OwnerEncounterRole))
+	assert.False(t, repo.CheckPermission(ctx, encounterUUID, userID+1, models.ViewerEncounterRole))
+
+	// Campaign players see only the encounter card on the dashboard, reading the data needs a share.
+	var playerID int
+	err = pool.QueryRow(ctx, seedCampaignSQL, userID, encounterUUID).Scan(&playerID)
+	if err != nil {
+		t.Fatalf("failed to seed test campaign: %v", err)
+	}
+
+	assert.False(t, repo.CheckPermission(ctx, encounterUUID, playerID, models.ViewerEncounterRole))
+
+	link := &models.EncounterShareLink{Token: "integration-link", EncounterID: encounterUUID, CreatedBy: userID}
+	assert.NoError(t, repo.CreateShareLink(ctx, link))
+
+	shared, err := repo.GetEncounterByShareLink(ctx, link.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, encounterUUID, shared.UUID)
+
+	assert.NoError(t, repo.RevokeShareLink(ctx, encounterUUID, link.Token))
+	assert.ErrorIs(t, repo.RevokeShareLink(ctx, encounterUUID, link.Token), apperrors.ShareLinkNotFoundErr)
+
+	// Update records a new version.
+	err = repo.UpdateEncounter(ctx, json.RawMessage(`{"monsters":[{}]}`), encounterUUID,
+		&models.EncounterChange{AuthorID: userID, Source: models.TableVersionSource})
+	assert.NoError(t, err)
+
+	versions, err := repo.GetEncounterVersions(ctx, encounterUUID, 10, 0)
+	assert.NoError(t, err)
+	assert.Len(t, *versions, 2)
+	assert.Equal(t, 2, (*versions)[0].Version)
+	assert.Equal(t, models.TableVersionSource, (*versions)[0].Source)
+
+	first, err := repo.GetEncounterVersion(ctx, encounterUUID, 1)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"monsters":[]}`, string(first.Data))
+
+	_, err = repo.GetEncounterVersion(ctx, encounterUUID, 3)
+	assert.ErrorIs(t, err, apperrors.EncounterVersionNotFoundErr)
+
+	// Only the latest versions are kept.
+	for range repository.MaxEncounterVersions {
+		err = repo.UpdateEncounter(ctx, json.RawMessage(`{"monsters":[]}`), encounterUUID,
+			&models.EncounterChange{AuthorID: userID, Source: models.RESTVersionSource})
+		assert.NoError(t, err)
+	}
+
+	versions, err = repo.GetEncounterVersions(ctx, encounterUUID, repository.MaxEncounterVersions+10, 0)
+	assert.NoError(t, err)
+	assert.Len(t, *versions, repository.MaxEncounterVersions)
+	assert.Equal(t, repository.MaxEncounterVersions+2, (*versions)[0].Version)
+
+	_, err = repo.GetEncounterVersion(ctx, encounterUUID, 2)
+	assert.ErrorIs(t, err, apperrors.EncounterVersionNotFoundErr)
+
+	// Removed encounters go to the trash and can be restored by their owner.
+	err = repo.RemoveEncounter(ctx, encounterUUID)
+	assert.NoError(t, err)
+
+	trash, err := repo.GetDeletedEncounters(ctx, 10, 0, userID)
+	assert.NoError(t, err)
+	assert.Len(t, *trash, 1)
+
+	assert.ErrorIs(t, repo.RestoreEncounter(ctx, encounterUUID, userID+1), apperrors.EncounterNotInTrashErr)
+	assert.NoError(t, repo.RestoreEncounter(ctx, encounterUUID, userID))
+
+	// Only encounters deleted before the cutoff are purged.
+	assert.NoError(t, repo.RemoveEncounter(ctx, encounterUUID))
+
+	// Trashed encounters are not updated and no version is written.
+	err = repo.UpdateEncounter(ctx, json.RawMessage(`{"monsters":[]}`), encounterUUID,
+		&models.EncounterChange{AuthorID: userID, Source: models.TableVersionSource})
+	assert.ErrorIs(t, err, apperrors.EncounterNotFoundErr)
+
+	purged, err := repo.PurgeDeletedEncounters(ctx, time.Now().Add(-time.Hour))
+	assert.NoError(t, err)
+	assert.Zero(t, purged)
+
+	assert.NoError(t, repo.PurgeEncounter(ctx, encounterUUID, userID))
+	assert.ErrorIs(t, repo.PurgeEncounter(ctx, encounterUUID, userID), apperrors.EncounterNotInTrashErr)
 }
@@ -4,51 +4,137 @@ const (
 	CheckPermissionQuery = `
 		SELECT EXISTS(
 		    SELECT 1
-			FROM public.encounter_store
-			WHERE uuid = $1 AND user_id = $2 AND NOT(is_deleted)
+			FROM public.encounter_store e
+			WHERE e.uuid = $1 AND NOT(e.is_deleted) AND (
+				e.user_id = $2 OR EXISTS(
+					SELECT 1
+					FROM public.encounter_shares s
+					WHERE s.encounter_id = e.uuid AND s.user_id = $2 AND s.role = ANY($3)
+				)
+			)
 		);
 	`
 
 	GetEncounterByIDQuery = `
-		SELECT user_id, name, data, uuid
+		SELECT user_id, name, data, uuid, folder_id, tags, is_template, created_at, updated_at
 		FROM public.encounter_store
 		WHERE uuid = $1 AND NOT(is_deleted);
 	`
 
 	GetEncountersListQuery = `
-		SELECT user_id, name, uuid
+		SELECT user_id, name, uuid, folder_id, tags, is_template, created_at, updated_at
 		FROM public.encounter_store
-		WHERE user_id = $1 AND NOT(is_deleted)
-		LIMIT $2 OFFSET $3;
+		WHERE ` + encountersListFilter + `
+		ORDER BY ` + encountersListOrder + `
+		LIMIT $7 OFFSET $8;
 	`
 
 	GetEncountersListWithSearchQuery = `
-		SELECT user_id, name, uuid
+		SELECT user_id, name, uuid, folder_id, tags, is_template, created_at, updated_at
 		FROM public.encounter_store
-		WHERE user_id = $1
-			AND NOT is_deleted
+		WHERE ` + encountersListFilter + `
 			AND (
 				to_tsvector('russian', name) || to_tsvector('english', name)
 			) @@ (
-				to_tsquery('russian', $2) || to_tsquery('english', $2)
+				to_tsquery('russian', $9) || to_tsquery('english', $9)
 			)
-		LIMIT $3 OFFSET $4;
+		ORDER BY ` + encountersListOrder + `
+		LIMIT $7 OFFSET $8;
 	`
 
+	// Фильтры списка: $2 — папка, $3 — тег, $4 — признак шаблона. NULL и пустой тег не ограничивают выборку.
+	// Тег проверяется через @>, чтобы использовался GIN-индекс по tags
+	encountersListFilter = `user_id = $1
+			AND NOT(is_deleted)
+			AND ($2::BIGINT IS NULL OR folder_id = $2)
+			AND ($3::TEXT = '' OR tags @> ARRAY[$3]::TEXT[])
+			AND ($4::BOOLEAN IS NULL OR is_template = $4)`
+
+	// Сортировка списка: $5 — поле, $6 — направление. uuid делает порядок детерминированным для пагинации
+	encountersListOrder = `CASE WHEN $5::TEXT = 'name' AND $6::TEXT = 'asc' THEN name END ASC,
+			CASE WHEN $5 = 'name' AND $6 = 'desc' THEN name END DESC,
+			CASE WHEN $5 = 'createdAt' AND $6 = 'asc' THEN created_at END ASC,
+			CASE WHEN $5 = 'createdAt' AND $6 = 'desc' THEN created_at END DESC,
+			CASE WHEN $5 = 'updatedAt' AND $6 = 'asc' THEN updated_at END ASC,
+			CASE WHEN $5 = 'updatedAt' AND $6 = 'desc' THEN updated_at END DESC,
+			uuid`
+
 	SaveEncounterQuery = `
-		INSERT INTO public.encounter_store (user_id, name, data, uuid) 
-		VALUES ($1, $2, $3, $4);
+		INSERT INTO public.encounter_store (user_id, name, data, uuid, folder_id, tags, is_template)
+		VALUES ($1, $2, $3, $4, $5, COALESCE($6::TEXT[], '{}'), $7);
 	`
 
 	UpdateEncounterQuery = `
 		UPDATE public.encounter_store
-		SET data = $2
-		WHERE uuid = $1;
+		SET data = $2, updated_at = now()
+		WHERE uuid = $1 AND NOT(is_deleted);
+	`
+
+	UpdateEncounterOrganizationQuery = `
+		UPDATE public.encounter_store
+		SET folder_id = $2, tags = COALESCE($3::TEXT[], '{}'), is_template = $4
+		WHERE uuid = $1 AND NOT(is_deleted);
+	`
+
+	// Номер версии вычисляется под блокировкой строки энкаунтера, которую берёт предшествующий UPDATE
+	SaveEncounterVersionQuery = `
+		INSERT INTO public.encounter_versions (encounter_id, version, data, author_id, source, restored_from)
+		SELECT $1, COALESCE(MAX(version), 0) + 1, $2, $3, $4, $5
+		FROM public.encounter_versions
+		WHERE encounter_id = $1;
+	`
+
+	// Удаляет версии старше последних $2, номера оставшихся версий не меняются
+	PruneEncounterVersionsQuery = `
+		DELETE FROM public.encounter_versions
+		WHERE encounter_id = $1 AND version <= (
+			SELECT MAX(version) - $2
+			FROM public.encounter_versions
+			WHERE encounter_id = $1
+		);
+	`
+
+	GetEncounterVersionsQuery = `
+		SELECT encounter_id, version, author_id, source, COALESCE(restored_from, 0), created_at
+		FROM public.encounter_versions
+		WHERE encounter_id = $1
+		ORDER BY version DESC
+		LIMIT $2 OFFSET $3;
+	`
+
+	GetEncounterVersionQuery = `
+		SELECT encounter_id, version, author_id, source, COALESCE(restored_from, 0), created_at, data
+		FROM public.encounter_versions
+		WHERE encounter_id = $1 AND version = $2;
 	`
 
 	DeleteEncounterQuery = `
 		UPDATE public.encounter_store
-		SET is_deleted = TRUE
+		SET is_deleted = TRUE, deleted_at = now()
 		WHERE uuid = $1;
 	`
+
+	GetDeletedEncountersQuery = `
+		SELECT user_id, name, uuid, deleted_at
+		FROM public.encounter_store
+		WHERE user_id = $1 AND is_deleted
+		ORDER BY deleted_at DESC
+		LIMIT $2 OFFSET $3;
+	`
+
+	RestoreEncounterQuery = `
+		UPDATE public.encounter_store
+		SET is_deleted = FALSE, deleted_at = NULL
+		WHERE uuid = $1 AND user_id = $2 AND is_deleted;
+	`
+
+	PurgeEncounterQuery = `
+		DELETE FROM public.encounter_store
+		WHERE uuid = $1 AND user_id = $2 AND is_deleted;
+	`
+
+	PurgeDeletedEncountersQuery = `
+		DELETE FROM public.encounter_store
+		WHERE is_deleted AND deleted_at < $1;
+	`
 )
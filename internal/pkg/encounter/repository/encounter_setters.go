@@ -2,13 +2,18 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/repository/dbcall"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
+// MaxEncounterVersions — сколько последних версий энкаунтера хранится, более старые удаляются
+const MaxEncounterVersions = 100
+
 func (s *encounterStorage) SaveEncounter(ctx context.Context, encounter *models.SaveEncounterReq, id string,
 	userID int) error {
 	l := logger.FromContext(ctx)
@@ -21,7 +26,13 @@ func (s *encounterStorage) SaveEncounter(ctx context.Context, encounter *models.
 		}
 		defer tx.Rollback(ctx)
 
-		_, err = tx.Exec(ctx, SaveEncounterQuery, userID, encounter.Name, encounter.Data, id)
+		_, err = tx.Exec(ctx, SaveEncounterQuery, userID, encounter.Name, encounter.Data, id, encounter.FolderID,
+			encounter.Tags, encounter.IsTemplate)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(ctx, SaveEncounterVersionQuery, id, encounter.Data, userID, models.RESTVersionSource, nil)
 		if err != nil {
 			return err
 		}
@@ -33,6 +44,12 @@ func (s *encounterStorage) SaveEncounter(ctx context.Context, encounter *models.
 		return nil
 	})
 	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			l.RepoWarn(err, map[string]any{"id": id, "folder_id": encounter.FolderID})
+			return apperrors.InvalidEncounterFolderErr
+		}
+
 		l.RepoError(err, map[string]any{"id": id, "encounter_name": encounter.Name})
 		return apperrors.TxError
 	}
@@ -40,7 +57,11 @@ func (s *encounterStorage) SaveEncounter(ctx context.Context, encounter *models.
 	return nil
 }
 
-func (s *encounterStorage) UpdateEncounter(ctx context.Context, data []byte, id string) error {
+// UpdateEncounter заменяет данные энкаунтера и в той же транзакции сохраняет их как новую версию.
+// Хранятся только последние MaxEncounterVersions версий. Удалённый в корзину или несуществующий энкаунтер
+// не обновляется, возвращается EncounterNotFoundErr
+func (s *encounterStorage) UpdateEncounter(ctx context.Context, data []byte, id string,
+	change *models.EncounterChange) error {
 	l := logger.FromContext(ctx)
 	fnName := utils.GetFunctionName()
 
@@ -51,9 +72,28 @@ func (s *encounterStorage) UpdateEncounter(ctx context.Context, data []byte, id
 		}
 		defer tx.Rollback(ctx)
 
-		_, err = tx.Exec(ctx, UpdateEncounterQuery, id, data)
+		tag, err := tx.Exec(ctx, UpdateEncounterQuery, id, data)
+		if err != nil {
+			return err
+		}
+
+		if tag.RowsAffected() == 0 {
+			return apperrors.EncounterNotFoundErr
+		}
+
+		var restoredFrom *int
+		if change.RestoredFrom != 0 {
+			restoredFrom = &change.RestoredFrom
+		}
+
+		_, err = tx.Exec(ctx, SaveEncounterVersionQuery, id, data, change.AuthorID, change.Source, restoredFrom)
 		if err != nil {
-			return apperrors.TxError
+			return err
+		}
+
+		_, err = tx.Exec(ctx, PruneEncounterVersionsQuery, id, MaxEncounterVersions)
+		if err != nil {
+			return err
 		}
 
 		if err := tx.Commit(ctx); err != nil {
@@ -62,8 +102,11 @@ func (s *encounterStorage) UpdateEncounter(ctx context.Context, data []byte, id
 
 		return nil
 	})
-	if err != nil {
-		l.RepoError(err, map[string]any{"id": id})
+	if errors.Is(err, apperrors.EncounterNotFoundErr) {
+		l.RepoWarn(err, map[string]any{"id": id, "author_id": change.AuthorID, "source": change.Source})
+		return err
+	} else if err != nil {
+		l.RepoError(err, map[string]any{"id": id, "author_id": change.AuthorID, "source": change.Source})
 		return apperrors.TxError
 	}
 
@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/repository/dbcall"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+const foreignKeyViolation = "23503"
+
+func (s *encounterStorage) GetSharedEncounters(ctx context.Context, size, start,
+	userID int) (*models.SharedEncountersList, error) {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	rows, err := dbcall.DBCall[pgx.Rows](fnName, s.metrics, func() (pgx.Rows, error) {
+		return s.pool.Query(ctx, GetSharedEncountersQuery, userID, size, start)
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"size": size, "start": start, "userID": userID})
+		return nil, apperrors.QueryError
+	}
+	defer rows.Close()
+
+	list := make(models.SharedEncountersList, 0)
+
+	for rows.Next() {
+		var encounter models.SharedEncounter
+
+		if err := rows.Scan(&encounter.UserID, &encounter.Name, &encounter.UUID, &encounter.Role); err != nil {
+			l.RepoError(err, map[string]any{"size": size, "start": start, "userID": userID})
+			return nil, apperrors.ScanError
+		}
+
+		list = append(list, &encounter)
+	}
+
+	return &list, nil
+}
+
+func (s *encounterStorage) GetEncounterShares(ctx context.Context, id string) (*models.EncounterSharesList, error) {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	rows, err := dbcall.DBCall[pgx.Rows](fnName, s.metrics, func() (pgx.Rows, error) {
+		return s.pool.Query(ctx, GetEncounterSharesQuery, id)
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"id": id})
+		return nil, apperrors.QueryError
+	}
+	defer rows.Close()
+
+	list := make(models.EncounterSharesList, 0)
+
+	for rows.Next() {
+		var share models.EncounterShare
+
+		if err := rows.Scan(&share.EncounterID, &share.UserID, &share.DisplayName, &share.Role, &share.GrantedBy,
+			&share.CreatedAt); err != nil {
+			l.RepoError(err, map[string]any{"id": id})
+			return nil, apperrors.ScanError
+		}
+
+		list = append(list, &share)
+	}
+
+	return &list, nil
+}
+
+// ShareEncounter выдаёт пользователю роль или меняет уже выданную
+func (s *encounterStorage) ShareEncounter(ctx context.Context, share *models.EncounterShare) error {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	err := dbcall.ErrOnlyDBCall(fnName, s.metrics, func() error {
+		_, err := s.pool.Exec(ctx, ShareEncounterQuery, share.EncounterID, share.UserID, share.Role, share.GrantedBy)
+
+		return err
+	})
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == foreignKeyViolation {
+			l.RepoWarn(err, map[string]any{"id": share.EncounterID, "userID": share.UserID})
+			return apperrors.InvalidShareErr
+		}
+
+		l.RepoError(err, map[string]any{"id": share.EncounterID, "userID": share.UserID, "role": share.Role})
+		return apperrors.TxError
+	}
+
+	return nil
+}
+
+func (s *encounterStorage) RevokeEncounterShare(ctx context.Context, id string, userID int) error {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	err := dbcall.ErrOnlyDBCall(fnName, s.metrics, func() error {
+		_, err := s.pool.Exec(ctx, RevokeEncounterShareQuery, id, userID)
+
+		return err
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"id": id, "userID": userID})
+		return apperrors.TxError
+	}
+
+	return nil
+}
+
+func (s *encounterStorage) CreateShareLink(ctx context.Context, link *models.EncounterShareLink) error {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	_, err := dbcall.DBCall[*models.EncounterShareLink](fnName, s.metrics, func() (*models.EncounterShareLink, error) {
+		line := s.pool.QueryRow(ctx, CreateShareLinkQuery, link.Token, link.EncounterID, link.CreatedBy,
+			link.ExpiresAt)
+		if err := line.Scan(&link.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		return link, nil
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"id": link.EncounterID, "userID": link.CreatedBy})
+		return apperrors.TxError
+	}
+
+	return nil
+}
+
+// GetShareLinks возвращает действующие ссылки на просмотр энкаунтера
+func (s *encounterStorage) GetShareLinks(ctx context.Context, id string) (*models.EncounterShareLinksList, error) {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	rows, err := dbcall.DBCall[pgx.Rows](fnName, s.metrics, func() (pgx.Rows, error) {
+		return s.pool.Query(ctx, GetShareLinksQuery, id)
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"id": id})
+		return nil, apperrors.QueryError
+	}
+	defer rows.Close()
+
+	list := make(models.EncounterShareLinksList, 0)
+
+	for rows.Next() {
+		var link models.EncounterShareLink
+
+		if err := rows.Scan(&link.Token, &link.EncounterID, &link.CreatedBy, &link.CreatedAt,
+			&link.ExpiresAt); err != nil {
+			l.RepoError(err, map[string]any{"id": id})
+			return nil, apperrors.ScanError
+		}
+
+		list = append(list, &link)
+	}
+
+	return &list, nil
+}
+
+func (s *encounterStorage) RevokeShareLink(ctx context.Context, id, token string) error {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	var found bool
+
+	err := dbcall.ErrOnlyDBCall(fnName, s.metrics, func() error {
+		tag, err := s.pool.Exec(ctx, RevokeShareLinkQuery, id, token)
+		if err != nil {
+			return err
+		}
+
+		found = tag.RowsAffected() > 0
+
+		return nil
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"id": id})
+		return apperrors.TxError
+	}
+
+	if !found {
+		l.RepoWarn(apperrors.ShareLinkNotFoundErr, map[string]any{"id": id})
+		return apperrors.ShareLinkNotFoundErr
+	}
+
+	return nil
+}
+
+// GetEncounterByShareLink возвращает энкаунтер по действующей ссылке на просмотр
+func (s *encounterStorage) GetEncounterByShareLink(ctx context.Context, token string) (*models.Encounter, error) {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	var encounter models.Encounter
+
+	_, err := dbcall.DBCall[*models.Encounter](fnName, s.metrics, func() (*models.Encounter, error) {
+		line := s.pool.QueryRow(ctx, GetEncounterByShareLinkQuery, token)
+		if err := line.Scan(&encounter.UserID, &encounter.Name, &encounter.Data, &encounter.UUID); err != nil {
+			return nil, err
+		}
+
+		return &encounter, nil
+	})
+	if err != nil && errors.Is(err, pgx.ErrNoRows) {
+		l.RepoWarn(err, nil)
+		return nil, apperrors.ShareLinkNotFoundErr
+	} else if err != nil {
+		l.RepoError(err, nil)
+		return nil, apperrors.ScanError
+	}
+
+	return &encounter, nil
+}
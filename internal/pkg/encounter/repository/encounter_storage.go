@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
 	encounterinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter"
 	mymetrics "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/metrics"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/repository/dbcall"
@@ -21,13 +22,22 @@ func NewEncounterStorage(pool serverrepo.PostgresPool, metrics mymetrics.DBMetri
 	}
 }
 
-func (s *encounterStorage) CheckPermission(ctx context.Context, id string, userID int) bool {
+// CheckPermission проверяет, что пользователь — владелец энкаунтера или получил роль не ниже role.
+// Участие в кампании полного доступа не даёт: игроки читают привязанный энкаунтер через кампанию,
+// без скрытых от игроков данных
+func (s *encounterStorage) CheckPermission(ctx context.Context, id string, userID int,
+	role models.EncounterRole) bool {
 	fnName := utils.GetFunctionName()
 
 	var hasPermission bool
 
+	granted := make([]string, 0, 3)
+	for _, grantedRole := range models.GrantedRolesIncluding(role) {
+		granted = append(granted, string(grantedRole))
+	}
+
 	hasPermission, _ = dbcall.DBCall[bool](fnName, s.metrics, func() (bool, error) {
-		line := s.pool.QueryRow(ctx, CheckPermissionQuery, id, userID)
+		line := s.pool.QueryRow(ctx, CheckPermissionQuery, id, userID, granted)
 		if err := line.Scan(&hasPermission); err != nil {
 			return false, nil
 		}
@@ -0,0 +1,103 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/repository/dbcall"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils"
+	"github.com/jackc/pgx/v5"
+)
+
+func (s *encounterStorage) GetDeletedEncounters(ctx context.Context, size, start,
+	userID int) (*models.DeletedEncountersList, error) {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	rows, err := dbcall.DBCall[pgx.Rows](fnName, s.metrics, func() (pgx.Rows, error) {
+		return s.pool.Query(ctx, GetDeletedEncountersQuery, userID, size, start)
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"size": size, "start": start, "userID": userID})
+		return nil, apperrors.QueryError
+	}
+	defer rows.Close()
+
+	list := make(models.DeletedEncountersList, 0)
+
+	for rows.Next() {
+		var encounter models.DeletedEncounter
+
+		if err := rows.Scan(&encounter.UserID, &encounter.Name, &encounter.UUID, &encounter.DeletedAt); err != nil {
+			l.RepoError(err, map[string]any{"size": size, "start": start, "userID": userID})
+			return nil, apperrors.ScanError
+		}
+
+		list = append(list, &encounter)
+	}
+
+	return &list, nil
+}
+
+// RestoreEncounter достаёт энкаунтер пользователя из корзины
+func (s *encounterStorage) RestoreEncounter(ctx context.Context, id string, userID int) error {
+	return s.execTrashQuery(ctx, RestoreEncounterQuery, id, userID)
+}
+
+// PurgeEncounter удаляет энкаунтер пользователя из корзины вместе с историей версий
+func (s *encounterStorage) PurgeEncounter(ctx context.Context, id string, userID int) error {
+	return s.execTrashQuery(ctx, PurgeEncounterQuery, id, userID)
+}
+
+func (s *encounterStorage) execTrashQuery(ctx context.Context, query, id string, userID int) error {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	var found bool
+
+	err := dbcall.ErrOnlyDBCall(fnName, s.metrics, func() error {
+		tag, err := s.pool.Exec(ctx, query, id, userID)
+		if err != nil {
+			return err
+		}
+
+		found = tag.RowsAffected() > 0
+
+		return nil
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"id": id, "userID": userID})
+		return apperrors.TxError
+	}
+
+	if !found {
+		l.RepoWarn(apperrors.EncounterNotInTrashErr, map[string]any{"id": id, "userID": userID})
+		return apperrors.EncounterNotInTrashErr
+	}
+
+	return nil
+}
+
+// PurgeDeletedEncounters удаляет энкаунтеры, лежащие в корзине с момента раньше before
+func (s *encounterStorage) PurgeDeletedEncounters(ctx context.Context, before time.Time) (int64, error) {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	purged, err := dbcall.DBCall[int64](fnName, s.metrics, func() (int64, error) {
+		tag, err := s.pool.Exec(ctx, PurgeDeletedEncountersQuery, before)
+		if err != nil {
+			return 0, err
+		}
+
+		return tag.RowsAffected(), nil
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"before": before})
+		return 0, apperrors.TxError
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,27 @@
+package repository
+
+const (
+	GetEncounterFoldersQuery = `
+		SELECT id, user_id, name, created_at
+		FROM public.encounter_folders
+		WHERE user_id = $1
+		ORDER BY name;
+	`
+
+	CreateEncounterFolderQuery = `
+		INSERT INTO public.encounter_folders (user_id, name)
+		VALUES ($1, $2)
+		RETURNING id, created_at;
+	`
+
+	RenameEncounterFolderQuery = `
+		UPDATE public.encounter_folders
+		SET name = $3
+		WHERE id = $1 AND user_id = $2;
+	`
+
+	RemoveEncounterFolderQuery = `
+		DELETE FROM public.encounter_folders
+		WHERE id = $1 AND user_id = $2;
+	`
+)
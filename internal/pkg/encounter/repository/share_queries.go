@@ -0,0 +1,57 @@
+package repository
+
+const (
+	GetSharedEncountersQuery = `
+		SELECT e.user_id, e.name, e.uuid, s.role
+		FROM public.encounter_shares s
+		JOIN public.encounter_store e ON e.uuid = s.encounter_id
+		WHERE s.user_id = $1 AND NOT(e.is_deleted)
+		ORDER BY s.created_at DESC
+		LIMIT $2 OFFSET $3;
+	`
+
+	GetEncounterSharesQuery = `
+		SELECT s.encounter_id, s.user_id, u.display_name, s.role, s.granted_by, s.created_at
+		FROM public.encounter_shares s
+		JOIN public."user" u ON u.id = s.user_id
+		WHERE s.encounter_id = $1
+		ORDER BY s.created_at;
+	`
+
+	ShareEncounterQuery = `
+		INSERT INTO public.encounter_shares (encounter_id, user_id, role, granted_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (encounter_id, user_id) DO UPDATE
+		SET role = EXCLUDED.role, granted_by = EXCLUDED.granted_by;
+	`
+
+	RevokeEncounterShareQuery = `
+		DELETE FROM public.encounter_shares
+		WHERE encounter_id = $1 AND user_id = $2;
+	`
+
+	CreateShareLinkQuery = `
+		INSERT INTO public.encounter_share_links (token, encounter_id, created_by, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at;
+	`
+
+	GetShareLinksQuery = `
+		SELECT token, encounter_id, created_by, created_at, expires_at
+		FROM public.encounter_share_links
+		WHERE encounter_id = $1 AND (expires_at IS NULL OR expires_at > now())
+		ORDER BY created_at;
+	`
+
+	RevokeShareLinkQuery = `
+		DELETE FROM public.encounter_share_links
+		WHERE encounter_id = $1 AND token = $2;
+	`
+
+	GetEncounterByShareLinkQuery = `
+		SELECT e.user_id, e.name, e.data, e.uuid
+		FROM public.encounter_share_links l
+		JOIN public.encounter_store e ON e.uuid = l.encounter_id
+		WHERE l.token = $1 AND NOT(e.is_deleted) AND (l.expires_at IS NULL OR l.expires_at > now());
+	`
+)
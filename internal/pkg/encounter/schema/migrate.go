@@ -0,0 +1,90 @@
+package schema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+)
+
+// migrations[v] переводит данные из версии v в версию v+1. Миграции работают с произвольным JSON,
+// чтобы не терять поля, которых нет в модели
+var migrations = []func(content map[string]interface{}) error{
+	migrateV0,
+}
+
+// Migrate обновляет данные энкаунтера до текущей версии формата. upgraded сообщает, изменились ли
+// данные. Пустые данные возвращаются без изменений
+func Migrate(data []byte) ([]byte, bool, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return data, false, nil
+	}
+
+	var content map[string]interface{}
+
+	decoder := json.NewDecoder(bytes.NewReader(trimmed))
+	decoder.UseNumber()
+
+	err := decoder.Decode(&content)
+	if err != nil || content == nil {
+		return nil, false, fmt.Errorf("%w: data must be a JSON object", ErrInvalidContent)
+	}
+
+	version, err := schemaVersion(content)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if version == models.EncounterSchemaVersion {
+		return data, false, nil
+	}
+
+	for ; version < models.EncounterSchemaVersion; version++ {
+		err = migrations[version](content)
+		if err != nil {
+			return nil, false, fmt.Errorf("%w: migration from version %d: %v", ErrInvalidContent, version, err)
+		}
+	}
+
+	content["schemaVersion"] = models.EncounterSchemaVersion
+
+	migrated, err := json.Marshal(content)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %v", ErrInvalidContent, err)
+	}
+
+	return migrated, true, nil
+}
+
+func schemaVersion(content map[string]interface{}) (int, error) {
+	raw, ok := content["schemaVersion"]
+	if !ok {
+		return 0, nil
+	}
+
+	number, ok := raw.(json.Number)
+	if !ok {
+		return 0, fmt.Errorf("%w: schemaVersion must be a number", ErrInvalidContent)
+	}
+
+	version, err := number.Int64()
+	if err != nil || version < 0 || version > models.EncounterSchemaVersion {
+		return 0, fmt.Errorf("%w: %v", ErrUnsupportedVersion, number)
+	}
+
+	return int(version), nil
+}
+
+// migrateV0 переводит данные, сохранённые до появления схемы. До версии 1 данные были состоянием
+// клиента без фиксированного формата, единственное известное поле — monsters. Старые поля остаются
+// нетронутыми, чтобы патчи клиентов старого формата продолжали применяться, добавляется только
+// пустой список participants
+func migrateV0(content map[string]interface{}) error {
+	if participants, ok := content["participants"]; !ok || participants == nil {
+		content["participants"] = make([]interface{}, 0)
+	}
+
+	return nil
+}
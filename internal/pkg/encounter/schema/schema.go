@@ -0,0 +1,139 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+)
+
+const (
+	maxParticipants   = 100
+	maxExhaustion     = 6
+	maxNotesLen       = 10000
+	maxParticipantLen = 100
+)
+
+var (
+	ErrInvalidContent     = errors.New("invalid encounter content")
+	ErrUnsupportedVersion = errors.New("unsupported encounter schema version")
+)
+
+// Empty возвращает данные энкаунтера без участников в текущей версии формата
+func Empty() []byte {
+	data, _ := json.Marshal(models.EncounterContent{
+		SchemaVersion: models.EncounterSchemaVersion,
+		Participants:  make([]models.EncounterParticipant, 0),
+	})
+
+	return data
+}
+
+// Parse обновляет данные до текущей версии и проверяет их
+func Parse(data []byte) (*models.EncounterContent, error) {
+	data, _, err := Migrate(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return Validate(data)
+}
+
+// Validate проверяет данные текущей версии формата. Неизвестные поля допускаются, чтобы клиент мог
+// хранить в энкаунтере своё состояние
+func Validate(data []byte) (*models.EncounterContent, error) {
+	var content models.EncounterContent
+
+	err := json.Unmarshal(data, &content)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidContent, err)
+	}
+
+	if content.SchemaVersion != models.EncounterSchemaVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, content.SchemaVersion)
+	}
+
+	if len(content.Participants) > maxParticipants {
+		return nil, fmt.Errorf("%w: too many participants", ErrInvalidContent)
+	}
+
+	if len(content.Notes) > maxNotesLen {
+		return nil, fmt.Errorf("%w: notes are too long", ErrInvalidContent)
+	}
+
+	ids := make(map[string]struct{}, len(content.Participants))
+
+	for i := range content.Participants {
+		participant := &content.Participants[i]
+
+		err = validateParticipant(participant)
+		if err != nil {
+			return nil, fmt.Errorf("%w: participant %d: %v", ErrInvalidContent, i, err)
+		}
+
+		if _, ok := ids[participant.ID]; ok {
+			return nil, fmt.Errorf("%w: duplicate participant id %q", ErrInvalidContent, participant.ID)
+		}
+
+		ids[participant.ID] = struct{}{}
+	}
+
+	return &content, nil
+}
+
+func validateParticipant(participant *models.EncounterParticipant) error {
+	if participant.ID == "" || len(participant.ID) > maxParticipantLen {
+		return errors.New("id must not be empty and longer than 100 characters")
+	}
+
+	if len(participant.Name) > maxParticipantLen {
+		return errors.New("name is too long")
+	}
+
+	switch participant.Kind {
+	case models.CreatureCombatant, models.CharacterCombatant:
+		if participant.SourceID == "" {
+			return fmt.Errorf("%v must have sourceID", participant.Kind)
+		}
+	case models.CustomCombatant:
+	default:
+		return fmt.Errorf("unknown kind %q", participant.Kind)
+	}
+
+	if participant.MaxHP < 0 || participant.CurrentHP < 0 || participant.CurrentHP > participant.MaxHP {
+		return errors.New("hp must be between 0 and maxHP")
+	}
+
+	if participant.TempHP < 0 {
+		return errors.New("tempHP must not be negative")
+	}
+
+	seen := make(map[models.Condition]struct{}, len(participant.Conditions))
+
+	for _, condition := range participant.Conditions {
+		if !condition.IsValid() {
+			return fmt.Errorf("unknown condition %q", condition)
+		}
+
+		if _, ok := seen[condition]; ok {
+			return fmt.Errorf("duplicate condition %q", condition)
+		}
+
+		seen[condition] = struct{}{}
+	}
+
+	if participant.ExhaustionLevel < 0 || participant.ExhaustionLevel > maxExhaustion {
+		return errors.New("exhaustion level must be between 0 and 6")
+	}
+
+	if participant.Position != nil && (participant.Position.X < 0 || participant.Position.Y < 0) {
+		return errors.New("position must not be negative")
+	}
+
+	if len(participant.Notes) > maxNotesLen {
+		return errors.New("notes are too long")
+	}
+
+	return nil
+}
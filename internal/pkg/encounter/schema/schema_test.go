@@ -0,0 +1,215 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/schema"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		data         string
+		expected     string
+		wantUpgraded bool
+		wantErr      error
+	}{
+		{
+			name:     "empty data is left as is",
+			data:     ``,
+			expected: ``,
+		},
+		{
+			name:     "current version is left as is",
+			data:     `{"schemaVersion": 1, "participants": []}`,
+			expected: `{"schemaVersion": 1, "participants": []}`,
+		},
+		{
+			name:         "legacy monsters are kept as is",
+			data:         `{"monsters":[]}`,
+			expected:     `{"monsters":[],"participants":[],"schemaVersion":1}`,
+			wantUpgraded: true,
+		},
+		{
+			name:         "legacy client state is kept as is",
+			data:         `{"hp": 10, "list": [1, 2]}`,
+			expected:     `{"hp":10,"list":[1,2],"participants":[],"schemaVersion":1}`,
+			wantUpgraded: true,
+		},
+		{
+			name:         "legacy participants are left for validation",
+			data:         `{"participants": [{"id": "a", "kind": "custom"}]}`,
+			expected:     `{"participants":[{"id":"a","kind":"custom"}],"schemaVersion":1}`,
+			wantUpgraded: true,
+		},
+		{
+			name:         "legacy data without participants",
+			data:         `{}`,
+			expected:     `{"participants":[],"schemaVersion":1}`,
+			wantUpgraded: true,
+		},
+		{
+			name:    "newer version is rejected",
+			data:    `{"schemaVersion": 2}`,
+			wantErr: schema.ErrUnsupportedVersion,
+		},
+		{
+			name:    "array is rejected",
+			data:    `[1, 2]`,
+			wantErr: schema.ErrInvalidContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			migrated, upgraded, err := schema.Migrate([]byte(tt.data))
+
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "expected %v, got %v", tt.wantErr, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantUpgraded, upgraded)
+			assert.Equal(t, tt.expected, string(migrated))
+		})
+	}
+}
+
+func TestMigrate_LegacyDataIsValid(t *testing.T) {
+	t.Parallel()
+
+	migrated, upgraded, err := schema.Migrate([]byte(`{"monsters":[]}`))
+	assert.NoError(t, err)
+	assert.True(t, upgraded)
+
+	content, err := schema.Validate(migrated)
+	assert.NoError(t, err)
+	assert.Empty(t, content.Participants)
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	participant := func(mutate func(p map[string]any)) string {
+		p := map[string]any{
+			"id": "p1", "kind": "creature", "sourceID": "goblin", "name": "Goblin",
+			"maxHP": 10, "currentHP": 7, "tempHP": 0,
+		}
+		mutate(p)
+
+		data, _ := json.Marshal(map[string]any{"schemaVersion": 1, "participants": []any{p}})
+
+		return string(data)
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		wantErr error
+	}{
+		{
+			name: "valid participant",
+			data: participant(func(p map[string]any) {
+				p["conditions"] = []string{"prone", "poisoned"}
+				p["initiative"] = 15
+				p["position"] = map[string]int{"x": 3, "y": 4}
+				p["custom"] = "kept"
+			}),
+		},
+		{
+			name:    "wrong version",
+			data:    `{"schemaVersion": 0, "participants": []}`,
+			wantErr: schema.ErrUnsupportedVersion,
+		},
+		{
+			name:    "empty id",
+			data:    participant(func(p map[string]any) { p["id"] = "" }),
+			wantErr: schema.ErrInvalidContent,
+		},
+		{
+			name:    "unknown kind",
+			data:    participant(func(p map[string]any) { p["kind"] = "dragon" }),
+			wantErr: schema.ErrInvalidContent,
+		},
+		{
+			name:    "creature without source",
+			data:    participant(func(p map[string]any) { delete(p, "sourceID") }),
+			wantErr: schema.ErrInvalidContent,
+		},
+		{
+			name:    "hp above max",
+			data:    participant(func(p map[string]any) { p["currentHP"] = 11 }),
+			wantErr: schema.ErrInvalidContent,
+		},
+		{
+			name:    "negative temp hp",
+			data:    participant(func(p map[string]any) { p["tempHP"] = -1 }),
+			wantErr: schema.ErrInvalidContent,
+		},
+		{
+			name:    "unknown condition",
+			data:    participant(func(p map[string]any) { p["conditions"] = []string{"sleepy"} }),
+			wantErr: schema.ErrInvalidContent,
+		},
+		{
+			name:    "duplicate condition",
+			data:    participant(func(p map[string]any) { p["conditions"] = []string{"prone", "prone"} }),
+			wantErr: schema.ErrInvalidContent,
+		},
+		{
+			name:    "exhaustion out of range",
+			data:    participant(func(p map[string]any) { p["exhaustionLevel"] = 7 }),
+			wantErr: schema.ErrInvalidContent,
+		},
+		{
+			name:    "negative position",
+			data:    participant(func(p map[string]any) { p["position"] = map[string]int{"x": -1, "y": 0} }),
+			wantErr: schema.ErrInvalidContent,
+		},
+		{
+			name:    "wrong field type",
+			data:    participant(func(p map[string]any) { p["maxHP"] = "ten" }),
+			wantErr: schema.ErrInvalidContent,
+		},
+		{
+			name: "duplicate ids",
+			data: `{"schemaVersion": 1, "participants": [{"id": "a", "kind": "custom"}, ` +
+				`{"id": "a", "kind": "custom"}]}`,
+			wantErr: schema.ErrInvalidContent,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			content, err := schema.Validate([]byte(tt.data))
+
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "expected %v, got %v", tt.wantErr, err)
+				assert.Nil(t, content)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.NotNil(t, content)
+		})
+	}
+}
+
+func TestEmpty_IsValid(t *testing.T) {
+	t.Parallel()
+
+	content, err := schema.Validate(schema.Empty())
+	assert.NoError(t, err)
+	assert.Empty(t, content.Participants)
+}
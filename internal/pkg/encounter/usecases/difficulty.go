@@ -0,0 +1,187 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/difficulty"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/schema"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+)
+
+const maxDifficultyCombatants = 100
+
+// GetDifficulty считает сложность боя по правилам DMG. Существа и персонажи сохранённого энкаунтера
+// доступны его владельцу, перечисленные в запросе должны быть общими или принадлежать пользователю
+func (uc *encounterUsecases) GetDifficulty(ctx context.Context, req *models.DifficultyReq,
+	userID int) (*models.EncounterDifficulty, error) {
+	l := logger.FromContext(ctx)
+
+	var participants []models.EncounterParticipant
+
+	if req.EncounterID != "" {
+		content, err := uc.encounterContent(ctx, req.EncounterID, userID)
+		if err != nil {
+			return nil, err
+		}
+
+		participants = content.Participants
+	}
+
+	if len(participants)+len(req.Creatures)+len(req.Characters)+len(req.PartyLevels) > maxDifficultyCombatants {
+		l.UsecasesWarn(apperrors.InvalidDifficultyErr, userID, map[string]any{"id": req.EncounterID})
+		return nil, apperrors.InvalidDifficultyErr
+	}
+
+	creatureXP := make([]int, 0, len(req.Creatures))
+	levels := slices.Clone(req.PartyLevels)
+	cache := make(map[string]int)
+
+	addCreature := func(engName string, trusted bool) error {
+		xp, ok := cache[engName]
+		if !ok {
+			creature, err := uc.findCreature(ctx, engName, userID, trusted)
+			if err != nil {
+				return err
+			}
+
+			xp, err = difficulty.CreatureXP(creature)
+			if err != nil {
+				l.UsecasesWarn(err, userID, map[string]any{"creature": engName})
+				return apperrors.InvalidDifficultyErr
+			}
+
+			cache[engName] = xp
+		}
+
+		creatureXP = append(creatureXP, xp)
+
+		return nil
+	}
+
+	addCharacter := func(id string, trusted bool) error {
+		level, err := uc.characterLevel(ctx, id, userID, trusted)
+		if err != nil {
+			return err
+		}
+
+		levels = append(levels, level)
+
+		return nil
+	}
+
+	for _, participant := range participants {
+		var err error
+
+		switch participant.Kind {
+		case models.CreatureCombatant:
+			err = addCreature(participant.SourceID, true)
+		case models.CharacterCombatant:
+			err = addCharacter(participant.SourceID, true)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, engName := range req.Creatures {
+		if err := addCreature(engName, false); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, id := range req.Characters {
+		if err := addCharacter(id, false); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := difficulty.Calculate(creatureXP, levels)
+	if err != nil {
+		l.UsecasesWarn(err, userID, map[string]any{"id": req.EncounterID, "levels": levels})
+		return nil, apperrors.InvalidDifficultyErr
+	}
+
+	return result, nil
+}
+
+func (uc *encounterUsecases) encounterContent(ctx context.Context, id string,
+	userID int) (*models.EncounterContent, error) {
+	l := logger.FromContext(ctx)
+
+	hasPermission := uc.repo.CheckPermission(ctx, id, userID, models.ViewerEncounterRole)
+	if !hasPermission {
+		l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"id": id})
+		return nil, apperrors.PermissionDeniedError
+	}
+
+	encounter, err := uc.repo.GetEncounterByID(ctx, id)
+	if err != nil {
+		l.UsecasesError(err, userID, map[string]any{"id": id})
+		return nil, err
+	}
+
+	data, err := migrateData(encounter.Data)
+	if err != nil {
+		l.UsecasesWarn(err, userID, map[string]any{"id": id})
+		return nil, apperrors.InvalidEncounterDataErr
+	}
+
+	content, err := schema.Validate(data)
+	if err != nil {
+		l.UsecasesWarn(err, userID, map[string]any{"id": id})
+		return nil, apperrors.InvalidEncounterDataErr
+	}
+
+	return content, nil
+}
+
+// findCreature ищет существо сначала в общем бестиарии, затем среди пользовательских. Чужие
+// пользовательские существа доступны, только если они уже добавлены в энкаунтер
+func (uc *encounterUsecases) findCreature(ctx context.Context, engName string, userID int,
+	trusted bool) (*models.Creature, error) {
+	l := logger.FromContext(ctx)
+
+	creature, err := uc.bestiaryRepo.GetCreatureByEngName(ctx, engName, false)
+	if err == nil && creature == nil {
+		creature, err = uc.bestiaryRepo.GetCreatureByEngName(ctx, engName, true)
+		if err == nil && creature != nil && !trusted && creature.UserID != strconv.Itoa(userID) {
+			creature = nil
+		}
+	}
+
+	if err != nil {
+		l.UsecasesError(err, userID, map[string]any{"creature": engName})
+		return nil, err
+	}
+
+	if creature == nil {
+		l.UsecasesWarn(apperrors.InvalidDifficultyErr, userID, map[string]any{"creature": engName})
+		return nil, apperrors.InvalidDifficultyErr
+	}
+
+	return creature, nil
+}
+
+func (uc *encounterUsecases) characterLevel(ctx context.Context, id string, userID int,
+	trusted bool) (int, error) {
+	l := logger.FromContext(ctx)
+
+	character, err := uc.characterRepo.GetCharacterByMongoId(ctx, id)
+	if err != nil && !errors.Is(err, apperrors.InvalidIDErr) {
+		l.UsecasesError(err, userID, map[string]any{"character": id})
+		return 0, err
+	}
+
+	if character == nil || (!trusted && character.UserID != "*" && character.UserID != strconv.Itoa(userID)) {
+		l.UsecasesWarn(apperrors.InvalidDifficultyErr, userID, map[string]any{"character": id})
+		return 0, apperrors.InvalidDifficultyErr
+	}
+
+	return character.Data.Info.Level.Value, nil
+}
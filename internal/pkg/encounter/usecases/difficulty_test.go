@@ -0,0 +1,168 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	bestiarymocks "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/bestiary/mocks"
+	charactermocks "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/character/mocks"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetDifficulty(t *testing.T) {
+	t.Parallel()
+
+	characterWithLevel := func(userID string, level int) *models.Character {
+		character := &models.Character{UserID: userID}
+		character.Data.Info.Level.Value = level
+
+		return character
+	}
+
+	stored := &models.Encounter{UUID: "enc-1", Data: []byte(`{"schemaVersion":1,"participants":[` +
+		`{"id":"a","kind":"creature","sourceID":"bugbear"},{"id":"b","kind":"creature","sourceID":"hobgoblin"},` +
+		`{"id":"c","kind":"character","sourceID":"char-2"},{"id":"d","kind":"custom"}]}`)}
+	dbErr := errors.New("db failure")
+
+	tests := []struct {
+		name  string
+		req   *models.DifficultyReq
+		setup func(repo *mocks.MockEncounterRepository, bestiary *bestiarymocks.MockBestiaryRepository,
+			characters *charactermocks.MockCharacterRepository)
+		want    *models.EncounterDifficulty
+		wantErr error
+	}{
+		{
+			name: "ad-hoc creatures and party levels",
+			req:  &models.DifficultyReq{Creatures: []string{"goblin", "goblin"}, PartyLevels: []int{1, 1, 1, 1}},
+			setup: func(_ *mocks.MockEncounterRepository, bestiary *bestiarymocks.MockBestiaryRepository,
+				_ *charactermocks.MockCharacterRepository) {
+				bestiary.EXPECT().GetCreatureByEngName(gomock.Any(), "goblin", false).
+					Return(&models.Creature{ChallengeRating: "1/4"}, nil).Times(1)
+			},
+			want: &models.EncounterDifficulty{
+				TotalXP:      100,
+				AdjustedXP:   150,
+				Multiplier:   1.5,
+				MonsterCount: 2,
+				PartyLevels:  []int{1, 1, 1, 1},
+				Thresholds:   models.DifficultyThresholds{Easy: 100, Medium: 200, Hard: 300, Deadly: 400},
+				Difficulty:   models.EasyDifficulty,
+			},
+		},
+		{
+			name: "saved encounter is combined with ad-hoc characters",
+			req:  &models.DifficultyReq{EncounterID: "enc-1", Characters: []string{"char-1"}},
+			setup: func(repo *mocks.MockEncounterRepository, bestiary *bestiarymocks.MockBestiaryRepository,
+				characters *charactermocks.MockCharacterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.ViewerEncounterRole).Return(true)
+				repo.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").Return(stored, nil)
+				bestiary.EXPECT().GetCreatureByEngName(gomock.Any(), "bugbear", false).
+					Return(&models.Creature{ChallengeRating: "1", Experience: 200}, nil)
+				bestiary.EXPECT().GetCreatureByEngName(gomock.Any(), "hobgoblin", false).Return(nil, nil)
+				bestiary.EXPECT().GetCreatureByEngName(gomock.Any(), "hobgoblin", true).
+					Return(&models.Creature{ChallengeRating: "1/2", UserID: "7"}, nil)
+				characters.EXPECT().GetCharacterByMongoId(gomock.Any(), "char-2").
+					Return(characterWithLevel("7", 3), nil)
+				characters.EXPECT().GetCharacterByMongoId(gomock.Any(), "char-1").
+					Return(characterWithLevel("1", 2), nil)
+			},
+			want: &models.EncounterDifficulty{
+				TotalXP:      300,
+				AdjustedXP:   600,
+				Multiplier:   2,
+				MonsterCount: 2,
+				PartyLevels:  []int{3, 2},
+				Thresholds:   models.DifficultyThresholds{Easy: 125, Medium: 250, Hard: 375, Deadly: 600},
+				Difficulty:   models.DeadlyDifficulty,
+			},
+		},
+		{
+			name: "foreign character is rejected",
+			req:  &models.DifficultyReq{Characters: []string{"char-2"}},
+			setup: func(_ *mocks.MockEncounterRepository, _ *bestiarymocks.MockBestiaryRepository,
+				characters *charactermocks.MockCharacterRepository) {
+				characters.EXPECT().GetCharacterByMongoId(gomock.Any(), "char-2").
+					Return(characterWithLevel("7", 3), nil)
+			},
+			wantErr: apperrors.InvalidDifficultyErr,
+		},
+		{
+			name: "foreign user creature is rejected",
+			req:  &models.DifficultyReq{Creatures: []string{"homebrew"}, PartyLevels: []int{1}},
+			setup: func(_ *mocks.MockEncounterRepository, bestiary *bestiarymocks.MockBestiaryRepository,
+				_ *charactermocks.MockCharacterRepository) {
+				bestiary.EXPECT().GetCreatureByEngName(gomock.Any(), "homebrew", false).Return(nil, nil)
+				bestiary.EXPECT().GetCreatureByEngName(gomock.Any(), "homebrew", true).
+					Return(&models.Creature{ChallengeRating: "1", UserID: "7"}, nil)
+			},
+			wantErr: apperrors.InvalidDifficultyErr,
+		},
+		{
+			name: "unknown challenge rating is rejected",
+			req:  &models.DifficultyReq{Creatures: []string{"odd"}, PartyLevels: []int{1}},
+			setup: func(_ *mocks.MockEncounterRepository, bestiary *bestiarymocks.MockBestiaryRepository,
+				_ *charactermocks.MockCharacterRepository) {
+				bestiary.EXPECT().GetCreatureByEngName(gomock.Any(), "odd", false).
+					Return(&models.Creature{ChallengeRating: "?"}, nil)
+			},
+			wantErr: apperrors.InvalidDifficultyErr,
+		},
+		{
+			name: "empty party is rejected",
+			req:  &models.DifficultyReq{},
+			setup: func(_ *mocks.MockEncounterRepository, _ *bestiarymocks.MockBestiaryRepository,
+				_ *charactermocks.MockCharacterRepository) {
+			},
+			wantErr: apperrors.InvalidDifficultyErr,
+		},
+		{
+			name: "no permission for encounter",
+			req:  &models.DifficultyReq{EncounterID: "enc-1"},
+			setup: func(repo *mocks.MockEncounterRepository, _ *bestiarymocks.MockBestiaryRepository,
+				_ *charactermocks.MockCharacterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.ViewerEncounterRole).Return(false)
+			},
+			wantErr: apperrors.PermissionDeniedError,
+		},
+		{
+			name: "bestiary error is propagated",
+			req:  &models.DifficultyReq{Creatures: []string{"goblin"}, PartyLevels: []int{1}},
+			setup: func(_ *mocks.MockEncounterRepository, bestiary *bestiarymocks.MockBestiaryRepository,
+				_ *charactermocks.MockCharacterRepository) {
+				bestiary.EXPECT().GetCreatureByEngName(gomock.Any(), "goblin", false).Return(nil, dbErr)
+			},
+			wantErr: dbErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			repo := mocks.NewMockEncounterRepository(ctrl)
+			bestiary := bestiarymocks.NewMockBestiaryRepository(ctrl)
+			characters := charactermocks.NewMockCharacterRepository(ctrl)
+			tt.setup(repo, bestiary, characters)
+
+			uc := NewEncounterUsecases(repo, bestiary, characters)
+			result, err := uc.GetDifficulty(context.Background(), tt.req, 1)
+
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "expected %v, got %v", tt.wantErr, err)
+				assert.Nil(t, result)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, result)
+		})
+	}
+}
@@ -1,26 +1,39 @@
 package usecases
 
 import (
+	"bytes"
 	"context"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
 	"github.com/google/uuid"
+	"strings"
 
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	bestiaryinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/bestiary"
+	characterinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/character"
 	encounterinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/schema"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils/merger"
 )
 
 type encounterUsecases struct {
-	repo encounterinterfaces.EncounterRepository
+	repo          encounterinterfaces.EncounterRepository
+	bestiaryRepo  bestiaryinterfaces.BestiaryRepository
+	characterRepo characterinterfaces.CharacterRepository
 }
 
-func NewEncounterUsecases(repo encounterinterfaces.EncounterRepository) encounterinterfaces.EncounterUsecases {
+func NewEncounterUsecases(repo encounterinterfaces.EncounterRepository,
+	bestiaryRepo bestiaryinterfaces.BestiaryRepository,
+	characterRepo characterinterfaces.CharacterRepository) encounterinterfaces.EncounterUsecases {
 	return &encounterUsecases{
-		repo: repo,
+		repo:          repo,
+		bestiaryRepo:  bestiaryRepo,
+		characterRepo: characterRepo,
 	}
 }
 
 func (uc *encounterUsecases) GetEncountersList(ctx context.Context, size, start, userID int,
+	filter *models.EncounterFilterParams, order *models.Order,
 	search *models.SearchParams) (*models.EncountersList, error) {
 	l := logger.FromContext(ctx)
 
@@ -29,54 +42,196 @@ func (uc *encounterUsecases) GetEncountersList(ctx context.Context, size, start,
 		return nil, apperrors.StartPosSizeError
 	}
 
+	order, err := listOrder(order)
+	if err != nil {
+		l.UsecasesWarn(err, userID, map[string]any{"order": order})
+		return nil, err
+	}
+
+	filter = &models.EncounterFilterParams{
+		FolderID: filter.FolderID,
+		Tag:      strings.ToLower(strings.TrimSpace(filter.Tag)),
+		Template: filter.Template,
+	}
+
 	if search.Value == "" {
-		return uc.repo.GetEncountersList(ctx, size, start, userID)
+		return uc.repo.GetEncountersList(ctx, size, start, userID, filter, order)
 	} else {
-		return uc.repo.GetEncountersListWithSearch(ctx, size, start, userID, search)
+		return uc.repo.GetEncountersListWithSearch(ctx, size, start, userID, filter, order, search)
+	}
+}
+
+// listOrder проверяет сортировку списка энкаунтеров. По умолчанию сначала идут недавно изменённые,
+// без направления имена сортируются по алфавиту, а даты — от новых к старым
+func listOrder(order *models.Order) (*models.Order, error) {
+	result := *order
+
+	switch result.Field {
+	case "":
+		result.Field = models.EncounterOrderByUpdatedAt
+	case models.EncounterOrderByName, models.EncounterOrderByCreatedAt, models.EncounterOrderByUpdatedAt:
+	default:
+		return order, apperrors.InvalidEncounterOrderErr
+	}
+
+	switch result.Direction {
+	case "":
+		result.Direction = "desc"
+		if result.Field == models.EncounterOrderByName {
+			result.Direction = "asc"
+		}
+	case "asc", "desc":
+	default:
+		return order, apperrors.InvalidEncounterOrderErr
 	}
+
+	return &result, nil
 }
 
 func (uc *encounterUsecases) GetEncounterByID(ctx context.Context, id string, userID int) (*models.Encounter, error) {
 	l := logger.FromContext(ctx)
 
-	hasPermission := uc.repo.CheckPermission(ctx, id, userID)
+	hasPermission := uc.repo.CheckPermission(ctx, id, userID, models.ViewerEncounterRole)
 	if !hasPermission {
 		l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"id": id})
 		return nil, apperrors.PermissionDeniedError
 	}
 
-	return uc.repo.GetEncounterByID(ctx, id)
+	encounter, err := uc.repo.GetEncounterByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Данные мигрируют только в памяти: чтение не меняет энкаунтер, в базу обновлённая схема попадёт
+	// со следующим изменением
+	data, _, err := schema.Migrate(encounter.Data)
+	if err != nil {
+		l.UsecasesWarn(err, userID, map[string]any{"id": id})
+		return encounter, nil
+	}
+
+	encounter.Data = data
+
+	return encounter, nil
 }
 
 func (uc *encounterUsecases) SaveEncounter(ctx context.Context, encounter *models.SaveEncounterReq, userID int) error {
+	_, err := uc.saveEncounter(ctx, encounter, userID)
+
+	return err
+}
+
+// saveEncounter проверяет и сохраняет новый энкаунтер, возвращает его идентификатор
+func (uc *encounterUsecases) saveEncounter(ctx context.Context, encounter *models.SaveEncounterReq,
+	userID int) (string, error) {
 	l := logger.FromContext(ctx)
 
-	if encounter.Name == "" || len(encounter.Name) > 60 {
+	if encounter.Name == "" || len(encounter.Name) > maxEncounterName {
 		l.UsecasesWarn(apperrors.InvalidInputError, userID, map[string]any{"name": encounter.Name})
-		return apperrors.InvalidInputError
+		return "", apperrors.InvalidInputError
 	}
 
+	tags, err := normalizeTags(encounter.Tags)
+	if err != nil {
+		l.UsecasesWarn(err, userID, map[string]any{"name": encounter.Name, "tags": encounter.Tags})
+		return "", err
+	}
+
+	data, err := prepareData(encounter.Data)
+	if err != nil {
+		l.UsecasesWarn(err, userID, map[string]any{"name": encounter.Name})
+		return "", apperrors.InvalidEncounterDataErr
+	}
+
+	encounter.Data = data
+	encounter.Tags = tags
 	id := uuid.NewString()
 
-	return uc.repo.SaveEncounter(ctx, encounter, id, userID)
+	return id, uc.repo.SaveEncounter(ctx, encounter, id, userID)
 }
 
-func (uc *encounterUsecases) UpdateEncounter(ctx context.Context, data []byte, id string, userID int) error {
+// UpdateEncounter заменяет данные энкаунтера. Если задан формат патча, data — патч RFC 6902 или
+// RFC 7386, который применяется к текущим данным. Результат должен соответствовать схеме энкаунтера
+func (uc *encounterUsecases) UpdateEncounter(ctx context.Context, data []byte, format models.PatchFormat,
+	id string, userID int) error {
 	l := logger.FromContext(ctx)
 
-	hasPermission := uc.repo.CheckPermission(ctx, id, userID)
+	hasPermission := uc.repo.CheckPermission(ctx, id, userID, models.EditorEncounterRole)
 	if !hasPermission {
 		l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"id": id})
 		return apperrors.PermissionDeniedError
 	}
 
-	return uc.repo.UpdateEncounter(ctx, data, id)
+	change := &models.EncounterChange{AuthorID: userID, Source: models.RESTVersionSource}
+
+	if format == models.LegacyPatch {
+		return uc.updateData(ctx, data, id, change)
+	}
+
+	encounter, err := uc.repo.GetEncounterByID(ctx, id)
+	if err != nil {
+		l.UsecasesError(err, userID, map[string]any{"id": id})
+		return err
+	}
+
+	stored, err := migrateData(encounter.Data)
+	if err != nil {
+		l.UsecasesError(err, userID, map[string]any{"id": id})
+		return apperrors.InvalidEncounterDataErr
+	}
+
+	patched, err := merger.Apply(format, stored, data)
+	if err != nil {
+		l.UsecasesWarn(err, userID, map[string]any{"id": id, "format": format})
+		return apperrors.InvalidPatchErr
+	}
+
+	return uc.updateData(ctx, patched, id, change)
+}
+
+func (uc *encounterUsecases) updateData(ctx context.Context, data []byte, id string,
+	change *models.EncounterChange) error {
+	l := logger.FromContext(ctx)
+
+	data, err := prepareData(data)
+	if err != nil {
+		l.UsecasesWarn(err, change.AuthorID, map[string]any{"id": id})
+		return apperrors.InvalidEncounterDataErr
+	}
+
+	return uc.repo.UpdateEncounter(ctx, data, id, change)
+}
+
+// migrateData приводит данные энкаунтера к текущей версии схемы. Пустые данные заменяются
+// энкаунтером без участников
+func migrateData(data []byte) ([]byte, error) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+		return schema.Empty(), nil
+	}
+
+	data, _, err := schema.Migrate(data)
+
+	return data, err
+}
+
+func prepareData(data []byte) ([]byte, error) {
+	data, err := migrateData(data)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := schema.Validate(data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
 }
 
 func (uc *encounterUsecases) RemoveEncounter(ctx context.Context, id string, userID int) error {
 	l := logger.FromContext(ctx)
 
-	hasPermission := uc.repo.CheckPermission(ctx, id, userID)
+	hasPermission := uc.repo.CheckPermission(ctx, id, userID, models.OwnerEncounterRole)
 	if !hasPermission {
 		l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"id": id})
 		return apperrors.PermissionDeniedError
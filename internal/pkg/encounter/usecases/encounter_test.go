@@ -24,6 +24,8 @@ func TestGetEncountersList(t *testing.T) {
 		size       int
 		start      int
 		search     *models.SearchParams
+		filter     models.EncounterFilterParams
+		order      models.Order
 		setup      func(repo *mocks.MockEncounterRepository)
 		wantErr    error
 		wantNil    bool
@@ -53,18 +55,66 @@ func TestGetEncountersList(t *testing.T) {
 			start:  0,
 			search: &models.SearchParams{},
 			setup: func(repo *mocks.MockEncounterRepository) {
-				repo.EXPECT().GetEncountersList(gomock.Any(), 10, 0, 1).
+				repo.EXPECT().GetEncountersList(gomock.Any(), 10, 0, 1, gomock.Any(),
+					&models.Order{Field: models.EncounterOrderByUpdatedAt, Direction: "desc"}).
 					Return(expected, nil)
 			},
 			wantResult: expected,
 		},
+		{
+			name:   "name order defaults to ascending",
+			size:   10,
+			start:  0,
+			search: &models.SearchParams{},
+			order:  models.Order{Field: models.EncounterOrderByName},
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().GetEncountersList(gomock.Any(), 10, 0, 1, gomock.Any(),
+					&models.Order{Field: models.EncounterOrderByName, Direction: "asc"}).
+					Return(expected, nil)
+			},
+			wantResult: expected,
+		},
+		{
+			name:   "tag filter is normalized",
+			size:   10,
+			start:  0,
+			search: &models.SearchParams{},
+			filter: models.EncounterFilterParams{Tag: " Goblins "},
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().GetEncountersList(gomock.Any(), 10, 0, 1,
+					&models.EncounterFilterParams{Tag: "goblins"}, gomock.Any()).
+					Return(expected, nil)
+			},
+			wantResult: expected,
+		},
+		{
+			name:    "unknown order field returns InvalidEncounterOrderErr",
+			size:    10,
+			start:   0,
+			search:  &models.SearchParams{},
+			order:   models.Order{Field: "data"},
+			setup:   func(_ *mocks.MockEncounterRepository) {},
+			wantErr: apperrors.InvalidEncounterOrderErr,
+			wantNil: true,
+		},
+		{
+			name:    "unknown direction returns InvalidEncounterOrderErr",
+			size:    10,
+			start:   0,
+			search:  &models.SearchParams{},
+			order:   models.Order{Field: models.EncounterOrderByCreatedAt, Direction: "up"},
+			setup:   func(_ *mocks.MockEncounterRepository) {},
+			wantErr: apperrors.InvalidEncounterOrderErr,
+			wantNil: true,
+		},
 		{
 			name:   "happy path with search delegates to search method",
 			size:   10,
 			start:  0,
 			search: &models.SearchParams{Value: "dragon"},
 			setup: func(repo *mocks.MockEncounterRepository) {
-				repo.EXPECT().GetEncountersListWithSearch(gomock.Any(), 10, 0, 1, gomock.Any()).
+				repo.EXPECT().GetEncountersListWithSearch(gomock.Any(), 10, 0, 1, gomock.Any(), gomock.Any(),
+					gomock.Any()).
 					Return(expected, nil)
 			},
 			wantResult: expected,
@@ -75,7 +125,7 @@ func TestGetEncountersList(t *testing.T) {
 			start:  0,
 			search: &models.SearchParams{},
 			setup: func(repo *mocks.MockEncounterRepository) {
-				repo.EXPECT().GetEncountersList(gomock.Any(), 10, 0, 1).
+				repo.EXPECT().GetEncountersList(gomock.Any(), 10, 0, 1, gomock.Any(), gomock.Any()).
 					Return(nil, repoErr)
 			},
 			wantErr: repoErr,
@@ -91,8 +141,9 @@ func TestGetEncountersList(t *testing.T) {
 			repo := mocks.NewMockEncounterRepository(ctrl)
 			tt.setup(repo)
 
-			uc := NewEncounterUsecases(repo)
-			result, err := uc.GetEncountersList(context.Background(), tt.size, tt.start, 1, tt.search)
+			uc := NewEncounterUsecases(repo, nil, nil)
+			result, err := uc.GetEncountersList(context.Background(), tt.size, tt.start, 1, &tt.filter, &tt.order,
+				tt.search)
 
 			if tt.wantErr != nil {
 				assert.True(t, errors.Is(err, tt.wantErr), "expected %v, got %v", tt.wantErr, err)
@@ -140,6 +191,19 @@ func TestSaveEncounter(t *testing.T) {
 					Return(nil)
 			},
 		},
+		{
+			name: "invalid data returns InvalidEncounterDataErr",
+			encounter: &models.SaveEncounterReq{Name: "Battle",
+				Data: []byte(`{"schemaVersion":1,"participants":[{"id":"a","kind":"custom","tempHP":-1}]}`)},
+			setup:   func(_ *mocks.MockEncounterRepository) {},
+			wantErr: apperrors.InvalidEncounterDataErr,
+		},
+		{
+			name:      "too many tags return InvalidEncounterTagsErr",
+			encounter: &models.SaveEncounterReq{Name: "Battle", Tags: make([]string, 21)},
+			setup:     func(_ *mocks.MockEncounterRepository) {},
+			wantErr:   apperrors.InvalidEncounterTagsErr,
+		},
 		{
 			name:      "repo error is propagated",
 			encounter: &models.SaveEncounterReq{Name: "Battle"},
@@ -159,7 +223,7 @@ func TestSaveEncounter(t *testing.T) {
 			repo := mocks.NewMockEncounterRepository(ctrl)
 			tt.setup(repo)
 
-			uc := NewEncounterUsecases(repo)
+			uc := NewEncounterUsecases(repo, nil, nil)
 			err := uc.SaveEncounter(context.Background(), tt.encounter, 1)
 
 			if tt.wantErr != nil {
@@ -186,7 +250,7 @@ func TestGetEncounterByID(t *testing.T) {
 		{
 			name: "no permission returns PermissionDeniedError",
 			setup: func(repo *mocks.MockEncounterRepository) {
-				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1).Return(false)
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.ViewerEncounterRole).Return(false)
 			},
 			wantErr: apperrors.PermissionDeniedError,
 			wantNil: true,
@@ -194,14 +258,14 @@ func TestGetEncounterByID(t *testing.T) {
 		{
 			name: "happy path returns encounter",
 			setup: func(repo *mocks.MockEncounterRepository) {
-				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1).Return(true)
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.ViewerEncounterRole).Return(true)
 				repo.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").Return(expectedEncounter, nil)
 			},
 		},
 		{
 			name: "repo error is propagated",
 			setup: func(repo *mocks.MockEncounterRepository) {
-				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1).Return(true)
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.ViewerEncounterRole).Return(true)
 				repo.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").Return(nil, repoErr)
 			},
 			wantErr: repoErr,
@@ -217,7 +281,7 @@ func TestGetEncounterByID(t *testing.T) {
 			repo := mocks.NewMockEncounterRepository(ctrl)
 			tt.setup(repo)
 
-			uc := NewEncounterUsecases(repo)
+			uc := NewEncounterUsecases(repo, nil, nil)
 			result, err := uc.GetEncounterByID(context.Background(), "enc-1", 1)
 
 			if tt.wantErr != nil {
@@ -235,27 +299,138 @@ func TestGetEncounterByID(t *testing.T) {
 	}
 }
 
+func TestSaveEncounter_StoresSchemaData(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockEncounterRepository(ctrl)
+	repo.EXPECT().SaveEncounter(gomock.Any(), gomock.Any(), gomock.Not(""), 1).
+		DoAndReturn(func(_ context.Context, encounter *models.SaveEncounterReq, _ string, _ int) error {
+			assert.JSONEq(t, `{"schemaVersion":1,"participants":[]}`, string(encounter.Data))
+			return nil
+		})
+
+	uc := NewEncounterUsecases(repo, nil, nil)
+	err := uc.SaveEncounter(context.Background(), &models.SaveEncounterReq{Name: "Battle"}, 1)
+	assert.NoError(t, err)
+}
+
+func TestGetEncounterByID_UpgradesLegacyData(t *testing.T) {
+	t.Parallel()
+
+	legacy := &models.Encounter{UUID: "enc-1", Data: []byte(`{"monsters":[]}`)}
+	upgraded := []byte(`{"monsters":[],"participants":[],"schemaVersion":1}`)
+
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockEncounterRepository(ctrl)
+	repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.ViewerEncounterRole).Return(true)
+	repo.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").Return(legacy, nil)
+
+	uc := NewEncounterUsecases(repo, nil, nil)
+	result, err := uc.GetEncounterByID(context.Background(), "enc-1", 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, string(upgraded), string(result.Data))
+}
+
 func TestUpdateEncounter(t *testing.T) {
 	t.Parallel()
 
+	stored := &models.Encounter{UUID: "enc-1", Data: []byte(`{"hp":10,"list":[1,2]}`)}
+	restChange := &models.EncounterChange{AuthorID: 1, Source: models.RESTVersionSource}
+
 	tests := []struct {
 		name    string
+		data    string
+		format  models.PatchFormat
 		setup   func(repo *mocks.MockEncounterRepository)
 		wantErr error
 	}{
 		{
 			name: "no permission returns PermissionDeniedError",
+			data: `{}`,
 			setup: func(repo *mocks.MockEncounterRepository) {
-				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1).Return(false)
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(false)
 			},
 			wantErr: apperrors.PermissionDeniedError,
 		},
 		{
 			name: "happy path delegates to repo",
+			data: `{"schemaVersion":1,"participants":[]}`,
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(true)
+				repo.EXPECT().UpdateEncounter(gomock.Any(), []byte(`{"schemaVersion":1,"participants":[]}`), "enc-1",
+					restChange).Return(nil)
+			},
+		},
+		{
+			name: "empty object is saved as empty encounter",
+			data: `{}`,
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(true)
+				repo.EXPECT().UpdateEncounter(gomock.Any(), []byte(`{"participants":[],"schemaVersion":1}`), "enc-1",
+					restChange).Return(nil)
+			},
+		},
+		{
+			name: "legacy data is upgraded before saving",
+			data: `{"monsters":[]}`,
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(true)
+				repo.EXPECT().UpdateEncounter(gomock.Any(), []byte(`{"monsters":[],"participants":[],"schemaVersion":1}`),
+					"enc-1", restChange).Return(nil)
+			},
+		},
+		{
+			name: "invalid data returns InvalidEncounterDataErr",
+			data: `{"schemaVersion":1,"participants":[{"id":"a","kind":"dragon"}]}`,
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(true)
+			},
+			wantErr: apperrors.InvalidEncounterDataErr,
+		},
+		{
+			name:   "json patch is applied to stored data",
+			data:   `[{"op":"replace","path":"/hp","value":7},{"op":"add","path":"/list/0","value":0}]`,
+			format: models.JSONPatch,
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(true)
+				repo.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").Return(stored, nil)
+				repo.EXPECT().UpdateEncounter(gomock.Any(),
+					[]byte(`{"hp":7,"list":[0,1,2],"participants":[],"schemaVersion":1}`), "enc-1", restChange).
+					Return(nil)
+			},
+		},
+		{
+			name:   "merge patch removes null fields",
+			data:   `{"list":null}`,
+			format: models.MergePatch,
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(true)
+				repo.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").Return(stored, nil)
+				repo.EXPECT().UpdateEncounter(gomock.Any(), []byte(`{"hp":10,"participants":[],"schemaVersion":1}`),
+					"enc-1", restChange).Return(nil)
+			},
+		},
+		{
+			name:   "patch breaking the schema returns InvalidEncounterDataErr",
+			data:   `[{"op":"add","path":"/participants","value":[{"id":"a","kind":"dragon"}]}]`,
+			format: models.JSONPatch,
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(true)
+				repo.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").Return(stored, nil)
+			},
+			wantErr: apperrors.InvalidEncounterDataErr,
+		},
+		{
+			name:   "failed test operation returns InvalidPatchErr",
+			data:   `[{"op":"test","path":"/hp","value":3}]`,
+			format: models.JSONPatch,
 			setup: func(repo *mocks.MockEncounterRepository) {
-				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1).Return(true)
-				repo.EXPECT().UpdateEncounter(gomock.Any(), []byte(`{}`), "enc-1").Return(nil)
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(true)
+				repo.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").Return(stored, nil)
 			},
+			wantErr: apperrors.InvalidPatchErr,
 		},
 	}
 
@@ -267,8 +442,8 @@ func TestUpdateEncounter(t *testing.T) {
 			repo := mocks.NewMockEncounterRepository(ctrl)
 			tt.setup(repo)
 
-			uc := NewEncounterUsecases(repo)
-			err := uc.UpdateEncounter(context.Background(), []byte(`{}`), "enc-1", 1)
+			uc := NewEncounterUsecases(repo, nil, nil)
+			err := uc.UpdateEncounter(context.Background(), []byte(tt.data), tt.format, "enc-1", 1)
 
 			if tt.wantErr != nil {
 				assert.True(t, errors.Is(err, tt.wantErr))
@@ -290,14 +465,14 @@ func TestRemoveEncounter(t *testing.T) {
 		{
 			name: "no permission returns PermissionDeniedError",
 			setup: func(repo *mocks.MockEncounterRepository) {
-				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1).Return(false)
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.OwnerEncounterRole).Return(false)
 			},
 			wantErr: apperrors.PermissionDeniedError,
 		},
 		{
 			name: "happy path delegates to repo",
 			setup: func(repo *mocks.MockEncounterRepository) {
-				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1).Return(true)
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.OwnerEncounterRole).Return(true)
 				repo.EXPECT().RemoveEncounter(gomock.Any(), "enc-1").Return(nil)
 			},
 		},
@@ -311,7 +486,7 @@ func TestRemoveEncounter(t *testing.T) {
 			repo := mocks.NewMockEncounterRepository(ctrl)
 			tt.setup(repo)
 
-			uc := NewEncounterUsecases(repo)
+			uc := NewEncounterUsecases(repo, nil, nil)
 			err := uc.RemoveEncounter(context.Background(), "enc-1", 1)
 
 			if tt.wantErr != nil {
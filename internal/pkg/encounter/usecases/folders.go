@@ -0,0 +1,109 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+)
+
+const (
+	maxEncounterTags = 20
+	maxTagLength     = 30
+)
+
+// UpdateEncounterOrganization меняет папку, теги и признак шаблона. Папки личные, поэтому
+// раскладывать энкаунтер по ним может только владелец
+func (uc *encounterUsecases) UpdateEncounterOrganization(ctx context.Context, id string,
+	req *models.EncounterOrganizationReq, userID int) error {
+	l := logger.FromContext(ctx)
+
+	hasPermission := uc.repo.CheckPermission(ctx, id, userID, models.OwnerEncounterRole)
+	if !hasPermission {
+		l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"id": id})
+		return apperrors.PermissionDeniedError
+	}
+
+	tags, err := normalizeTags(req.Tags)
+	if err != nil {
+		l.UsecasesWarn(err, userID, map[string]any{"id": id, "tags": req.Tags})
+		return err
+	}
+
+	return uc.repo.UpdateEncounterOrganization(ctx, id, &models.EncounterOrganizationReq{
+		FolderID:   req.FolderID,
+		Tags:       tags,
+		IsTemplate: req.IsTemplate,
+	})
+}
+
+func (uc *encounterUsecases) GetEncounterFolders(ctx context.Context,
+	userID int) (*models.EncounterFoldersList, error) {
+	return uc.repo.GetEncounterFolders(ctx, userID)
+}
+
+func (uc *encounterUsecases) CreateEncounterFolder(ctx context.Context, req *models.EncounterFolderReq,
+	userID int) (*models.EncounterFolder, error) {
+	l := logger.FromContext(ctx)
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxEncounterName {
+		l.UsecasesWarn(apperrors.InvalidEncounterFolderErr, userID, map[string]any{"name": req.Name})
+		return nil, apperrors.InvalidEncounterFolderErr
+	}
+
+	folder := &models.EncounterFolder{UserID: userID, Name: name}
+
+	if err := uc.repo.CreateEncounterFolder(ctx, folder); err != nil {
+		return nil, err
+	}
+
+	return folder, nil
+}
+
+func (uc *encounterUsecases) RenameEncounterFolder(ctx context.Context, id int, req *models.EncounterFolderReq,
+	userID int) error {
+	l := logger.FromContext(ctx)
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxEncounterName {
+		l.UsecasesWarn(apperrors.InvalidEncounterFolderErr, userID, map[string]any{"id": id, "name": req.Name})
+		return apperrors.InvalidEncounterFolderErr
+	}
+
+	return uc.repo.RenameEncounterFolder(ctx, id, userID, name)
+}
+
+func (uc *encounterUsecases) RemoveEncounterFolder(ctx context.Context, id, userID int) error {
+	return uc.repo.RemoveEncounterFolder(ctx, id, userID)
+}
+
+// normalizeTags приводит теги к нижнему регистру и убирает повторы, чтобы фильтр по тегу
+// не зависел от того, как его набрали
+func normalizeTags(tags []string) ([]string, error) {
+	if len(tags) > maxEncounterTags {
+		return nil, apperrors.InvalidEncounterTagsErr
+	}
+
+	result := make([]string, 0, len(tags))
+	seen := make(map[string]struct{}, len(tags))
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+			return nil, apperrors.InvalidEncounterTagsErr
+		}
+
+		if _, ok := seen[tag]; ok {
+			continue
+		}
+
+		seen[tag] = struct{}{}
+		result = append(result, tag)
+	}
+
+	return result, nil
+}
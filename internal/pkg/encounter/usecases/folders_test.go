@@ -0,0 +1,99 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestUpdateEncounterOrganization(t *testing.T) {
+	t.Parallel()
+
+	folderID := 3
+
+	tests := []struct {
+		name    string
+		req     *models.EncounterOrganizationReq
+		setup   func(repo *mocks.MockEncounterRepository)
+		wantErr error
+	}{
+		{
+			name: "only the owner can organize the encounter",
+			req:  &models.EncounterOrganizationReq{},
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.OwnerEncounterRole).Return(false)
+			},
+			wantErr: apperrors.PermissionDeniedError,
+		},
+		{
+			name: "long tag returns InvalidEncounterTagsErr",
+			req:  &models.EncounterOrganizationReq{Tags: []string{strings.Repeat("т", maxTagLength+1)}},
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.OwnerEncounterRole).Return(true)
+			},
+			wantErr: apperrors.InvalidEncounterTagsErr,
+		},
+		{
+			name: "tags are normalized before saving",
+			req: &models.EncounterOrganizationReq{FolderID: &folderID, Tags: []string{"Goblins", " goblins", "Лес"},
+				IsTemplate: true},
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.OwnerEncounterRole).Return(true)
+				repo.EXPECT().UpdateEncounterOrganization(gomock.Any(), "enc-1", &models.EncounterOrganizationReq{
+					FolderID:   &folderID,
+					Tags:       []string{"goblins", "лес"},
+					IsTemplate: true,
+				}).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			repo := mocks.NewMockEncounterRepository(ctrl)
+			tt.setup(repo)
+
+			uc := NewEncounterUsecases(repo, nil, nil)
+
+			err := uc.UpdateEncounterOrganization(context.Background(), "enc-1", tt.req, 1)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCreateEncounterFolder(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockEncounterRepository(ctrl)
+	repo.EXPECT().CreateEncounterFolder(gomock.Any(), &models.EncounterFolder{UserID: 1, Name: "Curse of Strahd"}).
+		Return(nil)
+
+	uc := NewEncounterUsecases(repo, nil, nil)
+
+	folder, err := uc.CreateEncounterFolder(context.Background(), &models.EncounterFolderReq{Name: " Curse of Strahd "}, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Curse of Strahd", folder.Name)
+
+	_, err = uc.CreateEncounterFolder(context.Background(), &models.EncounterFolderReq{Name: "  "}, 1)
+	assert.ErrorIs(t, err, apperrors.InvalidEncounterFolderErr)
+
+	// The limit is in characters, so a Cyrillic name of maxEncounterName letters fits
+	cyrillic := strings.Repeat("я", maxEncounterName)
+	repo.EXPECT().CreateEncounterFolder(gomock.Any(), &models.EncounterFolder{UserID: 1, Name: cyrillic}).Return(nil)
+
+	_, err = uc.CreateEncounterFolder(context.Background(), &models.EncounterFolderReq{Name: cyrillic}, 1)
+	assert.NoError(t, err)
+
+	_, err = uc.CreateEncounterFolder(context.Background(), &models.EncounterFolderReq{Name: cyrillic + "я"}, 1)
+	assert.ErrorIs(t, err, apperrors.InvalidEncounterFolderErr)
+}
@@ -0,0 +1,242 @@
+package usecases
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/difficulty"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/generator"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+)
+
+const (
+	defaultCandidates  = 3
+	maxCandidates      = 5
+	defaultMaxMonsters = 10
+	maxMonstersLimit   = 20
+	generatorPoolSize  = 200
+	maxEncounterName   = 60
+)
+
+// GenerateEncounters подбирает случайные группы монстров из бестиария под заданную сложность.
+// Запрос к бестиарию ограничивается показателями опасности, которые укладываются в бюджет
+func (uc *encounterUsecases) GenerateEncounters(ctx context.Context, req *models.GenerateEncounterReq,
+	userID int) (*models.GeneratedEncounters, error) {
+	l := logger.FromContext(ctx)
+
+	count := req.Count
+	if count == 0 {
+		count = defaultCandidates
+	}
+
+	maxMonsters := req.MaxMonsters
+	if maxMonsters == 0 {
+		maxMonsters = defaultMaxMonsters
+	}
+
+	if count < 0 || count > maxCandidates || maxMonsters < 0 || maxMonsters > maxMonstersLimit ||
+		len(req.PartyLevels)+len(req.Characters) > maxDifficultyCombatants {
+		l.UsecasesWarn(apperrors.InvalidGenerateErr, userID, map[string]any{"count": count,
+			"max_monsters": maxMonsters})
+		return nil, apperrors.InvalidGenerateErr
+	}
+
+	levels := slices.Clone(req.PartyLevels)
+	for _, id := range req.Characters {
+		level, err := uc.characterLevel(ctx, id, userID, false)
+		if errors.Is(err, apperrors.InvalidDifficultyErr) {
+			return nil, apperrors.InvalidGenerateErr
+		} else if err != nil {
+			return nil, err
+		}
+
+		levels = append(levels, level)
+	}
+
+	thresholds, err := difficulty.PartyThresholds(levels)
+	if err != nil {
+		l.UsecasesWarn(err, userID, map[string]any{"levels": levels})
+		return nil, apperrors.InvalidGenerateErr
+	}
+
+	low, high, err := generator.Budget(thresholds, req.Difficulty)
+	if err != nil {
+		l.UsecasesWarn(err, userID, map[string]any{"difficulty": req.Difficulty})
+		return nil, apperrors.InvalidGenerateErr
+	}
+
+	result := &models.GeneratedEncounters{
+		Difficulty: req.Difficulty,
+		MinXP:      low,
+		MaxXP:      high,
+		Candidates: make([]*models.EncounterCandidate, 0, count),
+	}
+
+	filter := req.Filter
+	filter.Environment = append(slices.Clone(filter.Environment), req.Environment...)
+
+	ratings := difficulty.ChallengeRatingsBelow(high)
+	if len(filter.ChallengeRating) > 0 {
+		ratings = slices.DeleteFunc(ratings, func(rating string) bool {
+			return !slices.Contains(filter.ChallengeRating, rating)
+		})
+	}
+
+	if len(ratings) == 0 {
+		return result, nil
+	}
+
+	filter.ChallengeRating = ratings
+
+	pool, err := uc.bestiaryRepo.SampleCreatures(ctx, generatorPoolSize, filter)
+	if err != nil {
+		l.UsecasesError(err, userID, map[string]any{"difficulty": req.Difficulty})
+		return nil, err
+	}
+
+	creatures := make(map[string]*models.BestiaryCreature, len(pool))
+	options := make([]generator.Option, 0, len(pool))
+
+	for _, creature := range pool {
+		xp, err := difficulty.ChallengeXP(creature.ChallengeRating)
+		if err != nil {
+			continue
+		}
+
+		engName := strings.TrimPrefix(creature.URL, "/bestiary/")
+		creatures[engName] = creature
+		options = append(options, generator.Option{ID: engName, XP: xp})
+	}
+
+	groups, err := generator.Generate(options, levels, req.Difficulty, count, maxMonsters, generator.DefaultRNG)
+	if errors.Is(err, generator.ErrNoGroups) {
+		return result, nil
+	} else if err != nil {
+		l.UsecasesWarn(err, userID, map[string]any{"difficulty": req.Difficulty})
+		return nil, apperrors.InvalidGenerateErr
+	}
+
+	hits := make(map[string]int)
+
+	for _, group := range groups {
+		candidate, err := uc.buildCandidate(ctx, group, creatures, hits)
+		if err != nil {
+			l.UsecasesError(err, userID, map[string]any{"difficulty": req.Difficulty})
+			return nil, err
+		}
+
+		result.Candidates = append(result.Candidates, candidate)
+	}
+
+	return result, nil
+}
+
+// buildCandidate собирает данные энкаунтера для группы. Хиты берутся из карточек существ, hits кэширует
+// их между группами
+func (uc *encounterUsecases) buildCandidate(ctx context.Context, group generator.Group,
+	creatures map[string]*models.BestiaryCreature, hits map[string]int) (*models.EncounterCandidate, error) {
+	candidate := &models.EncounterCandidate{
+		Creatures:  make([]models.GeneratedCreature, 0, len(group.Picks)),
+		Difficulty: group.Difficulty,
+	}
+
+	content := models.EncounterContent{
+		SchemaVersion: models.EncounterSchemaVersion,
+		Participants:  make([]models.EncounterParticipant, 0, group.Difficulty.MonsterCount),
+	}
+
+	names := make([]string, 0, len(group.Picks))
+
+	for _, pick := range group.Picks {
+		creature := creatures[pick.ID]
+		xp, _ := difficulty.ChallengeXP(creature.ChallengeRating)
+
+		hp, ok := hits[pick.ID]
+		if !ok {
+			full, err := uc.bestiaryRepo.GetCreatureByEngName(ctx, pick.ID, false)
+			if err != nil {
+				return nil, err
+			}
+
+			if full != nil {
+				hp = full.Hits.Average
+			}
+
+			hits[pick.ID] = hp
+		}
+
+		candidate.Creatures = append(candidate.Creatures, models.GeneratedCreature{
+			EngName:         pick.ID,
+			Name:            creature.Name,
+			ChallengeRating: creature.ChallengeRating,
+			Experience:      xp,
+			Count:           pick.Count,
+		})
+
+		name := creature.Name.Rus
+		if name == "" {
+			name = creature.Name.Eng
+		}
+
+		if name == "" {
+			name = pick.ID
+		}
+
+		for i := 1; i <= pick.Count; i++ {
+			participantName := name
+			if pick.Count > 1 {
+				participantName = fmt.Sprintf("%s %d", name, i)
+			}
+
+			content.Participants = append(content.Participants, models.EncounterParticipant{
+				ID:        uuid.NewString(),
+				Kind:      models.CreatureCombatant,
+				SourceID:  pick.ID,
+				Name:      participantName,
+				MaxHP:     hp,
+				CurrentHP: hp,
+			})
+		}
+
+		if pick.Count > 1 {
+			name = fmt.Sprintf("%s ×%d", name, pick.Count)
+		}
+
+		names = append(names, name)
+	}
+
+	data, err := json.Marshal(content)
+	if err != nil {
+		return nil, err
+	}
+
+	candidate.Encounter = models.SaveEncounterReq{
+		Name: truncateName(strings.Join(names, ", ")),
+		Data: data,
+	}
+
+	return candidate, nil
+}
+
+// truncateName обрезает название до допустимой длины в байтах, не разрезая символы
+func truncateName(name string) string {
+	if len(name) <= maxEncounterName {
+		return name
+	}
+
+	cut := maxEncounterName - len("…")
+	for cut > 0 && !utf8.RuneStart(name[cut]) {
+		cut--
+	}
+
+	return name[:cut] + "…"
+}
@@ -0,0 +1,157 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	bestiarymocks "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/bestiary/mocks"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/difficulty"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/mocks"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/schema"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGenerateEncounters_CandidateCanBeSaved(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockEncounterRepository(ctrl)
+	bestiary := bestiarymocks.NewMockBestiaryRepository(ctrl)
+
+	expectedFilter := models.FilterParams{
+		Type:            []string{"гуманоид"},
+		Environment:     []string{"forest"},
+		ChallengeRating: difficulty.ChallengeRatingsBelow(200),
+	}
+	goblin := &models.BestiaryCreature{
+		Name:            models.Name{Rus: "Гоблин", Eng: "Goblin"},
+		ChallengeRating: "1/4",
+		URL:             "/bestiary/goblin",
+	}
+
+	bestiary.EXPECT().SampleCreatures(gomock.Any(), generatorPoolSize, expectedFilter).Return([]*models.BestiaryCreature{goblin}, nil)
+	bestiary.EXPECT().GetCreatureByEngName(gomock.Any(), "goblin", false).
+		Return(&models.Creature{Hits: models.Hits{Average: 7}}, nil).Times(1)
+	repo.EXPECT().SaveEncounter(gomock.Any(), gomock.Any(), gomock.Not(""), 1).Return(nil)
+
+	uc := NewEncounterUsecases(repo, bestiary, nil)
+	result, err := uc.GenerateEncounters(context.Background(), &models.GenerateEncounterReq{
+		Difficulty:  models.EasyDifficulty,
+		PartyLevels: []int{1, 1, 1, 1},
+		Environment: []string{"forest"},
+		Filter:      models.FilterParams{Type: []string{"гуманоид"}},
+	}, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 100, result.MinXP)
+	assert.Equal(t, 200, result.MaxXP)
+	assert.Len(t, result.Candidates, 1)
+
+	candidate := result.Candidates[0]
+	assert.Equal(t, []models.GeneratedCreature{{EngName: "goblin", Name: goblin.Name, ChallengeRating: "1/4",
+		Experience: 50, Count: 2}}, candidate.Creatures)
+	assert.Equal(t, models.EasyDifficulty, candidate.Difficulty.Difficulty)
+	assert.Equal(t, "Гоблин ×2", candidate.Encounter.Name)
+
+	content, err := schema.Validate(candidate.Encounter.Data)
+	assert.NoError(t, err)
+	assert.Len(t, content.Participants, 2)
+	assert.Equal(t, "Гоблин 1", content.Participants[0].Name)
+	assert.Equal(t, 7, content.Participants[1].MaxHP)
+
+	err = uc.SaveEncounter(context.Background(), &candidate.Encounter, 1)
+	assert.NoError(t, err)
+}
+
+func TestGenerateEncounters(t *testing.T) {
+	t.Parallel()
+
+	dbErr := errors.New("db failure")
+	dragon := &models.BestiaryCreature{ChallengeRating: "30", URL: "/bestiary/dragon"}
+
+	tests := []struct {
+		name           string
+		req            *models.GenerateEncounterReq
+		setup          func(bestiary *bestiarymocks.MockBestiaryRepository)
+		wantErr        error
+		wantCandidates int
+	}{
+		{
+			name:    "trivial difficulty is rejected",
+			req:     &models.GenerateEncounterReq{Difficulty: models.TrivialDifficulty, PartyLevels: []int{1}},
+			setup:   func(_ *bestiarymocks.MockBestiaryRepository) {},
+			wantErr: apperrors.InvalidGenerateErr,
+		},
+		{
+			name: "too many candidates are rejected",
+			req: &models.GenerateEncounterReq{Difficulty: models.HardDifficulty, PartyLevels: []int{1},
+				Count: 6},
+			setup:   func(_ *bestiarymocks.MockBestiaryRepository) {},
+			wantErr: apperrors.InvalidGenerateErr,
+		},
+		{
+			name:    "empty party is rejected",
+			req:     &models.GenerateEncounterReq{Difficulty: models.HardDifficulty},
+			setup:   func(_ *bestiarymocks.MockBestiaryRepository) {},
+			wantErr: apperrors.InvalidGenerateErr,
+		},
+		{
+			name: "challenge rating filter outside of budget gives no candidates",
+			req: &models.GenerateEncounterReq{Difficulty: models.EasyDifficulty, PartyLevels: []int{1},
+				Filter: models.FilterParams{ChallengeRating: []string{"10"}}},
+			setup: func(_ *bestiarymocks.MockBestiaryRepository) {},
+		},
+		{
+			name: "no fitting group gives no candidates",
+			req:  &models.GenerateEncounterReq{Difficulty: models.EasyDifficulty, PartyLevels: []int{1}},
+			setup: func(bestiary *bestiarymocks.MockBestiaryRepository) {
+				bestiary.EXPECT().SampleCreatures(gomock.Any(), generatorPoolSize, gomock.Any()).Return([]*models.BestiaryCreature{dragon}, nil)
+			},
+		},
+		{
+			name: "bestiary error is propagated",
+			req:  &models.GenerateEncounterReq{Difficulty: models.EasyDifficulty, PartyLevels: []int{1}},
+			setup: func(bestiary *bestiarymocks.MockBestiaryRepository) {
+				bestiary.EXPECT().SampleCreatures(gomock.Any(), generatorPoolSize, gomock.Any()).Return(nil, dbErr)
+			},
+			wantErr: dbErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			bestiary := bestiarymocks.NewMockBestiaryRepository(ctrl)
+			tt.setup(bestiary)
+
+			uc := NewEncounterUsecases(mocks.NewMockEncounterRepository(ctrl), bestiary, nil)
+			result, err := uc.GenerateEncounters(context.Background(), tt.req, 1)
+
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "expected %v, got %v", tt.wantErr, err)
+				assert.Nil(t, result)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Len(t, result.Candidates, tt.wantCandidates)
+		})
+	}
+}
+
+func TestTruncateName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "Гоблин", truncateName("Гоблин"))
+
+	long := truncateName("Гоблин ×3, Хобгоблин ×2, Багбир, Волк ×4, Орк ×2")
+	assert.LessOrEqual(t, len(long), maxEncounterName)
+	assert.Equal(t, "Гоблин ×3, Хобгоблин ×2, Багбир, …", long)
+}
@@ -0,0 +1,198 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"time"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/schema"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+)
+
+const (
+	maxSharedPage      = 100
+	maxShareLinkTTL    = 365 * 24 * time.Hour
+	shareLinkTokenSize = 24
+)
+
+// GetSharedEncounters возвращает чужие энкаунтеры, к которым пользователю выдан доступ
+func (uc *encounterUsecases) GetSharedEncounters(ctx context.Context, size, start,
+	userID int) (*models.SharedEncountersList, error) {
+	l := logger.FromContext(ctx)
+
+	if start < 0 || size <= 0 {
+		l.UsecasesWarn(apperrors.StartPosSizeError, userID, map[string]any{"start": start, "size": size})
+		return nil, apperrors.StartPosSizeError
+	}
+
+	return uc.repo.GetSharedEncounters(ctx, min(size, maxSharedPage), start, userID)
+}
+
+func (uc *encounterUsecases) GetEncounterShares(ctx context.Context, id string,
+	userID int) (*models.EncounterSharesList, error) {
+	l := logger.FromContext(ctx)
+
+	hasPermission := uc.repo.CheckPermission(ctx, id, userID, models.CoOwnerEncounterRole)
+	if !hasPermission {
+		l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"id": id})
+		return nil, apperrors.PermissionDeniedError
+	}
+
+	return uc.repo.GetEncounterShares(ctx, id)
+}
+
+// ShareEncounter выдаёт пользователю targetID роль в энкаунтере. Доступом управляют владелец
+// и совладельцы, роль владельца и собственную роль изменить нельзя
+func (uc *encounterUsecases) ShareEncounter(ctx context.Context, id string, targetID int,
+	role models.EncounterRole, userID int) error {
+	l := logger.FromContext(ctx)
+
+	hasPermission := uc.repo.CheckPermission(ctx, id, userID, models.CoOwnerEncounterRole)
+	if !hasPermission {
+		l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"id": id})
+		return apperrors.PermissionDeniedError
+	}
+
+	if !role.IsGrantable() || targetID == userID {
+		l.UsecasesWarn(apperrors.InvalidShareErr, userID, map[string]any{"id": id, "target_id": targetID,
+			"role": role})
+		return apperrors.InvalidShareErr
+	}
+
+	encounter, err := uc.repo.GetEncounterByID(ctx, id)
+	if err != nil {
+		l.UsecasesError(err, userID, map[string]any{"id": id})
+		return err
+	}
+
+	if encounter.UserID == targetID {
+		l.UsecasesWarn(apperrors.InvalidShareErr, userID, map[string]any{"id": id, "target_id": targetID})
+		return apperrors.InvalidShareErr
+	}
+
+	return uc.repo.ShareEncounter(ctx, &models.EncounterShare{
+		EncounterID: id,
+		UserID:      targetID,
+		Role:        role,
+		GrantedBy:   userID,
+	})
+}
+
+// RevokeEncounterShare отзывает доступ пользователя targetID. Любой получивший доступ может
+// отказаться от него сам
+func (uc *encounterUsecases) RevokeEncounterShare(ctx context.Context, id string, targetID, userID int) error {
+	l := logger.FromContext(ctx)
+
+	required := models.CoOwnerEncounterRole
+	if targetID == userID {
+		required = models.ViewerEncounterRole
+	}
+
+	hasPermission := uc.repo.CheckPermission(ctx, id, userID, required)
+	if !hasPermission {
+		l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"id": id, "target_id": targetID})
+		return apperrors.PermissionDeniedError
+	}
+
+	return uc.repo.RevokeEncounterShare(ctx, id, targetID)
+}
+
+// CreateShareLink создаёт ссылку, по которой энкаунтер может посмотреть любой вошедший пользователь
+func (uc *encounterUsecases) CreateShareLink(ctx context.Context, id string, req *models.CreateShareLinkReq,
+	userID int) (*models.EncounterShareLink, error) {
+	l := logger.FromContext(ctx)
+
+	hasPermission := uc.repo.CheckPermission(ctx, id, userID, models.CoOwnerEncounterRole)
+	if !hasPermission {
+		l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"id": id})
+		return nil, apperrors.PermissionDeniedError
+	}
+
+	ttl := time.Duration(req.ExpiresIn) * time.Second
+	if ttl < 0 || ttl > maxShareLinkTTL {
+		l.UsecasesWarn(apperrors.InvalidShareErr, userID, map[string]any{"id": id, "expires_in": req.ExpiresIn})
+		return nil, apperrors.InvalidShareErr
+	}
+
+	token, err := newShareToken()
+	if err != nil {
+		l.UsecasesError(err, userID, map[string]any{"id": id})
+		return nil, err
+	}
+
+	link := &models.EncounterShareLink{
+		Token:       token,
+		EncounterID: id,
+		CreatedBy:   userID,
+	}
+
+	if ttl > 0 {
+		expiresAt := time.Now().Add(ttl)
+		link.ExpiresAt = &expiresAt
+	}
+
+	if err := uc.repo.CreateShareLink(ctx, link); err != nil {
+		return nil, err
+	}
+
+	return link, nil
+}
+
+func (uc *encounterUsecases) GetShareLinks(ctx context.Context, id string,
+	userID int) (*models.EncounterShareLinksList, error) {
+	l := logger.FromContext(ctx)
+
+	hasPermission := uc.repo.CheckPermission(ctx, id, userID, models.CoOwnerEncounterRole)
+	if !hasPermission {
+		l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"id": id})
+		return nil, apperrors.PermissionDeniedError
+	}
+
+	return uc.repo.GetShareLinks(ctx, id)
+}
+
+func (uc *encounterUsecases) RevokeShareLink(ctx context.Context, id, token string, userID int) error {
+	l := logger.FromContext(ctx)
+
+	hasPermission := uc.repo.CheckPermission(ctx, id, userID, models.CoOwnerEncounterRole)
+	if !hasPermission {
+		l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"id": id})
+		return apperrors.PermissionDeniedError
+	}
+
+	return uc.repo.RevokeShareLink(ctx, id, token)
+}
+
+// GetEncounterByShareLink возвращает энкаунтер по ссылке на просмотр. Данные приводятся к текущей
+// схеме, но не сохраняются: ссылка не даёт права на запись
+func (uc *encounterUsecases) GetEncounterByShareLink(ctx context.Context, token string,
+	userID int) (*models.Encounter, error) {
+	l := logger.FromContext(ctx)
+
+	encounter, err := uc.repo.GetEncounterByShareLink(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+
+	data, _, err := schema.Migrate(encounter.Data)
+	if err != nil {
+		l.UsecasesWarn(err, userID, map[string]any{"id": encounter.UUID})
+		return encounter, nil
+	}
+
+	encounter.Data = data
+
+	return encounter, nil
+}
+
+func newShareToken() (string, error) {
+	buf := make([]byte, shareLinkTokenSize)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
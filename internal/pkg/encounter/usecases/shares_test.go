@@ -0,0 +1,192 @@
+package usecases
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestShareEncounter(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		targetID int
+		role     models.EncounterRole
+		setup    func(repo *mocks.MockEncounterRepository)
+		wantErr  error
+	}{
+		{
+			name:     "editor cannot manage shares",
+			targetID: 2,
+			role:     models.ViewerEncounterRole,
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.CoOwnerEncounterRole).Return(false)
+			},
+			wantErr: apperrors.PermissionDeniedError,
+		},
+		{
+			name:     "owner role cannot be granted",
+			targetID: 2,
+			role:     models.OwnerEncounterRole,
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.CoOwnerEncounterRole).Return(true)
+			},
+			wantErr: apperrors.InvalidShareErr,
+		},
+		{
+			name:     "own role cannot be changed",
+			targetID: 1,
+			role:     models.EditorEncounterRole,
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.CoOwnerEncounterRole).Return(true)
+			},
+			wantErr: apperrors.InvalidShareErr,
+		},
+		{
+			name:     "co-owner cannot share with the owner",
+			targetID: 5,
+			role:     models.ViewerEncounterRole,
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.CoOwnerEncounterRole).Return(true)
+				repo.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").
+					Return(&models.Encounter{UUID: "enc-1", UserID: 5}, nil)
+			},
+			wantErr: apperrors.InvalidShareErr,
+		},
+		{
+			name:     "grant is stored",
+			targetID: 2,
+			role:     models.EditorEncounterRole,
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.CoOwnerEncounterRole).Return(true)
+				repo.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").
+					Return(&models.Encounter{UUID: "enc-1", UserID: 1}, nil)
+				repo.EXPECT().ShareEncounter(gomock.Any(), &models.EncounterShare{
+					EncounterID: "enc-1",
+					UserID:      2,
+					Role:        models.EditorEncounterRole,
+					GrantedBy:   1,
+				}).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			repo := mocks.NewMockEncounterRepository(ctrl)
+			tt.setup(repo)
+
+			uc := NewEncounterUsecases(repo, nil, nil)
+
+			err := uc.ShareEncounter(context.Background(), "enc-1", tt.targetID, tt.role, 1)
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestRevokeEncounterShare_UserCanLeaveShare(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockEncounterRepository(ctrl)
+	repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 2, models.ViewerEncounterRole).Return(true)
+	repo.EXPECT().RevokeEncounterShare(gomock.Any(), "enc-1", 2).Return(nil)
+
+	uc := NewEncounterUsecases(repo, nil, nil)
+
+	assert.NoError(t, uc.RevokeEncounterShare(context.Background(), "enc-1", 2, 2))
+}
+
+func TestCreateShareLink(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		expiresIn  int
+		setup      func(repo *mocks.MockEncounterRepository)
+		wantErr    error
+		wantExpiry bool
+	}{
+		{
+			name:      "negative lifetime returns InvalidShareErr",
+			expiresIn: -1,
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.CoOwnerEncounterRole).Return(true)
+			},
+			wantErr: apperrors.InvalidShareErr,
+		},
+		{
+			name:      "lifetime over a year returns InvalidShareErr",
+			expiresIn: int(maxShareLinkTTL/time.Second) + 1,
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.CoOwnerEncounterRole).Return(true)
+			},
+			wantErr: apperrors.InvalidShareErr,
+		},
+		{
+			name: "zero lifetime creates a link without expiry",
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.CoOwnerEncounterRole).Return(true)
+				repo.EXPECT().CreateShareLink(gomock.Any(), gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:      "positive lifetime sets expiry",
+			expiresIn: 3600,
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.CoOwnerEncounterRole).Return(true)
+				repo.EXPECT().CreateShareLink(gomock.Any(), gomock.Any()).Return(nil)
+			},
+			wantExpiry: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			repo := mocks.NewMockEncounterRepository(ctrl)
+			tt.setup(repo)
+
+			uc := NewEncounterUsecases(repo, nil, nil)
+
+			link, err := uc.CreateShareLink(context.Background(), "enc-1",
+				&models.CreateShareLinkReq{ExpiresIn: tt.expiresIn}, 1)
+			assert.ErrorIs(t, err, tt.wantErr)
+
+			if tt.wantErr != nil {
+				return
+			}
+
+			assert.NotEmpty(t, link.Token)
+			assert.Equal(t, "enc-1", link.EncounterID)
+			assert.Equal(t, tt.wantExpiry, link.ExpiresAt != nil)
+		})
+	}
+}
+
+func TestGetEncounterByShareLink_UpgradesLegacyDataWithoutSaving(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockEncounterRepository(ctrl)
+	repo.EXPECT().GetEncounterByShareLink(gomock.Any(), "token").
+		Return(&models.Encounter{UUID: "enc-1", Data: []byte(`{"monsters":[]}`)}, nil)
+
+	uc := NewEncounterUsecases(repo, nil, nil)
+
+	encounter, err := uc.GetEncounterByShareLink(context.Background(), "token", 2)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"monsters":[],"schemaVersion":1,"participants":[]}`, string(encounter.Data))
+}
@@ -0,0 +1,90 @@
+package usecases
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+)
+
+const copySuffix = " (копия)"
+
+// DuplicateEncounter создаёт копию энкаунтера у пользователя. Копировать можно и чужой энкаунтер,
+// к которому выдан доступ на просмотр. Копия шаблона тоже остаётся шаблоном
+func (uc *encounterUsecases) DuplicateEncounter(ctx context.Context, id string, req *models.CopyEncounterReq,
+	userID int) (*models.Encounter, error) {
+	return uc.copyEncounter(ctx, id, req, userID, false)
+}
+
+// InstantiateTemplate создаёт из шаблона обычный энкаунтер
+func (uc *encounterUsecases) InstantiateTemplate(ctx context.Context, id string, req *models.CopyEncounterReq,
+	userID int) (*models.Encounter, error) {
+	return uc.copyEncounter(ctx, id, req, userID, true)
+}
+
+func (uc *encounterUsecases) copyEncounter(ctx context.Context, id string, req *models.CopyEncounterReq,
+	userID int, fromTemplate bool) (*models.Encounter, error) {
+	l := logger.FromContext(ctx)
+
+	hasPermission := uc.repo.CheckPermission(ctx, id, userID, models.ViewerEncounterRole)
+	if !hasPermission {
+		l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"id": id})
+		return nil, apperrors.PermissionDeniedError
+	}
+
+	source, err := uc.repo.GetEncounterByID(ctx, id)
+	if err != nil {
+		l.UsecasesError(err, userID, map[string]any{"id": id})
+		return nil, err
+	}
+
+	if fromTemplate && !source.IsTemplate {
+		l.UsecasesWarn(apperrors.EncounterNotTemplateErr, userID, map[string]any{"id": id})
+		return nil, apperrors.EncounterNotTemplateErr
+	}
+
+	name := req.Name
+	if name == "" {
+		name = source.Name
+		if !fromTemplate {
+			name = copyName(source.Name)
+		}
+	}
+
+	// Папки личные: папку исходного энкаунтера можно сохранить, только если он принадлежит пользователю
+	folderID := req.FolderID
+	if folderID == nil && source.UserID == userID {
+		folderID = source.FolderID
+	}
+
+	copied := &models.SaveEncounterReq{
+		Name:       name,
+		Data:       source.Data,
+		FolderID:   folderID,
+		Tags:       source.Tags,
+		IsTemplate: source.IsTemplate && !fromTemplate,
+	}
+
+	newID, err := uc.saveEncounter(ctx, copied, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.repo.GetEncounterByID(ctx, newID)
+}
+
+// copyName добавляет к имени пометку о копии, обрезая исходное имя, если результат не помещается
+func copyName(name string) string {
+	if len(name)+len(copySuffix) <= maxEncounterName {
+		return name + copySuffix
+	}
+
+	cut := maxEncounterName - len(copySuffix)
+	for cut > 0 && !utf8.RuneStart(name[cut]) {
+		cut--
+	}
+
+	return name[:cut] + copySuffix
+}
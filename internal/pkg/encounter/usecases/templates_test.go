@@ -0,0 +1,149 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestDuplicateEncounter(t *testing.T) {
+	t.Parallel()
+
+	folderID := 7
+	otherFolderID := 9
+
+	tests := []struct {
+		name   string
+		source *models.Encounter
+		req    *models.CopyEncounterReq
+		want   *models.SaveEncounterReq
+	}{
+		{
+			name: "own encounter keeps folder and tags",
+			source: &models.Encounter{UUID: "enc-1", UserID: 1, Name: "Засада гоблинов", FolderID: &folderID,
+				Tags: []string{"goblins"}},
+			req: &models.CopyEncounterReq{},
+			want: &models.SaveEncounterReq{Name: "Засада гоблинов (копия)", FolderID: &folderID,
+				Tags: []string{"goblins"}},
+		},
+		{
+			name:   "shared encounter is copied without the owner's folder",
+			source: &models.Encounter{UUID: "enc-1", UserID: 5, Name: "Ambush", FolderID: &folderID},
+			req:    &models.CopyEncounterReq{Name: "My ambush"},
+			want:   &models.SaveEncounterReq{Name: "My ambush", Tags: []string{}},
+		},
+		{
+			name:   "copy of a template stays a template",
+			source: &models.Encounter{UUID: "enc-1", UserID: 1, Name: "Ambush", IsTemplate: true},
+			req:    &models.CopyEncounterReq{FolderID: &otherFolderID},
+			want: &models.SaveEncounterReq{Name: "Ambush (копия)", FolderID: &otherFolderID, Tags: []string{},
+				IsTemplate: true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			repo := mocks.NewMockEncounterRepository(ctrl)
+
+			var newID string
+
+			repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.ViewerEncounterRole).Return(true)
+			repo.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").Return(tt.source, nil)
+			repo.EXPECT().SaveEncounter(gomock.Any(), gomock.Any(), gomock.Not(""), 1).DoAndReturn(
+				func(_ context.Context, encounter *models.SaveEncounterReq, id string, _ int) error {
+					newID = id
+
+					assert.Equal(t, tt.want.Name, encounter.Name)
+					assert.Equal(t, tt.want.FolderID, encounter.FolderID)
+					assert.Equal(t, tt.want.Tags, encounter.Tags)
+					assert.Equal(t, tt.want.IsTemplate, encounter.IsTemplate)
+					assert.JSONEq(t, `{"schemaVersion":1,"participants":[]}`, string(encounter.Data))
+
+					return nil
+				})
+			repo.EXPECT().GetEncounterByID(gomock.Any(), gomock.Any()).DoAndReturn(
+				func(_ context.Context, id string) (*models.Encounter, error) {
+					assert.Equal(t, newID, id)
+					return &models.Encounter{UUID: id}, nil
+				})
+
+			uc := NewEncounterUsecases(repo, nil, nil)
+
+			encounter, err := uc.DuplicateEncounter(context.Background(), "enc-1", tt.req, 1)
+			assert.NoError(t, err)
+			assert.Equal(t, newID, encounter.UUID)
+		})
+	}
+}
+
+func TestInstantiateTemplate(t *testing.T) {
+	t.Parallel()
+
+	t.Run("regular encounter returns EncounterNotTemplateErr", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		repo := mocks.NewMockEncounterRepository(ctrl)
+		repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.ViewerEncounterRole).Return(true)
+		repo.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").
+			Return(&models.Encounter{UUID: "enc-1", UserID: 1, Name: "Ambush"}, nil)
+
+		uc := NewEncounterUsecases(repo, nil, nil)
+
+		_, err := uc.InstantiateTemplate(context.Background(), "enc-1", &models.CopyEncounterReq{}, 1)
+		assert.ErrorIs(t, err, apperrors.EncounterNotTemplateErr)
+	})
+
+	t.Run("instance is a regular encounter with the template name", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		repo := mocks.NewMockEncounterRepository(ctrl)
+		repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.ViewerEncounterRole).Return(true)
+		repo.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").
+			Return(&models.Encounter{UUID: "enc-1", UserID: 1, Name: "Ambush", IsTemplate: true}, nil)
+		repo.EXPECT().SaveEncounter(gomock.Any(), gomock.Cond(func(encounter *models.SaveEncounterReq) bool {
+			return encounter.Name == "Ambush" && !encounter.IsTemplate
+		}), gomock.Not(""), 1).Return(nil)
+		repo.EXPECT().GetEncounterByID(gomock.Any(), gomock.Not("enc-1")).Return(&models.Encounter{}, nil)
+
+		uc := NewEncounterUsecases(repo, nil, nil)
+
+		_, err := uc.InstantiateTemplate(context.Background(), "enc-1", &models.CopyEncounterReq{}, 1)
+		assert.NoError(t, err)
+	})
+
+	t.Run("viewer access is required", func(t *testing.T) {
+		t.Parallel()
+
+		ctrl := gomock.NewController(t)
+		repo := mocks.NewMockEncounterRepository(ctrl)
+		repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.ViewerEncounterRole).Return(false)
+
+		uc := NewEncounterUsecases(repo, nil, nil)
+
+		_, err := uc.InstantiateTemplate(context.Background(), "enc-1", &models.CopyEncounterReq{}, 1)
+		assert.ErrorIs(t, err, apperrors.PermissionDeniedError)
+	})
+}
+
+func TestCopyName(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "Ambush (копия)", copyName("Ambush"))
+
+	long := copyName(strings.Repeat("Гоблин", 10))
+	assert.LessOrEqual(t, len(long), maxEncounterName)
+	assert.True(t, utf8.ValidString(long))
+	assert.True(t, strings.HasSuffix(long, copySuffix))
+}
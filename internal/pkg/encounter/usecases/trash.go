@@ -0,0 +1,74 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+)
+
+const maxTrashPage = 100
+
+// GetDeletedEncounters возвращает корзину пользователя, последние удалённые энкаунтеры идут первыми
+func (uc *encounterUsecases) GetDeletedEncounters(ctx context.Context, size, start,
+	userID int) (*models.DeletedEncountersList, error) {
+	l := logger.FromContext(ctx)
+
+	if start < 0 || size <= 0 {
+		l.UsecasesWarn(apperrors.StartPosSizeError, userID, map[string]any{"start": start, "size": size})
+		return nil, apperrors.StartPosSizeError
+	}
+
+	return uc.repo.GetDeletedEncounters(ctx, min(size, maxTrashPage), start, userID)
+}
+
+func (uc *encounterUsecases) RestoreEncounter(ctx context.Context, id string, userID int) error {
+	return uc.repo.RestoreEncounter(ctx, id, userID)
+}
+
+func (uc *encounterUsecases) PurgeEncounter(ctx context.Context, id string, userID int) error {
+	return uc.repo.PurgeEncounter(ctx, id, userID)
+}
+
+// RunTrashPurge периодически удаляет энкаунтеры, пролежавшие в корзине дольше retention. Первая
+// очистка выполняется сразу. Блокируется до отмены контекста. Если retention или interval
+// не положительны, очистка не запускается
+func (uc *encounterUsecases) RunTrashPurge(ctx context.Context, retention, interval time.Duration) {
+	l := logger.FromContext(ctx)
+
+	if retention <= 0 || interval <= 0 {
+		l.UsecasesError(apperrors.InvalidTrashPurgeErr, 0, map[string]any{"retention": retention.String(),
+			"interval": interval.String()})
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		uc.purgeTrash(ctx, retention)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (uc *encounterUsecases) purgeTrash(ctx context.Context, retention time.Duration) {
+	l := logger.FromContext(ctx)
+
+	purged, err := uc.repo.PurgeDeletedEncounters(ctx, time.Now().Add(-retention))
+	if err != nil {
+		l.UsecasesError(err, 0, map[string]any{"retention": retention.String()})
+		return
+	}
+
+	if purged > 0 {
+		l.UsecasesInfo(fmt.Sprintf("purged %d encounters from trash", purged), 0)
+	}
+}
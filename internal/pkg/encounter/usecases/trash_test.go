@@ -0,0 +1,102 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetDeletedEncounters(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockEncounterRepository(ctrl)
+	repo.EXPECT().GetDeletedEncounters(gomock.Any(), maxTrashPage, 0, 1).
+		Return(&models.DeletedEncountersList{{UUID: "enc-1"}}, nil)
+
+	uc := NewEncounterUsecases(repo, nil, nil)
+
+	list, err := uc.GetDeletedEncounters(context.Background(), 500, 0, 1)
+	assert.NoError(t, err)
+	assert.Len(t, *list, 1)
+
+	_, err = uc.GetDeletedEncounters(context.Background(), 0, 0, 1)
+	assert.ErrorIs(t, err, apperrors.StartPosSizeError)
+}
+
+func TestRunTrashPurge_PurgesOlderThanRetention(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockEncounterRepository(ctrl)
+
+	retention := 30 * 24 * time.Hour
+	before := time.Now().Add(-retention)
+
+	repo.EXPECT().PurgeDeletedEncounters(gomock.Any(), gomock.Cond(func(cutoff time.Time) bool {
+		return !cutoff.Before(before) && cutoff.Before(before.Add(time.Minute))
+	})).Return(int64(2), nil)
+
+	// A cancelled context stops the job right after the first purge
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	uc := NewEncounterUsecases(repo, nil, nil)
+	uc.RunTrashPurge(ctx, retention, time.Hour)
+}
+
+func TestRunTrashPurge_ErrorDoesNotStopJob(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockEncounterRepository(ctrl)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	gomock.InOrder(
+		repo.EXPECT().PurgeDeletedEncounters(gomock.Any(), gomock.Any()).Return(int64(0), errors.New("db failure")),
+		repo.EXPECT().PurgeDeletedEncounters(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(_ context.Context, _ time.Time) (int64, error) {
+				cancel()
+				return 0, nil
+			}),
+	)
+
+	uc := NewEncounterUsecases(repo, nil, nil)
+	uc.RunTrashPurge(ctx, time.Hour, time.Millisecond)
+}
+
+func TestRunTrashPurge_InvalidConfigSkipsJob(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		retention time.Duration
+		interval  time.Duration
+	}{
+		{"zero interval", time.Hour, 0},
+		{"negative interval", time.Hour, -time.Second},
+		{"zero retention", 0, time.Hour},
+		{"negative retention", -time.Hour, time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// No purge is expected and the job returns without waiting for the context
+			ctrl := gomock.NewController(t)
+			repo := mocks.NewMockEncounterRepository(ctrl)
+
+			uc := NewEncounterUsecases(repo, nil, nil)
+			uc.RunTrashPurge(context.Background(), tt.retention, tt.interval)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils/merger"
+)
+
+const maxVersionsPage = 100
+
+// GetEncounterVersions возвращает историю версий энкаунтера от новых к старым, без данных
+func (uc *encounterUsecases) GetEncounterVersions(ctx context.Context, id string, size, start,
+	userID int) (*models.EncounterVersionsList, error) {
+	l := logger.FromContext(ctx)
+
+	if start < 0 || size <= 0 {
+		l.UsecasesWarn(apperrors.StartPosSizeError, userID, map[string]any{"start": start, "size": size})
+		return nil, apperrors.StartPosSizeError
+	}
+
+	hasPermission := uc.repo.CheckPermission(ctx, id, userID, models.ViewerEncounterRole)
+	if !hasPermission {
+		l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"id": id})
+		return nil, apperrors.PermissionDeniedError
+	}
+
+	return uc.repo.GetEncounterVersions(ctx, id, min(size, maxVersionsPage), start)
+}
+
+func (uc *encounterUsecases) GetEncounterVersion(ctx context.Context, id string, version,
+	userID int) (*models.EncounterVersion, error) {
+	l := logger.FromContext(ctx)
+
+	hasPermission := uc.repo.CheckPermission(ctx, id, userID, models.ViewerEncounterRole)
+	if !hasPermission {
+		l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"id": id})
+		return nil, apperrors.PermissionDeniedError
+	}
+
+	return uc.repo.GetEncounterVersion(ctx, id, version)
+}
+
+// DiffEncounterVersions сравнивает две версии энкаунтера. Перед сравнением обе версии приводятся
+// к текущей схеме, чтобы разница в формате старых данных не попадала в патч
+func (uc *encounterUsecases) DiffEncounterVersions(ctx context.Context, id string, from, to,
+	userID int) (*models.EncounterVersionsDiff, error) {
+	l := logger.FromContext(ctx)
+
+	hasPermission := uc.repo.CheckPermission(ctx, id, userID, models.ViewerEncounterRole)
+	if !hasPermission {
+		l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"id": id})
+		return nil, apperrors.PermissionDeniedError
+	}
+
+	oldData, err := uc.versionData(ctx, id, from, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	newData, err := uc.versionData(ctx, id, to, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	patch, err := merger.Diff(oldData, newData)
+	if err != nil {
+		l.UsecasesError(err, userID, map[string]any{"id": id, "from": from, "to": to})
+		return nil, err
+	}
+
+	return &models.EncounterVersionsDiff{From: from, To: to, Patch: patch}, nil
+}
+
+// RestoreEncounterVersion делает данные выбранной версии текущими. Восстановление записывается
+// как новая версия, поэтому история не теряется
+func (uc *encounterUsecases) RestoreEncounterVersion(ctx context.Context, id string, version, userID int) error {
+	l := logger.FromContext(ctx)
+
+	hasPermission := uc.repo.CheckPermission(ctx, id, userID, models.EditorEncounterRole)
+	if !hasPermission {
+		l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"id": id})
+		return apperrors.PermissionDeniedError
+	}
+
+	encounterVersion, err := uc.repo.GetEncounterVersion(ctx, id, version)
+	if err != nil {
+		return err
+	}
+
+	change := &models.EncounterChange{
+		AuthorID:     userID,
+		Source:       models.RestoreVersionSource,
+		RestoredFrom: version,
+	}
+
+	return uc.updateData(ctx, encounterVersion.Data, id, change)
+}
+
+func (uc *encounterUsecases) versionData(ctx context.Context, id string, version, userID int) ([]byte, error) {
+	l := logger.FromContext(ctx)
+
+	encounterVersion, err := uc.repo.GetEncounterVersion(ctx, id, version)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := migrateData(encounterVersion.Data)
+	if err != nil {
+		l.UsecasesWarn(err, userID, map[string]any{"id": id, "version": version})
+		return nil, apperrors.InvalidEncounterDataErr
+	}
+
+	return data, nil
+}
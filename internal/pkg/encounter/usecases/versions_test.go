@@ -0,0 +1,162 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetEncounterVersions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		size    int
+		start   int
+		setup   func(repo *mocks.MockEncounterRepository)
+		wantErr error
+	}{
+		{
+			name:  "page size is clamped",
+			size:  1000,
+			start: 5,
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.ViewerEncounterRole).Return(true)
+				repo.EXPECT().GetEncounterVersions(gomock.Any(), "enc-1", maxVersionsPage, 5).
+					Return(&models.EncounterVersionsList{}, nil)
+			},
+		},
+		{
+			name:    "negative start is rejected",
+			size:    10,
+			start:   -1,
+			setup:   func(_ *mocks.MockEncounterRepository) {},
+			wantErr: apperrors.StartPosSizeError,
+		},
+		{
+			name: "no permission",
+			size: 10,
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.ViewerEncounterRole).Return(false)
+			},
+			wantErr: apperrors.PermissionDeniedError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			repo := mocks.NewMockEncounterRepository(ctrl)
+			tt.setup(repo)
+
+			uc := NewEncounterUsecases(repo, nil, nil)
+			_, err := uc.GetEncounterVersions(context.Background(), "enc-1", tt.size, tt.start, 1)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestDiffEncounterVersions(t *testing.T) {
+	t.Parallel()
+
+	legacy := &models.EncounterVersion{Version: 1, Data: []byte(`{"monsters":[]}`)}
+	current := &models.EncounterVersion{Version: 2, Data: []byte(`{"monsters":[],"schemaVersion":1,"participants":[` +
+		`{"id":"m1","kind":"creature","sourceID":"goblin","maxHP":7,"currentHP":3}]}`)}
+
+	ctrl := gomock.NewController(t)
+	repo := mocks.NewMockEncounterRepository(ctrl)
+	repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.ViewerEncounterRole).Return(true)
+	repo.EXPECT().GetEncounterVersion(gomock.Any(), "enc-1", 1).Return(legacy, nil)
+	repo.EXPECT().GetEncounterVersion(gomock.Any(), "enc-1", 2).Return(current, nil)
+
+	uc := NewEncounterUsecases(repo, nil, nil)
+	diff, err := uc.DiffEncounterVersions(context.Background(), "enc-1", 1, 2, 1)
+
+	// The legacy version is migrated first, so only the added participant ends up in the patch
+	assert.NoError(t, err)
+	assert.Equal(t, 1, diff.From)
+	assert.Equal(t, 2, diff.To)
+	assert.JSONEq(t, `[{"op":"add","path":"/participants/0","value":{"id":"m1","kind":"creature",`+
+		`"sourceID":"goblin","maxHP":7,"currentHP":3}}]`, string(diff.Patch))
+}
+
+func TestRestoreEncounterVersion(t *testing.T) {
+	t.Parallel()
+
+	dbErr := errors.New("db failure")
+
+	tests := []struct {
+		name    string
+		setup   func(repo *mocks.MockEncounterRepository)
+		wantErr error
+	}{
+		{
+			name: "restore writes a new version",
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(true)
+				repo.EXPECT().GetEncounterVersion(gomock.Any(), "enc-1", 3).
+					Return(&models.EncounterVersion{Version: 3, Data: []byte(`{"monsters":[]}`)}, nil)
+				repo.EXPECT().UpdateEncounter(gomock.Any(), []byte(`{"monsters":[],"participants":[],"schemaVersion":1}`),
+					"enc-1", &models.EncounterChange{AuthorID: 1, Source: models.RestoreVersionSource, RestoredFrom: 3}).
+					Return(nil)
+			},
+		},
+		{
+			name: "unknown version",
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(true)
+				repo.EXPECT().GetEncounterVersion(gomock.Any(), "enc-1", 3).
+					Return(nil, apperrors.EncounterVersionNotFoundErr)
+			},
+			wantErr: apperrors.EncounterVersionNotFoundErr,
+		},
+		{
+			name: "invalid version data",
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(true)
+				repo.EXPECT().GetEncounterVersion(gomock.Any(), "enc-1", 3).
+					Return(&models.EncounterVersion{Version: 3, Data: []byte(`[]`)}, nil)
+			},
+			wantErr: apperrors.InvalidEncounterDataErr,
+		},
+		{
+			name: "no permission",
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(false)
+			},
+			wantErr: apperrors.PermissionDeniedError,
+		},
+		{
+			name: "repo error is propagated",
+			setup: func(repo *mocks.MockEncounterRepository) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(true)
+				repo.EXPECT().GetEncounterVersion(gomock.Any(), "enc-1", 3).Return(nil, dbErr)
+			},
+			wantErr: dbErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			repo := mocks.NewMockEncounterRepository(ctrl)
+			tt.setup(repo)
+
+			uc := NewEncounterUsecases(repo, nil, nil)
+			err := uc.RestoreEncounterVersion(context.Background(), "enc-1", 3, 1)
+
+			assert.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
@@ -15,6 +15,7 @@ type MapsRepository interface {
 	DeleteMap(ctx context.Context, userID int, id string) error
 	ListMaps(ctx context.Context, userID int, start, size int) (*models.MapsList, error)
 	CheckPermission(ctx context.Context, id string, userID int) bool
+	CheckReadPermission(ctx context.Context, id string, userID int) bool
 }
 
 type MapsUsecases interface {
@@ -9,6 +9,21 @@ const (
 		);
 	`
 
+	CheckMapReadPermissionQuery = `
+		SELECT EXISTS(
+			SELECT 1
+			FROM public.maps m
+			WHERE m.id = $1 AND (
+				m.user_id = $2 OR EXISTS(
+					SELECT 1
+					FROM public.campaign_maps cm
+					JOIN public.campaign_members mb ON mb.campaign_id = cm.campaign_id
+					WHERE cm.map_id = m.id AND mb.user_id = $2
+				)
+			)
+		);
+	`
+
 	CreateMapQuery = `
 		INSERT INTO public.maps (user_id, name, data)
 		VALUES ($1, $2, $3)
@@ -16,9 +31,16 @@ const (
 	`
 
 	GetMapByIDQuery = `
-		SELECT id, user_id, name, data, created_at, updated_at
-		FROM public.maps
-		WHERE id = $1 AND user_id = $2;
+		SELECT m.id, m.user_id, m.name, m.data, m.created_at, m.updated_at
+		FROM public.maps m
+		WHERE m.id = $1 AND (
+			m.user_id = $2 OR EXISTS(
+				SELECT 1
+				FROM public.campaign_maps cm
+				JOIN public.campaign_members mb ON mb.campaign_id = cm.campaign_id
+				WHERE cm.map_id = m.id AND mb.user_id = $2
+			)
+		);
 	`
 
 	UpdateMapQuery = `
@@ -45,6 +45,24 @@ func (s *mapsStorage) CheckPermission(ctx context.Context, id string, userID int
 	return hasPermission
 }
 
+// CheckReadPermission проверяет доступ на чтение: кроме владельца карту видят игроки кампаний, к которым она привязана
+func (s *mapsStorage) CheckReadPermission(ctx context.Context, id string, userID int) bool {
+	fnName := utils.GetFunctionName()
+
+	var hasPermission bool
+
+	hasPermission, _ = dbcall.DBCall[bool](fnName, s.metrics, func() (bool, error) {
+		line := s.pool.QueryRow(ctx, CheckMapReadPermissionQuery, id, userID)
+		if err := line.Scan(&hasPermission); err != nil {
+			return false, nil
+		}
+
+		return hasPermission, nil
+	})
+
+	return hasPermission
+}
+
 func (s *mapsStorage) CreateMap(ctx context.Context, userID int, name string, data []byte) (*models.MapFull, error) {
 	l := logger.FromContext(ctx)
 	fnName := utils.GetFunctionName()
@@ -74,6 +92,7 @@ func (s *mapsStorage) CreateMap(ctx context.Context, userID int, name string, da
 	return &mapFull, nil
 }
 
+// GetMapByID возвращает карту её владельцу и игрокам кампаний, к которым она привязана
 func (s *mapsStorage) GetMapByID(ctx context.Context, userID int, id string) (*models.MapFull, error) {
 	l := logger.FromContext(ctx)
 	fnName := utils.GetFunctionName()
@@ -45,8 +45,8 @@ func (uc *mapsUsecases) CreateMap(ctx context.Context, userID int, req *models.C
 func (uc *mapsUsecases) GetMapByID(ctx context.Context, userID int, id string) (*models.MapFull, error) {
 	l := logger.FromContext(ctx)
 
-	// Check permission first
-	hasPermission := uc.repo.CheckPermission(ctx, id, userID)
+	// Check read permission first: campaign players can view attached maps
+	hasPermission := uc.repo.CheckReadPermission(ctx, id, userID)
 	if !hasPermission {
 		l.UsecasesWarn(apperrors.MapPermissionDenied, userID, map[string]any{"id": id})
 		return nil, apperrors.MapPermissionDenied
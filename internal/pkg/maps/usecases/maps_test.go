@@ -199,9 +199,9 @@ func TestGetMapByID(t *testing.T) {
 		wantNil bool
 	}{
 		{
-			name: "no permission returns MapPermissionDenied",
+			name: "no read permission returns MapPermissionDenied",
 			setup: func(repo *mocks.MockMapsRepository) {
-				repo.EXPECT().CheckPermission(gomock.Any(), "map-id", 1).Return(false)
+				repo.EXPECT().CheckReadPermission(gomock.Any(), "map-id", 1).Return(false)
 			},
 			wantErr: apperrors.MapPermissionDenied,
 			wantNil: true,
@@ -209,14 +209,14 @@ func TestGetMapByID(t *testing.T) {
 		{
 			name: "happy path returns map",
 			setup: func(repo *mocks.MockMapsRepository) {
-				repo.EXPECT().CheckPermission(gomock.Any(), "map-id", 1).Return(true)
+				repo.EXPECT().CheckReadPermission(gomock.Any(), "map-id", 1).Return(true)
 				repo.EXPECT().GetMapByID(gomock.Any(), 1, "map-id").Return(expectedMap, nil)
 			},
 		},
 		{
 			name: "repo error is propagated",
 			setup: func(repo *mocks.MockMapsRepository) {
-				repo.EXPECT().CheckPermission(gomock.Any(), "map-id", 1).Return(true)
+				repo.EXPECT().CheckReadPermission(gomock.Any(), "map-id", 1).Return(true)
 				repo.EXPECT().GetMapByID(gomock.Any(), 1, "map-id").Return(nil, repoErr)
 			},
 			wantErr: repoErr,
@@ -225,7 +225,7 @@ func TestGetMapByID(t *testing.T) {
 		{
 			name: "map not found is propagated",
 			setup: func(repo *mocks.MockMapsRepository) {
-				repo.EXPECT().CheckPermission(gomock.Any(), "map-id", 1).Return(true)
+				repo.EXPECT().CheckReadPermission(gomock.Any(), "map-id", 1).Return(true)
 				repo.EXPECT().GetMapByID(gomock.Any(), 1, "map-id").Return(nil, apperrors.MapNotFoundError)
 			},
 			wantErr: apperrors.MapNotFoundError,
@@ -22,4 +22,8 @@ type WSMetrics interface {
 type WSSessionMetrics interface {
 	IncReceivedMsgs()
 	IncSentMsgs()
+	IncSlowConsumers()
+	IncHeartbeatTimeouts()
+	IncWriteErrors()
+	ObserveQueueLength(length int)
 }
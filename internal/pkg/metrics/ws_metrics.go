@@ -12,8 +12,12 @@ type wsMetrics struct {
 }
 
 type wsSessionMetrics struct {
-	receivedMsgs *prometheus.CounterVec
-	sentMsgs     *prometheus.CounterVec
+	receivedMsgs      *prometheus.CounterVec
+	sentMsgs          *prometheus.CounterVec
+	slowConsumers     *prometheus.CounterVec
+	heartbeatTimeouts *prometheus.CounterVec
+	writeErrors       *prometheus.CounterVec
+	queueLength       *prometheus.HistogramVec
 }
 
 func NewWSMetrics() (WSMetrics, error) {
@@ -76,6 +80,47 @@ func NewWSSessionMetrics() (WSSessionMetrics, error) {
 		return nil, err
 	}
 
+	metrics.slowConsumers = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ws_slow_consumers_count",
+			Help: "Number of WS connections closed because of outbound queue overflow",
+		},
+		[]string{})
+	if err := prometheus.Register(metrics.slowConsumers); err != nil {
+		return nil, err
+	}
+
+	metrics.heartbeatTimeouts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ws_heartbeat_timeouts_count",
+			Help: "Number of WS connections closed because the client stopped answering pings",
+		},
+		[]string{})
+	if err := prometheus.Register(metrics.heartbeatTimeouts); err != nil {
+		return nil, err
+	}
+
+	metrics.writeErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ws_write_errors_count",
+			Help: "Number of failed writes to WS connections",
+		},
+		[]string{})
+	if err := prometheus.Register(metrics.writeErrors); err != nil {
+		return nil, err
+	}
+
+	metrics.queueLength = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "ws_outbound_queue_length",
+			Help:    "Length of WS connection outbound queue after enqueueing a message",
+			Buckets: []float64{1, 2, 4, 8, 16, 32, 64, 128},
+		},
+		[]string{})
+	if err := prometheus.Register(metrics.queueLength); err != nil {
+		return nil, err
+	}
+
 	return &metrics, nil
 }
 
@@ -95,6 +140,22 @@ func (m *wsSessionMetrics) IncSentMsgs() {
 	m.sentMsgs.WithLabelValues().Inc()
 }
 
+func (m *wsSessionMetrics) IncSlowConsumers() {
+	m.slowConsumers.WithLabelValues().Inc()
+}
+
+func (m *wsSessionMetrics) IncHeartbeatTimeouts() {
+	m.heartbeatTimeouts.WithLabelValues().Inc()
+}
+
+func (m *wsSessionMetrics) IncWriteErrors() {
+	m.writeErrors.WithLabelValues().Inc()
+}
+
+func (m *wsSessionMetrics) ObserveQueueLength(length int) {
+	m.queueLength.WithLabelValues().Observe(float64(length))
+}
+
 func (m *wsMetrics) IncreaseDuration(duration time.Duration) {
 	m.duration.WithLabelValues().Observe(duration.Minutes())
 }
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"crypto/rand"
 	"fmt"
 	"log"
 	"net/http"
@@ -14,6 +15,7 @@ import (
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/metrics"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/repository/dbinit"
 
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table/invite"
 	tablerepo "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table/repository"
 	tableuc "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table/usecases"
 
@@ -33,6 +35,8 @@ import (
 	bestiaryext "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/bestiary/external"
 	bestiaryrepo "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/bestiary/repository"
 	bestiaryuc "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/bestiary/usecases"
+	campaignrepo "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/campaign/repository"
+	campaignuc "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/campaign/usecases"
 	characterrepo "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/character/repository"
 	characteruc "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/character/usecases"
 	descriptiondlv "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/description/delivery"
@@ -210,13 +214,28 @@ func (srv *Server) Run() error {
 	bestiaryS3Manager := bestiaryrepo.NewMinioManager(minioClient, "creature-images")
 	llmInmemoryStorage := bestiaryrepo.NewInMemoryLLMRepo()
 	characterRepository := characterrepo.NewCharacterStorage(mongoDatabase, mongoMetrics)
+	campaignRepository := campaignrepo.NewCampaignStorage(postgresPool, postgresMetrics)
 	encounterRepository := encounterrepo.NewEncounterStorage(postgresPool, postgresMetrics)
 	maptileRepository := maptilerepo.NewMapTilesStorage(mongoDatabase, mongoMetrics)
 	mapsRepository := mapsrepo.NewMapsStorage(postgresPool, postgresMetrics)
 	authRepository := authrepo.NewAuthStorage(postgresPool, postgresMetrics)
 	identityRepository := authrepo.NewIdentityStorage(postgresPool, postgresMetrics)
 	sessionManager := authrepo.NewSessionManager(redisClient, redisMetrics)
-	tableManager := tablerepo.NewTableManager(wsMetrics, wsSessionMetrics)
+	tableLogRepository := tablerepo.NewTableLogStorage(postgresPool, postgresMetrics)
+	tableSessionStore := tablerepo.NewInMemoryTableSessionStore()
+	if cfg.Table.Store == "redis" {
+		tableSessionStore = tablerepo.NewTableSessionStore(redisClient, redisMetrics, cfg.Table.SnapshotTTL)
+	}
+	tablePubSub := tablerepo.NewInMemoryTablePubSub()
+	sessionLocker := tablerepo.NewInMemorySessionLocker()
+	if cfg.Table.Broker == "redis" {
+		tablePubSub = tablerepo.NewTablePubSub(redisClient, redisMetrics)
+		sessionLocker = tablerepo.NewSessionLocker(redisClient, redisMetrics)
+	}
+	combatantStats := tableuc.NewCombatantStatsProvider(bestiaryRepository, characterRepository)
+	tableManager := tablerepo.NewTableManager(wsMetrics, wsSessionMetrics, tableSessionStore, tablePubSub,
+		sessionLocker, combatantStats, tableLogRepository, cfg.Table.LockTTL, cfg.Table.ReconnectGrace,
+		cfg.Table.DeltaBroadcasts)
 
 	bestiaryUsecases := bestiaryuc.NewBestiaryUsecases(bestiaryRepository, bestiaryS3Manager, geminiClient)
 	actionProcessorGateway := bestiarydlv.NewActionProcessorAdapter(actionProcessorClient)
@@ -226,8 +245,8 @@ func (srv *Server) Run() error {
 		bestiaryuc.NewGoRunner(), bestiaryuc.NewUUIDGenerator())
 	descriptionGateway := descriptiondlv.NewDescriptionGatewayAdapter(descriptionClient)
 	descriptionUsecases := descriptionuc.NewDescriptionUsecase(descriptionGateway)
-	characterUsecases := characteruc.NewCharacterUsecases(characterRepository)
-	encounterUsecases := encounteruc.NewEncounterUsecases(encounterRepository)
+	characterUsecases := characteruc.NewCharacterUsecases(characterRepository, campaignRepository)
+	encounterUsecases := encounteruc.NewEncounterUsecases(encounterRepository, bestiaryRepository, characterRepository)
 	googleClient := authext.NewGoogleOAuth(cfg.GoogleOAuth.ClientID, cfg.GoogleOAuth.ClientSecret,
 		cfg.GoogleOAuth.RedirectURI)
 	yandexClient := authext.NewYandexOAuth(cfg.YandexOAuth.ClientID, cfg.YandexOAuth.ClientSecret)
@@ -238,10 +257,36 @@ func (srv *Server) Run() error {
 		yandexClient.Name(): yandexClient,
 	}
 	authUsecases := authuc.NewAuthUsecases(authRepository, identityRepository, oauthProviders, sessionManager)
-	tableUsecases := tableuc.NewTableUsecases(encounterRepository, tableManager,
-		tableuc.NewRandSessionIDGen(), tableuc.NewRealTimerFactory())
+	// Приглашения проверяются на любой реплике, поэтому с общим брокером ключ должен быть общим
+	inviteSecret := []byte(cfg.Table.InviteSecret)
+	if len(inviteSecret) == 0 {
+		if cfg.Table.Broker == "redis" {
+			log.Fatal("TABLE_INVITE_SECRET must be set when TABLE_BROKER is redis")
+		}
+
+		log.Println("TABLE_INVITE_SECRET is not set, table invites will not survive restart")
+
+		inviteSecret = make([]byte, 32)
+		if _, err := rand.Read(inviteSecret); err != nil {
+			log.Fatal("Something went wrong generating table invite secret, ", err)
+		}
+	}
+
+	tableUsecases := tableuc.NewTableUsecases(encounterRepository, tableLogRepository, tableManager,
+		tableuc.NewRandSessionIDGen(), tableuc.NewRealTimerFactory(), invite.NewSigner(inviteSecret))
+
+	tableCtx := logger.WithContext(context.Background())
+	tableUsecases.RestoreSessions(tableCtx)
+	go tableManager.RunSnapshots(tableCtx, cfg.Table.SnapshotInterval)
+	go tableUsecases.RunRecovery(tableCtx, cfg.Table.LockTTL)
+
+	encounterCtx := logger.WithContext(context.Background())
+	go encounterUsecases.RunTrashPurge(encounterCtx, cfg.Encounter.TrashRetention, cfg.Encounter.TrashPurgeInterval)
+
 	maptilesUsecases := maptileuc.NewMapTilesUsecases(maptileRepository)
 	mapsUsecases := mapsuc.NewMapsUsecases(mapsRepository)
+	campaignUsecases := campaignuc.NewCampaignUsecases(campaignRepository, encounterRepository, mapsRepository,
+		characterRepository, tableManager)
 
 	credentials := handlers.AllowCredentials()
 	headersOk := handlers.AllowedHeaders(cfg.Server.Headers)
@@ -261,6 +306,7 @@ func (srv *Server) Run() error {
 		llmUsecases,
 		maptilesUsecases,
 		mapsUsecases,
+		campaignUsecases,
 	)
 	muxWithCORS := handlers.CORS(credentials, originsOk, headersOk, methodsOk)(router)
 
@@ -42,10 +42,36 @@ const (
 	ErrWrongFileType = "Invalid file type. Only JSON files are allowed"
 
 	ErrWrongEncounterName = "Encounter name must not be empty and more than 60 characters"
+	ErrWrongPatch         = "Patch cannot be applied to the encounter"
+	ErrWrongEncounterData = "Encounter data does not match the encounter schema"
+	ErrWrongDifficulty    = "Difficulty requires a party of level 1-20 characters and known creatures"
+	ErrWrongGenerate      = "Generation requires a target difficulty, a party of level 1-20 characters, up to 5 candidates and 20 monsters"
+	ErrWrongVersion       = "Encounter has no version with this number"
+	ErrNotInTrash         = "Encounter not found in trash"
+	ErrEncounterNotFound  = "Encounter not found"
+	ErrWrongShare         = "Share requires another existing user, a viewer, editor or co-owner role and a link lifetime of up to 365 days"
+	ErrWrongShareLink     = "Share link not found or expired"
+	ErrWrongOrder         = "Encounters can be ordered by name, createdAt or updatedAt in asc or desc direction"
+	ErrWrongTags          = "Encounter can have up to 20 non-empty tags of up to 30 characters"
+	ErrWrongFolder        = "Folder not found or its name is empty, taken or more than 60 characters"
+	ErrNotTemplate        = "Encounter is not a template"
 	ErrInvalidID          = "Invalid ID"
 
-	ErrWrongTableID = "Wrong table ID"
-	ErrWSUpgrade    = "Websocket upgrade error"
+	ErrWrongCampaign       = "Campaign name must not be empty and more than 60 characters, description more than 2000 characters"
+	ErrCampaignNotFound    = "Campaign not found"
+	ErrWrongCampaignMember = "Campaign member must be another existing user, master cannot leave the campaign"
+
+	ErrWrongTableID       = "Wrong table ID"
+	ErrWSUpgrade          = "Websocket upgrade error"
+	ErrWrongMaxPlayers    = "Max players number must be between 1 and 20"
+	ErrWrongRole          = "Role must be player or spectator"
+	ErrWrongLastSeq       = "Last sequence number must be a non-negative integer"
+	ErrWrongControl       = "Kick, ban and transfer require the ID of another participant"
+	ErrSessionExists      = "This encounter already has a running session"
+	ErrNoEncounterSession = "This encounter has no running session"
+	ErrWrongInvite        = "Invalid invite token"
+	ErrInviteExpired      = "Invite token has expired"
+	ErrWrongInviteParams  = "Invite must expire within 7 days, allow 0 to 100 uses and grant player or spectator role"
 
 	ErrWrongImage  = "Bad image"
 	ErrEmptyImage  = "Image not provided"
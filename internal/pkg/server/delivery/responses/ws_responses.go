@@ -13,10 +13,31 @@ const (
 )
 
 const (
-	ErrInvalidWSSessionID  = "Invalid session ID"
-	ErrMaxPlayersWS        = "Max players number had reached"
-	ErrInternalWS          = "Internal server error"
-	ErrUserAlreadyExistsWS = "User with this name already exists in session"
+	ErrInvalidWSSessionID     = "Invalid session ID"
+	ErrMaxPlayersWS           = "Max players number had reached"
+	ErrInternalWS             = "Internal server error"
+	ErrUserAlreadyExistsWS    = "User with this name already exists in session"
+	ErrInvalidUndoWS          = "Undo count must be a positive number"
+	ErrUndoForbiddenWS        = "Only the game master can undo operations"
+	ErrInvalidPatchWS         = "Invalid patch or base revision"
+	ErrPatchForbiddenWS       = "Patch touches fields you are not allowed to edit"
+	ErrPermissionsForbiddenWS = "Only the game master can change permissions"
+	ErrInvalidPermissionsWS   = "Invalid permissions request"
+	ErrInvalidRollWS          = "Invalid dice formula"
+	ErrInvalidInitiativeWS    = "Invalid initiative request"
+	ErrInitiativeForbiddenWS  = "Only the game master or the combatant's owner can do this"
+	ErrSpectatorReadOnlyWS    = "Spectators cannot change the table"
+	ErrConnReplacedWS         = "Connection was replaced by a new one"
+	ErrInvalidChatWS          = "Invalid chat message"
+	ErrWhisperForbiddenWS     = "Players can only whisper to the game master"
+	ErrInvalidControlWS       = "Invalid session control request"
+	ErrControlForbiddenWS     = "Only the game master can control the session"
+	ErrKickedWS               = "You were removed from the session by the game master"
+	ErrBannedWS               = "You are banned from this session"
+	ErrInviteExhaustedWS      = "Invite has no uses left"
+	ErrJoinDeniedWS           = "The game master denied your request to join"
+	ErrInvalidJoinDecisionWS  = "Invalid join decision"
+	ErrJoinPendingWS          = "Wait until the game master lets you in"
 )
 
 func newWsErrResponse(err string) *models.WSErrResponse {
@@ -33,10 +54,31 @@ func newWSOkResponse(msgType models.WSMsgType, msgContent any) *models.WSRespons
 	}
 }
 
-func SendWSErrResponse(conn *websocket.Conn, code int, message string) {
-	msg := newWsErrResponse(message)
+func MarshalWSErrResponse(message string) ([]byte, error) {
+	return json.Marshal(newWsErrResponse(message))
+}
+
+func MarshalWSOkResponse(msgType models.WSMsgType, msgContent any) ([]byte, error) {
+	return json.Marshal(newWSOkResponse(msgType, msgContent))
+}
+
+// MarshalWSSeqOkResponse добавляет к сообщению номер, по которому клиент возобновляет сессию
+func MarshalWSSeqOkResponse(seq int64, msgType models.WSMsgType, msgContent any) ([]byte, error) {
+	response := newWSOkResponse(msgType, msgContent)
+	response.Seq = seq
+
+	return json.Marshal(response)
+}
+
+func MarshalWSSeqErrResponse(seq int64, message string) ([]byte, error) {
+	response := newWsErrResponse(message)
+	response.Seq = seq
+
+	return json.Marshal(response)
+}
 
-	serverResponse, err := json.Marshal(msg)
+func SendWSErrResponse(conn *websocket.Conn, code int, message string) {
+	serverResponse, err := MarshalWSErrResponse(message)
 	if err != nil {
 		log.Println("Something went wrong while marshalling JSON", err)
 
@@ -48,9 +90,7 @@ func SendWSErrResponse(conn *websocket.Conn, code int, message string) {
 }
 
 func SendWSOkResponse(conn *websocket.Conn, msgType models.WSMsgType, msgContent any) error {
-	msg := newWSOkResponse(msgType, msgContent)
-
-	serverResponse, err := json.Marshal(msg)
+	serverResponse, err := MarshalWSOkResponse(msgType, msgContent)
 	if err != nil {
 		log.Println("Something went wrong while marshalling JSON", err)
 
@@ -0,0 +1,29 @@
+package router
+
+import (
+	campaigndel "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/campaign/delivery"
+	"github.com/gorilla/mux"
+)
+
+func ServeCampaignRouter(router *mux.Router, campaignHandler *campaigndel.CampaignHandler,
+	loginRequiredMiddleware mux.MiddlewareFunc) {
+	subrouter := router.PathPrefix("/campaign").Subrouter()
+	subrouter.Use(loginRequiredMiddleware)
+
+	subrouter.HandleFunc("", campaignHandler.GetCampaigns).Methods("GET")
+	subrouter.HandleFunc("", campaignHandler.CreateCampaign).Methods("POST")
+	subrouter.HandleFunc("/{id}", campaignHandler.GetCampaign).Methods("GET")
+	subrouter.HandleFunc("/{id}", campaignHandler.UpdateCampaign).Methods("PUT")
+	subrouter.HandleFunc("/{id}", campaignHandler.RemoveCampaign).Methods("DELETE")
+	subrouter.HandleFunc("/{id}/dashboard", campaignHandler.GetCampaignDashboard).Methods("GET")
+	subrouter.HandleFunc("/{id}/members", campaignHandler.GetCampaignMembers).Methods("GET")
+	subrouter.HandleFunc("/{id}/members/{userID:[0-9]+}", campaignHandler.AddCampaignMember).Methods("PUT")
+	subrouter.HandleFunc("/{id}/members/{userID:[0-9]+}", campaignHandler.RemoveCampaignMember).Methods("DELETE")
+	subrouter.HandleFunc("/{id}/encounters/{itemID}", campaignHandler.GetCampaignEncounter).Methods("GET")
+	subrouter.HandleFunc("/{id}/encounters/{itemID}", campaignHandler.AttachEncounter).Methods("PUT")
+	subrouter.HandleFunc("/{id}/encounters/{itemID}", campaignHandler.DetachEncounter).Methods("DELETE")
+	subrouter.HandleFunc("/{id}/maps/{itemID}", campaignHandler.AttachMap).Methods("PUT")
+	subrouter.HandleFunc("/{id}/maps/{itemID}", campaignHandler.DetachMap).Methods("DELETE")
+	subrouter.HandleFunc("/{id}/characters/{itemID}", campaignHandler.AttachCharacter).Methods("PUT")
+	subrouter.HandleFunc("/{id}/characters/{itemID}", campaignHandler.DetachCharacter).Methods("DELETE")
+}
@@ -12,7 +12,32 @@ func ServeEncounteRouter(router *mux.Router, encounterHandler *encounterdel.Enco
 
 	subrouter.HandleFunc("", encounterHandler.SaveEncounter).Methods("POST")
 	subrouter.HandleFunc("/list", encounterHandler.GetEncountersList).Methods("POST")
+	subrouter.HandleFunc("/difficulty", encounterHandler.GetDifficulty).Methods("POST")
+	subrouter.HandleFunc("/generate", encounterHandler.GenerateEncounters).Methods("POST")
+	subrouter.HandleFunc("/trash", encounterHandler.GetDeletedEncounters).Methods("GET")
+	subrouter.HandleFunc("/trash/{id}/restore", encounterHandler.RestoreEncounter).Methods("POST")
+	subrouter.HandleFunc("/trash/{id}", encounterHandler.PurgeEncounter).Methods("DELETE")
+	subrouter.HandleFunc("/shared", encounterHandler.GetSharedEncounters).Methods("GET")
+	subrouter.HandleFunc("/link/{token}", encounterHandler.GetEncounterByShareLink).Methods("GET")
+	subrouter.HandleFunc("/folders", encounterHandler.GetEncounterFolders).Methods("GET")
+	subrouter.HandleFunc("/folders", encounterHandler.CreateEncounterFolder).Methods("POST")
+	subrouter.HandleFunc("/folders/{folderID:[0-9]+}", encounterHandler.RenameEncounterFolder).Methods("PUT")
+	subrouter.HandleFunc("/folders/{folderID:[0-9]+}", encounterHandler.RemoveEncounterFolder).Methods("DELETE")
 	subrouter.HandleFunc("/{id}", encounterHandler.GetEncounterByID).Methods("GET")
 	subrouter.HandleFunc("/{id}", encounterHandler.UpdateEncounter).Methods("POST")
 	subrouter.HandleFunc("/{id}", encounterHandler.RemoveEncounter).Methods("DELETE")
+	subrouter.HandleFunc("/{id}/duplicate", encounterHandler.DuplicateEncounter).Methods("POST")
+	subrouter.HandleFunc("/{id}/instantiate", encounterHandler.InstantiateTemplate).Methods("POST")
+	subrouter.HandleFunc("/{id}/organization", encounterHandler.UpdateEncounterOrganization).Methods("PUT")
+	subrouter.HandleFunc("/{id}/versions", encounterHandler.GetEncounterVersions).Methods("GET")
+	subrouter.HandleFunc("/{id}/versions/diff", encounterHandler.DiffEncounterVersions).Methods("GET")
+	subrouter.HandleFunc("/{id}/versions/{version:[0-9]+}", encounterHandler.GetEncounterVersion).Methods("GET")
+	subrouter.HandleFunc("/{id}/versions/{version:[0-9]+}/restore",
+		encounterHandler.RestoreEncounterVersion).Methods("POST")
+	subrouter.HandleFunc("/{id}/shares", encounterHandler.GetEncounterShares).Methods("GET")
+	subrouter.HandleFunc("/{id}/shares/{userID:[0-9]+}", encounterHandler.ShareEncounter).Methods("PUT")
+	subrouter.HandleFunc("/{id}/shares/{userID:[0-9]+}", encounterHandler.RevokeEncounterShare).Methods("DELETE")
+	subrouter.HandleFunc("/{id}/links", encounterHandler.GetShareLinks).Methods("GET")
+	subrouter.HandleFunc("/{id}/links", encounterHandler.CreateShareLink).Methods("POST")
+	subrouter.HandleFunc("/{id}/links/{token}", encounterHandler.RevokeShareLink).Methods("DELETE")
 }
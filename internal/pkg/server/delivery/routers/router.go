@@ -5,6 +5,8 @@ import (
 	authdel "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/auth/delivery"
 	bestiaryinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/bestiary"
 	bestiarydel "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/bestiary/delivery"
+	campaigninterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/campaign"
+	campaigndel "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/campaign/delivery"
 	characterinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/character"
 	characterdel "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/character/delivery"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/config"
@@ -40,7 +42,8 @@ func NewRouter(cfg *config.Config,
 	tableInterface tableinterfaces.TableUsecases,
 	llmInterface bestiaryinterfaces.GenerationUsecases,
 	maptilesInterface maptilesinterfaces.MapTilesUsecases,
-	mapsInterface mapsinterfaces.MapsUsecases) *mux.Router {
+	mapsInterface mapsinterfaces.MapsUsecases,
+	campaignInterface campaigninterfaces.CampaignUsecases) *mux.Router {
 
 	bestiaryHandler := bestiarydel.NewBestiaryHandler(bestiaryInterface, cfg.CtxUserKey)
 	descriptionHandler := descriptiondel.NewDescriptionHandler(descriptionInterface)
@@ -51,6 +54,7 @@ func NewRouter(cfg *config.Config,
 	llmHandler := bestiarydel.NewLLMHandler(llmInterface)
 	mapTilesHandler := maptilesdel.NewMapTilesHandler(maptilesInterface, cfg.CtxUserKey)
 	mapsHandler := mapsdel.NewMapsHandler(mapsInterface, cfg.CtxUserKey)
+	campaignHandler := campaigndel.NewCampaignHandler(campaignInterface, cfg.CtxUserKey)
 
 	loginRequiredMiddleware := myauth.LoginRequiredMiddleware(authInterface, cfg.CtxUserKey)
 
@@ -77,6 +81,7 @@ func NewRouter(cfg *config.Config,
 	ServeLLMRouter(rootRouter, llmHandler, loginRequiredMiddleware)
 	ServeMapTilesRouter(rootRouter, mapTilesHandler, loginRequiredMiddleware)
 	ServeMapsRouter(rootRouter, mapsHandler, loginRequiredMiddleware)
+	ServeCampaignRouter(rootRouter, campaignHandler, loginRequiredMiddleware)
 
 	return router
 }
@@ -11,6 +11,12 @@ func ServeTableRouter(router *mux.Router, tableHandler *tabledel.TableHandler,
 	subrouter.Use(loginRequiredMiddleware)
 
 	subrouter.HandleFunc("/session", tableHandler.CreateSession).Methods("POST")
+	subrouter.HandleFunc("/sessions", tableHandler.ListSessions).Methods("GET")
+	subrouter.HandleFunc("/encounter/{id}/session", tableHandler.GetEncounterSession).Methods("GET")
 	subrouter.HandleFunc("/session/{id}", tableHandler.GetTableData).Methods("GET")
+	subrouter.HandleFunc("/session/{id}/log", tableHandler.GetOperationLog).Methods("GET")
 	subrouter.HandleFunc("/session/{id}/connect", tableHandler.ServeWS).Methods("GET")
+	subrouter.HandleFunc("/session/{id}/invite", tableHandler.CreateInvite).Methods("POST")
+	subrouter.HandleFunc("/session/{id}/{action:end|pause|resume|kick|ban|transfer}",
+		tableHandler.ControlSession).Methods("POST")
 }
@@ -27,23 +27,58 @@ type wsRecordingUsecases struct {
 	connReceived bool
 	sessionIDGot string
 	userGot      *models.User
+	paramsGot    models.ConnectionParams
+	claims       *models.InviteClaims
 	done         chan struct{}
 }
 
-func (f *wsRecordingUsecases) CreateSession(_ context.Context, _ *models.User, _ string) (string, error) {
+func (f *wsRecordingUsecases) CreateSession(_ context.Context, _ *models.User, _ string, _ int) (string, error) {
 	return "", nil
 }
 
-func (f *wsRecordingUsecases) GetTableData(_ context.Context, _ string) (*models.TableData, error) {
+func (f *wsRecordingUsecases) GetTableData(_ context.Context, _ string, _ int) (*models.TableData, error) {
 	return nil, nil
 }
 
+func (f *wsRecordingUsecases) RestoreSessions(_ context.Context) {}
+
+func (f *wsRecordingUsecases) RunRecovery(_ context.Context, _ time.Duration) {}
+
+func (f *wsRecordingUsecases) GetOperationLog(_ context.Context, _ string,
+	_ int) (*models.TableOperationLog, error) {
+	return nil, nil
+}
+
+func (f *wsRecordingUsecases) ControlSession(_ context.Context, _ string, _ int,
+	_ *models.SessionControlRequest) error {
+	return nil
+}
+
+func (f *wsRecordingUsecases) ListSessions(_ context.Context, _ int) ([]*models.TableSessionSummary, error) {
+	return nil, nil
+}
+
+func (f *wsRecordingUsecases) GetEncounterSession(_ context.Context, _ string,
+	_ int) (*models.TableSessionSummary, error) {
+	return nil, nil
+}
+
+func (f *wsRecordingUsecases) CreateInvite(_ context.Context, _ string, _ int,
+	_ *models.CreateInviteRequest) (*models.InviteResponse, error) {
+	return nil, nil
+}
+
+func (f *wsRecordingUsecases) VerifyInvite(_ context.Context, _, _ string) (*models.InviteClaims, error) {
+	return f.claims, nil
+}
+
 func (f *wsRecordingUsecases) AddNewConnection(_ context.Context, user *models.User, sessionID string,
-	conn *websocket.Conn) {
+	params models.ConnectionParams, conn *websocket.Conn) {
 	f.mu.Lock()
 	f.connReceived = true
 	f.sessionIDGot = sessionID
 	f.userGot = user
+	f.paramsGot = params
 	f.mu.Unlock()
 
 	close(f.done)
@@ -70,7 +105,7 @@ func TestServeWS_UpgradeAndConnect(t *testing.T) {
 	server := httptest.NewServer(router)
 	defer server.Close()
 
-	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/table/session/test-session-42/connect"
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") + "/table/session/test-session-42/connect?lastSeq=7"
 
 	conn, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
 	assert.NoError(t, err, "websocket dial should succeed")
@@ -98,4 +133,51 @@ func TestServeWS_UpgradeAndConnect(t *testing.T) {
 	assert.Equal(t, "test-session-42", fake.sessionIDGot)
 	assert.Equal(t, testUser.ID, fake.userGot.ID)
 	assert.Equal(t, testUser.DisplayName, fake.userGot.DisplayName)
+	assert.Equal(t, models.ConnectionParams{Role: models.Player, LastSeq: 7}, fake.paramsGot)
+}
+
+// TestServeWS_InviteOverridesRole verifies that the claims of a valid invite are
+// passed to the usecase and that the invite role wins over the query role.
+func TestServeWS_InviteOverridesRole(t *testing.T) {
+	testUser := &models.User{ID: 7, DisplayName: "Invited"}
+	claims := &models.InviteClaims{ID: "invite-1", SessionID: "test-session", Role: models.Spectator}
+	fake := &wsRecordingUsecases{done: make(chan struct{}), claims: claims}
+	handler := delivery.NewTableHandler(fake, integrationCtxUserKey)
+
+	router := mux.NewRouter()
+	router.Use(func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), integrationCtxUserKey, testUser)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	})
+	router.HandleFunc("/table/session/{id}/connect", handler.ServeWS).Methods("GET")
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	wsURL := "ws" + strings.TrimPrefix(server.URL, "http") +
+		"/table/session/test-session/connect?role=player&invite=token"
+
+	conn, resp, err := websocket.DefaultDialer.Dial(wsURL, nil)
+	assert.NoError(t, err, "websocket dial should succeed")
+
+	if conn != nil {
+		defer conn.Close()
+	}
+
+	if resp != nil {
+		resp.Body.Close()
+	}
+
+	select {
+	case <-fake.done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for AddNewConnection to be called")
+	}
+
+	fake.mu.Lock()
+	defer fake.mu.Unlock()
+
+	assert.Equal(t, models.ConnectionParams{Role: models.Spectator, Invite: claims}, fake.paramsGot)
 }
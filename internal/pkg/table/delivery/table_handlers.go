@@ -10,7 +10,9 @@ import (
 	tableinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table"
 	"github.com/gorilla/mux"
 	"github.com/gorilla/websocket"
+	"io"
 	"net/http"
+	"strconv"
 )
 
 type TableHandler struct {
@@ -49,9 +51,18 @@ func (h *TableHandler) CreateSession(w http.ResponseWriter, r *http.Request) {
 
 	user := ctx.Value(h.ctxUserKey).(*models.User)
 
-	id, err := h.usecases.CreateSession(ctx, user, reqData.EncounterID)
+	id, err := h.usecases.CreateSession(ctx, user, reqData.EncounterID, reqData.MaxPlayers)
 	if err != nil {
 		switch {
+		case errors.Is(err, apperrors.EncounterSessionErr):
+			l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrSessionExists, nil, nil)
+			responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrSessionExists)
+		case errors.Is(err, apperrors.InvalidMaxPlayersErr):
+			l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrWrongMaxPlayers, nil, nil)
+			responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrWrongMaxPlayers)
+		case errors.Is(err, apperrors.InvalidEncounterDataErr):
+			l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrWrongEncounterData, nil, nil)
+			responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrWrongEncounterData)
 		case errors.Is(err, apperrors.PermissionDeniedError) || errors.Is(err, apperrors.ScanError):
 			l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, nil, nil)
 			responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
@@ -80,7 +91,9 @@ func (h *TableHandler) GetTableData(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	data, err := h.usecases.GetTableData(ctx, sessionID)
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+
+	data, err := h.usecases.GetTableData(ctx, sessionID, user.ID)
 	if err != nil {
 		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrWrongTableID, nil, nil)
 		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrWrongTableID)
@@ -91,6 +104,196 @@ func (h *TableHandler) GetTableData(w http.ResponseWriter, r *http.Request) {
 	responses.SendOkResponse(w, data)
 }
 
+func (h *TableHandler) GetOperationLog(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+	vars := mux.Vars(r)
+
+	sessionID, ok := vars["id"]
+	if !ok || sessionID == "" {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+
+	opLog, err := h.usecases.GetOperationLog(ctx, sessionID, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.PermissionDeniedError):
+			l.DeliveryError(ctx, responses.StatusForbidden, responses.ErrForbidden, nil, nil)
+			responses.SendErrResponse(w, responses.StatusForbidden, responses.ErrForbidden)
+		case errors.Is(err, apperrors.TableNotFoundErr):
+			l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrWrongTableID, nil, nil)
+			responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrWrongTableID)
+		default:
+			l.DeliveryError(ctx, responses.StatusInternalServerError, responses.ErrInternalServer, err, nil)
+			responses.SendErrResponse(w, responses.StatusInternalServerError, responses.ErrInternalServer)
+		}
+
+		return
+	}
+
+	responses.SendOkResponse(w, opLog)
+}
+
+func (h *TableHandler) ListSessions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+
+	sessions, err := h.usecases.ListSessions(ctx, user.ID)
+	if err != nil {
+		l.DeliveryError(ctx, responses.StatusInternalServerError, responses.ErrInternalServer, err, nil)
+		responses.SendErrResponse(w, responses.StatusInternalServerError, responses.ErrInternalServer)
+
+		return
+	}
+
+	responses.SendOkResponse(w, sessions)
+}
+
+func (h *TableHandler) GetEncounterSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+	vars := mux.Vars(r)
+
+	encounterID, ok := vars["id"]
+	if !ok || encounterID == "" {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+
+	session, err := h.usecases.GetEncounterSession(ctx, encounterID, user.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.PermissionDeniedError):
+			l.DeliveryError(ctx, responses.StatusForbidden, responses.ErrForbidden, nil, nil)
+			responses.SendErrResponse(w, responses.StatusForbidden, responses.ErrForbidden)
+		case errors.Is(err, apperrors.TableNotFoundErr):
+			l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrNoEncounterSession, nil, nil)
+			responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrNoEncounterSession)
+		default:
+			l.DeliveryError(ctx, responses.StatusInternalServerError, responses.ErrInternalServer, err, nil)
+			responses.SendErrResponse(w, responses.StatusInternalServerError, responses.ErrInternalServer)
+		}
+
+		return
+	}
+
+	responses.SendOkResponse(w, session)
+}
+
+// ControlSession выполняет команду ведущего. Команда выполняется репликой-владельцем сессии,
+// её результат участники получают сообщением sessionState
+func (h *TableHandler) ControlSession(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+	vars := mux.Vars(r)
+
+	sessionID, ok := vars["id"]
+	if !ok || sessionID == "" {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return
+	}
+
+	// Тело запроса нужно только командам, применяемым к участнику
+	var reqData models.SessionControlRequest
+
+	err := json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil && !errors.Is(err, io.EOF) {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrBadJSON, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrBadJSON)
+
+		return
+	}
+
+	reqData.Action = models.SessionAction(vars["action"])
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+
+	err = h.usecases.ControlSession(ctx, sessionID, user.ID, &reqData)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.InvalidControlErr):
+			l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrWrongControl, nil, nil)
+			responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrWrongControl)
+		case errors.Is(err, apperrors.PermissionDeniedError):
+			l.DeliveryError(ctx, responses.StatusForbidden, responses.ErrForbidden, nil, nil)
+			responses.SendErrResponse(w, responses.StatusForbidden, responses.ErrForbidden)
+		case errors.Is(err, apperrors.TableNotFoundErr):
+			l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrWrongTableID, nil, nil)
+			responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrWrongTableID)
+		default:
+			l.DeliveryError(ctx, responses.StatusInternalServerError, responses.ErrInternalServer, err, nil)
+			responses.SendErrResponse(w, responses.StatusInternalServerError, responses.ErrInternalServer)
+		}
+
+		return
+	}
+
+	responses.SendOkResponse(w, nil)
+}
+
+// CreateInvite выпускает подписанное приглашение в сессию. Создавать приглашения может только ведущий
+func (h *TableHandler) CreateInvite(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	l := logger.FromContext(ctx)
+	vars := mux.Vars(r)
+
+	sessionID, ok := vars["id"]
+	if !ok || sessionID == "" {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrInvalidID, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrInvalidID)
+
+		return
+	}
+
+	// Без тела запроса выпускается приглашение игрока на сутки без ограничения числа использований
+	var reqData models.CreateInviteRequest
+
+	err := json.NewDecoder(r.Body).Decode(&reqData)
+	if err != nil && !errors.Is(err, io.EOF) {
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrBadJSON, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrBadJSON)
+
+		return
+	}
+
+	user := ctx.Value(h.ctxUserKey).(*models.User)
+
+	invite, err := h.usecases.CreateInvite(ctx, sessionID, user.ID, &reqData)
+	if err != nil {
+		switch {
+		case errors.Is(err, apperrors.InvalidInviteParamsErr):
+			l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrWrongInviteParams, nil, nil)
+			responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrWrongInviteParams)
+		case errors.Is(err, apperrors.PermissionDeniedError):
+			l.DeliveryError(ctx, responses.StatusForbidden, responses.ErrForbidden, nil, nil)
+			responses.SendErrResponse(w, responses.StatusForbidden, responses.ErrForbidden)
+		case errors.Is(err, apperrors.TableNotFoundErr):
+			l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrWrongTableID, nil, nil)
+			responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrWrongTableID)
+		default:
+			l.DeliveryError(ctx, responses.StatusInternalServerError, responses.ErrInternalServer, err, nil)
+			responses.SendErrResponse(w, responses.StatusInternalServerError, responses.ErrInternalServer)
+		}
+
+		return
+	}
+
+	responses.SendOkResponse(w, invite)
+}
+
 func (h *TableHandler) ServeWS(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	l := logger.FromContext(ctx)
@@ -104,6 +307,56 @@ func (h *TableHandler) ServeWS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Без параметра role пользователь подключается игроком
+	role := models.Role(r.URL.Query().Get("role"))
+	switch role {
+	case "":
+		role = models.Player
+	case models.Player, models.Spectator:
+	default:
+		l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrWrongRole, nil, nil)
+		responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrWrongRole)
+
+		return
+	}
+
+	// lastSeq передаётся при переподключении, чтобы получить пропущенные сообщения
+	var lastSeq int64
+	if rawSeq := r.URL.Query().Get("lastSeq"); rawSeq != "" {
+		seq, err := strconv.ParseInt(rawSeq, 10, 64)
+		if err != nil || seq < 0 {
+			l.DeliveryError(ctx, responses.StatusBadRequest, responses.ErrWrongLastSeq, nil, nil)
+			responses.SendErrResponse(w, responses.StatusBadRequest, responses.ErrWrongLastSeq)
+
+			return
+		}
+
+		lastSeq = seq
+	}
+
+	// С приглашением пользователь входит без одобрения ведущего и с ролью, указанной в приглашении
+	var invite *models.InviteClaims
+	if token := r.URL.Query().Get("invite"); token != "" {
+		claims, err := h.usecases.VerifyInvite(ctx, sessionID, token)
+		if err != nil {
+			message := responses.ErrWrongInvite
+			if errors.Is(err, apperrors.InviteExpiredErr) {
+				message = responses.ErrInviteExpired
+			}
+
+			l.DeliveryError(ctx, responses.StatusBadRequest, message, nil, nil)
+			responses.SendErrResponse(w, responses.StatusBadRequest, message)
+
+			return
+		}
+
+		if claims.Role != "" {
+			role = claims.Role
+		}
+
+		invite = claims
+	}
+
 	user := ctx.Value(h.ctxUserKey).(*models.User)
 
 	conn, err := h.upgrader.Upgrade(w, r, nil)
@@ -114,5 +367,9 @@ func (h *TableHandler) ServeWS(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	h.usecases.AddNewConnection(ctx, user, sessionID, conn)
+	h.usecases.AddNewConnection(ctx, user, sessionID, models.ConnectionParams{
+		Role:    role,
+		LastSeq: lastSeq,
+		Invite:  invite,
+	}, conn)
 }
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
@@ -26,16 +27,54 @@ type fakeTableUsecases struct {
 	createErr error
 	tableData *models.TableData
 	tableErr  error
+	opLog     *models.TableOperationLog
+	opLogErr  error
+	control   *models.SessionControlRequest
+	ctrlErr   error
+	sessions  []*models.TableSessionSummary
+	summary   *models.TableSessionSummary
+	listErr   error
+	inviteReq *models.CreateInviteRequest
+	invite    *models.InviteResponse
+	inviteErr error
+	claims    *models.InviteClaims
+	verifyErr error
 }
 
-func (f *fakeTableUsecases) CreateSession(_ context.Context, _ *models.User, _ string) (string, error) {
+func (f *fakeTableUsecases) CreateSession(_ context.Context, _ *models.User, _ string, _ int) (string, error) {
 	return f.sessionID, f.createErr
 }
-func (f *fakeTableUsecases) GetTableData(_ context.Context, _ string) (*models.TableData, error) {
+func (f *fakeTableUsecases) GetTableData(_ context.Context, _ string, _ int) (*models.TableData, error) {
 	return f.tableData, f.tableErr
 }
 func (f *fakeTableUsecases) AddNewConnection(_ context.Context, _ *models.User, _ string,
-	_ *websocket.Conn) {
+	_ models.ConnectionParams, _ *websocket.Conn) {
+}
+func (f *fakeTableUsecases) RestoreSessions(_ context.Context)              {}
+func (f *fakeTableUsecases) RunRecovery(_ context.Context, _ time.Duration) {}
+func (f *fakeTableUsecases) GetOperationLog(_ context.Context, _ string,
+	_ int) (*models.TableOperationLog, error) {
+	return f.opLog, f.opLogErr
+}
+func (f *fakeTableUsecases) ControlSession(_ context.Context, _ string, _ int,
+	req *models.SessionControlRequest) error {
+	f.control = req
+	return f.ctrlErr
+}
+func (f *fakeTableUsecases) ListSessions(_ context.Context, _ int) ([]*models.TableSessionSummary, error) {
+	return f.sessions, f.listErr
+}
+func (f *fakeTableUsecases) GetEncounterSession(_ context.Context, _ string,
+	_ int) (*models.TableSessionSummary, error) {
+	return f.summary, f.listErr
+}
+func (f *fakeTableUsecases) CreateInvite(_ context.Context, _ string, _ int,
+	req *models.CreateInviteRequest) (*models.InviteResponse, error) {
+	f.inviteReq = req
+	return f.invite, f.inviteErr
+}
+func (f *fakeTableUsecases) VerifyInvite(_ context.Context, _, _ string) (*models.InviteClaims, error) {
+	return f.claims, f.verifyErr
 }
 
 // --- helpers ---
@@ -125,6 +164,62 @@ func TestCreateSession_GenericError_Returns500(t *testing.T) {
 	assert.Equal(t, responses.ErrInternalServer, testhelpers.DecodeErrorResponse(t, rr.Body))
 }
 
+func TestCreateSession_InvalidMaxPlayers_Returns400(t *testing.T) {
+	t.Parallel()
+
+	handler := delivery.NewTableHandler(
+		&fakeTableUsecases{createErr: apperrors.InvalidMaxPlayersErr},
+		ctxUserKey,
+	)
+
+	body := testhelpers.MustJSON(t, models.CreateTableRequest{EncounterID: "enc-1", MaxPlayers: 100})
+	req := httptest.NewRequest(http.MethodPost, "/api/table/create", nil)
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req = withUser(req, ctxUserKey, &models.User{ID: 1, DisplayName: "Tester"})
+
+	rr := httptest.NewRecorder()
+	handler.CreateSession(rr, req)
+
+	assert.Equal(t, responses.StatusBadRequest, rr.Code)
+	assert.Equal(t, responses.ErrWrongMaxPlayers, testhelpers.DecodeErrorResponse(t, rr.Body))
+}
+
+// --- ServeWS tests ---
+
+func TestServeWS_WrongRole_Returns400(t *testing.T) {
+	t.Parallel()
+
+	handler := delivery.NewTableHandler(&fakeTableUsecases{}, ctxUserKey)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/table/session/session-1/connect?role=admin", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "session-1"})
+	req = withUser(req, ctxUserKey, &models.User{ID: 1})
+
+	rr := httptest.NewRecorder()
+	handler.ServeWS(rr, req)
+
+	assert.Equal(t, responses.StatusBadRequest, rr.Code)
+	assert.Equal(t, responses.ErrWrongRole, testhelpers.DecodeErrorResponse(t, rr.Body))
+}
+
+func TestServeWS_WrongLastSeq_Returns400(t *testing.T) {
+	t.Parallel()
+
+	for _, lastSeq := range []string{"abc", "-1"} {
+		handler := delivery.NewTableHandler(&fakeTableUsecases{}, ctxUserKey)
+
+		req := httptest.NewRequest(http.MethodGet, "/api/table/session/session-1/connect?lastSeq="+lastSeq, nil)
+		req = mux.SetURLVars(req, map[string]string{"id": "session-1"})
+		req = withUser(req, ctxUserKey, &models.User{ID: 1})
+
+		rr := httptest.NewRecorder()
+		handler.ServeWS(rr, req)
+
+		assert.Equal(t, responses.StatusBadRequest, rr.Code, lastSeq)
+		assert.Equal(t, responses.ErrWrongLastSeq, testhelpers.DecodeErrorResponse(t, rr.Body), lastSeq)
+	}
+}
+
 // --- GetTableData tests ---
 
 func TestGetTableData_MissingID_Returns400(t *testing.T) {
@@ -151,6 +246,7 @@ func TestGetTableData_UsecaseError_Returns400(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/table/session-1", nil)
 	req = mux.SetURLVars(req, map[string]string{"id": "session-1"})
+	req = withUser(req, ctxUserKey, &models.User{ID: 1})
 
 	rr := httptest.NewRecorder()
 	handler.GetTableData(rr, req)
@@ -174,6 +270,7 @@ func TestGetTableData_HappyPath_Returns200(t *testing.T) {
 
 	req := httptest.NewRequest(http.MethodGet, "/api/table/session-1", nil)
 	req = mux.SetURLVars(req, map[string]string{"id": "session-1"})
+	req = withUser(req, ctxUserKey, &models.User{ID: 1})
 
 	rr := httptest.NewRecorder()
 	handler.GetTableData(rr, req)
@@ -185,3 +282,352 @@ func TestGetTableData_HappyPath_Returns200(t *testing.T) {
 	assert.Equal(t, "Admin", got.AdminName)
 	assert.Equal(t, "Battle", got.EncounterName)
 }
+
+func TestGetOperationLog_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   int
+		wantStatus string
+	}{
+		{"not admin returns 403", apperrors.PermissionDeniedError, responses.StatusForbidden, responses.ErrForbidden},
+		{"unknown session returns 400", apperrors.TableNotFoundErr, responses.StatusBadRequest,
+			responses.ErrWrongTableID},
+		{"generic error returns 500", errors.New("db down"), responses.StatusInternalServerError,
+			responses.ErrInternalServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := delivery.NewTableHandler(&fakeTableUsecases{opLogErr: tt.err}, ctxUserKey)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/table/session/session-1/log", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "session-1"})
+			req = withUser(req, ctxUserKey, &models.User{ID: 1})
+
+			rr := httptest.NewRecorder()
+			handler.GetOperationLog(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Equal(t, tt.wantStatus, testhelpers.DecodeErrorResponse(t, rr.Body))
+		})
+	}
+}
+
+func TestGetOperationLog_HappyPath_Returns200(t *testing.T) {
+	t.Parallel()
+
+	expected := &models.TableOperationLog{
+		SessionID: "session-1",
+		AdminID:   1,
+		Operations: []models.TableOperation{
+			{Seq: 1, AuthorID: 2, Kind: models.PatchOperation},
+			{Seq: 2, AuthorID: 1, Kind: models.UndoOperation, Reverts: []int{1}},
+		},
+	}
+
+	handler := delivery.NewTableHandler(&fakeTableUsecases{opLog: expected}, ctxUserKey)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/table/session/session-1/log", nil)
+	req = mux.SetURLVars(req, map[string]string{"id": "session-1"})
+	req = withUser(req, ctxUserKey, &models.User{ID: 1})
+
+	rr := httptest.NewRecorder()
+	handler.GetOperationLog(rr, req)
+
+	assert.Equal(t, responses.StatusOk, rr.Code)
+
+	var got models.TableOperationLog
+	testhelpers.DecodeJSON(t, rr.Body, &got)
+	assert.Len(t, got.Operations, 2)
+	assert.Equal(t, []int{1}, got.Operations[1].Reverts)
+}
+
+func TestControlSession_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantCode   int
+		wantStatus string
+	}{
+		{"bad JSON returns 400", `{invalid`, nil, responses.StatusBadRequest, responses.ErrBadJSON},
+		{"invalid command returns 400", `{"userID":1}`, apperrors.InvalidControlErr, responses.StatusBadRequest,
+			responses.ErrWrongControl},
+		{"not admin returns 403", ``, apperrors.PermissionDeniedError, responses.StatusForbidden,
+			responses.ErrForbidden},
+		{"unknown session returns 400", ``, apperrors.TableNotFoundErr, responses.StatusBadRequest,
+			responses.ErrWrongTableID},
+		{"generic error returns 500", ``, errors.New("broker down"), responses.StatusInternalServerError,
+			responses.ErrInternalServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := delivery.NewTableHandler(&fakeTableUsecases{ctrlErr: tt.err}, ctxUserKey)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/table/session/session-1/kick",
+				bytes.NewReader([]byte(tt.body)))
+			req = mux.SetURLVars(req, map[string]string{"id": "session-1", "action": "kick"})
+			req = withUser(req, ctxUserKey, &models.User{ID: 1})
+
+			rr := httptest.NewRecorder()
+			handler.ControlSession(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Equal(t, tt.wantStatus, testhelpers.DecodeErrorResponse(t, rr.Body))
+		})
+	}
+}
+
+func TestControlSession_HappyPath_Returns200(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		action string
+		body   string
+		want   models.SessionControlRequest
+	}{
+		{"end without body", "end", ``, models.SessionControlRequest{Action: models.EndSession}},
+		{"pause without body", "pause", ``, models.SessionControlRequest{Action: models.PauseSession}},
+		{"transfer reads target from body", "transfer", `{"userID":2}`,
+			models.SessionControlRequest{Action: models.TransferAdmin, UserID: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fake := &fakeTableUsecases{}
+			handler := delivery.NewTableHandler(fake, ctxUserKey)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/table/session/session-1/"+tt.action,
+				bytes.NewReader([]byte(tt.body)))
+			req = mux.SetURLVars(req, map[string]string{"id": "session-1", "action": tt.action})
+			req = withUser(req, ctxUserKey, &models.User{ID: 1})
+
+			rr := httptest.NewRecorder()
+			handler.ControlSession(rr, req)
+
+			assert.Equal(t, responses.StatusOk, rr.Code)
+			if assert.NotNil(t, fake.control) {
+				assert.Equal(t, tt.want, *fake.control)
+			}
+		})
+	}
+}
+
+func TestCreateSession_EncounterHasSession_Returns400(t *testing.T) {
+	t.Parallel()
+
+	handler := delivery.NewTableHandler(&fakeTableUsecases{createErr: apperrors.EncounterSessionErr}, ctxUserKey)
+
+	body := testhelpers.MustJSON(t, models.CreateTableRequest{EncounterID: "enc-1"})
+	req := httptest.NewRequest(http.MethodPost, "/api/table/session", bytes.NewReader(body))
+	req = withUser(req, ctxUserKey, &models.User{ID: 1})
+
+	rr := httptest.NewRecorder()
+	handler.CreateSession(rr, req)
+
+	assert.Equal(t, responses.StatusBadRequest, rr.Code)
+	assert.Equal(t, responses.ErrSessionExists, testhelpers.DecodeErrorResponse(t, rr.Body))
+}
+
+func TestCreateSession_InvalidEncounterData_Returns400(t *testing.T) {
+	t.Parallel()
+
+	handler := delivery.NewTableHandler(&fakeTableUsecases{createErr: apperrors.InvalidEncounterDataErr}, ctxUserKey)
+
+	body := testhelpers.MustJSON(t, models.CreateTableRequest{EncounterID: "enc-1"})
+	req := httptest.NewRequest(http.MethodPost, "/api/table/session", bytes.NewReader(body))
+	req = withUser(req, ctxUserKey, &models.User{ID: 1})
+
+	rr := httptest.NewRecorder()
+	handler.CreateSession(rr, req)
+
+	assert.Equal(t, responses.StatusBadRequest, rr.Code)
+	assert.Equal(t, responses.ErrWrongEncounterData, testhelpers.DecodeErrorResponse(t, rr.Body))
+}
+
+func TestListSessions_HappyPath_Returns200(t *testing.T) {
+	t.Parallel()
+
+	idleLeft := 600
+	fake := &fakeTableUsecases{sessions: []*models.TableSessionSummary{
+		{SessionID: "session-1", EncounterName: "Battle", Role: models.Admin, ParticipantsNum: 3,
+			IdleSecondsLeft: &idleLeft},
+		{SessionID: "session-2", EncounterName: "Ambush", Role: models.Player, Paused: true},
+	}}
+	handler := delivery.NewTableHandler(fake, ctxUserKey)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/table/sessions", nil)
+	req = withUser(req, ctxUserKey, &models.User{ID: 1})
+
+	rr := httptest.NewRecorder()
+	handler.ListSessions(rr, req)
+
+	assert.Equal(t, responses.StatusOk, rr.Code)
+
+	var got []models.TableSessionSummary
+	testhelpers.DecodeJSON(t, rr.Body, &got)
+	if assert.Len(t, got, 2) {
+		assert.Equal(t, 3, got[0].ParticipantsNum)
+		assert.Equal(t, &idleLeft, got[0].IdleSecondsLeft)
+		assert.Nil(t, got[1].IdleSecondsLeft)
+	}
+}
+
+func TestListSessions_UsecaseError_Returns500(t *testing.T) {
+	t.Parallel()
+
+	handler := delivery.NewTableHandler(&fakeTableUsecases{listErr: errors.New("redis down")}, ctxUserKey)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/table/sessions", nil)
+	req = withUser(req, ctxUserKey, &models.User{ID: 1})
+
+	rr := httptest.NewRecorder()
+	handler.ListSessions(rr, req)
+
+	assert.Equal(t, responses.StatusInternalServerError, rr.Code)
+	assert.Equal(t, responses.ErrInternalServer, testhelpers.DecodeErrorResponse(t, rr.Body))
+}
+
+func TestGetEncounterSession_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantCode   int
+		wantStatus string
+	}{
+		{"stranger returns 403", apperrors.PermissionDeniedError, responses.StatusForbidden, responses.ErrForbidden},
+		{"no running session returns 400", apperrors.TableNotFoundErr, responses.StatusBadRequest,
+			responses.ErrNoEncounterSession},
+		{"generic error returns 500", errors.New("redis down"), responses.StatusInternalServerError,
+			responses.ErrInternalServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := delivery.NewTableHandler(&fakeTableUsecases{listErr: tt.err}, ctxUserKey)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/table/encounter/enc-1/session", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "enc-1"})
+			req = withUser(req, ctxUserKey, &models.User{ID: 1})
+
+			rr := httptest.NewRecorder()
+			handler.GetEncounterSession(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Equal(t, tt.wantStatus, testhelpers.DecodeErrorResponse(t, rr.Body))
+		})
+	}
+}
+
+func TestCreateInvite_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		body       string
+		err        error
+		wantCode   int
+		wantStatus string
+	}{
+		{"bad JSON returns 400", `{invalid`, nil, responses.StatusBadRequest, responses.ErrBadJSON},
+		{"invalid params returns 400", `{"maxUses":1000}`, apperrors.InvalidInviteParamsErr,
+			responses.StatusBadRequest, responses.ErrWrongInviteParams},
+		{"not admin returns 403", ``, apperrors.PermissionDeniedError, responses.StatusForbidden,
+			responses.ErrForbidden},
+		{"unknown session returns 400", ``, apperrors.TableNotFoundErr, responses.StatusBadRequest,
+			responses.ErrWrongTableID},
+		{"generic error returns 500", ``, errors.New("broker down"), responses.StatusInternalServerError,
+			responses.ErrInternalServer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := delivery.NewTableHandler(&fakeTableUsecases{inviteErr: tt.err}, ctxUserKey)
+
+			req := httptest.NewRequest(http.MethodPost, "/api/table/session/session-1/invite",
+				bytes.NewReader([]byte(tt.body)))
+			req = mux.SetURLVars(req, map[string]string{"id": "session-1"})
+			req = withUser(req, ctxUserKey, &models.User{ID: 1})
+
+			rr := httptest.NewRecorder()
+			handler.CreateInvite(rr, req)
+
+			assert.Equal(t, tt.wantCode, rr.Code)
+			assert.Equal(t, tt.wantStatus, testhelpers.DecodeErrorResponse(t, rr.Body))
+		})
+	}
+}
+
+func TestCreateInvite_HappyPath_Returns200(t *testing.T) {
+	t.Parallel()
+
+	expiresAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	fake := &fakeTableUsecases{invite: &models.InviteResponse{Token: "payload.sig", ExpiresAt: expiresAt}}
+	handler := delivery.NewTableHandler(fake, ctxUserKey)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/table/session/session-1/invite",
+		bytes.NewReader([]byte(`{"expiresIn":3600,"maxUses":5,"role":"spectator"}`)))
+	req = mux.SetURLVars(req, map[string]string{"id": "session-1"})
+	req = withUser(req, ctxUserKey, &models.User{ID: 1})
+
+	rr := httptest.NewRecorder()
+	handler.CreateInvite(rr, req)
+
+	assert.Equal(t, responses.StatusOk, rr.Code)
+	assert.Equal(t, &models.CreateInviteRequest{ExpiresIn: 3600, MaxUses: 5, Role: models.Spectator}, fake.inviteReq)
+
+	var got models.InviteResponse
+	testhelpers.DecodeJSON(t, rr.Body, &got)
+	assert.Equal(t, "payload.sig", got.Token)
+	assert.True(t, expiresAt.Equal(got.ExpiresAt))
+}
+
+func TestServeWS_BadInvite_Returns400(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus string
+	}{
+		{"invalid token", apperrors.InvalidInviteErr, responses.ErrWrongInvite},
+		{"expired token", apperrors.InviteExpiredErr, responses.ErrInviteExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			handler := delivery.NewTableHandler(&fakeTableUsecases{verifyErr: tt.err}, ctxUserKey)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/table/session/session-1/connect?invite=token", nil)
+			req = mux.SetURLVars(req, map[string]string{"id": "session-1"})
+			req = withUser(req, ctxUserKey, &models.User{ID: 1})
+
+			rr := httptest.NewRecorder()
+			handler.ServeWS(rr, req)
+
+			assert.Equal(t, responses.StatusBadRequest, rr.Code)
+			assert.Equal(t, tt.wantStatus, testhelpers.DecodeErrorResponse(t, rr.Body))
+		})
+	}
+}
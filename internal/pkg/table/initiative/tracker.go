@@ -0,0 +1,343 @@
+package initiative
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils/dice"
+)
+
+const maxCombatants = 100
+
+var (
+	ErrCombatantNotFound = errors.New("combatant not found")
+	ErrCombatantExists   = errors.New("combatant already exists")
+	ErrInvalidCombatant  = errors.New("invalid combatant")
+	ErrNotStarted        = errors.New("initiative is not rolled yet")
+)
+
+// Tracker ведёт порядок ходов. Не потокобезопасен, синхронизация — на стороне вызывающего
+type Tracker struct {
+	combatants []models.Combatant
+	round      int
+	turn       int
+}
+
+func NewTracker(state *models.InitiativeState) *Tracker {
+	if state == nil {
+		return &Tracker{}
+	}
+
+	return &Tracker{
+		combatants: slices.Clone(state.Combatants),
+		round:      state.Round,
+		turn:       state.Turn,
+	}
+}
+
+func (t *Tracker) State() *models.InitiativeState {
+	state := &models.InitiativeState{
+		Combatants: slices.Clone(t.combatants),
+		Round:      t.round,
+		Turn:       t.turn,
+	}
+
+	if state.Combatants == nil {
+		state.Combatants = make([]models.Combatant, 0)
+	}
+
+	if t.round > 0 && t.turn < len(t.combatants) {
+		state.CurrentID = t.combatants[t.turn].ID
+	}
+
+	return state
+}
+
+func (t *Tracker) Current() (*models.Combatant, bool) {
+	if t.round == 0 || t.turn >= len(t.combatants) {
+		return nil, false
+	}
+
+	return &t.combatants[t.turn], true
+}
+
+func (t *Tracker) Get(id string) (*models.Combatant, bool) {
+	i := t.index(id)
+	if i < 0 {
+		return nil, false
+	}
+
+	return &t.combatants[i], true
+}
+
+// Add добавляет участника. Если бой уже идёт, участник встаёт в очередь по своей инициативе
+func (t *Tracker) Add(c models.Combatant) error {
+	if c.ID == "" || strings.TrimSpace(c.Name) == "" || len(t.combatants) >= maxCombatants {
+		return ErrInvalidCombatant
+	}
+
+	if t.index(c.ID) >= 0 {
+		return ErrCombatantExists
+	}
+
+	if t.round == 0 {
+		t.combatants = append(t.combatants, c)
+		return nil
+	}
+
+	pos := len(t.combatants)
+	for i, other := range t.combatants {
+		if before(c, other) {
+			pos = i
+			break
+		}
+	}
+
+	t.combatants = slices.Insert(t.combatants, pos, c)
+	if pos <= t.turn && len(t.combatants) > 1 {
+		t.turn++
+	}
+
+	return nil
+}
+
+func (t *Tracker) Remove(id string) error {
+	i := t.index(id)
+	if i < 0 {
+		return ErrCombatantNotFound
+	}
+
+	t.combatants = slices.Delete(t.combatants, i, i+1)
+
+	switch {
+	case len(t.combatants) == 0:
+		t.turn = 0
+	case i < t.turn:
+		t.turn--
+	case i == t.turn:
+		if t.turn >= len(t.combatants) {
+			t.turn = 0
+			t.round++
+		}
+
+		if t.round > 0 {
+			t.beginTurn()
+		}
+	}
+
+	return nil
+}
+
+// SetInitiative задаёт инициативу вручную и пересортировывает очередь, сохраняя текущего участника
+func (t *Tracker) SetInitiative(id string, value int) error {
+	i := t.index(id)
+	if i < 0 {
+		return ErrCombatantNotFound
+	}
+
+	t.combatants[i].Initiative = value
+	t.combatants[i].Roll = nil
+	t.sortKeepingCurrent()
+
+	return nil
+}
+
+// RollAll бросает к20 + модификатор ловкости за всех участников и начинает первый раунд
+func (t *Tracker) RollAll(rng dice.RNG) error {
+	for i := range t.combatants {
+		c := &t.combatants[i]
+
+		expr, err := dice.Parse("d20" + modifierSuffix(c.DexModifier))
+		if err != nil {
+			return err
+		}
+
+		roll, err := expr.Roll(rng)
+		if err != nil {
+			return err
+		}
+
+		c.Roll = roll
+		c.Initiative = roll.Total
+		c.Delayed = false
+		c.Ready = false
+	}
+
+	slices.SortStableFunc(t.combatants, compare)
+
+	t.round = 1
+	t.turn = 0
+	t.beginTurn()
+
+	return nil
+}
+
+// Next передаёт ход следующему участнику, пропуская отложивших ход. В начале хода подготовленное
+// действие сбрасывается
+func (t *Tracker) Next() error {
+	if t.round == 0 || len(t.combatants) == 0 {
+		return ErrNotStarted
+	}
+
+	t.step(1)
+	t.beginTurn()
+
+	return nil
+}
+
+func (t *Tracker) Prev() error {
+	if t.round == 0 || len(t.combatants) == 0 {
+		return ErrNotStarted
+	}
+
+	if t.round == 1 && t.turn == t.firstActive() {
+		return nil
+	}
+
+	t.step(-1)
+	t.skipDelayed(-1)
+
+	return nil
+}
+
+// Delay откладывает ход участника. Если он ходит сейчас, ход переходит к следующему
+func (t *Tracker) Delay(id string) error {
+	i := t.index(id)
+	if i < 0 {
+		return ErrCombatantNotFound
+	}
+
+	t.combatants[i].Delayed = true
+
+	if t.round > 0 && i == t.turn {
+		return t.Next()
+	}
+
+	return nil
+}
+
+// Resume возвращает отложившего ход участника в очередь перед текущим участником и передаёт ему ход
+func (t *Tracker) Resume(id string) error {
+	i := t.index(id)
+	if i < 0 {
+		return ErrCombatantNotFound
+	}
+
+	c := t.combatants[i]
+	if !c.Delayed {
+		return nil
+	}
+
+	c.Delayed = false
+
+	current, ok := t.Current()
+	if !ok {
+		t.combatants[i] = c
+		return nil
+	}
+
+	c.Initiative = current.Initiative
+
+	t.combatants = slices.Delete(t.combatants, i, i+1)
+	if i < t.turn {
+		t.turn--
+	}
+
+	t.combatants = slices.Insert(t.combatants, t.turn, c)
+
+	return nil
+}
+
+// Ready отмечает подготовленное действие. Отметка снимается в начале следующего хода участника
+func (t *Tracker) Ready(id string) error {
+	i := t.index(id)
+	if i < 0 {
+		return ErrCombatantNotFound
+	}
+
+	t.combatants[i].Ready = !t.combatants[i].Ready
+
+	return nil
+}
+
+func (t *Tracker) index(id string) int {
+	return slices.IndexFunc(t.combatants, func(c models.Combatant) bool { return c.ID == id })
+}
+
+func (t *Tracker) step(direction int) {
+	t.turn += direction
+
+	if t.turn >= len(t.combatants) {
+		t.turn = 0
+		t.round++
+	} else if t.turn < 0 {
+		t.turn = len(t.combatants) - 1
+		t.round = max(t.round-1, 1)
+	}
+}
+
+// beginTurn передаёт ход ближайшему не отложившему ход участнику и сбрасывает его подготовленное действие
+func (t *Tracker) beginTurn() {
+	t.skipDelayed(1)
+
+	if c, ok := t.Current(); ok {
+		c.Ready = false
+	}
+}
+
+// skipDelayed сдвигает ход с отложивших его участников. Если отложили все, ход не меняется
+func (t *Tracker) skipDelayed(direction int) {
+	for range t.combatants {
+		if !t.combatants[t.turn].Delayed {
+			return
+		}
+
+		t.step(direction)
+	}
+}
+
+func (t *Tracker) firstActive() int {
+	i := slices.IndexFunc(t.combatants, func(c models.Combatant) bool { return !c.Delayed })
+
+	return max(i, 0)
+}
+
+func (t *Tracker) sortKeepingCurrent() {
+	var currentID string
+	if c, ok := t.Current(); ok {
+		currentID = c.ID
+	}
+
+	slices.SortStableFunc(t.combatants, compare)
+
+	if currentID != "" {
+		t.turn = t.index(currentID)
+	}
+}
+
+// compare упорядочивает по убыванию инициативы, при равенстве — по убыванию ловкости
+func compare(a, b models.Combatant) int {
+	if a.Initiative != b.Initiative {
+		return b.Initiative - a.Initiative
+	}
+
+	return b.DexModifier - a.DexModifier
+}
+
+func before(a, b models.Combatant) bool {
+	return compare(a, b) < 0
+}
+
+func modifierSuffix(modifier int) string {
+	switch {
+	case modifier > 0:
+		return "+" + strconv.Itoa(modifier)
+	case modifier < 0:
+		return "-" + strconv.Itoa(-modifier)
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,211 @@
+package initiative_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table/initiative"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils/dice"
+	"github.com/stretchr/testify/assert"
+)
+
+// sequenceRNG возвращает заранее заданные значения по порядку
+func sequenceRNG(values ...int) dice.RNG {
+	i := 0
+
+	return func(sides int) (int, error) {
+		v := values[i%len(values)]
+		i++
+
+		return v, nil
+	}
+}
+
+func order(state *models.InitiativeState) []string {
+	ids := make([]string, 0, len(state.Combatants))
+	for _, c := range state.Combatants {
+		ids = append(ids, c.ID)
+	}
+
+	return ids
+}
+
+// newRolledTracker создаёт трекер с тремя участниками и инициативой fighter 18, goblin 15, wizard 9
+func newRolledTracker(t *testing.T) *initiative.Tracker {
+	t.Helper()
+
+	tracker := initiative.NewTracker(nil)
+	assert.NoError(t, tracker.Add(models.Combatant{ID: "wizard", Name: "Wizard", DexModifier: 1}))
+	assert.NoError(t, tracker.Add(models.Combatant{ID: "goblin", Name: "Goblin", DexModifier: 2}))
+	assert.NoError(t, tracker.Add(models.Combatant{ID: "fighter", Name: "Fighter", DexModifier: 0}))
+	assert.NoError(t, tracker.RollAll(sequenceRNG(8, 13, 18)))
+
+	return tracker
+}
+
+func TestRollAll(t *testing.T) {
+	t.Parallel()
+
+	tracker := newRolledTracker(t)
+	state := tracker.State()
+
+	assert.Equal(t, []string{"fighter", "goblin", "wizard"}, order(state))
+	assert.Equal(t, 1, state.Round)
+	assert.Equal(t, "fighter", state.CurrentID)
+	assert.Equal(t, 15, state.Combatants[1].Initiative)
+	if assert.NotNil(t, state.Combatants[1].Roll) {
+		assert.Equal(t, "d20+2", state.Combatants[1].Roll.Formula)
+	}
+}
+
+func TestRollAll_TieBrokenByDex(t *testing.T) {
+	t.Parallel()
+
+	tracker := initiative.NewTracker(nil)
+	assert.NoError(t, tracker.Add(models.Combatant{ID: "slow", Name: "Slow", DexModifier: 0}))
+	assert.NoError(t, tracker.Add(models.Combatant{ID: "fast", Name: "Fast", DexModifier: 3}))
+	assert.NoError(t, tracker.RollAll(sequenceRNG(15, 12)))
+
+	assert.Equal(t, []string{"fast", "slow"}, order(tracker.State()))
+}
+
+func TestNextPrev(t *testing.T) {
+	t.Parallel()
+
+	tracker := newRolledTracker(t)
+
+	assert.NoError(t, tracker.Prev())
+	assert.Equal(t, "fighter", tracker.State().CurrentID, "prev must not go before the first turn")
+
+	assert.NoError(t, tracker.Next())
+	assert.NoError(t, tracker.Next())
+	assert.Equal(t, "wizard", tracker.State().CurrentID)
+
+	assert.NoError(t, tracker.Next())
+	state := tracker.State()
+	assert.Equal(t, "fighter", state.CurrentID)
+	assert.Equal(t, 2, state.Round)
+
+	assert.NoError(t, tracker.Prev())
+	state = tracker.State()
+	assert.Equal(t, "wizard", state.CurrentID)
+	assert.Equal(t, 1, state.Round)
+}
+
+func TestNext_NotStarted(t *testing.T) {
+	t.Parallel()
+
+	tracker := initiative.NewTracker(nil)
+	assert.NoError(t, tracker.Add(models.Combatant{ID: "a", Name: "A"}))
+
+	assert.True(t, errors.Is(tracker.Next(), initiative.ErrNotStarted))
+	assert.True(t, errors.Is(tracker.Prev(), initiative.ErrNotStarted))
+}
+
+func TestDelayResume(t *testing.T) {
+	t.Parallel()
+
+	tracker := newRolledTracker(t)
+
+	assert.NoError(t, tracker.Delay("fighter"))
+	assert.Equal(t, "goblin", tracker.State().CurrentID, "delaying the current combatant passes the turn")
+
+	assert.NoError(t, tracker.Next())
+	assert.Equal(t, "wizard", tracker.State().CurrentID)
+
+	assert.NoError(t, tracker.Next())
+	assert.Equal(t, "goblin", tracker.State().CurrentID, "delayed combatant is skipped")
+
+	assert.NoError(t, tracker.Resume("fighter"))
+	state := tracker.State()
+	assert.Equal(t, "fighter", state.CurrentID)
+	assert.Equal(t, []string{"fighter", "goblin", "wizard"}, order(state))
+	assert.Equal(t, 15, state.Combatants[0].Initiative)
+	assert.False(t, state.Combatants[0].Delayed)
+
+	assert.NoError(t, tracker.Next())
+	assert.Equal(t, "goblin", tracker.State().CurrentID)
+}
+
+func TestResume_MovesBeforeCurrent(t *testing.T) {
+	t.Parallel()
+
+	tracker := newRolledTracker(t)
+
+	assert.NoError(t, tracker.Delay("goblin"))
+	assert.NoError(t, tracker.Next())
+	assert.Equal(t, "wizard", tracker.State().CurrentID)
+
+	assert.NoError(t, tracker.Resume("goblin"))
+	state := tracker.State()
+	assert.Equal(t, []string{"fighter", "goblin", "wizard"}, order(state))
+	assert.Equal(t, "goblin", state.CurrentID)
+	assert.Equal(t, 9, state.Combatants[1].Initiative)
+}
+
+func TestReady(t *testing.T) {
+	t.Parallel()
+
+	tracker := newRolledTracker(t)
+
+	assert.NoError(t, tracker.Ready("goblin"))
+	c, ok := tracker.Get("goblin")
+	assert.True(t, ok)
+	assert.True(t, c.Ready)
+
+	assert.NoError(t, tracker.Next())
+	c, _ = tracker.Get("goblin")
+	assert.False(t, c.Ready, "ready action is cleared when the combatant's turn starts")
+}
+
+func TestAddRemove(t *testing.T) {
+	t.Parallel()
+
+	tracker := newRolledTracker(t)
+	assert.NoError(t, tracker.Next())
+
+	assert.NoError(t, tracker.Add(models.Combatant{ID: "ogre", Name: "Ogre", Initiative: 20}))
+	state := tracker.State()
+	assert.Equal(t, []string{"ogre", "fighter", "goblin", "wizard"}, order(state))
+	assert.Equal(t, "goblin", state.CurrentID, "adding a combatant keeps the current turn")
+
+	assert.True(t, errors.Is(tracker.Add(models.Combatant{ID: "ogre", Name: "Ogre"}),
+		initiative.ErrCombatantExists))
+	assert.True(t, errors.Is(tracker.Add(models.Combatant{ID: "x"}), initiative.ErrInvalidCombatant))
+
+	assert.NoError(t, tracker.Remove("goblin"))
+	assert.Equal(t, "wizard", tracker.State().CurrentID, "removing the current combatant passes the turn")
+
+	assert.NoError(t, tracker.Remove("wizard"))
+	state = tracker.State()
+	assert.Equal(t, "ogre", state.CurrentID)
+	assert.Equal(t, 2, state.Round)
+
+	assert.True(t, errors.Is(tracker.Remove("wizard"), initiative.ErrCombatantNotFound))
+}
+
+func TestSetInitiative_KeepsCurrent(t *testing.T) {
+	t.Parallel()
+
+	tracker := newRolledTracker(t)
+	assert.NoError(t, tracker.Next())
+
+	assert.NoError(t, tracker.SetInitiative("wizard", 25))
+	state := tracker.State()
+	assert.Equal(t, []string{"wizard", "fighter", "goblin"}, order(state))
+	assert.Equal(t, "goblin", state.CurrentID)
+	assert.Nil(t, state.Combatants[0].Roll)
+}
+
+func TestNewTracker_RestoresState(t *testing.T) {
+	t.Parallel()
+
+	state := newRolledTracker(t).State()
+
+	restored := initiative.NewTracker(state)
+	assert.NoError(t, restored.Next())
+
+	assert.Equal(t, "goblin", restored.State().CurrentID)
+	assert.Equal(t, "fighter", state.CurrentID, "restored tracker must not share the original slice")
+}
@@ -12,18 +12,90 @@ import (
 
 type TableManager interface {
 	CreateSession(ctx context.Context, admin *models.User, encounter *models.Encounter, sessionID string,
-		callback func(sessionID string))
+		maxPlayers int, lifecycle SessionLifecycle) error
 	RemoveSession(ctx context.Context, sessionID string)
-	GetTableData(ctx context.Context, sessionID string) (*models.TableData, error)
+	GetTableData(ctx context.Context, sessionID string, userID int) (*models.TableData, error)
 	GetEncounterData(ctx context.Context, sessionID string) ([]byte, error)
-	AddNewConnection(ctx context.Context, user *models.User, sessionID string, conn *websocket.Conn)
+	AddNewConnection(ctx context.Context, user *models.User, sessionID string, params models.ConnectionParams,
+		conn *websocket.Conn)
 	HasActiveUsers(ctx context.Context, sessionID string) bool
+	AdoptSession(ctx context.Context, sessionID string,
+		lifecycle SessionLifecycle) (*models.TableSessionSnapshot, bool)
+	RestoreSessions(ctx context.Context, lifecycle SessionLifecycle) []*models.TableSessionSnapshot
+	RunSnapshots(ctx context.Context, interval time.Duration)
+	GetOperationLog(ctx context.Context, sessionID string) (*models.TableOperationLog, error)
+	ControlSession(ctx context.Context, sessionID string, userID int, req *models.SessionControlRequest) error
+	ListSessions(ctx context.Context, userID int) ([]*models.TableSessionSnapshot, error)
+	FindEncounterSession(ctx context.Context, encounterID string) (*models.TableSessionSnapshot, error)
+	FindEncounterSessions(ctx context.Context, encounterIDs []string) ([]*models.TableSessionSnapshot, error)
+	GetSessionAdmin(ctx context.Context, sessionID string) (int, error)
 }
 
 type TableUsecases interface {
-	CreateSession(ctx context.Context, admin *models.User, encounterID string) (string, error)
-	GetTableData(ctx context.Context, sessionID string) (*models.TableData, error)
-	AddNewConnection(ctx context.Context, user *models.User, sessionID string, conn *websocket.Conn)
+	CreateSession(ctx context.Context, admin *models.User, encounterID string, maxPlayers int) (string, error)
+	GetTableData(ctx context.Context, sessionID string, userID int) (*models.TableData, error)
+	AddNewConnection(ctx context.Context, user *models.User, sessionID string, params models.ConnectionParams,
+		conn *websocket.Conn)
+	RestoreSessions(ctx context.Context)
+	RunRecovery(ctx context.Context, interval time.Duration)
+	GetOperationLog(ctx context.Context, sessionID string, userID int) (*models.TableOperationLog, error)
+	ControlSession(ctx context.Context, sessionID string, userID int, req *models.SessionControlRequest) error
+	ListSessions(ctx context.Context, userID int) ([]*models.TableSessionSummary, error)
+	GetEncounterSession(ctx context.Context, encounterID string, userID int) (*models.TableSessionSummary, error)
+	CreateInvite(ctx context.Context, sessionID string, userID int,
+		req *models.CreateInviteRequest) (*models.InviteResponse, error)
+	VerifyInvite(ctx context.Context, sessionID, token string) (*models.InviteClaims, error)
+}
+
+// SessionLifecycle получает от сессии уведомления об активности участников и командах ведущего,
+// управляющих таймером бездействия
+type SessionLifecycle interface {
+	Refresh(sessionID string)
+	Pause(sessionID string)
+	Resume(sessionID string)
+	End(sessionID string)
+}
+
+// TableLogRepository хранит журналы изменений сессий: операции долгих сессий, вытесненные из памяти,
+// и полные журналы завершённых. Операции дописываются к сохранённым, уже записанные пропускаются
+type TableLogRepository interface {
+	SaveOperationLog(ctx context.Context, log *models.TableOperationLog) error
+	AppendOperations(ctx context.Context, log *models.TableOperationLog) error
+	GetOperationLog(ctx context.Context, sessionID string) (*models.TableOperationLog, error)
+}
+
+// TableSessionStore — долговременное хранилище снапшотов игровых сессий
+type TableSessionStore interface {
+	SaveSession(ctx context.Context, snapshot *models.TableSessionSnapshot) error
+	GetSession(ctx context.Context, sessionID string) (*models.TableSessionSnapshot, error)
+	ListSessions(ctx context.Context) ([]*models.TableSessionSnapshot, error)
+	RemoveSession(ctx context.Context, sessionID string) error
+}
+
+// TablePubSub — шина сообщений между репликами бэкенда. Подписка живёт до отмены переданного контекста,
+// после чего канал закрывается
+type TablePubSub interface {
+	Publish(ctx context.Context, channel string, msg []byte) error
+	Subscribe(ctx context.Context, channel string) (<-chan []byte, error)
+}
+
+// SessionLocker координирует владение игровыми сессиями между репликами: в каждый момент времени
+// состояние сессии ведёт только одна реплика
+type SessionLocker interface {
+	TryLock(ctx context.Context, sessionID, owner string, ttl time.Duration) (bool, error)
+	Refresh(ctx context.Context, sessionID, owner string, ttl time.Duration) (bool, error)
+	Unlock(ctx context.Context, sessionID, owner string) error
+}
+
+// CombatantStatsProvider достаёт характеристики участников боя из бестиария и карточек персонажей
+type CombatantStatsProvider interface {
+	GetDexModifier(ctx context.Context, kind models.CombatantKind, sourceID string) (int, error)
+}
+
+// InviteSigner подписывает приглашения в сессии и проверяет их подпись и срок действия
+type InviteSigner interface {
+	Sign(claims *models.InviteClaims) (string, error)
+	Verify(token string) (*models.InviteClaims, error)
 }
 
 type SessionIDGenerator interface {
@@ -0,0 +1,75 @@
+package invite
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid invite token")
+	ErrTokenExpired = errors.New("invite token expired")
+)
+
+// Signer выпускает приглашения в игровые сессии и проверяет их. Приглашение — это содержимое
+// в base64url и HMAC-SHA256 подпись к нему, разделённые точкой
+type Signer struct {
+	secret []byte
+}
+
+func NewSigner(secret []byte) *Signer {
+	return &Signer{secret: secret}
+}
+
+func (s *Signer) Sign(claims *models.InviteClaims) (string, error) {
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.signature(encoded)), nil
+}
+
+// Verify проверяет подпись и срок действия приглашения
+func (s *Signer) Verify(token string) (*models.InviteClaims, error) {
+	encoded, rawSignature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(rawSignature)
+	if err != nil || !hmac.Equal(signature, s.signature(encoded)) {
+		return nil, ErrInvalidToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	var claims models.InviteClaims
+	if err := json.Unmarshal(payload, &claims); err != nil || claims.ID == "" || claims.SessionID == "" {
+		return nil, ErrInvalidToken
+	}
+
+	if time.Now().Unix() >= claims.ExpiresAt {
+		return nil, ErrTokenExpired
+	}
+
+	return &claims, nil
+}
+
+func (s *Signer) signature(encoded string) []byte {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(encoded))
+
+	return mac.Sum(nil)
+}
@@ -0,0 +1,77 @@
+package invite_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table/invite"
+	"github.com/stretchr/testify/assert"
+)
+
+func validClaims() *models.InviteClaims {
+	return &models.InviteClaims{
+		ID:          "invite-1",
+		SessionID:   "session-1",
+		ExpiresAt:   time.Now().Add(time.Hour).Unix(),
+		MaxUses:     3,
+		Role:        models.Spectator,
+		CharacterID: "char-1",
+	}
+}
+
+func TestSignVerify_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	signer := invite.NewSigner([]byte("secret"))
+	claims := validClaims()
+
+	token, err := signer.Sign(claims)
+	assert.NoError(t, err)
+
+	got, err := signer.Verify(token)
+	assert.NoError(t, err)
+	assert.Equal(t, claims, got)
+}
+
+func TestVerify_Rejects(t *testing.T) {
+	t.Parallel()
+
+	signer := invite.NewSigner([]byte("secret"))
+
+	token, err := signer.Sign(validClaims())
+	assert.NoError(t, err)
+
+	expiredClaims := validClaims()
+	expiredClaims.ExpiresAt = time.Now().Add(-time.Minute).Unix()
+	expired, err := signer.Sign(expiredClaims)
+	assert.NoError(t, err)
+
+	foreign, err := invite.NewSigner([]byte("other")).Sign(validClaims())
+	assert.NoError(t, err)
+
+	payload, signature, _ := strings.Cut(token, ".")
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{"expired token", expired, invite.ErrTokenExpired},
+		{"signed with another secret", foreign, invite.ErrInvalidToken},
+		{"tampered payload", "x" + payload + "." + signature, invite.ErrInvalidToken},
+		{"missing signature", payload, invite.ErrInvalidToken},
+		{"garbage", "not-a-token.at-all", invite.ErrInvalidToken},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := signer.Verify(tt.token)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Nil(t, got)
+		})
+	}
+}
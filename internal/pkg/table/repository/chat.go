@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/delivery/responses"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils/dice"
+	"github.com/google/uuid"
+)
+
+const (
+	maxChatHistory   = 200
+	maxChatMsgLength = 2000
+	maxInlineRolls   = 10
+)
+
+// inlineRollPattern находит в тексте сообщения формулы вида [[2d6+3]]
+var inlineRollPattern = regexp.MustCompile(`\[\[([^\[\]]+)\]\]`)
+
+// handleChat рассылает сообщение чата. Сообщение с получателем — шёпот: ведущий может шептать любому
+// участнику, игрок — только ведущему. Шёпот видят только отправитель и получатель
+func (s *session) handleChat(ctx context.Context, event *tableEvent, data json.RawMessage) {
+	l := logger.FromContext(ctx)
+
+	var req models.ChatRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		l.RepoWarn(apperrors.InvalidChatErr, map[string]any{"session_id": s.id, "user_id": event.UserID})
+		s.sendErrToConn(ctx, event.ConnID, responses.ErrInvalidChatWS)
+
+		return
+	}
+
+	text := strings.TrimSpace(req.Text)
+	if text == "" || utf8.RuneCountInString(text) > maxChatMsgLength || req.To == event.UserID {
+		l.RepoWarn(apperrors.InvalidChatErr, map[string]any{"session_id": s.id, "user_id": event.UserID})
+		s.sendErrToConn(ctx, event.ConnID, responses.ErrInvalidChatWS)
+
+		return
+	}
+
+	if req.To != 0 && event.UserID != s.adminID && req.To != s.adminID {
+		l.RepoWarn(apperrors.PermissionDeniedError, map[string]any{"session_id": s.id, "user_id": event.UserID,
+			"to": req.To})
+		s.sendErrToConn(ctx, event.ConnID, responses.ErrWhisperForbiddenWS)
+
+		return
+	}
+
+	rolls, err := rollInline(text)
+	if err != nil {
+		l.RepoWarn(apperrors.InvalidChatErr, map[string]any{"session_id": s.id, "user_id": event.UserID,
+			"text": text})
+		s.sendErrToConn(ctx, event.ConnID, responses.ErrInvalidChatWS)
+
+		return
+	}
+
+	// Данные сессии меняются только в горутине run, поэтому между проверкой и изменением они те же
+	s.mu.RLock()
+
+	msg := models.ChatMsg{
+		ID:        uuid.NewString(),
+		UserID:    event.UserID,
+		To:        req.To,
+		Text:      text,
+		Rolls:     rolls,
+		Timestamp: time.Now(),
+	}
+	if p, ok := s.participants[event.UserID]; ok {
+		msg.Name = p.Name
+	}
+
+	if req.To != 0 {
+		p, ok := s.participants[req.To]
+		if !ok {
+			s.mu.RUnlock()
+			l.RepoWarn(apperrors.InvalidChatErr, map[string]any{"session_id": s.id, "user_id": event.UserID,
+				"to": req.To})
+			s.sendErrToConn(ctx, event.ConnID, responses.ErrInvalidChatWS)
+
+			return
+		}
+
+		msg.ToName = p.Name
+	}
+
+	s.mu.RUnlock()
+
+	s.touch()
+
+	s.mu.Lock()
+	s.chat = appendBounded(s.chat, msg, maxChatHistory)
+	s.dirty = true
+
+	s.mu.Unlock()
+
+	if msg.To == 0 {
+		s.sendToAll(ctx, models.Chat, &msg)
+		return
+	}
+
+	s.send(ctx, &outboundMsg{UserID: msg.To}, models.Chat, &msg)
+	s.send(ctx, &outboundMsg{UserID: msg.UserID}, models.Chat, &msg)
+}
+
+// rollInline бросает все формулы из текста сообщения
+func rollInline(text string) ([]models.InlineRoll, error) {
+	matches := inlineRollPattern.FindAllStringSubmatch(text, -1)
+	if len(matches) > maxInlineRolls {
+		return nil, dice.ErrInvalidFormula
+	}
+
+	rolls := make([]models.InlineRoll, 0, len(matches))
+	for _, match := range matches {
+		formula := strings.TrimSpace(match[1])
+
+		result, err := dice.Roll(formula)
+		if err != nil {
+			return nil, err
+		}
+
+		rolls = append(rolls, models.InlineRoll{Formula: formula, Result: result})
+	}
+
+	return rolls, nil
+}
+
+// publicChat возвращает сообщения чата без шёпотов
+func publicChat(chat []models.ChatMsg) []models.ChatMsg {
+	public := make([]models.ChatMsg, 0, len(chat))
+	for _, msg := range chat {
+		if msg.To == 0 {
+			public = append(public, msg)
+		}
+	}
+
+	return public
+}
+
+// writeChatHistory отправляет подключившемуся участнику историю чата без чужих шёпотов
+func (s *session) writeChatHistory(ctx context.Context, connID string, userID int) {
+	s.mu.RLock()
+
+	history := make([]models.ChatMsg, 0, len(s.chat))
+	for _, msg := range s.chat {
+		if msg.To == 0 || msg.To == userID || msg.UserID == userID {
+			history = append(history, msg)
+		}
+	}
+
+	s.mu.RUnlock()
+
+	if len(history) == 0 {
+		return
+	}
+
+	s.sendToConn(ctx, connID, models.ChatHistory, &models.ChatHistoryMsg{Messages: history})
+}
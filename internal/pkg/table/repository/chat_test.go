@@ -0,0 +1,126 @@
+package repository
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/delivery/responses"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSession_WhisperRouting(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		from      int
+		to        int
+		wantErr   string
+		wantSeeBy []int
+	}{
+		{
+			name:      "public message reaches everybody",
+			from:      2,
+			wantSeeBy: []int{testAdminID, 2, 3},
+		},
+		{
+			name:      "player whispers to the game master",
+			from:      2,
+			to:        testAdminID,
+			wantSeeBy: []int{testAdminID, 2},
+		},
+		{
+			name:      "game master whispers to a player",
+			from:      testAdminID,
+			to:        3,
+			wantSeeBy: []int{testAdminID, 3},
+		},
+		{
+			name:    "player cannot whisper to another player",
+			from:    2,
+			to:      3,
+			wantErr: responses.ErrWhisperForbiddenWS,
+		},
+		{
+			name:    "whisper to a user outside of the session is rejected",
+			from:    testAdminID,
+			to:      4,
+			wantErr: responses.ErrInvalidChatWS,
+		},
+	}
+
+	connOf := map[int]string{testAdminID: testAdminConn, 2: "conn-2", 3: "conn-3"}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt := newTestTable(t, `{"hp":10}`)
+			tt.joinAdmin()
+			tt.joinPlayer(2, connOf[2])
+			tt.joinPlayer(3, connOf[3])
+
+			tt.s.lastActivity = time.Time{}
+
+			out := tt.send(tc.from, connOf[tc.from], models.Chat, &models.ChatRequest{Text: "psst", To: tc.to})
+
+			if tc.wantErr != "" {
+				assert.Equal(t, []string{tc.wantErr}, errorsOf(received(t, out, connOf[tc.from], tc.from)))
+				assert.Empty(t, tt.s.chat)
+
+				// Rejected messages do not keep the session alive
+				assert.True(t, tt.s.lastActivity.IsZero())
+
+				return
+			}
+
+			for userID, connID := range connOf {
+				msg, ok := findMsg(received(t, out, connID, userID), models.Chat)
+				assert.Equal(t, slices.Contains(tc.wantSeeBy, userID), ok, "user %d", userID)
+
+				if ok {
+					assert.Equal(t, tc.to, decodeData[models.ChatMsg](t, msg).To)
+				}
+			}
+
+			// History sent on connect hides other people's whispers as well, empty history is not sent
+			for userID, connID := range connOf {
+				tt.s.writeChatHistory(tt.ctx, connID, userID)
+
+				history, ok := findMsg(received(t, tt.drain(), connID, userID), models.ChatHistory)
+				assert.Equal(t, slices.Contains(tc.wantSeeBy, userID), ok, "user %d", userID)
+
+				if ok {
+					assert.Len(t, decodeData[models.ChatHistoryMsg](t, history).Messages, 1)
+				}
+			}
+		})
+	}
+}
+
+func TestSession_EncounterDataSkipsWhispers(t *testing.T) {
+	t.Parallel()
+
+	tt := newTestTable(t, `{"hp":10}`)
+	tt.joinAdmin()
+	tt.joinPlayer(2, "conn-2")
+
+	tt.send(2, "conn-2", models.Chat, &models.ChatRequest{Text: "hello everyone"})
+	tt.send(2, "conn-2", models.Chat, &models.ChatRequest{Text: "I am the traitor", To: testAdminID})
+
+	data, err := tt.s.GetEncounterData()
+	assert.NoError(t, err)
+
+	var saved map[string]json.RawMessage
+	assert.NoError(t, json.Unmarshal(data, &saved))
+
+	var chat []models.ChatMsg
+	assert.NoError(t, json.Unmarshal(saved[chatHistoryKey], &chat))
+
+	assert.Len(t, chat, 1)
+	assert.Equal(t, "hello everyone", chat[0].Text)
+	assert.Len(t, tt.s.chat, 2)
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/metrics"
+	"github.com/gorilla/websocket"
+)
+
+const (
+	writeWait      = 10 * time.Second  // Время на запись одного сообщения в сокет
+	pongWait       = 60 * time.Second  // Время ожидания любого сообщения или pong от клиента
+	pingPeriod     = pongWait * 9 / 10 // Период отправки ping, меньше pongWait
+	maxMessageSize = 1 << 20           // Максимальный размер входящего сообщения
+	outboundQueue  = 128               // Размер очереди исходящих сообщений соединения
+
+	sessionEndedText = "Session ended"
+)
+
+type outboundFrame struct {
+	payload []byte
+
+	// closeCode, если задан, закрывает соединение после отправки payload
+	closeCode int
+	closeText string
+}
+
+// hubConn — соединение клиента с собственной очередью исходящих сообщений. В сокет пишет только
+// горутина writeLoop, читает только readLoop
+type hubConn struct {
+	id     string
+	userID int
+	name   string
+	role   models.Role
+	invite *models.InviteClaims // Приглашение, с которым подключился клиент
+
+	// admitted — участник допущен в сессию, изменяется под sessionHub.mu
+	admitted bool
+	conn     *websocket.Conn
+
+	queue     chan outboundFrame
+	done      chan struct{}
+	closeOnce sync.Once
+
+	metrics metrics.WSSessionMetrics
+}
+
+func newHubConn(id string, user *models.User, params models.ConnectionParams, conn *websocket.Conn,
+	metrics metrics.WSSessionMetrics) *hubConn {
+	return &hubConn{
+		id:      id,
+		userID:  user.ID,
+		name:    user.DisplayName,
+		role:    params.Role,
+		invite:  params.Invite,
+		conn:    conn,
+		queue:   make(chan outboundFrame, outboundQueue),
+		done:    make(chan struct{}),
+		metrics: metrics,
+	}
+}
+
+// enqueue ставит сообщение в очередь без блокировки. Возвращает false, если очередь переполнена
+func (c *hubConn) enqueue(frame outboundFrame) bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+	}
+
+	select {
+	case c.queue <- frame:
+		c.metrics.ObserveQueueLength(len(c.queue))
+		return true
+	default:
+		return false
+	}
+}
+
+// close закрывает done: writeLoop завершается и закрывает сокет, после чего readLoop завершается с ошибкой
+func (c *hubConn) close() {
+	c.closeOnce.Do(func() {
+		close(c.done)
+	})
+}
+
+func (c *hubConn) writeLoop(ctx context.Context) {
+	l := logger.FromContext(ctx)
+
+	ticker := time.NewTicker(pingPeriod)
+
+	defer func() {
+		ticker.Stop()
+		c.close()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case frame := <-c.queue:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+
+			if frame.payload != nil {
+				if err := c.conn.WriteMessage(websocket.TextMessage, frame.payload); err != nil {
+					l.RepoError(err, map[string]any{"user_id": c.userID, "conn_id": c.id})
+					c.metrics.IncWriteErrors()
+
+					return
+				}
+
+				c.metrics.IncSentMsgs()
+			}
+
+			if frame.closeCode != 0 {
+				c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(frame.closeCode,
+					frame.closeText))
+
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				l.RepoError(err, map[string]any{"user_id": c.userID, "conn_id": c.id})
+				c.metrics.IncWriteErrors()
+
+				return
+			}
+		}
+	}
+}
+
+// readLoop передаёт входящие сообщения в handle до ошибки чтения. Клиент, не ответивший на ping
+// за pongWait, считается отключившимся
+func (c *hubConn) readLoop(ctx context.Context, handle func(msg []byte)) {
+	l := logger.FromContext(ctx)
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		_, msg, err := c.conn.ReadMessage()
+		if err != nil {
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				c.metrics.IncHeartbeatTimeouts()
+			}
+
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+				l.RepoError(err, map[string]any{"user_id": c.userID, "conn_id": c.id})
+			}
+
+			return
+		}
+
+		c.conn.SetReadDeadline(time.Now().Add(pongWait))
+		c.metrics.IncReceivedMsgs()
+
+		handle(msg)
+	}
+}
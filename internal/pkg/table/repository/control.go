@@ -0,0 +1,232 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/delivery/responses"
+)
+
+// handleControl выполняет команду ведущего, пришедшую по WS или через REST
+func (s *session) handleControl(ctx context.Context, event *tableEvent, data json.RawMessage) {
+	l := logger.FromContext(ctx)
+
+	if event.UserID != s.currentAdminID() {
+		l.RepoWarn(apperrors.PermissionDeniedError, map[string]any{"session_id": s.id, "user_id": event.UserID})
+		s.replyErr(ctx, event, responses.ErrControlForbiddenWS)
+
+		return
+	}
+
+	var req models.SessionControlRequest
+	if err := json.Unmarshal(data, &req); err != nil ||
+		(req.TargetsParticipant() && (req.UserID == 0 || req.UserID == event.UserID)) {
+		l.RepoWarn(apperrors.InvalidControlErr, map[string]any{"session_id": s.id, "user_id": event.UserID})
+		s.replyErr(ctx, event, responses.ErrInvalidControlWS)
+
+		return
+	}
+
+	l.RepoInfo("session control", map[string]any{"session_id": s.id, "user_id": event.UserID,
+		"action": req.Action, "target_id": req.UserID})
+
+	switch req.Action {
+	case models.EndSession:
+		s.broadcastSessionState(ctx, req.Action, 0)
+		s.lifecycle.End(s.id)
+	case models.PauseSession, models.ResumeSession:
+		s.setPaused(ctx, req.Action)
+	case models.KickParticipant, models.BanParticipant:
+		s.expel(ctx, event, req.Action, req.UserID)
+	case models.TransferAdmin:
+		s.transferAdmin(ctx, event, req.UserID)
+	default:
+		l.RepoWarn(apperrors.InvalidControlErr, map[string]any{"session_id": s.id, "user_id": event.UserID})
+		s.replyErr(ctx, event, responses.ErrInvalidControlWS)
+	}
+}
+
+// setPaused останавливает или возобновляет таймер бездействия сессии
+func (s *session) setPaused(ctx context.Context, action models.SessionAction) {
+	paused := action == models.PauseSession
+
+	s.mu.Lock()
+	if s.paused == paused {
+		s.mu.Unlock()
+		return
+	}
+
+	s.paused = paused
+	s.dirty = true
+	s.mu.Unlock()
+
+	if paused {
+		s.lifecycle.Pause(s.id)
+	} else {
+		s.lifecycle.Resume(s.id)
+	}
+
+	s.broadcastSessionState(ctx, action, 0)
+}
+
+// expel отключает участника от сессии. Забаненный участник не сможет подключиться снова, даже если
+// сейчас он не в сессии
+func (s *session) expel(ctx context.Context, event *tableEvent, action models.SessionAction, userID int) {
+	l := logger.FromContext(ctx)
+
+	ban := action == models.BanParticipant
+
+	s.mu.Lock()
+
+	if ban && !slices.Contains(s.banned, userID) {
+		s.banned = append(s.banned, userID)
+		s.dirty = true
+	}
+
+	// Исключённый участник больше не видит сессию в своём списке
+	delete(s.members, userID)
+
+	p, ok := s.participants[userID]
+	if !ok {
+		s.mu.Unlock()
+
+		if !ban {
+			l.RepoWarn(apperrors.InvalidControlErr, map[string]any{"session_id": s.id, "user_id": event.UserID,
+				"target_id": userID})
+			s.replyErr(ctx, event, responses.ErrInvalidControlWS)
+
+			return
+		}
+
+		s.broadcastSessionState(ctx, action, userID)
+
+		return
+	}
+
+	if p.graceTimer != nil {
+		p.graceTimer.Stop()
+	}
+
+	connID := p.ConnID
+	s.removeParticipant(userID)
+
+	s.mu.Unlock()
+
+	reason := responses.ErrKickedWS
+	if ban {
+		reason = responses.ErrBannedWS
+	}
+
+	s.rejectConn(ctx, connID, reason)
+	s.sendParticipantsInfo(ctx, userID, models.Disconnected)
+	s.broadcastSessionState(ctx, action, userID)
+}
+
+// transferAdmin передаёт права ведущего подключенному участнику, прежний ведущий становится игроком
+func (s *session) transferAdmin(ctx context.Context, event *tableEvent, userID int) {
+	l := logger.FromContext(ctx)
+
+	s.mu.Lock()
+
+	target, ok := s.participants[userID]
+	if !ok || target.graceTimer != nil {
+		s.mu.Unlock()
+
+		l.RepoWarn(apperrors.InvalidControlErr, map[string]any{"session_id": s.id, "user_id": event.UserID,
+			"target_id": userID})
+		s.replyErr(ctx, event, responses.ErrInvalidControlWS)
+
+		return
+	}
+
+	if target.Role == models.Player {
+		s.playersNum--
+	}
+
+	target.Role = models.Admin
+
+	var oldConnID string
+	if old, ok := s.participants[s.adminID]; ok {
+		old.Role = models.Player
+		oldConnID = old.ConnID
+		s.playersNum++
+	}
+
+	if _, ok := s.members[s.adminID]; !ok {
+		s.members[s.adminID] = models.MemberGrant{Role: models.Player}
+	}
+
+	s.adminID = target.ID
+	s.adminName = target.Name
+	s.dirty = true
+
+	newConnID := target.ConnID
+
+	s.mu.Unlock()
+
+	s.sendParticipantsInfo(ctx, userID, models.RoleChanged)
+	s.broadcastSessionState(ctx, models.TransferAdmin, userID)
+
+	// Скрытые поля энкаунтера и участники боя теперь видны только новому ведущему
+	s.writeFirstMsg(ctx, newConnID, userID)
+	s.writeInitiative(ctx, newConnID, userID)
+	s.writeJoinRequests(ctx, newConnID, userID)
+	if oldConnID != "" {
+		s.writeFirstMsg(ctx, oldConnID, event.UserID)
+		s.writeInitiative(ctx, oldConnID, event.UserID)
+	}
+}
+
+func (s *session) broadcastSessionState(ctx context.Context, action models.SessionAction, userID int) {
+	msg := s.sessionState()
+	msg.Action = action
+	msg.UserID = userID
+
+	s.sendToAll(ctx, models.SessionState, msg)
+}
+
+func (s *session) writeSessionState(ctx context.Context, connID string) {
+	s.sendToConn(ctx, connID, models.SessionState, s.sessionState())
+}
+
+func (s *session) sessionState() *models.SessionStateMsg {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return &models.SessionStateMsg{
+		AdminID:   s.adminID,
+		AdminName: s.adminName,
+		Paused:    s.paused,
+	}
+}
+
+func (s *session) currentAdminID() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.adminID
+}
+
+func (s *session) isBanned(userID int) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return slices.Contains(s.banned, userID)
+}
+
+// replyErr отвечает ошибкой автору события. У команд, пришедших через REST, нет соединения,
+// поэтому ошибка отправляется во все соединения автора
+func (s *session) replyErr(ctx context.Context, event *tableEvent, message string) {
+	if event.ConnID != "" {
+		s.sendErrToConn(ctx, event.ConnID, message)
+		return
+	}
+
+	s.sendNumbered(ctx, &outboundMsg{UserID: event.UserID}, func(seq int64) ([]byte, error) {
+		return responses.MarshalWSSeqErrResponse(seq, message)
+	})
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"encoding/json"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+)
+
+// Сообщения, которыми обмениваются реплики через TablePubSub. Входящие события от клиентов
+// публикуются в канал inbound и обрабатываются репликой-владельцем сессии, готовые WS-сообщения
+// владелец публикует в канал outbound, откуда их забирают хабы всех реплик с подключенными клиентами
+
+type tableEventKind string
+
+const (
+	eventJoin    tableEventKind = "join"
+	eventLeave   tableEventKind = "leave"
+	eventMessage tableEventKind = "message"
+	eventControl tableEventKind = "control" // Команда ведущего, пришедшая через REST
+)
+
+type tableEvent struct {
+	Kind    tableEventKind       `json:"kind"`
+	ConnID  string               `json:"connID"`
+	UserID  int                  `json:"userID"`
+	Name    string               `json:"name,omitempty"`
+	Role    models.Role          `json:"role,omitempty"`    // Роль, с которой участник просит подключиться
+	LastSeq int64                `json:"lastSeq,omitempty"` // Номер последнего полученного сообщения при переподключении
+	Invite  *models.InviteClaims `json:"invite,omitempty"`
+	Payload json.RawMessage      `json:"payload,omitempty"`
+}
+
+type outboundMsg struct {
+	// Seq совпадает с номером сообщения в Payload, у служебных сообщений не задан
+	Seq int64 `json:"seq,omitempty"`
+
+	// Адресат сообщения: конкретное соединение, пользователь или все участники, если поля пустые
+	ConnID string `json:"connID,omitempty"`
+	UserID int    `json:"userID,omitempty"`
+
+	// ExceptUserID исключает пользователя из рассылки всем участникам
+	ExceptUserID int `json:"exceptUserID,omitempty"`
+
+	Payload json.RawMessage `json:"payload,omitempty"`
+
+	// CloseCode, если задан, закрывает соединение адресата после отправки сообщения
+	CloseCode int    `json:"closeCode,omitempty"`
+	CloseText string `json:"closeText,omitempty"`
+
+	// Ended сообщает хабам о завершении сессии, Rejoin просит заново объявить свои соединения
+	// новому владельцу сессии
+	Ended  bool `json:"ended,omitempty"`
+	Rejoin bool `json:"rejoin,omitempty"`
+
+	// Admit отмечает соединение ConnID допущенным в сессию: до этого ему доставляются только
+	// адресованные лично сообщения
+	Admit bool `json:"admit,omitempty"`
+}
+
+func inboundChannel(sessionID string) string {
+	return "table:" + sessionID + ":in"
+}
+
+func outboundChannel(sessionID string) string {
+	return "table:" + sessionID + ":out"
+}
+
+func (m *outboundMsg) matches(connID string, userID int) bool {
+	switch {
+	case m.ConnID != "":
+		return m.ConnID == connID
+	case m.UserID != 0:
+		return m.UserID == userID
+	case m.ExceptUserID != 0:
+		return m.ExceptUserID != userID
+	default:
+		return true
+	}
+}
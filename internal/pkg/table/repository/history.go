@@ -0,0 +1,78 @@
+package repository
+
+import (
+	"encoding/json"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+)
+
+// Ключи, под которыми история сессии сохраняется в данных энкаунтера. Пока сессия идёт, история
+// хранится отдельно и в BattleInfo не попадает
+const (
+	rollHistoryKey = "_rollHistory"
+	chatHistoryKey = "_chatHistory"
+)
+
+// extractHistory отделяет историю, сохранённую под ключом key, от данных энкаунтера
+func extractHistory[T any](data []byte, key string) ([]byte, []T, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, nil, err
+	}
+
+	rawHistory, ok := fields[key]
+	if !ok {
+		return data, nil, nil
+	}
+
+	var history []T
+	if err := json.Unmarshal(rawHistory, &history); err != nil {
+		return nil, nil, err
+	}
+
+	delete(fields, key)
+
+	stripped, err := json.Marshal(fields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return stripped, history, nil
+}
+
+// withHistory возвращает данные энкаунтера вместе с историей для сохранения в базу.
+// Если энкаунтер не является JSON-объектом, история не сохраняется
+func withHistory[T any](data []byte, key string, history []T) ([]byte, error) {
+	if len(history) == 0 {
+		return data, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil || fields == nil {
+		return data, nil
+	}
+
+	rawHistory, err := json.Marshal(history)
+	if err != nil {
+		return nil, err
+	}
+
+	fields[key] = rawHistory
+
+	return json.Marshal(fields)
+}
+
+// extractSessionHistory отделяет от данных энкаунтера историю бросков и чата прошлых сессий
+func extractSessionHistory(data []byte) ([]byte, []models.RollMsg, []models.ChatMsg, error) {
+	data, rolls, err := extractHistory[models.RollMsg](data, rollHistoryKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	data, chat, err := extractHistory[models.ChatMsg](data, chatHistoryKey)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return data, rolls, chat, nil
+}
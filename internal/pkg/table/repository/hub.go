@@ -0,0 +1,150 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/metrics"
+	tableinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table"
+	"github.com/gorilla/websocket"
+)
+
+// sessionHub доставляет сообщения сессии клиентам, подключенным к этой реплике
+type sessionHub struct {
+	sessionID string
+	conns     map[string]*hubConn // Ключ - ConnID
+	mu        sync.RWMutex
+
+	pubsub  tableinterfaces.TablePubSub
+	cancel  context.CancelFunc
+	onEnd   func()
+	metrics metrics.WSSessionMetrics
+}
+
+func (h *sessionHub) run(ctx context.Context, outbound <-chan []byte) {
+	l := logger.FromContext(ctx)
+
+	for raw := range outbound {
+		var msg outboundMsg
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			l.RepoError(err, map[string]any{"session_id": h.sessionID})
+			continue
+		}
+
+		switch {
+		case msg.Ended:
+			h.closeAll()
+			h.onEnd()
+
+			return
+		case msg.Rejoin:
+			h.rejoin(ctx)
+		case msg.Admit:
+			h.admit(msg.ConnID)
+		default:
+			h.deliver(ctx, &msg)
+		}
+	}
+}
+
+// deliver раскладывает сообщение по очередям соединений. Запись в сокеты выполняют горутины соединений,
+// поэтому медленный клиент не задерживает остальных: при переполнении очереди его соединение закрывается
+func (h *sessionHub) deliver(ctx context.Context, msg *outboundMsg) {
+	l := logger.FromContext(ctx)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for id, c := range h.conns {
+		if !msg.matches(id, c.userID) || (!c.admitted && msg.ConnID == "") {
+			continue
+		}
+
+		if !c.enqueue(outboundFrame{payload: msg.Payload, closeCode: msg.CloseCode, closeText: msg.CloseText}) {
+			l.RepoWarn(apperrors.SlowConsumerErr, map[string]any{"session_id": h.sessionID, "user_id": c.userID})
+			h.metrics.IncSlowConsumers()
+			c.close()
+
+			delete(h.conns, id)
+
+			continue
+		}
+
+		if msg.CloseCode != 0 {
+			delete(h.conns, id)
+		}
+	}
+}
+
+// rejoin заново объявляет локальные соединения, когда сессию подхватила другая реплика
+func (h *sessionHub) rejoin(ctx context.Context) {
+	h.mu.RLock()
+	events := make([]*tableEvent, 0, len(h.conns))
+	for id, c := range h.conns {
+		events = append(events, &tableEvent{Kind: eventJoin, ConnID: id, UserID: c.userID, Name: c.name,
+			Role: c.role, Invite: c.invite})
+	}
+	h.mu.RUnlock()
+
+	for _, event := range events {
+		h.publish(ctx, event)
+	}
+}
+
+func (h *sessionHub) publish(ctx context.Context, event *tableEvent) {
+	l := logger.FromContext(ctx)
+
+	raw, err := json.Marshal(event)
+	if err != nil {
+		l.RepoError(err, map[string]any{"session_id": h.sessionID})
+		return
+	}
+
+	h.pubsub.Publish(ctx, inboundChannel(h.sessionID), raw)
+}
+
+func (h *sessionHub) admit(connID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if c, ok := h.conns[connID]; ok {
+		c.admitted = true
+	}
+}
+
+func (h *sessionHub) addConn(c *hubConn) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.conns[c.id] = c
+}
+
+func (h *sessionHub) removeConn(connID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.conns, connID)
+}
+
+func (h *sessionHub) isEmpty() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.conns) == 0
+}
+
+func (h *sessionHub) closeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for id, c := range h.conns {
+		if !c.enqueue(outboundFrame{closeCode: websocket.CloseNormalClosure, closeText: sessionEndedText}) {
+			c.close()
+		}
+
+		delete(h.conns, id)
+	}
+}
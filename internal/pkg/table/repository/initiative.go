@@ -0,0 +1,223 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/delivery/responses"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table/initiative"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils/dice"
+	"github.com/google/uuid"
+)
+
+// handleInitiative управляет порядком ходов. Состав участников, инициативу и смену хода меняет
+// ведущий, отложить ход и подготовить действие может также игрок, управляющий участником
+func (s *session) handleInitiative(ctx context.Context, event *tableEvent, data json.RawMessage) {
+	l := logger.FromContext(ctx)
+
+	var req models.InitiativeRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		l.RepoWarn(apperrors.InvalidInitiativeErr, map[string]any{"session_id": s.id, "user_id": event.UserID})
+		s.sendErrToConn(ctx, event.ConnID, responses.ErrInvalidInitiativeWS)
+
+		return
+	}
+
+	if req.Action == models.AddCombatant && event.UserID == s.adminID {
+		if err := s.prepareCombatant(ctx, req.Combatant); err != nil {
+			l.RepoWarn(apperrors.InvalidInitiativeErr, map[string]any{"session_id": s.id,
+				"user_id": event.UserID, "err": err.Error()})
+			s.sendErrToConn(ctx, event.ConnID, responses.ErrInvalidInitiativeWS)
+
+			return
+		}
+	}
+
+	s.mu.Lock()
+
+	if !s.canControlCombatant(event.UserID, req.Action, req.ID) {
+		s.mu.Unlock()
+		l.RepoWarn(apperrors.PermissionDeniedError, map[string]any{"session_id": s.id, "user_id": event.UserID,
+			"action": req.Action})
+		s.sendErrToConn(ctx, event.ConnID, responses.ErrInitiativeForbiddenWS)
+
+		return
+	}
+
+	before := s.initiative.State()
+
+	if err := s.applyInitiative(&req); err != nil {
+		s.mu.Unlock()
+		l.RepoWarn(apperrors.InvalidInitiativeErr, map[string]any{"session_id": s.id, "user_id": event.UserID,
+			"action": req.Action, "err": err.Error()})
+		s.sendErrToConn(ctx, event.ConnID, responses.ErrInvalidInitiativeWS)
+
+		return
+	}
+
+	after := s.initiative.State()
+	current, _ := s.initiative.Current()
+
+	var turn *models.TurnMsg
+	if after.CurrentID != before.CurrentID || after.Round != before.Round {
+		turn = &models.TurnMsg{Round: after.Round, CurrentID: after.CurrentID}
+		if current != nil {
+			c := *current
+			turn.Combatant = &c
+		}
+	}
+
+	s.dirty = true
+
+	s.mu.Unlock()
+
+	s.touch()
+
+	// Смена хода рассылается отдельным сообщением, остальные изменения — полным состоянием очереди.
+	// Ведущий получает очередь целиком, остальные участники — без скрытых участников боя
+	if req.Action != models.NextTurn && req.Action != models.PrevTurn {
+		s.send(ctx, &outboundMsg{UserID: s.adminID}, models.Initiative, after)
+		s.send(ctx, &outboundMsg{ExceptUserID: s.adminID}, models.Initiative, publicInitiative(after))
+	}
+
+	if turn != nil {
+		s.send(ctx, &outboundMsg{UserID: s.adminID}, models.Turn, turn)
+		s.send(ctx, &outboundMsg{ExceptUserID: s.adminID}, models.Turn, publicTurn(turn))
+	}
+}
+
+// publicInitiative возвращает очередь без участников боя, скрытых от игроков. Если ходит скрытый
+// участник, CurrentID не задан, а Turn указывает на место, где он стоял бы в очереди
+func publicInitiative(state *models.InitiativeState) *models.InitiativeState {
+	public := &models.InitiativeState{
+		Combatants: make([]models.Combatant, 0, len(state.Combatants)),
+		Round:      state.Round,
+	}
+
+	for i, c := range state.Combatants {
+		if i == state.Turn {
+			public.Turn = len(public.Combatants)
+		}
+
+		if c.DMOnly {
+			continue
+		}
+
+		if c.ID == state.CurrentID {
+			public.CurrentID = c.ID
+		}
+
+		public.Combatants = append(public.Combatants, c)
+	}
+
+	return public
+}
+
+// publicTurn скрывает от игроков, какой скрытый участник боя сейчас ходит
+func publicTurn(turn *models.TurnMsg) *models.TurnMsg {
+	if turn.Combatant == nil || !turn.Combatant.DMOnly {
+		return turn
+	}
+
+	return &models.TurnMsg{Round: turn.Round}
+}
+
+// prepareCombatant заполняет идентификатор и модификатор ловкости нового участника. Выполняется
+// до захвата s.mu, так как обращается к бестиарию и карточкам персонажей
+func (s *session) prepareCombatant(ctx context.Context, c *models.Combatant) error {
+	if c == nil {
+		return initiative.ErrInvalidCombatant
+	}
+
+	if c.ID == "" {
+		c.ID = uuid.NewString()
+	}
+
+	if c.Kind == "" {
+		c.Kind = models.CustomCombatant
+	}
+
+	c.Roll = nil
+	c.Delayed = false
+	c.Ready = false
+
+	if c.Kind == models.CustomCombatant || c.SourceID == "" {
+		return nil
+	}
+
+	if s.stats == nil {
+		return errors.New("combatant stats provider is not configured")
+	}
+
+	dex, err := s.stats.GetDexModifier(ctx, c.Kind, c.SourceID)
+	if err != nil {
+		return err
+	}
+
+	c.DexModifier = dex
+
+	return nil
+}
+
+// canControlCombatant проверяет права участника сессии на действие с очередью. Вызывается под s.mu
+func (s *session) canControlCombatant(userID int, action models.InitiativeAction, combatantID string) bool {
+	if userID == s.adminID {
+		return true
+	}
+
+	switch action {
+	case models.DelayTurn, models.ResumeTurn, models.ReadyAction:
+		c, ok := s.initiative.Get(combatantID)
+		return ok && c.OwnerID == userID
+	default:
+		return false
+	}
+}
+
+// applyInitiative выполняет действие над очередью. Вызывается под s.mu
+func (s *session) applyInitiative(req *models.InitiativeRequest) error {
+	switch req.Action {
+	case models.AddCombatant:
+		return s.initiative.Add(*req.Combatant)
+	case models.RemoveCombatant:
+		return s.initiative.Remove(req.ID)
+	case models.SetInitiative:
+		return s.initiative.SetInitiative(req.ID, req.Initiative)
+	case models.RollInitiative:
+		return s.initiative.RollAll(dice.CryptoRNG)
+	case models.NextTurn:
+		return s.initiative.Next()
+	case models.PrevTurn:
+		return s.initiative.Prev()
+	case models.DelayTurn:
+		return s.initiative.Delay(req.ID)
+	case models.ResumeTurn:
+		return s.initiative.Resume(req.ID)
+	case models.ReadyAction:
+		return s.initiative.Ready(req.ID)
+	default:
+		return apperrors.InvalidInitiativeErr
+	}
+}
+
+// writeInitiative отправляет подключившемуся участнику текущий порядок ходов, если он задан
+func (s *session) writeInitiative(ctx context.Context, connID string, userID int) {
+	s.mu.RLock()
+	state := s.initiative.State()
+	adminID := s.adminID
+	s.mu.RUnlock()
+
+	if userID != adminID {
+		state = publicInitiative(state)
+	}
+
+	if len(state.Combatants) == 0 {
+		return
+	}
+
+	s.sendToConn(ctx, connID, models.Initiative, state)
+}
@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table/initiative"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPublicInitiative(t *testing.T) {
+	t.Parallel()
+
+	combatants := []models.Combatant{{ID: "a"}, {ID: "hidden", DMOnly: true}, {ID: "b"}}
+
+	tests := []struct {
+		name  string
+		state *models.InitiativeState
+		want  *models.InitiativeState
+	}{
+		{
+			name:  "visible current combatant keeps its place",
+			state: &models.InitiativeState{Combatants: combatants, Round: 1, Turn: 2, CurrentID: "b"},
+			want: &models.InitiativeState{Combatants: []models.Combatant{{ID: "a"}, {ID: "b"}}, Round: 1, Turn: 1,
+				CurrentID: "b"},
+		},
+		{
+			name:  "hidden current combatant is not revealed",
+			state: &models.InitiativeState{Combatants: combatants, Round: 2, Turn: 1, CurrentID: "hidden"},
+			want:  &models.InitiativeState{Combatants: []models.Combatant{{ID: "a"}, {ID: "b"}}, Round: 2, Turn: 1},
+		},
+		{
+			name:  "battle not started",
+			state: &models.InitiativeState{Combatants: combatants},
+			want:  &models.InitiativeState{Combatants: []models.Combatant{{ID: "a"}, {ID: "b"}}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tc.want, publicInitiative(tc.state))
+		})
+	}
+}
+
+func TestSession_InitiativeHidesDMOnlyCombatants(t *testing.T) {
+	t.Parallel()
+
+	tt := newTestTable(t, `{}`)
+	tt.joinAdmin()
+	tt.joinPlayer(2, "conn-2")
+
+	out := tt.send(testAdminID, testAdminConn, models.Initiative, &models.InitiativeRequest{
+		Action:    models.AddCombatant,
+		Combatant: &models.Combatant{ID: "assassin", Name: "Assassin", DMOnly: true},
+	})
+
+	msg, ok := findMsg(received(t, out, testAdminConn, testAdminID), models.Initiative)
+	assert.True(t, ok)
+	assert.Len(t, decodeData[models.InitiativeState](t, msg).Combatants, 1)
+
+	msg, ok = findMsg(received(t, out, "conn-2", 2), models.Initiative)
+	assert.True(t, ok)
+	assert.Empty(t, decodeData[models.InitiativeState](t, msg).Combatants)
+
+	// On reconnect the game master gets the hidden combatant, a player gets no turn order at all
+	tt.s.writeInitiative(tt.ctx, testAdminConn, testAdminID)
+	_, ok = findMsg(received(t, tt.drain(), testAdminConn, testAdminID), models.Initiative)
+	assert.True(t, ok)
+
+	tt.s.writeInitiative(tt.ctx, "conn-2", 2)
+	_, ok = findMsg(received(t, tt.drain(), "conn-2", 2), models.Initiative)
+	assert.False(t, ok)
+}
+
+func TestSession_TurnOfDMOnlyCombatant(t *testing.T) {
+	t.Parallel()
+
+	tt := newTestTable(t, `{}`)
+	tt.s.initiative = initiative.NewTracker(&models.InitiativeState{
+		Combatants: []models.Combatant{{ID: "fighter", Name: "Fighter"}, {ID: "assassin", Name: "Assassin",
+			DMOnly: true}},
+		Round:     1,
+		CurrentID: "fighter",
+	})
+	tt.joinAdmin()
+	tt.joinPlayer(2, "conn-2")
+
+	out := tt.send(testAdminID, testAdminConn, models.Initiative, &models.InitiativeRequest{Action: models.NextTurn})
+
+	msg, ok := findMsg(received(t, out, testAdminConn, testAdminID), models.Turn)
+	assert.True(t, ok)
+	turn := decodeData[models.TurnMsg](t, msg)
+	assert.Equal(t, "assassin", turn.CurrentID)
+	assert.Equal(t, "Assassin", turn.Combatant.Name)
+
+	msg, ok = findMsg(received(t, out, "conn-2", 2), models.Turn)
+	assert.True(t, ok)
+	assert.Equal(t, models.TurnMsg{Round: 1}, decodeData[models.TurnMsg](t, msg))
+}
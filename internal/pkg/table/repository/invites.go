@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/delivery/responses"
+)
+
+// inviteUsable проверяет, что приглашение выдано в эту сессию и у него остались использования.
+// Вызывается под s.mu
+func (s *session) inviteUsable(invite *models.InviteClaims) bool {
+	if invite.SessionID != s.id {
+		return false
+	}
+
+	return invite.MaxUses == 0 || s.inviteUses[invite.ID] < invite.MaxUses
+}
+
+// requestJoin ставит подключение без приглашения в очередь на одобрение ведущим
+func (s *session) requestJoin(ctx context.Context, event *tableEvent) {
+	s.mu.Lock()
+	prev, replaced := s.pending[event.UserID]
+	s.pending[event.UserID] = event
+	s.mu.Unlock()
+
+	if replaced && prev.ConnID != event.ConnID {
+		s.rejectConn(ctx, prev.ConnID, responses.ErrConnReplacedWS)
+	}
+
+	msg := joinRequestMsg(event, models.JoinPending)
+
+	s.send(ctx, &outboundMsg{UserID: s.adminID}, models.JoinRequest, msg)
+	s.sendToConn(ctx, event.ConnID, models.JoinRequest, msg)
+}
+
+// cancelJoinRequest убирает запрос пользователя, отключившегося до решения ведущего
+func (s *session) cancelJoinRequest(ctx context.Context, event *tableEvent) {
+	s.mu.Lock()
+
+	pending, ok := s.pending[event.UserID]
+	if !ok || pending.ConnID != event.ConnID {
+		s.mu.Unlock()
+		return
+	}
+
+	delete(s.pending, event.UserID)
+
+	s.mu.Unlock()
+
+	s.send(ctx, &outboundMsg{UserID: s.adminID}, models.JoinRequest, joinRequestMsg(pending, models.JoinCancelled))
+}
+
+func (s *session) handleJoinDecision(ctx context.Context, event *tableEvent, data json.RawMessage) {
+	l := logger.FromContext(ctx)
+
+	if event.UserID != s.adminID {
+		l.RepoWarn(apperrors.PermissionDeniedError, map[string]any{"session_id": s.id, "user_id": event.UserID})
+		s.sendErrToConn(ctx, event.ConnID, responses.ErrControlForbiddenWS)
+
+		return
+	}
+
+	var req models.JoinDecisionRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		l.RepoWarn(apperrors.InvalidControlErr, map[string]any{"session_id": s.id, "user_id": event.UserID})
+		s.sendErrToConn(ctx, event.ConnID, responses.ErrInvalidJoinDecisionWS)
+
+		return
+	}
+
+	s.mu.Lock()
+
+	pending, ok := s.pending[req.UserID]
+	if !ok {
+		s.mu.Unlock()
+
+		l.RepoWarn(apperrors.InvalidControlErr, map[string]any{"session_id": s.id, "user_id": event.UserID,
+			"target_id": req.UserID})
+		s.sendErrToConn(ctx, event.ConnID, responses.ErrInvalidJoinDecisionWS)
+
+		return
+	}
+
+	delete(s.pending, req.UserID)
+
+	if !req.Accept {
+		s.mu.Unlock()
+
+		l.RepoInfo("join request denied", map[string]any{"session_id": s.id, "user_id": req.UserID})
+		s.send(ctx, &outboundMsg{UserID: s.adminID}, models.JoinRequest,
+			joinRequestMsg(pending, models.JoinDenied))
+		s.rejectConn(ctx, pending.ConnID, responses.ErrJoinDeniedWS)
+
+		return
+	}
+
+	// Пользователь мог успеть войти по приглашению с другого соединения
+	if _, exists := s.participants[req.UserID]; exists {
+		s.mu.Unlock()
+		s.rejectConn(ctx, pending.ConnID, responses.ErrUserAlreadyExistsWS)
+
+		return
+	}
+
+	l.RepoInfo("join request accepted", map[string]any{"session_id": s.id, "user_id": req.UserID})
+	s.send(ctx, &outboundMsg{UserID: s.adminID}, models.JoinRequest, joinRequestMsg(pending, models.JoinAccepted))
+
+	s.admit(ctx, pending)
+}
+
+// writeJoinRequests отправляет ведущему запросы, ожидающие решения
+func (s *session) writeJoinRequests(ctx context.Context, connID string, userID int) {
+	if userID != s.adminID {
+		return
+	}
+
+	s.mu.RLock()
+	requests := make([]*models.JoinRequestMsg, 0, len(s.pending))
+	for _, pending := range s.pending {
+		requests = append(requests, joinRequestMsg(pending, models.JoinPending))
+	}
+	s.mu.RUnlock()
+
+	for _, request := range requests {
+		s.sendToConn(ctx, connID, models.JoinRequest, request)
+	}
+}
+
+// markAdmitted разрешает хабам доставлять соединению сообщения для всех участников
+func (s *session) markAdmitted(ctx context.Context, connID string) {
+	s.publish(ctx, &outboundMsg{ConnID: connID, Admit: true})
+}
+
+// isParticipantConn сообщает, что соединение принадлежит допущенному в сессию участнику
+func (s *session) isParticipantConn(userID int, connID string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	p, ok := s.participants[userID]
+
+	return ok && p.ConnID == connID
+}
+
+func joinRequestMsg(event *tableEvent, status models.JoinRequestStatus) *models.JoinRequestMsg {
+	role := event.Role
+	if role == "" {
+		role = models.Player
+	}
+
+	return &models.JoinRequestMsg{
+		UserID: event.UserID,
+		Name:   event.Name,
+		Role:   role,
+		Status: status,
+	}
+}
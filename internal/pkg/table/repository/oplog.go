@@ -0,0 +1,251 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"time"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/delivery/responses"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils/merger"
+)
+
+const (
+	// maxOperations — сколько последних операций журнала сессия держит в памяти для отмены. Более старые
+	// сохраняются в table_operation_log, применяются к initialData и удаляются из памяти, отменить их
+	// уже нельзя
+	maxOperations = 1000
+	// archiveBatch — сколько операций сверх maxOperations копится перед сохранением в базу
+	archiveBatch = 100
+)
+
+// appendOperation добавляет запись в журнал сессии. Вызывается под s.mu
+func (s *session) appendOperation(op models.TableOperation) {
+	s.revision++
+
+	op.Seq = s.revision
+	op.Timestamp = time.Now()
+
+	s.operations = append(s.operations, op)
+}
+
+// archiveOperations сохраняет в table_operation_log операции сверх maxOperations и удаляет их из памяти.
+// Если сохранить не удалось, операции остаются в журнале сессии до следующей попытки. Вызывается
+// из горутины run без s.mu
+func (s *session) archiveOperations(ctx context.Context) {
+	s.mu.RLock()
+
+	count := len(s.operations) - maxOperations
+	if count < archiveBatch {
+		s.mu.RUnlock()
+		return
+	}
+
+	opLog := &models.TableOperationLog{
+		SessionID:   s.id,
+		EncounterID: s.encounterID,
+		AdminID:     s.adminID,
+		InitialData: s.initialData,
+		Operations:  slices.Clone(s.operations[:count]),
+		StartedAt:   s.start,
+	}
+
+	s.mu.RUnlock()
+
+	if err := s.logRepo.AppendOperations(ctx, opLog); err != nil {
+		return
+	}
+
+	// Журнал меняется только в горутине run, поэтому удаляются те же операции, что были сохранены
+	s.mu.Lock()
+	s.compactOperations(ctx, count)
+	s.mu.Unlock()
+}
+
+// compactOperations применяет первые count операций журнала к initialData и удаляет их.
+// Вызывается под s.mu
+func (s *session) compactOperations(ctx context.Context, count int) {
+	l := logger.FromContext(ctx)
+
+	data := s.initialData
+
+	for _, op := range s.operations[:count] {
+		if op.Kind != models.PatchOperation || op.Undone {
+			continue
+		}
+
+		merged, err := merger.Apply(op.Format, data, op.Patch)
+		if err != nil {
+			l.RepoError(err, map[string]any{"session_id": s.id, "seq": op.Seq})
+			continue
+		}
+
+		data = merged
+	}
+
+	s.initialData = data
+	s.operations = slices.Delete(s.operations, 0, count)
+}
+
+func (s *session) handleUndo(ctx context.Context, event *tableEvent, data json.RawMessage) {
+	l := logger.FromContext(ctx)
+
+	var req models.UndoRequest
+	if err := json.Unmarshal(data, &req); err != nil || req.Count <= 0 {
+		l.RepoWarn(apperrors.InvalidUndoErr, map[string]any{"session_id": s.id, "user_id": event.UserID})
+		s.sendErrToConn(ctx, event.ConnID, responses.ErrInvalidUndoWS)
+
+		return
+	}
+
+	if event.UserID != s.adminID {
+		l.RepoWarn(apperrors.PermissionDeniedError, map[string]any{"session_id": s.id, "user_id": event.UserID})
+		s.sendErrToConn(ctx, event.ConnID, responses.ErrUndoForbiddenWS)
+
+		return
+	}
+
+	s.touch()
+
+	s.mu.Lock()
+
+	reverts := make([]int, 0, req.Count)
+	for i := len(s.operations) - 1; i >= 0 && len(reverts) < req.Count; i-- {
+		op := &s.operations[i]
+		if op.Kind != models.PatchOperation || op.Undone {
+			continue
+		}
+
+		op.Undone = true
+		reverts = append(reverts, op.Seq)
+	}
+
+	if len(reverts) == 0 {
+		s.mu.Unlock()
+		return
+	}
+
+	slices.Reverse(reverts)
+
+	prevData := s.encounterData
+
+	s.encounterData = s.replay(ctx)
+	s.appendOperation(models.TableOperation{AuthorID: event.UserID, Kind: models.UndoOperation,
+		Reverts: reverts})
+	s.dirty = true
+
+	encounterData := s.encounterData
+	revision := s.revision
+
+	s.mu.Unlock()
+
+	s.broadcastBattleInfo(ctx, prevData, encounterData, revision)
+	s.archiveOperations(ctx)
+}
+
+// replay заново применяет к исходным данным все неотменённые патчи. Вызывается под s.mu
+func (s *session) replay(ctx context.Context) []byte {
+	l := logger.FromContext(ctx)
+
+	data := s.initialData
+
+	for _, op := range s.operations {
+		if op.Kind != models.PatchOperation || op.Undone {
+			continue
+		}
+
+		merged, err := merger.Apply(op.Format, data, op.Patch)
+		if err != nil {
+			l.RepoError(err, map[string]any{"session_id": s.id, "seq": op.Seq})
+			continue
+		}
+
+		data = merged
+	}
+
+	return data
+}
+
+// conflictingPaths возвращает пути патча, изменённые операциями после baseRevision. Если операции
+// после baseRevision уже удалены из журнала, конфликтными считаются все пути. Вызывается под s.mu
+func (s *session) conflictingPaths(baseRevision int, patch []byte, format models.PatchFormat) ([]string, error) {
+	if baseRevision == s.revision {
+		return nil, nil
+	}
+
+	patchPaths, err := merger.PatchPaths(format, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(s.operations) == 0 || s.operations[0].Seq > baseRevision+1 {
+		return patchPaths, nil
+	}
+
+	changed := make([]string, 0)
+	for _, op := range s.operations {
+		if op.Seq <= baseRevision {
+			continue
+		}
+
+		switch op.Kind {
+		case models.PatchOperation:
+			paths, _ := merger.PatchPaths(op.Format, op.Patch)
+			changed = append(changed, paths...)
+		case models.UndoOperation:
+			changed = append(changed, s.revertedPaths(op.Reverts)...)
+		}
+	}
+
+	conflicts := make([]string, 0)
+	for _, path := range patchPaths {
+		for _, changedPath := range changed {
+			if merger.Overlaps(path, changedPath) {
+				conflicts = append(conflicts, path)
+				break
+			}
+		}
+	}
+
+	return conflicts, nil
+}
+
+// revertedPaths возвращает пути, изменённые отменой операций seqs. Если отменённая операция уже
+// удалена из журнала, изменённым считается весь документ
+func (s *session) revertedPaths(seqs []int) []string {
+	paths := make([]string, 0)
+	found := 0
+
+	for _, op := range s.operations {
+		if op.Kind != models.PatchOperation || !slices.Contains(seqs, op.Seq) {
+			continue
+		}
+
+		opPaths, _ := merger.PatchPaths(op.Format, op.Patch)
+		paths = append(paths, opPaths...)
+		found++
+	}
+
+	if found < len(seqs) {
+		paths = append(paths, "")
+	}
+
+	return paths
+}
+
+func (s *session) GetOperationLog() *models.TableOperationLog {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return &models.TableOperationLog{
+		SessionID:   s.id,
+		EncounterID: s.encounterID,
+		AdminID:     s.adminID,
+		InitialData: s.initialData,
+		Operations:  slices.Clone(s.operations),
+		StartedAt:   s.start,
+	}
+}
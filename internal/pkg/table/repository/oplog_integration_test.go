@@ -0,0 +1,117 @@
+//go:build integration
+// +build integration
+
+package repository_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table/repository"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/testhelpers"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	setupSQL = `
+		CREATE TABLE IF NOT EXISTS public."user" (
+			id BIGINT NOT NULL GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+			display_name TEXT NOT NULL CHECK(display_name <> '')
+		);
+
+		CREATE TABLE IF NOT EXISTS public.table_operation_log (
+			session_id TEXT PRIMARY KEY,
+			encounter_id TEXT NOT NULL,
+			admin_id BIGINT NOT NULL REFERENCES public.user(id),
+			initial_data JSONB NOT NULL,
+			operations JSONB NOT NULL DEFAULT '[]'::jsonb,
+			started_at TIMESTAMPTZ NOT NULL,
+			finished_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		);
+	`
+
+	seedUserSQL = `
+		INSERT INTO public."user" (display_name) VALUES ('Integration Tester') RETURNING id;
+	`
+
+	teardownSQL = `
+		DROP TABLE IF EXISTS public.table_operation_log;
+		DROP TABLE IF EXISTS public."user";
+	`
+)
+
+func seqs(ops []models.TableOperation) []int {
+	result := make([]int, 0, len(ops))
+	for _, op := range ops {
+		result = append(result, op.Seq)
+	}
+
+	return result
+}
+
+func TestTableLogRepo_AppendAndSave(t *testing.T) {
+	dsn := os.Getenv("TEST_POSTGRES_DSN")
+	if dsn == "" {
+		t.Skip("TEST_POSTGRES_DSN not set — skipping integration test")
+	}
+
+	ctx := context.Background()
+
+	pool, err := pgxpool.New(ctx, dsn)
+	if err != nil {
+		t.Fatalf("failed to connect to postgres: %v", err)
+	}
+	defer pool.Close()
+
+	if _, err := pool.Exec(ctx, setupSQL); err != nil {
+		t.Fatalf("failed to setup schema: %v", err)
+	}
+	t.Cleanup(func() {
+		pool.Exec(ctx, teardownSQL)
+	})
+
+	var userID int
+	if err := pool.QueryRow(ctx, seedUserSQL).Scan(&userID); err != nil {
+		t.Fatalf("failed to seed test user: %v", err)
+	}
+
+	repo := repository.NewTableLogStorage(pool, testhelpers.NoopDBMetrics())
+
+	opLog := func(initialData string, from, to int) *models.TableOperationLog {
+		ops := make([]models.TableOperation, 0)
+		for seq := from; seq <= to; seq++ {
+			ops = append(ops, models.TableOperation{Seq: seq, AuthorID: userID, Kind: models.PatchOperation})
+		}
+
+		return &models.TableOperationLog{SessionID: "session-1", EncounterID: "enc-1", AdminID: userID,
+			InitialData: json.RawMessage(initialData), Operations: ops, StartedAt: time.Now()}
+	}
+
+	// Operations pushed out of a live session's memory
+	assert.NoError(t, repo.AppendOperations(ctx, opLog(`{"hp":0}`, 1, 2)))
+
+	// A session restored from an older snapshot sends operation 2 again, and its initial data has moved on
+	assert.NoError(t, repo.AppendOperations(ctx, opLog(`{"hp":2}`, 2, 3)))
+
+	got, err := repo.GetOperationLog(ctx, "session-1")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"hp":0}`, string(got.InitialData))
+	assert.Equal(t, []int{1, 2, 3}, seqs(got.Operations))
+
+	// The finished session saves the operations still kept in memory
+	finishedAt := time.Now()
+	final := opLog(`{"hp":3}`, 4, 5)
+	final.FinishedAt = &finishedAt
+	assert.NoError(t, repo.SaveOperationLog(ctx, final))
+
+	got, err = repo.GetOperationLog(ctx, "session-1")
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"hp":0}`, string(got.InitialData))
+	assert.Equal(t, []int{1, 2, 3, 4, 5}, seqs(got.Operations))
+	assert.WithinDuration(t, finishedAt, *got.FinishedAt, time.Second)
+}
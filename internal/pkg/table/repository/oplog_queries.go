@@ -0,0 +1,36 @@
+package repository
+
+const (
+	// Операции дописываются к уже сохранённым. Операции с номером не больше последнего сохранённого
+	// пропускаются: после сбоя реплики их повторно присылает сессия, восстановленная из снапшота
+	SaveOperationLogQuery = `
+		INSERT INTO public.table_operation_log (session_id, encounter_id, admin_id, initial_data, operations,
+			started_at, finished_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (session_id) DO UPDATE
+		SET operations = table_operation_log.operations || COALESCE((
+				SELECT jsonb_agg(ops.op ORDER BY ops.ord)
+				FROM jsonb_array_elements(EXCLUDED.operations) WITH ORDINALITY AS ops(op, ord)
+				WHERE (ops.op->>'seq')::int > COALESCE((table_operation_log.operations->-1->>'seq')::int, 0)
+			), '[]'::jsonb),
+			finished_at = EXCLUDED.finished_at;
+	`
+
+	AppendOperationsQuery = `
+		INSERT INTO public.table_operation_log (session_id, encounter_id, admin_id, initial_data, operations,
+			started_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (session_id) DO UPDATE
+		SET operations = table_operation_log.operations || COALESCE((
+				SELECT jsonb_agg(ops.op ORDER BY ops.ord)
+				FROM jsonb_array_elements(EXCLUDED.operations) WITH ORDINALITY AS ops(op, ord)
+				WHERE (ops.op->>'seq')::int > COALESCE((table_operation_log.operations->-1->>'seq')::int, 0)
+			), '[]'::jsonb);
+	`
+
+	GetOperationLogQuery = `
+		SELECT session_id, encounter_id, admin_id, initial_data, operations, started_at, finished_at
+		FROM public.table_operation_log
+		WHERE session_id = $1;
+	`
+)
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	mymetrics "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/metrics"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/repository/dbcall"
+	serverrepo "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/repository/dbinit"
+	tableinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils"
+	"github.com/jackc/pgx/v5"
+)
+
+type tableLogStorage struct {
+	pool    serverrepo.PostgresPool
+	metrics mymetrics.DBMetrics
+}
+
+func NewTableLogStorage(pool serverrepo.PostgresPool, metrics mymetrics.DBMetrics) tableinterfaces.TableLogRepository {
+	return &tableLogStorage{
+		pool:    pool,
+		metrics: metrics,
+	}
+}
+
+func (s *tableLogStorage) SaveOperationLog(ctx context.Context, log *models.TableOperationLog) error {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	operations, err := json.Marshal(log.Operations)
+	if err != nil {
+		l.RepoError(err, map[string]any{"session_id": log.SessionID})
+		return apperrors.TxError
+	}
+
+	finishedAt := time.Now()
+	if log.FinishedAt != nil {
+		finishedAt = *log.FinishedAt
+	}
+
+	err = dbcall.ErrOnlyDBCall(fnName, s.metrics, func() error {
+		_, err := s.pool.Exec(ctx, SaveOperationLogQuery, log.SessionID, log.EncounterID, log.AdminID,
+			[]byte(log.InitialData), operations, log.StartedAt, finishedAt)
+
+		return err
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"session_id": log.SessionID})
+		return apperrors.TxError
+	}
+
+	return nil
+}
+
+// AppendOperations сохраняет операции идущей сессии. InitialData записывается, только если журнала
+// сессии ещё нет в базе
+func (s *tableLogStorage) AppendOperations(ctx context.Context, log *models.TableOperationLog) error {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	operations, err := json.Marshal(log.Operations)
+	if err != nil {
+		l.RepoError(err, map[string]any{"session_id": log.SessionID})
+		return apperrors.TxError
+	}
+
+	err = dbcall.ErrOnlyDBCall(fnName, s.metrics, func() error {
+		_, err := s.pool.Exec(ctx, AppendOperationsQuery, log.SessionID, log.EncounterID, log.AdminID,
+			[]byte(log.InitialData), operations, log.StartedAt)
+
+		return err
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"session_id": log.SessionID})
+		return apperrors.TxError
+	}
+
+	return nil
+}
+
+func (s *tableLogStorage) GetOperationLog(ctx context.Context, sessionID string) (*models.TableOperationLog, error) {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	var (
+		log        models.TableOperationLog
+		operations []byte
+		finishedAt time.Time
+	)
+
+	_, err := dbcall.DBCall[*models.TableOperationLog](fnName, s.metrics, func() (*models.TableOperationLog, error) {
+		line := s.pool.QueryRow(ctx, GetOperationLogQuery, sessionID)
+		if err := line.Scan(&log.SessionID, &log.EncounterID, &log.AdminID, &log.InitialData, &operations,
+			&log.StartedAt, &finishedAt); err != nil {
+			return nil, err
+		}
+
+		return &log, nil
+	})
+	if err != nil && errors.Is(err, pgx.ErrNoRows) {
+		l.RepoWarn(err, map[string]any{"session_id": sessionID})
+		return nil, apperrors.TableNotFoundErr
+	} else if err != nil {
+		l.RepoError(err, map[string]any{"session_id": sessionID})
+		return nil, apperrors.ScanError
+	}
+
+	if err := json.Unmarshal(operations, &log.Operations); err != nil {
+		l.RepoError(err, map[string]any{"session_id": sessionID})
+		return nil, apperrors.ScanError
+	}
+
+	log.FinishedAt = &finishedAt
+
+	return &log, nil
+}
@@ -2,104 +2,255 @@ package repository
 
 import (
 	"context"
-	"fmt"
+	"time"
+
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/delivery/responses"
-	"github.com/gorilla/websocket"
 )
 
-func (s *session) AddParticipant(ctx context.Context, userID int, name string, conn *websocket.Conn) error {
+func (s *session) handleJoin(ctx context.Context, event *tableEvent) {
+	l := logger.FromContext(ctx)
+
+	if s.isBanned(event.UserID) {
+		l.RepoWarn(apperrors.BannedErr, map[string]any{"session_id": s.id, "user_id": event.UserID})
+		s.rejectConn(ctx, event.ConnID, responses.ErrBannedWS)
+
+		return
+	}
+
 	s.mu.Lock()
 
-	if s.playersNum == maxPlayersNum {
-		return apperrors.PlayersNumErr
+	if p, ok := s.participants[event.UserID]; ok {
+		// Повторное объявление того же соединения после смены владельца сессии
+		if p.ConnID == event.ConnID {
+			s.mu.Unlock()
+			return
+		}
+
+		// Переподключение: в период ожидания после обрыва или с номером последнего сообщения, если сервер
+		// ещё не заметил обрыв старого соединения
+		if p.graceTimer != nil || event.LastSeq > 0 {
+			s.reconnect(ctx, p, event)
+			return
+		}
+
+		s.mu.Unlock()
+
+		l.RepoWarn(apperrors.UserAlreadyExistsErr, map[string]any{"session_id": s.id, "user_id": event.UserID})
+		s.rejectConn(ctx, event.ConnID, responses.ErrUserAlreadyExistsWS)
+
+		return
 	}
-	s.playersNum++
 
-	newParticipant := &participant{
+	// Без приглашения в сессию попадают только ведущий и уже допущенные участники, остальные ждут
+	// решения ведущего
+	if _, member := s.members[event.UserID]; event.UserID != s.adminID && !member {
+		if event.Invite == nil {
+			s.mu.Unlock()
+			s.requestJoin(ctx, event)
+
+			return
+		}
+
+		if !s.inviteUsable(event.Invite) {
+			s.mu.Unlock()
+
+			l.RepoWarn(apperrors.InviteExhaustedErr, map[string]any{"session_id": s.id, "user_id": event.UserID,
+				"invite_id": event.Invite.ID})
+			s.rejectConn(ctx, event.ConnID, responses.ErrInviteExhaustedWS)
+
+			return
+		}
+	}
+
+	s.admit(ctx, event)
+}
+
+// admit добавляет участника в сессию. Роль и персонаж определяются при первом допуске, повторные
+// подключения получают их же. Вызывается под s.mu, освобождает его
+func (s *session) admit(ctx context.Context, event *tableEvent) {
+	l := logger.FromContext(ctx)
+
+	grant, member := s.members[event.UserID]
+	if !member {
+		grant = models.MemberGrant{Role: models.Player}
+		if event.Role == models.Spectator {
+			grant.Role = models.Spectator
+		}
+
+		if event.Invite != nil {
+			grant.CharacterID = event.Invite.CharacterID
+		}
+	}
+
+	role := grant.Role
+	if event.UserID == s.adminID {
+		role = models.Admin
+	}
+
+	if role == models.Player {
+		if s.playersNum >= s.maxPlayers {
+			s.mu.Unlock()
+
+			l.RepoWarn(apperrors.PlayersNumErr, map[string]any{"session_id": s.id, "user_id": event.UserID})
+			s.rejectConn(ctx, event.ConnID, responses.ErrMaxPlayersWS)
+
+			return
+		}
+
+		s.playersNum++
+	}
+
+	// Использование приглашения засчитывается, только когда по нему впервые допущен участник
+	if role != models.Admin && !member {
+		s.members[event.UserID] = grant
+		s.dirty = true
+
+		if event.Invite != nil {
+			s.inviteUses[event.Invite.ID]++
+		}
+	}
+
+	s.participants[event.UserID] = &participant{
 		Participant: models.Participant{
-			ID:   userID,
-			Name: name,
-			Role: models.Player,
+			ID:          event.UserID,
+			Name:        event.Name,
+			Role:        role,
+			CharacterID: grant.CharacterID,
 		},
-		Conn: conn,
+		ConnID: event.ConnID,
 	}
-	s.participants[userID] = newParticipant
 
 	s.mu.Unlock()
 
-	s.sendParticipantsInfo(ctx, userID, models.Connected)
+	s.markAdmitted(ctx, event.ConnID)
+	s.touch()
+	s.sendParticipantsInfo(ctx, event.UserID, models.Connected)
 
-	return nil
+	s.writeState(ctx, event.ConnID, event.UserID)
+
+	// Место участника уже освобождено, поэтому вместо повтора пропущенных сообщений он получает
+	// текущее состояние сессии
+	if event.LastSeq > 0 {
+		s.sendToConn(ctx, event.ConnID, models.Resume, &models.ResumeMsg{LastSeq: event.LastSeq, Snapshot: true})
+	}
 }
 
-func (s *session) AddAdmin(ctx context.Context, userID int, name string, conn *websocket.Conn) {
-	s.mu.Lock()
+// reconnect переносит участника на новое соединение без повторного объявления о подключении.
+// Вызывается под s.mu, освобождает его
+func (s *session) reconnect(ctx context.Context, p *participant, event *tableEvent) {
+	l := logger.FromContext(ctx)
 
-	newParticipant := &participant{
-		Participant: models.Participant{
-			ID:   userID,
-			Name: name,
-			Role: models.Admin,
-		},
-		Conn: conn,
+	oldConnID := p.ConnID
+	replaced := p.graceTimer == nil
+
+	if p.graceTimer != nil {
+		p.graceTimer.Stop()
+		p.graceTimer = nil
 	}
-	s.participants[userID] = newParticipant
+
+	p.ConnID = event.ConnID
 
 	s.mu.Unlock()
 
-	s.sendParticipantsInfo(ctx, userID, models.Connected)
+	if replaced {
+		s.rejectConn(ctx, oldConnID, responses.ErrConnReplacedWS)
+	}
+
+	l.RepoInfo("participant reconnected", map[string]any{"session_id": s.id, "user_id": event.UserID,
+		"last_seq": event.LastSeq})
+
+	s.markAdmitted(ctx, event.ConnID)
+	s.touch()
+	s.writeResume(ctx, event.ConnID, event.UserID, event.LastSeq)
 }
 
-func (s *session) RemoveParticipant(ctx context.Context, userID int) {
-	l := logger.FromContext(ctx)
+func (s *session) handleLeave(ctx context.Context, event *tableEvent) {
+	s.cancelJoinRequest(ctx, event)
 
-	defer func() {
-		if err := recover(); err != nil {
-			l.RepoError(fmt.Errorf("recovered from panic after deleting participant, %v", err),
-				map[string]any{"user_id": userID})
-		}
-	}()
+	s.mu.Lock()
+
+	p, ok := s.participants[event.UserID]
+	if !ok || p.ConnID != event.ConnID || p.graceTimer != nil {
+		s.mu.Unlock()
+		return
+	}
+
+	// Место участника сохраняется на случай быстрого переподключения, остальные участники
+	// узнают об отключении, только если он не вернулся
+	if s.reconnectGrace > 0 {
+		p.graceTimer = time.AfterFunc(s.reconnectGrace, func() {
+			s.expireParticipant(ctx, event.UserID, event.ConnID)
+		})
+		s.mu.Unlock()
+
+		return
+	}
+
+	s.removeParticipant(event.UserID)
+
+	s.mu.Unlock()
+
+	s.sendParticipantsInfo(ctx, event.UserID, models.Disconnected)
+}
+
+// expireParticipant освобождает место участника, не вернувшегося за время ожидания
+func (s *session) expireParticipant(ctx context.Context, userID int, connID string) {
+	if ctx.Err() != nil {
+		return
+	}
 
 	s.mu.Lock()
 
-	if s.participants[userID].Participant.Role != models.Admin {
-		s.playersNum--
+	p, ok := s.participants[userID]
+	if !ok || p.ConnID != connID || p.graceTimer == nil {
+		s.mu.Unlock()
+		return
 	}
 
-	s.participants[userID].Conn.Close()
-	delete(s.participants, userID)
+	s.removeParticipant(userID)
 
 	s.mu.Unlock()
 
 	s.sendParticipantsInfo(ctx, userID, models.Disconnected)
 }
 
+// removeParticipant вызывается под s.mu
+func (s *session) removeParticipant(userID int) {
+	if s.participants[userID].Role == models.Player {
+		s.playersNum--
+	}
+
+	delete(s.participants, userID)
+	s.dirty = true
+}
+
 func (s *session) sendParticipantsInfo(ctx context.Context, userID int, status models.ParticipantStatus) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	s.mu.RLock()
 
-	list := make([]models.Participant, 0, maxPlayersNum+1)
+	list := make([]models.Participant, 0, len(s.participants))
 	for _, p := range s.participants {
 		list = append(list, p.Participant)
 	}
 
-	connectedMsg := models.ParticipantsInfoMsg{
+	s.mu.RUnlock()
+
+	s.sendToAll(ctx, models.ParticipantsInfo, models.ParticipantsInfoMsg{
 		Status:       status,
 		ID:           userID,
 		Participants: list,
-	}
+	})
+}
 
-	for id, p := range s.participants {
-		err := responses.SendWSOkResponse(p.Conn, models.ParticipantsInfo, connectedMsg)
-		if err != nil {
-			p.Conn.Close()
+func (s *session) isSpectator(userID int) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
 
-			delete(s.participants, id)
-		}
-	}
+	p, ok := s.participants[userID]
+
+	return ok && p.Role == models.Spectator
 }
 
 func (s *session) hasActiveUsers() bool {
@@ -0,0 +1,329 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/delivery/responses"
+	"github.com/stretchr/testify/assert"
+)
+
+// closedWith returns the close reason of the connection, if the session closed it
+func closedWith(msgs []outboundMsg, connID string) (string, bool) {
+	for _, msg := range msgs {
+		if msg.ConnID == connID && msg.CloseCode != 0 {
+			return msg.CloseText, true
+		}
+	}
+
+	return "", false
+}
+
+func TestSession_ResumeReplaysMissedMessages(t *testing.T) {
+	t.Parallel()
+
+	tt := newTestTable(t, `{"hp":10}`)
+	tt.s.reconnectGrace = time.Minute
+
+	tt.joinAdmin()
+	tt.joinPlayer(2, "conn-2")
+
+	lastSeq := tt.s.lastSeq()
+
+	// The seat is kept during the grace period, so nobody is told about the disconnect
+	assert.Empty(t, tt.leave(2, "conn-2"))
+
+	tt.patch(testAdminID, testAdminConn, 0, models.MergePatch, `{"hp":4}`)
+	tt.send(testAdminID, testAdminConn, models.Chat, &models.ChatRequest{Text: "hello"})
+
+	msgs := received(t, tt.join(&tableEvent{UserID: 2, ConnID: "conn-2b", LastSeq: lastSeq}), "conn-2b", 2)
+	assert.Len(t, msgs, 3)
+
+	info, ok := findMsg(msgs, models.BattleInfo)
+	assert.True(t, ok)
+	assert.Equal(t, 1, decodeData[models.EncounterData](t, info).Revision)
+	assert.Greater(t, info.Seq, lastSeq)
+
+	_, ok = findMsg(msgs, models.Chat)
+	assert.True(t, ok)
+
+	resume, ok := findMsg(msgs, models.Resume)
+	assert.True(t, ok)
+	assert.Equal(t, models.ResumeMsg{LastSeq: lastSeq, Snapshot: false}, decodeData[models.ResumeMsg](t, resume))
+
+	assert.Equal(t, "conn-2b", tt.s.participants[2].ConnID)
+	assert.Nil(t, tt.s.participants[2].graceTimer)
+}
+
+func TestSession_ResumeWithUnknownSeqSendsSnapshot(t *testing.T) {
+	t.Parallel()
+
+	tt := newTestTable(t, `{"hp":10}`)
+	tt.s.reconnectGrace = time.Minute
+
+	tt.joinAdmin()
+	tt.joinPlayer(2, "conn-2")
+	tt.leave(2, "conn-2")
+
+	lastSeq := tt.s.lastSeq() + 100
+
+	msgs := received(t, tt.join(&tableEvent{UserID: 2, ConnID: "conn-2b", LastSeq: lastSeq}), "conn-2b", 2)
+
+	_, ok := findMsg(msgs, models.BattleInfo)
+	assert.True(t, ok)
+
+	resume, ok := findMsg(msgs, models.Resume)
+	assert.True(t, ok)
+	assert.Equal(t, models.ResumeMsg{LastSeq: lastSeq, Snapshot: true}, decodeData[models.ResumeMsg](t, resume))
+}
+
+func TestSession_ReconnectReplacesLiveConnection(t *testing.T) {
+	t.Parallel()
+
+	tt := newTestTable(t, `{"hp":10}`)
+	tt.joinAdmin()
+	tt.joinPlayer(2, "conn-2")
+
+	out := tt.join(&tableEvent{UserID: 2, ConnID: "conn-2b", LastSeq: tt.s.lastSeq()})
+
+	reason, ok := closedWith(out, "conn-2")
+	assert.True(t, ok)
+	assert.Equal(t, responses.ErrConnReplacedWS, reason)
+	assert.Equal(t, "conn-2b", tt.s.participants[2].ConnID)
+}
+
+func TestSession_DuplicateJoinIsRejected(t *testing.T) {
+	t.Parallel()
+
+	tt := newTestTable(t, `{"hp":10}`)
+	tt.joinAdmin()
+	tt.joinPlayer(2, "conn-2")
+
+	reason, ok := closedWith(tt.join(&tableEvent{UserID: 2, ConnID: "conn-2b"}), "conn-2b")
+	assert.True(t, ok)
+	assert.Equal(t, responses.ErrUserAlreadyExistsWS, reason)
+	assert.Equal(t, "conn-2", tt.s.participants[2].ConnID)
+}
+
+func TestSession_KickAndBan(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		action     models.SessionAction
+		wantReason string
+		wantBanned bool
+	}{
+		{
+			name:       "kicked player can ask to join again",
+			action:     models.KickParticipant,
+			wantReason: responses.ErrKickedWS,
+		},
+		{
+			name:       "banned player cannot join again",
+			action:     models.BanParticipant,
+			wantReason: responses.ErrBannedWS,
+			wantBanned: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt := newTestTable(t, `{"hp":10}`)
+			tt.joinAdmin()
+			tt.joinPlayer(2, "conn-2")
+
+			out := tt.send(testAdminID, testAdminConn, models.SessionControl,
+				&models.SessionControlRequest{Action: tc.action, UserID: 2})
+
+			reason, ok := closedWith(out, "conn-2")
+			assert.True(t, ok)
+			assert.Equal(t, tc.wantReason, reason)
+			assert.NotContains(t, tt.s.participants, 2)
+			assert.NotContains(t, tt.s.members, 2)
+			assert.Equal(t, 0, tt.s.playersNum)
+
+			state, ok := findMsg(received(t, out, testAdminConn, testAdminID), models.SessionState)
+			assert.True(t, ok)
+			assert.Equal(t, tc.action, decodeData[models.SessionStateMsg](t, state).Action)
+
+			out = tt.join(&tableEvent{UserID: 2, ConnID: "conn-2b"})
+
+			reason, closed := closedWith(out, "conn-2b")
+			if tc.wantBanned {
+				assert.True(t, closed)
+				assert.Equal(t, responses.ErrBannedWS, reason)
+				assert.NotContains(t, tt.s.pending, 2)
+
+				return
+			}
+
+			assert.False(t, closed)
+			assert.Contains(t, tt.s.pending, 2)
+		})
+	}
+}
+
+func TestSession_ControlIsAdminOnly(t *testing.T) {
+	t.Parallel()
+
+	tt := newTestTable(t, `{"hp":10}`)
+	tt.joinAdmin()
+	tt.joinPlayer(2, "conn-2")
+	tt.joinPlayer(3, "conn-3")
+
+	out := tt.send(2, "conn-2", models.SessionControl,
+		&models.SessionControlRequest{Action: models.KickParticipant, UserID: 3})
+
+	assert.Equal(t, []string{responses.ErrControlForbiddenWS}, errorsOf(received(t, out, "conn-2", 2)))
+	assert.Contains(t, tt.s.participants, 3)
+}
+
+func TestSession_Invites(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		invite     *models.InviteClaims
+		uses       int
+		wantReason string
+		wantRole   models.Role
+	}{
+		{
+			name:     "invite lets a player in",
+			invite:   &models.InviteClaims{ID: "inv-1", SessionID: testSessionID, MaxUses: 2, CharacterID: "chr-1"},
+			uses:     1,
+			wantRole: models.Player,
+		},
+		{
+			name:     "invite without a limit lets a spectator in",
+			invite:   &models.InviteClaims{ID: "inv-1", SessionID: testSessionID, Role: models.Spectator},
+			uses:     5,
+			wantRole: models.Spectator,
+		},
+		{
+			name:       "exhausted invite is rejected",
+			invite:     &models.InviteClaims{ID: "inv-1", SessionID: testSessionID, MaxUses: 1},
+			uses:       1,
+			wantReason: responses.ErrInviteExhaustedWS,
+		},
+		{
+			name:       "invite to another session is rejected",
+			invite:     &models.InviteClaims{ID: "inv-1", SessionID: "session-2"},
+			wantReason: responses.ErrInviteExhaustedWS,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt := newTestTable(t, `{"hp":10}`)
+			tt.s.inviteUses["inv-1"] = tc.uses
+			tt.joinAdmin()
+
+			out := tt.join(&tableEvent{UserID: 2, ConnID: "conn-2", Role: tc.invite.Role, Invite: tc.invite})
+
+			reason, closed := closedWith(out, "conn-2")
+			if tc.wantReason != "" {
+				assert.True(t, closed)
+				assert.Equal(t, tc.wantReason, reason)
+				assert.NotContains(t, tt.s.participants, 2)
+				assert.Equal(t, tc.uses, tt.s.inviteUses["inv-1"])
+
+				return
+			}
+
+			assert.False(t, closed)
+			assert.Equal(t, tc.wantRole, tt.s.participants[2].Role)
+			assert.Equal(t, tc.invite.CharacterID, tt.s.participants[2].CharacterID)
+			assert.Contains(t, tt.s.members, 2)
+			assert.Equal(t, tc.uses+1, tt.s.inviteUses["inv-1"])
+
+			// Reconnecting members do not spend the invite again
+			tt.leave(2, "conn-2")
+			tt.join(&tableEvent{UserID: 2, ConnID: "conn-2b", Role: tc.invite.Role, Invite: tc.invite})
+			assert.Equal(t, "conn-2b", tt.s.participants[2].ConnID)
+			assert.Equal(t, tc.uses+1, tt.s.inviteUses["inv-1"])
+		})
+	}
+}
+
+func TestSession_RejoinKeepsGrant(t *testing.T) {
+	t.Parallel()
+
+	tt := newTestTable(t, `{"hp":10}`)
+	tt.joinAdmin()
+
+	invite := &models.InviteClaims{ID: "inv-1", SessionID: testSessionID, Role: models.Spectator,
+		CharacterID: "chr-1"}
+	tt.join(&tableEvent{UserID: 2, ConnID: "conn-2", Role: models.Spectator, Invite: invite})
+	tt.leave(2, "conn-2")
+
+	// Without the invite the connection asks for a player seat, but the first grant wins
+	tt.join(&tableEvent{UserID: 2, ConnID: "conn-2b", Role: models.Player})
+
+	assert.Equal(t, models.Spectator, tt.s.participants[2].Role)
+	assert.Equal(t, "chr-1", tt.s.participants[2].CharacterID)
+	assert.Equal(t, 0, tt.s.playersNum)
+
+	msgs := received(t, tt.patch(2, "conn-2b", 0, models.MergePatch, `{"hp":1}`), "conn-2b", 2)
+	assert.Equal(t, []string{responses.ErrSpectatorReadOnlyWS}, errorsOf(msgs))
+
+	// Grants survive a restore from the snapshot
+	snapshot := tt.s.Snapshot()
+	assert.Equal(t, []int{2}, snapshot.Members)
+	assert.Equal(t, map[int]models.MemberGrant{2: {Role: models.Spectator, CharacterID: "chr-1"}},
+		snapshotMembers(snapshot))
+
+	snapshot.Grants = nil
+	assert.Equal(t, map[int]models.MemberGrant{2: {Role: models.Player}}, snapshotMembers(snapshot))
+}
+
+func TestSession_JoinRequest(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		accept     bool
+		wantStatus models.JoinRequestStatus
+	}{
+		{name: "accepted request admits the player", accept: true, wantStatus: models.JoinAccepted},
+		{name: "denied request closes the connection", wantStatus: models.JoinDenied},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt := newTestTable(t, `{"hp":10}`)
+			tt.joinAdmin()
+
+			out := tt.join(&tableEvent{UserID: 2, ConnID: "conn-2", Name: "Player 2"})
+
+			request, ok := findMsg(received(t, out, testAdminConn, testAdminID), models.JoinRequest)
+			assert.True(t, ok)
+			assert.Equal(t, models.JoinPending, decodeData[models.JoinRequestMsg](t, request).Status)
+			assert.NotContains(t, tt.s.participants, 2)
+
+			// Until the game master decides, the connection cannot change the table
+			msgs := received(t, tt.patch(2, "conn-2", 0, models.MergePatch, `{"hp":1}`), "conn-2", 2)
+			assert.Equal(t, []string{responses.ErrJoinPendingWS}, errorsOf(msgs))
+
+			out = tt.send(testAdminID, testAdminConn, models.JoinDecision,
+				&models.JoinDecisionRequest{UserID: 2, Accept: tc.accept})
+
+			request, ok = findMsg(received(t, out, testAdminConn, testAdminID), models.JoinRequest)
+			assert.True(t, ok)
+			assert.Equal(t, tc.wantStatus, decodeData[models.JoinRequestMsg](t, request).Status)
+			assert.NotContains(t, tt.s.pending, 2)
+
+			_, closed := closedWith(out, "conn-2")
+			assert.Equal(t, !tc.accept, closed)
+			assert.Equal(t, tc.accept, tt.s.isParticipantConn(2, "conn-2"))
+		})
+	}
+}
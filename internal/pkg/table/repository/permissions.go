@@ -0,0 +1,121 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/delivery/responses"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils/merger"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils/redactor"
+)
+
+// handleSetPermissions позволяет ведущему выдать участнику права на изменение отдельных путей
+// энкаунтера или снять ограничения. Права привязаны к UserID и сохраняются при переподключении
+func (s *session) handleSetPermissions(ctx context.Context, event *tableEvent, data json.RawMessage) {
+	l := logger.FromContext(ctx)
+
+	if event.UserID != s.adminID {
+		l.RepoWarn(apperrors.PermissionDeniedError, map[string]any{"session_id": s.id, "user_id": event.UserID})
+		s.sendErrToConn(ctx, event.ConnID, responses.ErrPermissionsForbiddenWS)
+
+		return
+	}
+
+	var req models.PermissionsMsg
+	if err := json.Unmarshal(data, &req); err != nil || req.UserID == 0 || req.UserID == s.adminID {
+		l.RepoWarn(apperrors.InvalidPermissionsErr, map[string]any{"session_id": s.id, "user_id": event.UserID})
+		s.sendErrToConn(ctx, event.ConnID, responses.ErrInvalidPermissionsWS)
+
+		return
+	}
+
+	s.touch()
+
+	s.mu.Lock()
+
+	if req.WritablePaths == nil {
+		delete(s.permissions, req.UserID)
+	} else {
+		s.permissions[req.UserID] = slices.Clone(req.WritablePaths)
+	}
+
+	s.dirty = true
+
+	s.mu.Unlock()
+
+	// Права участника видят только он сам и ведущий
+	s.send(ctx, &outboundMsg{UserID: req.UserID}, models.Permissions, &req)
+	s.send(ctx, &outboundMsg{UserID: s.adminID}, models.Permissions, &req)
+}
+
+// forbiddenPaths возвращает пути патча, которые участнику изменять нельзя: не выданные ему ведущим
+// и скрытые от игроков. Скрытые пути запрещено и читать через copy или test. Вызывается под s.mu
+func (s *session) forbiddenPaths(userID int, patch []byte, format models.PatchFormat) ([]string, error) {
+	if userID == s.adminID {
+		return nil, nil
+	}
+
+	patchPaths, err := merger.PatchPaths(format, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	hidden, err := redactor.HiddenPaths(s.encounterData)
+	if err != nil {
+		return nil, err
+	}
+
+	readPaths, err := merger.ReadPaths(format, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	isHidden := func(path string) bool {
+		return slices.ContainsFunc(hidden, func(hiddenPath string) bool {
+			return merger.Overlaps(path, hiddenPath)
+		})
+	}
+
+	writable, restricted := s.permissions[userID]
+
+	forbidden := make([]string, 0)
+	for _, path := range readPaths {
+		if isHidden(path) {
+			forbidden = append(forbidden, path)
+		}
+	}
+
+	for _, path := range patchPaths {
+		isWritable := !restricted || slices.ContainsFunc(writable, func(prefix string) bool {
+			return isWithin(path, prefix)
+		})
+
+		if isHidden(path) || !isWritable {
+			forbidden = append(forbidden, path)
+		}
+	}
+
+	return forbidden, nil
+}
+
+// writePermissions отправляет участнику его ограничения, если они заданы
+func (s *session) writePermissions(ctx context.Context, connID string, userID int) {
+	s.mu.RLock()
+	writable, restricted := s.permissions[userID]
+	s.mu.RUnlock()
+
+	if !restricted {
+		return
+	}
+
+	s.sendToConn(ctx, connID, models.Permissions, &models.PermissionsMsg{UserID: userID, WritablePaths: writable})
+}
+
+func isWithin(path, prefix string) bool {
+	return path == prefix || strings.HasPrefix(path, prefix+".")
+}
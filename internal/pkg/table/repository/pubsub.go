@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	mymetrics "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/metrics"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/repository/dbcall"
+	tableinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+const pubSubChannelSize = 256
+
+type tablePubSub struct {
+	client  *redis.Client
+	metrics mymetrics.DBMetrics
+}
+
+func NewTablePubSub(client *redis.Client, metrics mymetrics.DBMetrics) tableinterfaces.TablePubSub {
+	return &tablePubSub{
+		client:  client,
+		metrics: metrics,
+	}
+}
+
+func (ps *tablePubSub) Publish(ctx context.Context, channel string, msg []byte) error {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	err := dbcall.ErrOnlyDBCall(fnName, ps.metrics, func() error {
+		return ps.client.Publish(ctx, channel, msg).Err()
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"channel": channel})
+		return apperrors.PublishTableMsgErr
+	}
+
+	return nil
+}
+
+func (ps *tablePubSub) Subscribe(ctx context.Context, channel string) (<-chan []byte, error) {
+	l := logger.FromContext(ctx)
+
+	pubsub := ps.client.Subscribe(ctx, channel)
+
+	// Дожидаемся подтверждения подписки, чтобы не потерять сообщения, опубликованные сразу после неё
+	if _, err := pubsub.Receive(ctx); err != nil {
+		l.RepoError(err, map[string]any{"channel": channel})
+		pubsub.Close()
+
+		return nil, apperrors.SubscribeTableErr
+	}
+
+	out := make(chan []byte, pubSubChannelSize)
+
+	go func() {
+		defer close(out)
+
+		msgs := pubsub.Channel(redis.WithChannelSize(pubSubChannelSize))
+
+		for {
+			select {
+			case <-ctx.Done():
+				pubsub.Close()
+				return
+			case msg, ok := <-msgs:
+				if !ok {
+					return
+				}
+
+				select {
+				case out <- []byte(msg.Payload):
+				case <-ctx.Done():
+					pubsub.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	return out, nil
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	tableinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table"
+)
+
+type inMemorySubscriber struct {
+	ch     chan []byte
+	ctx    context.Context
+	closed bool
+	mu     sync.RWMutex
+}
+
+// inMemoryTablePubSub — шина сообщений в пределах одного процесса, для тестов и запуска в одну реплику
+type inMemoryTablePubSub struct {
+	mu          sync.RWMutex
+	subscribers map[string]map[*inMemorySubscriber]struct{}
+}
+
+func NewInMemoryTablePubSub() tableinterfaces.TablePubSub {
+	return &inMemoryTablePubSub{
+		subscribers: make(map[string]map[*inMemorySubscriber]struct{}),
+	}
+}
+
+func (ps *inMemoryTablePubSub) Publish(ctx context.Context, channel string, msg []byte) error {
+	ps.mu.RLock()
+	subscribers := make([]*inMemorySubscriber, 0, len(ps.subscribers[channel]))
+	for sub := range ps.subscribers[channel] {
+		subscribers = append(subscribers, sub)
+	}
+	ps.mu.RUnlock()
+
+	for _, sub := range subscribers {
+		sub.send(ctx, msg)
+	}
+
+	return nil
+}
+
+func (ps *inMemoryTablePubSub) Subscribe(ctx context.Context, channel string) (<-chan []byte, error) {
+	sub := &inMemorySubscriber{
+		ch:  make(chan []byte, pubSubChannelSize),
+		ctx: ctx,
+	}
+
+	ps.mu.Lock()
+	if _, ok := ps.subscribers[channel]; !ok {
+		ps.subscribers[channel] = make(map[*inMemorySubscriber]struct{})
+	}
+	ps.subscribers[channel][sub] = struct{}{}
+	ps.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		ps.mu.Lock()
+		delete(ps.subscribers[channel], sub)
+		if len(ps.subscribers[channel]) == 0 {
+			delete(ps.subscribers, channel)
+		}
+		ps.mu.Unlock()
+
+		sub.close()
+	}()
+
+	return sub.ch, nil
+}
+
+func (sub *inMemorySubscriber) send(ctx context.Context, msg []byte) {
+	sub.mu.RLock()
+	defer sub.mu.RUnlock()
+
+	if sub.closed {
+		return
+	}
+
+	select {
+	case sub.ch <- msg:
+	case <-sub.ctx.Done():
+	case <-ctx.Done():
+	}
+}
+
+func (sub *inMemorySubscriber) close() {
+	sub.mu.Lock()
+	defer sub.mu.Unlock()
+
+	sub.closed = true
+	close(sub.ch)
+}
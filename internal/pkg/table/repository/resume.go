@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+)
+
+// maxResumeBuffer — сколько последних сообщений сессии хранится для повтора при переподключении.
+// Если клиент пропустил больше, вместо повтора он получает текущее состояние сессии
+const maxResumeBuffer = 256
+
+// sendNumbered присваивает сообщению следующий номер, сохраняет его для повтора и публикует
+func (s *session) sendNumbered(ctx context.Context, msg *outboundMsg, marshal func(seq int64) ([]byte, error)) {
+	l := logger.FromContext(ctx)
+
+	s.outMu.Lock()
+	defer s.outMu.Unlock()
+
+	payload, err := marshal(s.outSeq + 1)
+	if err != nil {
+		l.RepoError(err, map[string]any{"session_id": s.id})
+		return
+	}
+
+	s.outSeq++
+	msg.Seq = s.outSeq
+	msg.Payload = payload
+
+	s.outbox = appendBounded(s.outbox, *msg, maxResumeBuffer)
+	s.publish(ctx, msg)
+}
+
+// replayMissed повторяет на новом соединении сообщения, пропущенные участником после lastSeq. Возвращает false,
+// если часть пропущенных сообщений уже вытеснена из буфера или номер не относится к этой сессии
+func (s *session) replayMissed(ctx context.Context, connID string, userID int, lastSeq int64) bool {
+	s.outMu.Lock()
+	defer s.outMu.Unlock()
+
+	if lastSeq > s.outSeq {
+		return false
+	}
+
+	if lastSeq < s.outSeq && (len(s.outbox) == 0 || s.outbox[0].Seq > lastSeq+1) {
+		return false
+	}
+
+	for _, msg := range s.outbox {
+		// Сообщения конкретным соединениям относятся к старому соединению и не повторяются
+		if msg.Seq <= lastSeq || msg.ConnID != "" || !msg.matches("", userID) {
+			continue
+		}
+
+		s.publish(ctx, &outboundMsg{Seq: msg.Seq, ConnID: connID, Payload: msg.Payload})
+	}
+
+	return true
+}
+
+// writeResume завершает переподключение участника: повторяет пропущенные сообщения или, если это
+// невозможно, отправляет текущее состояние сессии
+func (s *session) writeResume(ctx context.Context, connID string, userID int, lastSeq int64) {
+	resumed := s.replayMissed(ctx, connID, userID, lastSeq)
+	if !resumed {
+		s.writeState(ctx, connID, userID)
+	}
+
+	s.sendToConn(ctx, connID, models.Resume, &models.ResumeMsg{LastSeq: lastSeq, Snapshot: !resumed})
+}
+
+// writeState отправляет подключившемуся участнику текущее состояние сессии
+func (s *session) writeState(ctx context.Context, connID string, userID int) {
+	s.writeFirstMsg(ctx, connID, userID)
+	s.writeSessionState(ctx, connID)
+	s.writePermissions(ctx, connID, userID)
+	s.writeInitiative(ctx, connID, userID)
+	s.writeChatHistory(ctx, connID, userID)
+	s.writeJoinRequests(ctx, connID, userID)
+}
+
+func (s *session) lastSeq() int64 {
+	s.outMu.Lock()
+	defer s.outMu.Unlock()
+
+	return s.outSeq
+}
@@ -0,0 +1,66 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/delivery/responses"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils/dice"
+)
+
+const maxRollHistory = 100
+
+func (s *session) handleRoll(ctx context.Context, event *tableEvent, data json.RawMessage) {
+	l := logger.FromContext(ctx)
+
+	var req models.RollRequest
+	if err := json.Unmarshal(data, &req); err != nil {
+		l.RepoWarn(err, map[string]any{"session_id": s.id, "user_id": event.UserID})
+		s.sendErrToConn(ctx, event.ConnID, responses.ErrInvalidRollWS)
+
+		return
+	}
+
+	result, err := dice.Roll(req.Formula)
+	if err != nil {
+		l.RepoWarn(apperrors.InvalidRollErr, map[string]any{"session_id": s.id, "user_id": event.UserID,
+			"formula": req.Formula})
+		s.sendErrToConn(ctx, event.ConnID, responses.ErrInvalidRollWS)
+
+		return
+	}
+
+	s.touch()
+
+	s.mu.Lock()
+
+	msg := models.RollMsg{
+		UserID:    event.UserID,
+		Label:     req.Label,
+		Result:    result,
+		Timestamp: time.Now(),
+	}
+	if p, ok := s.participants[event.UserID]; ok {
+		msg.Name = p.Name
+	}
+
+	s.rolls = appendBounded(s.rolls, msg, maxRollHistory)
+	s.dirty = true
+
+	s.mu.Unlock()
+
+	s.sendToAll(ctx, models.Roll, &msg)
+}
+
+func appendBounded[T any](list []T, item T, limit int) []T {
+	list = append(list, item)
+	if len(list) > limit {
+		list = list[len(list)-limit:]
+	}
+
+	return list
+}
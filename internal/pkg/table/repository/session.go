@@ -2,92 +2,385 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
-	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/metrics"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/delivery/responses"
+	tableinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table/initiative"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils/merger"
-	"github.com/gorilla/websocket"
-	"sync"
-	"time"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils/redactor"
 )
 
 type participant struct {
 	models.Participant
-	Conn *websocket.Conn
+	ConnID string
+
+	// graceTimer задан, пока соединение участника оборвано и его место сохраняется до переподключения
+	graceTimer *time.Timer
 }
 
+// session — состояние игровой сессии. Существует только на реплике-владельце, все изменения
+// выполняются в горутине run
 type session struct {
+	id            string
 	encounterID   string
 	encounterName string
 	encounterData []byte
 
-	adminID         int
-	adminName       string
-	participants    map[int]*participant // Ключ - UserID
-	playersNum      int
-	broadcast       chan []byte
-	refreshCallback func(sessionID string) // Вызов обновления таймера
+	initialData []byte                  // Данные энкаунтера до первой операции журнала
+	operations  []models.TableOperation // Последние изменения, более старые хранятся в table_operation_log
+	revision    int                     // Номер последней операции, увеличивается при каждом изменении данных
+	permissions map[int][]string        // Ключ - UserID, значение - пути, доступные участнику для изменения
+	rolls       []models.RollMsg        // Последние броски костей
+	chat        []models.ChatMsg        // Последние сообщения чата
+	initiative  *initiative.Tracker     // Порядок ходов
+
+	adminID      int
+	adminName    string
+	participants map[int]*participant // Ключ - UserID
+	playersNum   int                  // Подключенные игроки, без ведущего и зрителей
+	maxPlayers   int
+	lifecycle    tableinterfaces.SessionLifecycle // Управление таймером бездействия
+	paused       bool                             // Таймер бездействия остановлен ведущим
+	banned       []int                            // Участники, которым запрещено подключаться
+	members      map[int]models.MemberGrant       // Пользователи, подключавшиеся к сессии, кроме ведущего
+	pending      map[int]*tableEvent              // Подключения, ждущие одобрения ведущего. Ключ - UserID
+	inviteUses   map[string]int                   // Ключ - ID приглашения
+
+	stats   tableinterfaces.CombatantStatsProvider
+	logRepo tableinterfaces.TableLogRepository // Хранилище операций, вытесненных из журнала в памяти
+
+	deltas bool // Изменения энкаунтера рассылаются разницей, а не полными данными
+
+	reconnectGrace time.Duration
+
+	// Исходящие сообщения нумеруются, последние из них хранятся для повтора при переподключении.
+	// outMu также сохраняет порядок публикации сообщений в соответствии с номерами
+	outMu  sync.Mutex
+	outSeq int64
+	outbox []outboundMsg
+
+	pubsub tableinterfaces.TablePubSub
+	cancel context.CancelFunc // Останавливает обработку событий сессии
 
 	mu sync.RWMutex
 
-	start   time.Time
-	metrics metrics.WSSessionMetrics
+	start        time.Time
+	lastActivity time.Time
+	dirty        bool // Есть изменения, не попавшие в снапшот
+}
+
+func (s *session) run(ctx context.Context, inbound <-chan []byte) {
+	l := logger.FromContext(ctx)
+
+	for raw := range inbound {
+		var event tableEvent
+		if err := json.Unmarshal(raw, &event); err != nil {
+			l.RepoError(err, map[string]any{"session_id": s.id})
+			continue
+		}
+
+		switch event.Kind {
+		case eventJoin:
+			s.handleJoin(ctx, &event)
+		case eventLeave:
+			s.handleLeave(ctx, &event)
+		case eventMessage:
+			s.handleMessage(ctx, &event)
+		case eventControl:
+			s.handleControl(ctx, &event, event.Payload)
+		}
+	}
 }
 
-func (s *session) run(ctx context.Context) {
+// touch отмечает активность участников: продлевает таймер бездействия и запоминает её время
+// для списка сессий
+func (s *session) touch() {
+	s.mu.Lock()
+	s.lastActivity = time.Now()
+	s.dirty = true
+	s.mu.Unlock()
+
+	s.lifecycle.Refresh(s.id)
+}
+
+func (s *session) handleMessage(ctx context.Context, event *tableEvent) {
 	l := logger.FromContext(ctx)
 
-	for {
-		select {
-		case msg := <-s.broadcast:
-			s.mu.Lock()
+	var req models.WSRequest
+	reqErr := json.Unmarshal(event.Payload, &req)
 
-			var err error
+	if !s.isParticipantConn(event.UserID, event.ConnID) {
+		l.RepoWarn(apperrors.JoinPendingErr, map[string]any{"session_id": s.id, "user_id": event.UserID})
+		s.sendErrToConn(ctx, event.ConnID, responses.ErrJoinPendingWS)
 
-			s.encounterData, err = merger.Merge(s.encounterData, msg)
-			if err != nil {
-				l.RepoError(err, nil)
-				return
-			}
+		return
+	}
 
-			for id, p := range s.participants {
-				err := responses.SendWSOkResponse(p.Conn, models.BattleInfo,
-					&models.EncounterData{EncounterData: s.encounterData})
-				if err != nil {
-					l.RepoError(err, nil)
-					p.Conn.Close()
+	// Полные данные могут запросить и зрители
+	if reqErr == nil && req.Type == models.Resync {
+		s.writeFirstMsg(ctx, event.ConnID, event.UserID)
+		return
+	}
 
-					delete(s.participants, id)
-				}
+	if s.isSpectator(event.UserID) {
+		l.RepoWarn(apperrors.SpectatorReadOnlyErr, map[string]any{"session_id": s.id, "user_id": event.UserID})
+		s.sendErrToConn(ctx, event.ConnID, responses.ErrSpectatorReadOnlyWS)
 
-				s.metrics.IncSentMsgs()
-			}
+		return
+	}
 
-			s.mu.Unlock()
+	if reqErr == nil {
+		switch req.Type {
+		case models.Patch:
+			s.handleRevisionedPatch(ctx, event, req.Data)
+			return
+		case models.Undo:
+			s.handleUndo(ctx, event, req.Data)
+			return
+		case models.Permissions:
+			s.handleSetPermissions(ctx, event, req.Data)
+			return
+		case models.Roll:
+			s.handleRoll(ctx, event, req.Data)
+			return
+		case models.Initiative:
+			s.handleInitiative(ctx, event, req.Data)
+			return
+		case models.Chat:
+			s.handleChat(ctx, event, req.Data)
+			return
+		case models.SessionControl:
+			s.handleControl(ctx, event, req.Data)
+			return
+		case models.JoinDecision:
+			s.handleJoinDecision(ctx, event, req.Data)
+			return
 		}
 	}
+
+	// Патч без ревизии применяется безусловно
+	s.handlePatch(ctx, event, event.Payload, models.LegacyPatch, nil)
 }
 
-func (s *session) WriteFirstMsg(ctx context.Context, userID int) {
+func (s *session) handleRevisionedPatch(ctx context.Context, event *tableEvent, data json.RawMessage) {
 	l := logger.FromContext(ctx)
 
+	var req models.PatchRequest
+	if err := json.Unmarshal(data, &req); err != nil || len(req.Patch) == 0 || req.BaseRevision < 0 ||
+		!req.Format.IsValid() {
+		l.RepoWarn(apperrors.InvalidPatchErr, map[string]any{"session_id": s.id, "user_id": event.UserID})
+		s.sendErrToConn(ctx, event.ConnID, responses.ErrInvalidPatchWS)
+
+		return
+	}
+
+	s.handlePatch(ctx, event, req.Patch, req.Format, &req.BaseRevision)
+}
+
+func (s *session) handlePatch(ctx context.Context, event *tableEvent, patch json.RawMessage,
+	format models.PatchFormat, baseRevision *int) {
+	l := logger.FromContext(ctx)
+
+	// Данные сессии меняются только в горутине run, поэтому между проверкой и изменением они те же
+	s.mu.RLock()
+	forbidden, err := s.forbiddenPaths(event.UserID, patch, format)
+	s.mu.RUnlock()
+
+	if err != nil || len(forbidden) > 0 {
+		l.RepoWarn(apperrors.PermissionDeniedError, map[string]any{"session_id": s.id, "user_id": event.UserID,
+			"paths": forbidden})
+		s.sendErrToConn(ctx, event.ConnID, responses.ErrPatchForbiddenWS)
+
+		return
+	}
+
+	s.touch()
+
 	s.mu.Lock()
-	defer s.mu.Unlock()
-	s.metrics.IncSentMsgs()
 
-	conn := s.participants[userID].Conn
+	if baseRevision != nil {
+		if *baseRevision > s.revision {
+			s.mu.Unlock()
+			l.RepoWarn(apperrors.InvalidPatchErr, map[string]any{"session_id": s.id, "user_id": event.UserID})
+			s.sendErrToConn(ctx, event.ConnID, responses.ErrInvalidPatchWS)
+
+			return
+		}
+
+		conflicts, err := s.conflictingPaths(*baseRevision, patch, format)
+		if err != nil {
+			s.mu.Unlock()
+			l.RepoError(err, map[string]any{"session_id": s.id, "user_id": event.UserID})
+			s.sendErrToConn(ctx, event.ConnID, responses.ErrInvalidPatchWS)
+
+			return
+		}
+
+		if len(conflicts) > 0 {
+			revision := s.revision
+			s.mu.Unlock()
+
+			s.sendToConn(ctx, event.ConnID, models.Conflict, &models.ConflictMsg{
+				BaseRevision:    *baseRevision,
+				CurrentRevision: revision,
+				Paths:           conflicts,
+			})
+
+			return
+		}
+	}
+
+	encounterData, err := merger.Apply(format, s.encounterData, patch)
+	if err != nil {
+		s.mu.Unlock()
+		l.RepoWarn(err, map[string]any{"session_id": s.id, "user_id": event.UserID, "format": format})
+		s.sendErrToConn(ctx, event.ConnID, responses.ErrInvalidPatchWS)
+
+		return
+	}
+
+	prevData := s.encounterData
+
+	s.encounterData = encounterData
+	s.appendOperation(models.TableOperation{AuthorID: event.UserID, Kind: models.PatchOperation,
+		Patch: patch, Format: format})
+	s.dirty = true
+
+	revision := s.revision
+
+	s.mu.Unlock()
+
+	s.broadcastBattleInfo(ctx, prevData, encounterData, revision)
+	s.archiveOperations(ctx)
+}
+
+// broadcastBattleInfo отправляет ведущему изменения полного энкаунтера, а остальным участникам — изменения
+// без скрытых полей. Каждая операция увеличивает ревизию на единицу, поэтому разница всегда
+// относится к предыдущей ревизии
+func (s *session) broadcastBattleInfo(ctx context.Context, prevData, encounterData []byte, revision int) {
+	l := logger.FromContext(ctx)
+
+	s.sendBattleUpdate(ctx, &outboundMsg{UserID: s.adminID}, prevData, encounterData, revision)
 
-	err := responses.SendWSOkResponse(conn, models.BattleInfo, &models.EncounterData{EncounterData: s.encounterData})
+	redacted, err := redactor.Redact(encounterData)
 	if err != nil {
-		l.RepoError(err, nil)
-		conn.Close()
+		l.RepoError(err, map[string]any{"session_id": s.id})
+		return
+	}
+
+	var prevRedacted []byte
+	if s.deltas {
+		prevRedacted, err = redactor.Redact(prevData)
+		if err != nil {
+			l.RepoError(err, map[string]any{"session_id": s.id})
+		}
+	}
+
+	s.sendBattleUpdate(ctx, &outboundMsg{ExceptUserID: s.adminID}, prevRedacted, redacted, revision)
+}
+
+// sendBattleUpdate отправляет разницу между данными энкаунтера. Если разницу рассылать не нужно или
+// её не удалось посчитать, отправляются полные данные
+func (s *session) sendBattleUpdate(ctx context.Context, msg *outboundMsg, prevData, encounterData []byte,
+	revision int) {
+	l := logger.FromContext(ctx)
+
+	if s.deltas && prevData != nil {
+		delta, err := merger.Diff(prevData, encounterData)
+		if err == nil {
+			s.send(ctx, msg, models.BattleDelta,
+				&models.EncounterDelta{Patch: delta, BaseRevision: revision - 1, Revision: revision})
+
+			return
+		}
+
+		l.RepoError(err, map[string]any{"session_id": s.id})
+	}
+
+	s.send(ctx, msg, models.BattleInfo, &models.EncounterData{EncounterData: encounterData, Revision: revision})
+}
 
-		delete(s.participants, userID)
+func (s *session) writeFirstMsg(ctx context.Context, connID string, userID int) {
+	l := logger.FromContext(ctx)
+
+	s.mu.RLock()
+	data := s.encounterData
+	revision := s.revision
+	s.mu.RUnlock()
+
+	if userID != s.adminID {
+		redacted, err := redactor.Redact(data)
+		if err != nil {
+			l.RepoError(err, map[string]any{"session_id": s.id, "user_id": userID})
+			return
+		}
+
+		data = redacted
 	}
+
+	s.sendToConn(ctx, connID, models.BattleInfo, &models.EncounterData{EncounterData: data, Revision: revision})
+}
+
+// requestRejoin просит хабы всех реплик заново объявить свои соединения новому владельцу
+func (s *session) requestRejoin(ctx context.Context) {
+	s.publish(ctx, &outboundMsg{Rejoin: true})
+}
+
+func (s *session) end(ctx context.Context) {
+	s.publish(ctx, &outboundMsg{Ended: true})
+}
+
+func (s *session) sendToAll(ctx context.Context, msgType models.WSMsgType, msgContent any) {
+	s.send(ctx, &outboundMsg{}, msgType, msgContent)
+}
+
+func (s *session) sendToConn(ctx context.Context, connID string, msgType models.WSMsgType, msgContent any) {
+	s.send(ctx, &outboundMsg{ConnID: connID}, msgType, msgContent)
+}
+
+func (s *session) sendErrToConn(ctx context.Context, connID, message string) {
+	s.sendNumbered(ctx, &outboundMsg{ConnID: connID}, func(seq int64) ([]byte, error) {
+		return responses.MarshalWSSeqErrResponse(seq, message)
+	})
 }
 
-func (s *session) GetTableData() *models.TableData {
+func (s *session) rejectConn(ctx context.Context, connID, message string) {
+	s.sendNumbered(ctx, &outboundMsg{
+		ConnID:    connID,
+		CloseCode: responses.WSStatusBadRequest,
+		CloseText: message,
+	}, func(seq int64) ([]byte, error) {
+		return responses.MarshalWSSeqErrResponse(seq, message)
+	})
+}
+
+func (s *session) send(ctx context.Context, msg *outboundMsg, msgType models.WSMsgType, msgContent any) {
+	s.sendNumbered(ctx, msg, func(seq int64) ([]byte, error) {
+		return responses.MarshalWSSeqOkResponse(seq, msgType, msgContent)
+	})
+}
+
+func (s *session) publish(ctx context.Context, msg *outboundMsg) {
+	l := logger.FromContext(ctx)
+
+	raw, err := json.Marshal(msg)
+	if err != nil {
+		l.RepoError(err, map[string]any{"session_id": s.id})
+		return
+	}
+
+	s.pubsub.Publish(ctx, outboundChannel(s.id), raw)
+}
+
+func (s *session) GetTableData(userID int) (*models.TableData, error) {
 	data := &models.TableData{}
 
 	s.mu.RLock()
@@ -96,29 +389,89 @@ func (s *session) GetTableData() *models.TableData {
 	data.EncounterName = s.encounterName
 	data.AdminName = s.adminName
 	data.EncounterData = s.encounterData
+	data.Revision = s.revision
+	data.MaxPlayers = s.maxPlayers
+	data.Paused = s.paused
+
+	if userID != s.adminID {
+		redacted, err := redactor.Redact(s.encounterData)
+		if err != nil {
+			return nil, err
+		}
 
-	return data
+		data.EncounterData = redacted
+	}
+
+	return data, nil
 }
 
-func (s *session) GetEncounterData() []byte {
+// GetEncounterData возвращает данные энкаунтера для сохранения в базу вместе с историей бросков и чата.
+// Данные энкаунтера видят все, у кого есть к нему доступ, поэтому шёпоты в базу не сохраняются
+func (s *session) GetEncounterData() ([]byte, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	return s.encounterData
+	data, err := withHistory(s.encounterData, rollHistoryKey, s.rolls)
+	if err != nil {
+		return nil, err
+	}
+
+	return withHistory(data, chatHistoryKey, publicChat(s.chat))
 }
 
-func (s *session) GetAdminID() int {
+func (s *session) Snapshot() *models.TableSessionSnapshot {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
-	return s.adminID
+	return s.snapshot()
 }
 
-func (s *session) CheckUser(userID int) bool {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+// TakeDirtySnapshot возвращает снапшот, только если с прошлого сохранения были изменения
+func (s *session) TakeDirtySnapshot() (*models.TableSessionSnapshot, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.dirty {
+		return nil, false
+	}
+
+	s.dirty = false
 
-	_, ok := s.participants[userID]
+	return s.snapshot(), true
+}
+
+func (s *session) MarkDirty() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	return ok
+	s.dirty = true
+}
+
+func (s *session) snapshot() *models.TableSessionSnapshot {
+	return &models.TableSessionSnapshot{
+		SessionID:     s.id,
+		EncounterID:   s.encounterID,
+		EncounterName: s.encounterName,
+		EncounterData: s.encounterData,
+		AdminID:       s.adminID,
+		AdminName:     s.adminName,
+		StartedAt:     s.start,
+		SavedAt:       time.Now(),
+		InitialData:   s.initialData,
+		Operations:    slices.Clone(s.operations),
+		Permissions:   maps.Clone(s.permissions),
+		Rolls:         slices.Clone(s.rolls),
+		Chat:          slices.Clone(s.chat),
+		Initiative:    s.initiative.State(),
+		MaxPlayers:    s.maxPlayers,
+		Seq:           s.lastSeq(),
+		Paused:        s.paused,
+		Banned:        slices.Clone(s.banned),
+
+		Members:         slices.Sorted(maps.Keys(s.members)),
+		Grants:          maps.Clone(s.members),
+		InviteUses:      maps.Clone(s.inviteUses),
+		ParticipantsNum: len(s.participants),
+		LastActivityAt:  s.lastActivity,
+	}
 }
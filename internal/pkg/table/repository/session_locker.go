@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	mymetrics "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/metrics"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/repository/dbcall"
+	tableinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+const tableOwnerKeyPrefix = "table:owner:"
+
+// Продлеваем и снимаем блокировку, только если она всё ещё принадлежит нам
+var (
+	refreshLockScript = redis.NewScript(`
+		if redis.call("GET", KEYS[1]) == ARGV[1] then
+			return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+		end
+		return 0
+	`)
+
+	unlockScript = redis.NewScript(`
+		if redis.call("GET", KEYS[1]) == ARGV[1] then
+			return redis.call("DEL", KEYS[1])
+		end
+		return 0
+	`)
+)
+
+type sessionLocker struct {
+	client  *redis.Client
+	metrics mymetrics.DBMetrics
+}
+
+func NewSessionLocker(client *redis.Client, metrics mymetrics.DBMetrics) tableinterfaces.SessionLocker {
+	return &sessionLocker{
+		client:  client,
+		metrics: metrics,
+	}
+}
+
+func tableOwnerKey(sessionID string) string {
+	return tableOwnerKeyPrefix + sessionID
+}
+
+func (sl *sessionLocker) TryLock(ctx context.Context, sessionID, owner string, ttl time.Duration) (bool, error) {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	ok, err := dbcall.DBCall[bool](fnName, sl.metrics, func() (bool, error) {
+		return sl.client.SetNX(ctx, tableOwnerKey(sessionID), owner, ttl).Result()
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"session_id": sessionID, "owner": owner})
+		return false, apperrors.TableLockErr
+	}
+
+	return ok, nil
+}
+
+func (sl *sessionLocker) Refresh(ctx context.Context, sessionID, owner string, ttl time.Duration) (bool, error) {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	res, err := dbcall.DBCall[int64](fnName, sl.metrics, func() (int64, error) {
+		return refreshLockScript.Run(ctx, sl.client, []string{tableOwnerKey(sessionID)},
+			owner, ttl.Milliseconds()).Int64()
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		l.RepoError(err, map[string]any{"session_id": sessionID, "owner": owner})
+		return false, apperrors.TableLockErr
+	}
+
+	return res == 1, nil
+}
+
+func (sl *sessionLocker) Unlock(ctx context.Context, sessionID, owner string) error {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	err := dbcall.ErrOnlyDBCall(fnName, sl.metrics, func() error {
+		return unlockScript.Run(ctx, sl.client, []string{tableOwnerKey(sessionID)}, owner).Err()
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		l.RepoError(err, map[string]any{"session_id": sessionID, "owner": owner})
+		return apperrors.TableLockErr
+	}
+
+	return nil
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	tableinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table"
+)
+
+type inMemoryLock struct {
+	owner     string
+	expiresAt time.Time
+}
+
+type inMemorySessionLocker struct {
+	mu    sync.Mutex
+	locks map[string]inMemoryLock
+}
+
+func NewInMemorySessionLocker() tableinterfaces.SessionLocker {
+	return &inMemorySessionLocker{
+		locks: make(map[string]inMemoryLock),
+	}
+}
+
+func (sl *inMemorySessionLocker) TryLock(_ context.Context, sessionID, owner string,
+	ttl time.Duration) (bool, error) {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	if lock, ok := sl.locks[sessionID]; ok && time.Now().Before(lock.expiresAt) {
+		return false, nil
+	}
+
+	sl.locks[sessionID] = inMemoryLock{owner: owner, expiresAt: time.Now().Add(ttl)}
+
+	return true, nil
+}
+
+func (sl *inMemorySessionLocker) Refresh(_ context.Context, sessionID, owner string,
+	ttl time.Duration) (bool, error) {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	lock, ok := sl.locks[sessionID]
+	if !ok || lock.owner != owner || time.Now().After(lock.expiresAt) {
+		return false, nil
+	}
+
+	sl.locks[sessionID] = inMemoryLock{owner: owner, expiresAt: time.Now().Add(ttl)}
+
+	return true, nil
+}
+
+func (sl *inMemorySessionLocker) Unlock(_ context.Context, sessionID, owner string) error {
+	sl.mu.Lock()
+	defer sl.mu.Unlock()
+
+	if lock, ok := sl.locks[sessionID]; ok && lock.owner == owner {
+		delete(sl.locks, sessionID)
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	mymetrics "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/metrics"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/repository/dbcall"
+	tableinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	tableSessionKeyPrefix = "table:session:"
+	tableSessionsIndexKey = "table:sessions"
+)
+
+type tableSessionStore struct {
+	client  *redis.Client
+	metrics mymetrics.DBMetrics
+	ttl     time.Duration
+}
+
+func NewTableSessionStore(client *redis.Client, metrics mymetrics.DBMetrics,
+	ttl time.Duration) tableinterfaces.TableSessionStore {
+	return &tableSessionStore{
+		client:  client,
+		metrics: metrics,
+		ttl:     ttl,
+	}
+}
+
+func tableSessionKey(sessionID string) string {
+	return tableSessionKeyPrefix + sessionID
+}
+
+func (s *tableSessionStore) SaveSession(ctx context.Context, snapshot *models.TableSessionSnapshot) error {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	rawSnapshot, err := json.Marshal(snapshot)
+	if err != nil {
+		l.RepoError(err, map[string]any{"session_id": snapshot.SessionID})
+		return apperrors.SaveTableSnapshotErr
+	}
+
+	err = dbcall.ErrOnlyDBCall(fnName, s.metrics, func() error {
+		_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Set(ctx, tableSessionKey(snapshot.SessionID), rawSnapshot, s.ttl)
+			pipe.SAdd(ctx, tableSessionsIndexKey, snapshot.SessionID)
+
+			return nil
+		})
+
+		return err
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"session_id": snapshot.SessionID})
+		return apperrors.SaveTableSnapshotErr
+	}
+
+	return nil
+}
+
+func (s *tableSessionStore) GetSession(ctx context.Context, sessionID string) (*models.TableSessionSnapshot, error) {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	rawSnapshot, err := dbcall.DBCall[string](fnName, s.metrics, func() (string, error) {
+		return s.client.Get(ctx, tableSessionKey(sessionID)).Result()
+	})
+	if errors.Is(err, redis.Nil) {
+		return nil, apperrors.TableNotFoundErr
+	} else if err != nil {
+		l.RepoError(err, map[string]any{"session_id": sessionID})
+		return nil, apperrors.GetTableSnapshotErr
+	}
+
+	var snapshot models.TableSessionSnapshot
+	if err := json.Unmarshal([]byte(rawSnapshot), &snapshot); err != nil {
+		l.RepoError(err, map[string]any{"session_id": sessionID})
+		return nil, apperrors.GetTableSnapshotErr
+	}
+
+	return &snapshot, nil
+}
+
+func (s *tableSessionStore) ListSessions(ctx context.Context) ([]*models.TableSessionSnapshot, error) {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	ids, err := dbcall.DBCall[[]string](fnName, s.metrics, func() ([]string, error) {
+		return s.client.SMembers(ctx, tableSessionsIndexKey).Result()
+	})
+	if err != nil {
+		l.RepoError(err, nil)
+		return nil, apperrors.GetTableSnapshotErr
+	}
+
+	snapshots := make([]*models.TableSessionSnapshot, 0, len(ids))
+
+	for _, id := range ids {
+		snapshot, err := s.GetSession(ctx, id)
+		if errors.Is(err, apperrors.TableNotFoundErr) {
+			// Снапшот истёк по TTL, чистим индекс
+			s.client.SRem(ctx, tableSessionsIndexKey, id)
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
+
+func (s *tableSessionStore) RemoveSession(ctx context.Context, sessionID string) error {
+	l := logger.FromContext(ctx)
+	fnName := utils.GetFunctionName()
+
+	err := dbcall.ErrOnlyDBCall(fnName, s.metrics, func() error {
+		_, err := s.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+			pipe.Del(ctx, tableSessionKey(sessionID))
+			pipe.SRem(ctx, tableSessionsIndexKey, sessionID)
+
+			return nil
+		})
+
+		return err
+	})
+	if err != nil {
+		l.RepoError(err, map[string]any{"session_id": sessionID})
+		return apperrors.RemoveTableSnapshotErr
+	}
+
+	return nil
+}
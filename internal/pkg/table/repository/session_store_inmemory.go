@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"sync"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	tableinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table"
+)
+
+// inMemoryTableSessionStore — хранилище снапшотов в памяти процесса, для тестов и локального запуска
+type inMemoryTableSessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]models.TableSessionSnapshot
+}
+
+func NewInMemoryTableSessionStore() tableinterfaces.TableSessionStore {
+	return &inMemoryTableSessionStore{
+		sessions: make(map[string]models.TableSessionSnapshot),
+	}
+}
+
+func (s *inMemoryTableSessionStore) SaveSession(_ context.Context, snapshot *models.TableSessionSnapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions[snapshot.SessionID] = *snapshot
+
+	return nil
+}
+
+func (s *inMemoryTableSessionStore) GetSession(_ context.Context,
+	sessionID string) (*models.TableSessionSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot, ok := s.sessions[sessionID]
+	if !ok {
+		return nil, apperrors.TableNotFoundErr
+	}
+
+	return &snapshot, nil
+}
+
+func (s *inMemoryTableSessionStore) ListSessions(_ context.Context) ([]*models.TableSessionSnapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshots := make([]*models.TableSessionSnapshot, 0, len(s.sessions))
+	for _, snapshot := range s.sessions {
+		snapshots = append(snapshots, &snapshot)
+	}
+
+	return snapshots, nil
+}
+
+func (s *inMemoryTableSessionStore) RemoveSession(_ context.Context, sessionID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, sessionID)
+
+	return nil
+}
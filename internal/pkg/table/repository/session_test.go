@@ -0,0 +1,486 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/delivery/responses"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table/initiative"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+const (
+	testSessionID = "session-1"
+	testAdminID   = 1
+	testAdminConn = "conn-admin"
+)
+
+// wsMsg is a decoded outbound payload: either an ok response or an error one
+type wsMsg struct {
+	Type  string          `json:"type"`
+	Data  json.RawMessage `json:"data"`
+	Error string          `json:"error"`
+	Seq   int64           `json:"seq"`
+}
+
+// testTable drives a session directly, without the run goroutine, and collects everything it publishes
+// to the outbound channel of the in-memory pubsub
+type testTable struct {
+	t   *testing.T
+	ctx context.Context
+	s   *session
+	out <-chan []byte
+}
+
+func newTestTable(t *testing.T, data string) *testTable {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	lifecycle := mocks.NewMockSessionLifecycle(ctrl)
+	lifecycle.EXPECT().Refresh(gomock.Any()).AnyTimes()
+	lifecycle.EXPECT().Pause(gomock.Any()).AnyTimes()
+	lifecycle.EXPECT().Resume(gomock.Any()).AnyTimes()
+	lifecycle.EXPECT().End(gomock.Any()).AnyTimes()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	pubsub := NewInMemoryTablePubSub()
+
+	out, err := pubsub.Subscribe(ctx, outboundChannel(testSessionID))
+	assert.NoError(t, err)
+
+	s := &session{
+		id:            testSessionID,
+		encounterID:   "enc-1",
+		encounterName: "Goblin ambush",
+		encounterData: []byte(data),
+		initialData:   []byte(data),
+		permissions:   make(map[int][]string),
+		initiative:    initiative.NewTracker(nil),
+		adminID:       testAdminID,
+		adminName:     "Admin",
+		maxPlayers:    defaultMaxPlayers,
+		participants:  make(map[int]*participant),
+		members:       make(map[int]models.MemberGrant),
+		lifecycle:     lifecycle,
+		pending:       make(map[int]*tableEvent),
+		inviteUses:    make(map[string]int),
+		pubsub:        pubsub,
+		cancel:        cancel,
+		start:         time.Now(),
+	}
+
+	return &testTable{t: t, ctx: ctx, s: s, out: out}
+}
+
+// drain returns the messages published since the previous call
+func (tt *testTable) drain() []outboundMsg {
+	tt.t.Helper()
+
+	msgs := make([]outboundMsg, 0)
+
+	for {
+		select {
+		case raw := <-tt.out:
+			var msg outboundMsg
+			assert.NoError(tt.t, json.Unmarshal(raw, &msg))
+			msgs = append(msgs, msg)
+		default:
+			return msgs
+		}
+	}
+}
+
+func (tt *testTable) join(event *tableEvent) []outboundMsg {
+	event.Kind = eventJoin
+	tt.s.handleJoin(tt.ctx, event)
+
+	return tt.drain()
+}
+
+func (tt *testTable) leave(userID int, connID string) []outboundMsg {
+	tt.s.handleLeave(tt.ctx, &tableEvent{Kind: eventLeave, UserID: userID, ConnID: connID})
+
+	return tt.drain()
+}
+
+func (tt *testTable) send(userID int, connID string, msgType models.WSMsgType, data any) []outboundMsg {
+	tt.t.Helper()
+
+	rawData, err := json.Marshal(data)
+	assert.NoError(tt.t, err)
+
+	payload, err := json.Marshal(&models.WSRequest{Type: msgType, Data: rawData})
+	assert.NoError(tt.t, err)
+
+	tt.s.handleMessage(tt.ctx, &tableEvent{Kind: eventMessage, UserID: userID, ConnID: connID, Payload: payload})
+
+	return tt.drain()
+}
+
+func (tt *testTable) patch(userID int, connID string, baseRevision int, format models.PatchFormat,
+	patch string) []outboundMsg {
+	return tt.send(userID, connID, models.Patch,
+		&models.PatchRequest{BaseRevision: baseRevision, Format: format, Patch: json.RawMessage(patch)})
+}
+
+// joinAdmin connects the game master and drops the initial state messages
+func (tt *testTable) joinAdmin() {
+	tt.join(&tableEvent{UserID: testAdminID, ConnID: testAdminConn, Name: "Admin"})
+}
+
+// joinPlayer connects a player who was already let into the session
+func (tt *testTable) joinPlayer(userID int, connID string) {
+	tt.s.members[userID] = models.MemberGrant{Role: models.Player}
+	tt.join(&tableEvent{UserID: userID, ConnID: connID, Name: fmt.Sprintf("Player %d", userID)})
+}
+
+// received decodes the messages a connection of the user would get
+func received(t *testing.T, msgs []outboundMsg, connID string, userID int) []wsMsg {
+	t.Helper()
+
+	result := make([]wsMsg, 0)
+
+	for _, msg := range msgs {
+		if len(msg.Payload) == 0 || !msg.matches(connID, userID) {
+			continue
+		}
+
+		var decoded wsMsg
+		assert.NoError(t, json.Unmarshal(msg.Payload, &decoded))
+		result = append(result, decoded)
+	}
+
+	return result
+}
+
+func findMsg(msgs []wsMsg, msgType models.WSMsgType) (wsMsg, bool) {
+	for _, msg := range msgs {
+		if msg.Type == string(msgType) {
+			return msg, true
+		}
+	}
+
+	return wsMsg{}, false
+}
+
+func errorsOf(msgs []wsMsg) []string {
+	errs := make([]string, 0)
+	for _, msg := range msgs {
+		if msg.Type == "error" {
+			errs = append(errs, msg.Error)
+		}
+	}
+
+	return errs
+}
+
+func decodeData[T any](t *testing.T, msg wsMsg) T {
+	t.Helper()
+
+	var data T
+	assert.NoError(t, json.Unmarshal(msg.Data, &data))
+
+	return data
+}
+
+func TestSession_PatchAndUndo(t *testing.T) {
+	t.Parallel()
+
+	tt := newTestTable(t, `{"hp":10,"name":"goblin"}`)
+	tt.joinAdmin()
+
+	msgs := received(t, tt.patch(testAdminID, testAdminConn, 0, models.MergePatch, `{"hp":5}`), testAdminConn,
+		testAdminID)
+	info, ok := findMsg(msgs, models.BattleInfo)
+	assert.True(t, ok)
+	assert.Equal(t, 1, decodeData[models.EncounterData](t, info).Revision)
+
+	tt.patch(testAdminID, testAdminConn, 1, models.JSONPatch, `[{"op":"replace","path":"/name","value":"orc"}]`)
+	assert.JSONEq(t, `{"hp":5,"name":"orc"}`, string(tt.s.encounterData))
+
+	msgs = received(t, tt.send(testAdminID, testAdminConn, models.Undo, &models.UndoRequest{Count: 1}),
+		testAdminConn, testAdminID)
+	info, ok = findMsg(msgs, models.BattleInfo)
+	assert.True(t, ok)
+	assert.Equal(t, 3, decodeData[models.EncounterData](t, info).Revision)
+	assert.JSONEq(t, `{"hp":5,"name":"goblin"}`, string(tt.s.encounterData))
+
+	log := tt.s.GetOperationLog()
+	assert.Len(t, log.Operations, 3)
+	assert.True(t, log.Operations[1].Undone)
+	assert.Equal(t, models.UndoOperation, log.Operations[2].Kind)
+	assert.Equal(t, []int{2}, log.Operations[2].Reverts)
+
+	// The undo changed "name" after revision 1, so a patch based on it conflicts, while "hp" does not
+	msgs = received(t, tt.patch(testAdminID, testAdminConn, 1, models.MergePatch, `{"name":"troll"}`),
+		testAdminConn, testAdminID)
+	conflict, ok := findMsg(msgs, models.Conflict)
+	assert.True(t, ok)
+	assert.Equal(t, models.ConflictMsg{BaseRevision: 1, CurrentRevision: 3, Paths: []string{"name"}},
+		decodeData[models.ConflictMsg](t, conflict))
+
+	tt.patch(testAdminID, testAdminConn, 1, models.MergePatch, `{"hp":1}`)
+	assert.Equal(t, 4, tt.s.revision)
+	assert.JSONEq(t, `{"hp":1,"name":"goblin"}`, string(tt.s.encounterData))
+}
+
+func TestSession_PatchFromFutureRevisionIsRejected(t *testing.T) {
+	t.Parallel()
+
+	tt := newTestTable(t, `{"hp":10}`)
+	tt.joinAdmin()
+
+	msgs := received(t, tt.patch(testAdminID, testAdminConn, 5, models.MergePatch, `{"hp":5}`), testAdminConn,
+		testAdminID)
+	assert.Equal(t, []string{responses.ErrInvalidPatchWS}, errorsOf(msgs))
+	assert.Equal(t, 0, tt.s.revision)
+}
+
+func TestSession_UndoIsAdminOnly(t *testing.T) {
+	t.Parallel()
+
+	tt := newTestTable(t, `{"hp":10}`)
+	tt.joinAdmin()
+	tt.joinPlayer(2, "conn-2")
+
+	tt.patch(testAdminID, testAdminConn, 0, models.MergePatch, `{"hp":5}`)
+
+	msgs := received(t, tt.send(2, "conn-2", models.Undo, &models.UndoRequest{Count: 1}), "conn-2", 2)
+	assert.Equal(t, []string{responses.ErrUndoForbiddenWS}, errorsOf(msgs))
+	assert.JSONEq(t, `{"hp":5}`, string(tt.s.encounterData))
+}
+
+func TestSession_PatchPermissions(t *testing.T) {
+	t.Parallel()
+
+	const data = `{"_hidden":["notes"],"notes":"trap","hp":10,"monsters":[{"hp":3}]}`
+
+	tests := []struct {
+		name        string
+		format      models.PatchFormat
+		patch       string
+		wantAllowed bool
+	}{
+		{
+			name:        "writable path is applied",
+			format:      models.MergePatch,
+			patch:       `{"hp":7}`,
+			wantAllowed: true,
+		},
+		{
+			name:   "path outside of the granted ones is forbidden",
+			format: models.MergePatch,
+			patch:  `{"monsters":[{"hp":1}]}`,
+		},
+		{
+			name:   "hidden path is forbidden",
+			format: models.MergePatch,
+			patch:  `{"notes":"none"}`,
+		},
+		{
+			name:   "hidden list itself is forbidden",
+			format: models.JSONPatch,
+			patch:  `[{"op":"remove","path":"/_hidden"}]`,
+		},
+		{
+			name:   "copying a hidden path into a writable one is forbidden",
+			format: models.JSONPatch,
+			patch:  `[{"op":"copy","from":"/notes","path":"/hp"}]`,
+		},
+		{
+			name:   "testing a hidden path is forbidden",
+			format: models.JSONPatch,
+			patch:  `[{"op":"test","path":"/notes","value":"trap"},{"op":"replace","path":"/hp","value":1}]`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tt := newTestTable(t, data)
+			tt.joinAdmin()
+			tt.joinPlayer(2, "conn-2")
+
+			tt.send(testAdminID, testAdminConn, models.Permissions,
+				&models.PermissionsMsg{UserID: 2, WritablePaths: []string{"hp"}})
+
+			msgs := received(t, tt.patch(2, "conn-2", 0, tc.format, tc.patch), "conn-2", 2)
+
+			if tc.wantAllowed {
+				assert.Empty(t, errorsOf(msgs))
+				assert.Equal(t, 1, tt.s.revision)
+
+				return
+			}
+
+			assert.Equal(t, []string{responses.ErrPatchForbiddenWS}, errorsOf(msgs))
+			assert.Equal(t, 0, tt.s.revision)
+			assert.JSONEq(t, data, string(tt.s.encounterData))
+		})
+	}
+}
+
+func TestSession_PermissionsAreAdminOnly(t *testing.T) {
+	t.Parallel()
+
+	tt := newTestTable(t, `{"hp":10}`)
+	tt.joinAdmin()
+	tt.joinPlayer(2, "conn-2")
+	tt.joinPlayer(3, "conn-3")
+
+	msgs := received(t, tt.send(2, "conn-2", models.Permissions,
+		&models.PermissionsMsg{UserID: 3, WritablePaths: []string{}}), "conn-2", 2)
+	assert.Equal(t, []string{responses.ErrPermissionsForbiddenWS}, errorsOf(msgs))
+	assert.NotContains(t, tt.s.permissions, 3)
+}
+
+func TestSession_PermissionsReachOnlyTargetAndAdmin(t *testing.T) {
+	t.Parallel()
+
+	tt := newTestTable(t, `{"hp":10}`)
+	tt.joinAdmin()
+	tt.joinPlayer(2, "conn-2")
+	tt.joinPlayer(3, "conn-3")
+
+	out := tt.send(testAdminID, testAdminConn, models.Permissions,
+		&models.PermissionsMsg{UserID: 2, WritablePaths: []string{"hp"}})
+
+	for _, tc := range []struct {
+		userID   int
+		connID   string
+		wantSeen bool
+	}{
+		{testAdminID, testAdminConn, true},
+		{2, "conn-2", true},
+		{3, "conn-3", false},
+	} {
+		_, ok := findMsg(received(t, out, tc.connID, tc.userID), models.Permissions)
+		assert.Equal(t, tc.wantSeen, ok, "user %d", tc.userID)
+	}
+}
+
+func TestSession_SpectatorIsReadOnly(t *testing.T) {
+	t.Parallel()
+
+	tt := newTestTable(t, `{"hp":10}`)
+	tt.joinAdmin()
+	tt.join(&tableEvent{UserID: 2, ConnID: "conn-2", Role: models.Spectator,
+		Invite: &models.InviteClaims{ID: "inv-1", SessionID: testSessionID, Role: models.Spectator}})
+
+	msgs := received(t, tt.patch(2, "conn-2", 0, models.MergePatch, `{"hp":1}`), "conn-2", 2)
+	assert.Equal(t, []string{responses.ErrSpectatorReadOnlyWS}, errorsOf(msgs))
+	assert.Equal(t, 0, tt.s.revision)
+}
+
+func TestSession_Redaction(t *testing.T) {
+	t.Parallel()
+
+	const data = `{"_hidden":["notes"],"notes":"trap","hp":10}`
+
+	tt := newTestTable(t, data)
+
+	msgs := received(t, tt.join(&tableEvent{UserID: testAdminID, ConnID: testAdminConn}), testAdminConn,
+		testAdminID)
+	info, ok := findMsg(msgs, models.BattleInfo)
+	assert.True(t, ok)
+	assert.JSONEq(t, data, string(decodeData[models.EncounterData](t, info).EncounterData))
+
+	tt.s.members[2] = models.MemberGrant{Role: models.Player}
+
+	msgs = received(t, tt.join(&tableEvent{UserID: 2, ConnID: "conn-2"}), "conn-2", 2)
+	info, ok = findMsg(msgs, models.BattleInfo)
+	assert.True(t, ok)
+	assert.JSONEq(t, `{"hp":10}`, string(decodeData[models.EncounterData](t, info).EncounterData))
+
+	// Updates are split into a full one for the game master and a redacted one for everybody else
+	out := tt.patch(testAdminID, testAdminConn, 0, models.MergePatch, `{"notes":"ambush","hp":8}`)
+
+	info, ok = findMsg(received(t, out, testAdminConn, testAdminID), models.BattleInfo)
+	assert.True(t, ok)
+	assert.JSONEq(t, `{"_hidden":["notes"],"notes":"ambush","hp":8}`,
+		string(decodeData[models.EncounterData](t, info).EncounterData))
+
+	info, ok = findMsg(received(t, out, "conn-2", 2), models.BattleInfo)
+	assert.True(t, ok)
+	assert.JSONEq(t, `{"hp":8}`, string(decodeData[models.EncounterData](t, info).EncounterData))
+
+	tableData, err := tt.s.GetTableData(2)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"hp":8}`, string(tableData.EncounterData))
+}
+
+// appendPatches adds count merge patches setting hp to the operation number
+func (tt *testTable) appendPatches(count int) {
+	for i := 1; i <= count; i++ {
+		tt.s.appendOperation(models.TableOperation{Kind: models.PatchOperation, Format: models.MergePatch,
+			Patch: json.RawMessage(fmt.Sprintf(`{"hp":%d}`, tt.s.revision+1))})
+	}
+}
+
+func TestSession_OperationLogCompaction(t *testing.T) {
+	t.Parallel()
+
+	tt := newTestTable(t, `{"hp":0}`)
+
+	logRepo := mocks.NewMockTableLogRepository(gomock.NewController(t))
+	tt.s.logRepo = logRepo
+
+	// Below the batch size nothing is written
+	tt.appendPatches(maxOperations + archiveBatch - 1)
+	tt.s.archiveOperations(tt.ctx)
+	assert.Len(t, tt.s.operations, maxOperations+archiveBatch-1)
+
+	tt.appendPatches(1)
+
+	logRepo.EXPECT().AppendOperations(gomock.Any(), gomock.Any()).DoAndReturn(
+		func(_ context.Context, opLog *models.TableOperationLog) error {
+			assert.Equal(t, testSessionID, opLog.SessionID)
+			assert.JSONEq(t, `{"hp":0}`, string(opLog.InitialData))
+			assert.Len(t, opLog.Operations, archiveBatch)
+			assert.Equal(t, 1, opLog.Operations[0].Seq)
+
+			return nil
+		})
+	tt.s.archiveOperations(tt.ctx)
+
+	assert.Len(t, tt.s.operations, maxOperations)
+	assert.Equal(t, archiveBatch+1, tt.s.operations[0].Seq)
+	assert.Equal(t, maxOperations+archiveBatch, tt.s.revision)
+	assert.JSONEq(t, fmt.Sprintf(`{"hp":%d}`, archiveBatch), string(tt.s.initialData))
+	assert.JSONEq(t, fmt.Sprintf(`{"hp":%d}`, maxOperations+archiveBatch), string(tt.s.replay(tt.ctx)))
+
+	// Operations after an old base revision are gone, so every path of the patch conflicts
+	conflicts, err := tt.s.conflictingPaths(1, []byte(`{"name":"orc"}`), models.MergePatch)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"name"}, conflicts)
+
+	conflicts, err = tt.s.conflictingPaths(archiveBatch, []byte(`{"name":"orc"}`), models.MergePatch)
+	assert.NoError(t, err)
+	assert.Empty(t, conflicts)
+}
+
+func TestSession_OperationLogKeptWhenArchiveFails(t *testing.T) {
+	t.Parallel()
+
+	tt := newTestTable(t, `{"hp":0}`)
+
+	logRepo := mocks.NewMockTableLogRepository(gomock.NewController(t))
+	logRepo.EXPECT().AppendOperations(gomock.Any(), gomock.Any()).Return(apperrors.TxError)
+	tt.s.logRepo = logRepo
+
+	tt.appendPatches(maxOperations + archiveBatch)
+	tt.s.archiveOperations(tt.ctx)
+
+	assert.Len(t, tt.s.operations, maxOperations+archiveBatch)
+	assert.JSONEq(t, `{"hp":0}`, string(tt.s.initialData))
+}
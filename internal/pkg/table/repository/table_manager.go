@@ -2,98 +2,462 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"slices"
+	"sync"
+	"time"
+
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/metrics"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/server/delivery/responses"
 	tableinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table/initiative"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils/redactor"
+	"github.com/google/uuid"
 	"github.com/gorilla/websocket"
-	"sync"
-	"time"
 )
 
-const maxPlayersNum = 4
+// defaultMaxPlayers используется, если ведущий не задал число игроков при создании сессии
+const defaultMaxPlayers = 4
 
 type tableManager struct {
-	sessions       map[string]*session
+	sessions       map[string]*session    // Сессии, владельцем которых является эта реплика
+	hubs           map[string]*sessionHub // Клиенты, подключенные к этой реплике
 	mu             sync.RWMutex
 	metrics        metrics.WSMetrics
 	sessionMetrics metrics.WSSessionMetrics
+
+	store  tableinterfaces.TableSessionStore
+	pubsub tableinterfaces.TablePubSub
+	locker tableinterfaces.SessionLocker
+
+	stats   tableinterfaces.CombatantStatsProvider
+	logRepo tableinterfaces.TableLogRepository
+
+	replicaID      string
+	lockTTL        time.Duration
+	reconnectGrace time.Duration
+	deltas         bool // Рассылать изменения энкаунтера разницей
 }
 
-func NewTableManager(metrics metrics.WSMetrics, sessionMetrics metrics.WSSessionMetrics) tableinterfaces.TableManager {
+func NewTableManager(metrics metrics.WSMetrics, sessionMetrics metrics.WSSessionMetrics,
+	store tableinterfaces.TableSessionStore, pubsub tableinterfaces.TablePubSub,
+	locker tableinterfaces.SessionLocker, stats tableinterfaces.CombatantStatsProvider,
+	logRepo tableinterfaces.TableLogRepository, lockTTL, reconnectGrace time.Duration, deltas bool) tableinterfaces.TableManager {
 	return &tableManager{
 		sessions:       make(map[string]*session),
+		hubs:           make(map[string]*sessionHub),
 		metrics:        metrics,
 		sessionMetrics: sessionMetrics,
+		store:          store,
+		pubsub:         pubsub,
+		locker:         locker,
+		stats:          stats,
+		logRepo:        logRepo,
+		replicaID:      uuid.NewString(),
+		lockTTL:        lockTTL,
+		reconnectGrace: reconnectGrace,
+		deltas:         deltas,
 	}
 }
 
 func (tm *tableManager) CreateSession(ctx context.Context, admin *models.User, encounter *models.Encounter,
-	sessionID string, callback func(sessionID string)) {
+	sessionID string, maxPlayers int, lifecycle tableinterfaces.SessionLifecycle) error {
 	l := logger.FromContext(ctx)
+
+	// У энкаунтера может быть только одна сессия: её закрепляет блокировка, владельцем которой
+	// является сессия, а не реплика
+	ok, err := tm.locker.TryLock(ctx, encounterLockKey(encounter.UUID), sessionID, tm.lockTTL)
+	if err != nil {
+		return err
+	} else if !ok {
+		l.RepoWarn(apperrors.EncounterSessionErr, map[string]any{"session_id": sessionID,
+			"encounter_id": encounter.UUID})
+		return apperrors.EncounterSessionErr
+	}
+
+	ok, err = tm.locker.TryLock(ctx, sessionID, tm.replicaID, tm.lockTTL)
+	if err != nil || !ok {
+		tm.locker.Unlock(ctx, encounterLockKey(encounter.UUID), sessionID)
+
+		if err != nil {
+			return err
+		}
+
+		l.RepoWarn(apperrors.TableLockErr, map[string]any{"session_id": sessionID})
+		return apperrors.TableLockErr
+	}
+
+	encounterData, rolls, chat, err := extractSessionHistory(encounter.Data)
+	if err != nil {
+		l.RepoWarn(err, map[string]any{"session_id": sessionID, "encounter_id": encounter.UUID})
+		encounterData, rolls, chat = encounter.Data, nil, nil
+	}
+
+	newSession, err := tm.startSession(ctx, &models.TableSessionSnapshot{
+		SessionID:     sessionID,
+		EncounterID:   encounter.UUID,
+		EncounterName: encounter.Name,
+		EncounterData: encounterData,
+		AdminID:       admin.ID,
+		AdminName:     admin.DisplayName,
+		StartedAt:     time.Now(),
+		Rolls:         rolls,
+		Chat:          chat,
+		MaxPlayers:    maxPlayers,
+	}, lifecycle)
+	if err != nil {
+		tm.locker.Unlock(ctx, sessionID, tm.replicaID)
+		tm.locker.Unlock(ctx, encounterLockKey(encounter.UUID), sessionID)
+
+		return err
+	}
+
+	if err := tm.store.SaveSession(ctx, newSession.Snapshot()); err != nil {
+		newSession.MarkDirty()
+	}
+
+	l.RepoInfo("created WS session", map[string]any{"admin_id": admin.ID, "session_id": sessionID})
+
+	return nil
+}
+
+// AdoptSession делает эту реплику владельцем сессии из хранилища снапшотов, если у сессии
+// нет живого владельца: после рестарта сервера или падения другой реплики
+func (tm *tableManager) AdoptSession(ctx context.Context, sessionID string,
+	lifecycle tableinterfaces.SessionLifecycle) (*models.TableSessionSnapshot, bool) {
+	tm.mu.RLock()
+	_, owned := tm.sessions[sessionID]
+	tm.mu.RUnlock()
+
+	if owned {
+		return nil, false
+	}
+
+	snapshot, err := tm.store.GetSession(ctx, sessionID)
+	if err != nil {
+		return nil, false
+	}
+
+	return snapshot, tm.adopt(ctx, snapshot, lifecycle)
+}
+
+// RestoreSessions подхватывает все сессии из хранилища снапшотов, оставшиеся без владельца
+func (tm *tableManager) RestoreSessions(ctx context.Context,
+	lifecycle tableinterfaces.SessionLifecycle) []*models.TableSessionSnapshot {
+	l := logger.FromContext(ctx)
+
+	snapshots, err := tm.store.ListSessions(ctx)
+	if err != nil {
+		l.RepoError(err, nil)
+		return nil
+	}
+
+	restored := make([]*models.TableSessionSnapshot, 0, len(snapshots))
+
+	for _, snapshot := range snapshots {
+		tm.mu.RLock()
+		_, owned := tm.sessions[snapshot.SessionID]
+		tm.mu.RUnlock()
+
+		if owned {
+			continue
+		}
+
+		if tm.adopt(ctx, snapshot, lifecycle) {
+			restored = append(restored, snapshot)
+		}
+	}
+
+	return restored
+}
+
+func (tm *tableManager) adopt(ctx context.Context, snapshot *models.TableSessionSnapshot,
+	lifecycle tableinterfaces.SessionLifecycle) bool {
+	l := logger.FromContext(ctx)
+
+	ok, err := tm.locker.TryLock(ctx, snapshot.SessionID, tm.replicaID, tm.lockTTL)
+	if err != nil || !ok {
+		return false
+	}
+
+	newSession, err := tm.startSession(ctx, snapshot, lifecycle)
+	if err != nil {
+		tm.locker.Unlock(ctx, snapshot.SessionID, tm.replicaID)
+		return false
+	}
+
+	tm.holdEncounterLock(ctx, newSession)
+	newSession.requestRejoin(context.WithoutCancel(ctx))
+
+	l.RepoInfo("restored WS session", map[string]any{"admin_id": snapshot.AdminID,
+		"session_id": snapshot.SessionID, "replica_id": tm.replicaID})
+
+	return true
+}
+
+// RunSnapshots периодически сохраняет изменённые сессии в хранилище. Блокируется до отмены контекста
+func (tm *tableManager) RunSnapshots(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			tm.saveSnapshots(ctx)
+		}
+	}
+}
+
+func (tm *tableManager) saveSnapshots(ctx context.Context) {
+	tm.mu.RLock()
+	sessions := make([]*session, 0, len(tm.sessions))
+	for _, activeSession := range tm.sessions {
+		sessions = append(sessions, activeSession)
+	}
+	tm.mu.RUnlock()
+
+	for _, activeSession := range sessions {
+		snapshot, ok := activeSession.TakeDirtySnapshot()
+		if !ok {
+			continue
+		}
+
+		if err := tm.store.SaveSession(ctx, snapshot); err != nil {
+			activeSession.MarkDirty()
+		}
+	}
+}
+
+func (tm *tableManager) startSession(ctx context.Context, snapshot *models.TableSessionSnapshot,
+	lifecycle tableinterfaces.SessionLifecycle) (*session, error) {
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+	inbound, err := tm.pubsub.Subscribe(runCtx, inboundChannel(snapshot.SessionID))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	initialData := snapshot.InitialData
+	if initialData == nil {
+		initialData = snapshot.EncounterData
+	}
+
+	permissions := snapshot.Permissions
+	if permissions == nil {
+		permissions = make(map[int][]string)
+	}
+
+	inviteUses := snapshot.InviteUses
+	if inviteUses == nil {
+		inviteUses = make(map[string]int)
+	}
+
+	lastActivity := snapshot.LastActivityAt
+	if lastActivity.IsZero() {
+		lastActivity = snapshot.StartedAt
+	}
+
 	newSession := &session{
-		encounterID:     encounter.UUID,
-		encounterName:   encounter.Name,
-		encounterData:   encounter.Data,
-		adminID:         admin.ID,
-		adminName:       admin.DisplayName,
-		participants:    make(map[int]*participant),
-		broadcast:       make(chan []byte),
-		refreshCallback: callback,
-		start:           time.Now(),
-		metrics:         tm.sessionMetrics,
+		id:             snapshot.SessionID,
+		encounterID:    snapshot.EncounterID,
+		encounterName:  snapshot.EncounterName,
+		encounterData:  snapshot.EncounterData,
+		initialData:    initialData,
+		operations:     snapshot.Operations,
+		revision:       snapshotRevision(snapshot),
+		permissions:    permissions,
+		rolls:          snapshot.Rolls,
+		chat:           snapshot.Chat,
+		initiative:     initiative.NewTracker(snapshot.Initiative),
+		adminID:        snapshot.AdminID,
+		adminName:      snapshot.AdminName,
+		maxPlayers:     snapshotMaxPlayers(snapshot),
+		participants:   make(map[int]*participant),
+		lifecycle:      lifecycle,
+		paused:         snapshot.Paused,
+		banned:         snapshot.Banned,
+		members:        snapshotMembers(snapshot),
+		pending:        make(map[int]*tableEvent),
+		inviteUses:     inviteUses,
+		lastActivity:   lastActivity,
+		stats:          tm.stats,
+		logRepo:        tm.logRepo,
+		reconnectGrace: tm.reconnectGrace,
+		deltas:         tm.deltas,
+		outSeq:         snapshot.Seq,
+		pubsub:         tm.pubsub,
+		cancel:         cancel,
+		start:          snapshot.StartedAt,
 	}
 
 	tm.mu.Lock()
-	tm.sessions[sessionID] = newSession
+	tm.sessions[snapshot.SessionID] = newSession
 	tm.metrics.IncSessions()
 	tm.mu.Unlock()
 
-	l.RepoInfo("created WS session", map[string]any{"admin_id": admin.ID, "session_id": sessionID})
+	go newSession.run(runCtx, inbound)
+	go tm.keepOwnership(runCtx, newSession)
+
+	return newSession, nil
+}
+
+// keepOwnership продлевает блокировку сессии. Если блокировку перехватила другая реплика,
+// сессия перестаёт обслуживаться здесь, но остаётся в хранилище
+func (tm *tableManager) keepOwnership(ctx context.Context, activeSession *session) {
+	l := logger.FromContext(ctx)
+
+	ticker := time.NewTicker(tm.lockTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ok, err := tm.locker.Refresh(ctx, activeSession.id, tm.replicaID, tm.lockTTL)
+			if err != nil {
+				continue
+			} else if ok {
+				tm.holdEncounterLock(ctx, activeSession)
+				continue
+			}
+
+			l.RepoWarn(apperrors.TableLockErr, map[string]any{"session_id": activeSession.id,
+				"replica_id": tm.replicaID})
 
-	go newSession.run(ctx)
+			tm.mu.Lock()
+			if tm.sessions[activeSession.id] == activeSession {
+				delete(tm.sessions, activeSession.id)
+			}
+			tm.mu.Unlock()
+
+			activeSession.cancel()
+
+			return
+		}
+	}
+}
+
+// holdEncounterLock продлевает блокировку энкаунтера сессии или заново захватывает её, если
+// она истекла, пока у сессии не было владельца
+func (tm *tableManager) holdEncounterLock(ctx context.Context, activeSession *session) {
+	key := encounterLockKey(activeSession.encounterID)
+
+	ok, err := tm.locker.Refresh(ctx, key, activeSession.id, tm.lockTTL)
+	if err != nil || ok {
+		return
+	}
+
+	tm.locker.TryLock(ctx, key, activeSession.id, tm.lockTTL)
+}
+
+func encounterLockKey(encounterID string) string {
+	return "encounter:" + encounterID
 }
 
 func (tm *tableManager) RemoveSession(ctx context.Context, sessionID string) {
 	l := logger.FromContext(ctx)
+	ctx = context.WithoutCancel(ctx)
 
-	tm.mu.RLock()
+	tm.mu.Lock()
 	activeSession, ok := tm.sessions[sessionID]
-	tm.mu.RUnlock()
+	delete(tm.sessions, sessionID)
+	tm.mu.Unlock()
 
 	if !ok {
 		l.RepoWarn(apperrors.TableNotFoundErr, map[string]any{"session_id": sessionID})
 		return
 	}
 
-	for key := range activeSession.participants {
-		activeSession.RemoveParticipant(ctx, key)
-		l.RepoInfo("user successfully removed", map[string]any{"session_id": sessionID, "participant_id": key})
-	}
+	activeSession.end(ctx)
+	activeSession.cancel()
 
-	tm.mu.Lock()
 	tm.metrics.IncreaseDuration(time.Since(activeSession.start))
-	delete(tm.sessions, sessionID)
-	tm.mu.Unlock()
+	tm.store.RemoveSession(ctx, sessionID)
+	tm.locker.Unlock(ctx, sessionID, tm.replicaID)
+	tm.locker.Unlock(ctx, encounterLockKey(activeSession.encounterID), sessionID)
 
 	l.RepoInfo("session removed", map[string]any{"session_id": sessionID})
 }
 
-func (tm *tableManager) GetTableData(ctx context.Context, sessionID string) (*models.TableData, error) {
+func (tm *tableManager) GetTableData(ctx context.Context, sessionID string, userID int) (*models.TableData, error) {
 	l := logger.FromContext(ctx)
 
 	tm.mu.RLock()
 	activeSession, ok := tm.sessions[sessionID]
 	tm.mu.RUnlock()
 
-	if !ok {
+	if ok {
+		data, err := activeSession.GetTableData(userID)
+		if err != nil {
+			l.RepoError(err, map[string]any{"session_id": sessionID, "user_id": userID})
+			return nil, apperrors.TableNotFoundErr
+		}
+
+		return data, nil
+	}
+
+	// Сессией владеет другая реплика, отдаём последний снапшот
+	snapshot, err := tm.store.GetSession(ctx, sessionID)
+	if err != nil {
 		l.RepoWarn(apperrors.TableNotFoundErr, map[string]any{"session_id": sessionID})
 		return nil, apperrors.TableNotFoundErr
 	}
 
-	return activeSession.GetTableData(), nil
+	encounterData := []byte(snapshot.EncounterData)
+	if userID != snapshot.AdminID {
+		encounterData, err = redactor.Redact(encounterData)
+		if err != nil {
+			l.RepoError(err, map[string]any{"session_id": sessionID, "user_id": userID})
+			return nil, apperrors.TableNotFoundErr
+		}
+	}
+
+	return &models.TableData{
+		AdminName:     snapshot.AdminName,
+		EncounterName: snapshot.EncounterName,
+		EncounterData: encounterData,
+		Revision:      snapshotRevision(snapshot),
+		MaxPlayers:    snapshotMaxPlayers(snapshot),
+	}, nil
+}
+
+func snapshotRevision(snapshot *models.TableSessionSnapshot) int {
+	if len(snapshot.Operations) == 0 {
+		return 0
+	}
+
+	return snapshot.Operations[len(snapshot.Operations)-1].Seq
+}
+
+// snapshotMembers восстанавливает права участников. В снапшотах, сохранённых до появления Grants,
+// участники считаются игроками без персонажа
+func snapshotMembers(snapshot *models.TableSessionSnapshot) map[int]models.MemberGrant {
+	members := make(map[int]models.MemberGrant, len(snapshot.Members))
+	for _, userID := range snapshot.Members {
+		grant, ok := snapshot.Grants[userID]
+		if !ok {
+			grant = models.MemberGrant{Role: models.Player}
+		}
+
+		members[userID] = grant
+	}
+
+	return members
+}
+
+func snapshotMaxPlayers(snapshot *models.TableSessionSnapshot) int {
+	if snapshot.MaxPlayers == 0 {
+		return defaultMaxPlayers
+	}
+
+	return snapshot.MaxPlayers
 }
 
 func (tm *tableManager) GetEncounterData(ctx context.Context, sessionID string) ([]byte, error) {
@@ -108,76 +472,288 @@ func (tm *tableManager) GetEncounterData(ctx context.Context, sessionID string)
 		return nil, apperrors.TableNotFoundErr
 	}
 
-	return activeSession.GetEncounterData(), nil
+	data, err := activeSession.GetEncounterData()
+	if err != nil {
+		l.RepoError(err, map[string]any{"session_id": sessionID})
+		return nil, err
+	}
+
+	return data, nil
 }
 
-func (tm *tableManager) AddNewConnection(ctx context.Context, user *models.User, sessionID string,
-	conn *websocket.Conn) {
+func (tm *tableManager) GetOperationLog(ctx context.Context, sessionID string) (*models.TableOperationLog, error) {
 	l := logger.FromContext(ctx)
 
 	tm.mu.RLock()
 	activeSession, ok := tm.sessions[sessionID]
-	tm.metrics.IncConns()
 	tm.mu.RUnlock()
 
-	if !ok {
+	if ok {
+		return activeSession.GetOperationLog(), nil
+	}
+
+	snapshot, err := tm.store.GetSession(ctx, sessionID)
+	if err != nil {
 		l.RepoWarn(apperrors.TableNotFoundErr, map[string]any{"session_id": sessionID})
-		responses.SendWSErrResponse(conn, responses.WSStatusBadRequest, responses.ErrInvalidWSSessionID)
-		conn.Close()
+		return nil, apperrors.TableNotFoundErr
+	}
 
-		return
+	return &models.TableOperationLog{
+		SessionID:   snapshot.SessionID,
+		EncounterID: snapshot.EncounterID,
+		AdminID:     snapshot.AdminID,
+		InitialData: snapshot.InitialData,
+		Operations:  snapshot.Operations,
+		StartedAt:   snapshot.StartedAt,
+	}, nil
+}
+
+// ListSessions возвращает снапшоты активных сессий, которые пользователь ведёт или к которым
+// подключался. Сессии этой реплики берутся из памяти, остальные — из хранилища
+func (tm *tableManager) ListSessions(ctx context.Context, userID int) ([]*models.TableSessionSnapshot, error) {
+	snapshots, err := tm.currentSnapshots(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	var err error
+	userSessions := make([]*models.TableSessionSnapshot, 0)
+	for _, snapshot := range snapshots {
+		if snapshot.AdminID == userID || slices.Contains(snapshot.Members, userID) {
+			userSessions = append(userSessions, snapshot)
+		}
+	}
 
-	if activeSession.CheckUser(user.ID) {
-		l.RepoWarn(apperrors.UserAlreadyExistsErr, map[string]any{"session_id": sessionID, "user_id": user.ID})
-		responses.SendWSErrResponse(conn, responses.WSStatusBadRequest, responses.ErrUserAlreadyExistsWS)
-		conn.Close()
+	return userSessions, nil
+}
 
-		return
+// FindEncounterSession возвращает снапшот сессии, идущей по энкаунтеру
+func (tm *tableManager) FindEncounterSession(ctx context.Context,
+	encounterID string) (*models.TableSessionSnapshot, error) {
+	l := logger.FromContext(ctx)
+
+	snapshots, err := tm.currentSnapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, snapshot := range snapshots {
+		if snapshot.EncounterID == encounterID {
+			return snapshot, nil
+		}
+	}
+
+	l.RepoWarn(apperrors.TableNotFoundErr, map[string]any{"encounter_id": encounterID})
+
+	return nil, apperrors.TableNotFoundErr
+}
+
+// FindEncounterSessions возвращает активные сессии по списку энкаунтеров. Энкаунтеры без сессии пропускаются
+func (tm *tableManager) FindEncounterSessions(ctx context.Context,
+	encounterIDs []string) ([]*models.TableSessionSnapshot, error) {
+	snapshots, err := tm.currentSnapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]*models.TableSessionSnapshot, 0)
+
+	for _, snapshot := range snapshots {
+		if slices.Contains(encounterIDs, snapshot.EncounterID) {
+			result = append(result, snapshot)
+		}
+	}
+
+	return result, nil
+}
+
+func (tm *tableManager) currentSnapshots(ctx context.Context) ([]*models.TableSessionSnapshot, error) {
+	l := logger.FromContext(ctx)
+
+	snapshots, err := tm.store.ListSessions(ctx)
+	if err != nil {
+		l.RepoError(err, nil)
+		return nil, err
+	}
+
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+
+	for i, snapshot := range snapshots {
+		if activeSession, ok := tm.sessions[snapshot.SessionID]; ok {
+			snapshots[i] = activeSession.Snapshot()
+		}
 	}
 
-	if user.ID == activeSession.GetAdminID() {
-		activeSession.AddAdmin(ctx, user.ID, user.DisplayName, conn)
-	} else {
-		err = activeSession.AddParticipant(ctx, user.ID, user.DisplayName, conn)
+	return snapshots, nil
+}
+
+// GetSessionAdmin возвращает ведущего сессии по её текущему состоянию или последнему снапшоту
+func (tm *tableManager) GetSessionAdmin(ctx context.Context, sessionID string) (int, error) {
+	l := logger.FromContext(ctx)
+
+	tm.mu.RLock()
+	activeSession, ok := tm.sessions[sessionID]
+	tm.mu.RUnlock()
+
+	if ok {
+		return activeSession.currentAdminID(), nil
 	}
 
+	snapshot, err := tm.store.GetSession(ctx, sessionID)
 	if err != nil {
-		l.RepoWarn(err, map[string]any{"session_id": sessionID, "user_id": user.ID})
-		responses.SendWSErrResponse(conn, responses.WSStatusBadRequest, responses.ErrMaxPlayersWS)
+		l.RepoWarn(apperrors.TableNotFoundErr, map[string]any{"session_id": sessionID})
+		return 0, apperrors.TableNotFoundErr
+	}
+
+	return snapshot.AdminID, nil
+}
+
+// ControlSession передаёт команду ведущего реплике-владельцу сессии. Права проверяются по текущему
+// состоянию сессии или по её последнему снапшоту, результат команды рассылается участникам
+func (tm *tableManager) ControlSession(ctx context.Context, sessionID string, userID int,
+	req *models.SessionControlRequest) error {
+	l := logger.FromContext(ctx)
+
+	adminID, err := tm.GetSessionAdmin(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if adminID != userID {
+		l.RepoWarn(apperrors.PermissionDeniedError, map[string]any{"session_id": sessionID, "user_id": userID})
+		return apperrors.PermissionDeniedError
+	}
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		l.RepoError(err, map[string]any{"session_id": sessionID, "user_id": userID})
+		return err
+	}
+
+	raw, err := json.Marshal(&tableEvent{Kind: eventControl, UserID: userID, Payload: payload})
+	if err != nil {
+		l.RepoError(err, map[string]any{"session_id": sessionID, "user_id": userID})
+		return err
+	}
+
+	return tm.pubsub.Publish(ctx, inboundChannel(sessionID), raw)
+}
+
+func (tm *tableManager) AddNewConnection(ctx context.Context, user *models.User, sessionID string,
+	params models.ConnectionParams, conn *websocket.Conn) {
+	l := logger.FromContext(ctx)
+	ctx = context.WithoutCancel(ctx)
+
+	tm.metrics.IncConns()
+
+	newConn := newHubConn(uuid.NewString(), user, params, conn, tm.sessionMetrics)
+
+	hub, err := tm.attachConn(ctx, sessionID, newConn)
+	if err != nil {
+		l.RepoWarn(err, map[string]any{"session_id": sessionID})
+		responses.SendWSErrResponse(conn, responses.WSStatusBadRequest, responses.ErrInvalidWSSessionID)
 		conn.Close()
 
 		return
 	}
 
-	activeSession.refreshCallback(sessionID)
-	activeSession.WriteFirstMsg(ctx, user.ID)
+	go newConn.writeLoop(ctx)
+
+	hub.publish(ctx, &tableEvent{Kind: eventJoin, ConnID: newConn.id, UserID: user.ID, Name: user.DisplayName,
+		Role: params.Role, LastSeq: params.LastSeq, Invite: params.Invite})
 
 	l.RepoInfo("new connection added", map[string]any{"session_id": sessionID, "user_id": user.ID})
 
 	go func() {
 		defer func() {
-			if activeSession.CheckUser(user.ID) {
-				l.RepoInfo("user successfully removed",
-					map[string]any{"session_id": sessionID, "user_id": user.ID})
-				activeSession.RemoveParticipant(ctx, user.ID)
-			}
+			tm.detachConn(hub, newConn.id)
+			newConn.close()
+
+			hub.publish(ctx, &tableEvent{Kind: eventLeave, ConnID: newConn.id, UserID: user.ID})
+			l.RepoInfo("user successfully removed", map[string]any{"session_id": sessionID, "user_id": user.ID})
 		}()
 
-		for {
-			_, msg, err := conn.ReadMessage()
-			if err != nil {
-				l.RepoError(err, map[string]any{"session_id": sessionID, "user_id": user.ID, "ws_msg": string(msg)})
-				break
-			}
+		newConn.readLoop(ctx, func(msg []byte) {
+			hub.publish(ctx, &tableEvent{Kind: eventMessage, ConnID: newConn.id, UserID: user.ID, Payload: msg})
+		})
+	}()
+}
+
+// attachConn добавляет соединение в локальный хаб сессии, при необходимости создавая его
+func (tm *tableManager) attachConn(ctx context.Context, sessionID string, c *hubConn) (*sessionHub, error) {
+	tm.mu.Lock()
+	if hub, ok := tm.hubs[sessionID]; ok {
+		hub.addConn(c)
+		tm.mu.Unlock()
 
-			activeSession.refreshCallback(sessionID)
-			activeSession.broadcast <- msg
-			activeSession.metrics.IncReceivedMsgs()
+		return hub, nil
+	}
+	_, owned := tm.sessions[sessionID]
+	tm.mu.Unlock()
+
+	if !owned {
+		if _, err := tm.store.GetSession(ctx, sessionID); err != nil {
+			return nil, apperrors.TableNotFoundErr
 		}
-	}()
+	}
+
+	hubCtx, cancel := context.WithCancel(ctx)
+
+	outbound, err := tm.pubsub.Subscribe(hubCtx, outboundChannel(sessionID))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	newHub := &sessionHub{
+		sessionID: sessionID,
+		conns:     make(map[string]*hubConn),
+		pubsub:    tm.pubsub,
+		cancel:    cancel,
+		metrics:   tm.sessionMetrics,
+	}
+	newHub.onEnd = func() { tm.dropHub(sessionID, newHub) }
+
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	// Хаб мог успеть создаться параллельным подключением
+	if hub, ok := tm.hubs[sessionID]; ok {
+		cancel()
+		hub.addConn(c)
+
+		return hub, nil
+	}
+
+	newHub.addConn(c)
+	tm.hubs[sessionID] = newHub
+
+	go newHub.run(hubCtx, outbound)
+
+	return newHub, nil
+}
+
+func (tm *tableManager) detachConn(hub *sessionHub, connID string) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	hub.removeConn(connID)
+
+	if hub.isEmpty() && tm.hubs[hub.sessionID] == hub {
+		delete(tm.hubs, hub.sessionID)
+		hub.cancel()
+	}
+}
+
+func (tm *tableManager) dropHub(sessionID string, hub *sessionHub) {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	if tm.hubs[sessionID] == hub {
+		delete(tm.hubs, sessionID)
+	}
+
+	hub.cancel()
 }
 
 func (tm *tableManager) HasActiveUsers(ctx context.Context, sessionID string) bool {
@@ -0,0 +1,69 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	bestiaryinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/bestiary"
+	characterinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/character"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	tableinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table"
+)
+
+type combatantStats struct {
+	bestiaryRepo  bestiaryinterfaces.BestiaryRepository
+	characterRepo characterinterfaces.CharacterRepository
+}
+
+func NewCombatantStatsProvider(bestiaryRepo bestiaryinterfaces.BestiaryRepository,
+	characterRepo characterinterfaces.CharacterRepository) tableinterfaces.CombatantStatsProvider {
+	return &combatantStats{
+		bestiaryRepo:  bestiaryRepo,
+		characterRepo: characterRepo,
+	}
+}
+
+func (s *combatantStats) GetDexModifier(ctx context.Context, kind models.CombatantKind,
+	sourceID string) (int, error) {
+	l := logger.FromContext(ctx)
+
+	switch kind {
+	case models.CreatureCombatant:
+		// Существо ищется сначала в общем бестиарии, затем среди пользовательских
+		creature, err := s.bestiaryRepo.GetCreatureByEngName(ctx, sourceID, false)
+		if err != nil {
+			creature, err = s.bestiaryRepo.GetCreatureByEngName(ctx, sourceID, true)
+		}
+
+		if err != nil {
+			l.UsecasesWarn(err, 0, map[string]any{"creature": sourceID})
+			return 0, err
+		}
+
+		return abilityModifier(creature.Ability.Dex), nil
+	case models.CharacterCombatant:
+		character, err := s.characterRepo.GetCharacterByMongoId(ctx, sourceID)
+		if err != nil {
+			l.UsecasesWarn(err, 0, map[string]any{"character": sourceID})
+			return 0, err
+		}
+
+		dex := character.Data.Stats.Dex
+		if dex.Score == 0 {
+			return dex.Modifier, nil
+		}
+
+		return abilityModifier(dex.Score), nil
+	case models.CustomCombatant:
+		return 0, nil
+	default:
+		return 0, apperrors.InvalidInitiativeErr
+	}
+}
+
+// abilityModifier считает модификатор характеристики: (значение - 10) / 2 с округлением вниз.
+// Значения характеристик неотрицательны, поэтому достаточно целочисленного деления
+func abilityModifier(score int) int {
+	return score/2 - 5
+}
@@ -0,0 +1,142 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	bestiarymocks "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/bestiary/mocks"
+	charactermocks "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/character/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestGetDexModifier(t *testing.T) {
+	t.Parallel()
+
+	repoErr := errors.New("not found")
+
+	characterWithDex := func(score, modifier int) *models.Character {
+		character := &models.Character{}
+		character.Data.Stats.Dex.Score = score
+		character.Data.Stats.Dex.Modifier = modifier
+
+		return character
+	}
+
+	tests := []struct {
+		name     string
+		kind     models.CombatantKind
+		sourceID string
+		setup    func(bestiary *bestiarymocks.MockBestiaryRepository, characters *charactermocks.MockCharacterRepository)
+		want     int
+		wantErr  error
+	}{
+		{
+			name:     "creature modifier is computed from dex score",
+			kind:     models.CreatureCombatant,
+			sourceID: "goblin",
+			setup: func(bestiary *bestiarymocks.MockBestiaryRepository, _ *charactermocks.MockCharacterRepository) {
+				bestiary.EXPECT().GetCreatureByEngName(gomock.Any(), "goblin", false).
+					Return(&models.Creature{Ability: models.Ability{Dex: 14}}, nil)
+			},
+			want: 2,
+		},
+		{
+			name:     "odd low dex score is rounded down",
+			kind:     models.CreatureCombatant,
+			sourceID: "zombie",
+			setup: func(bestiary *bestiarymocks.MockBestiaryRepository, _ *charactermocks.MockCharacterRepository) {
+				bestiary.EXPECT().GetCreatureByEngName(gomock.Any(), "zombie", false).
+					Return(&models.Creature{Ability: models.Ability{Dex: 7}}, nil)
+			},
+			want: -2,
+		},
+		{
+			name:     "user creature is looked up when public one is missing",
+			kind:     models.CreatureCombatant,
+			sourceID: "homebrew",
+			setup: func(bestiary *bestiarymocks.MockBestiaryRepository, _ *charactermocks.MockCharacterRepository) {
+				bestiary.EXPECT().GetCreatureByEngName(gomock.Any(), "homebrew", false).Return(nil, repoErr)
+				bestiary.EXPECT().GetCreatureByEngName(gomock.Any(), "homebrew", true).
+					Return(&models.Creature{Ability: models.Ability{Dex: 18}}, nil)
+			},
+			want: 4,
+		},
+		{
+			name:     "missing creature error is propagated",
+			kind:     models.CreatureCombatant,
+			sourceID: "unknown",
+			setup: func(bestiary *bestiarymocks.MockBestiaryRepository, _ *charactermocks.MockCharacterRepository) {
+				bestiary.EXPECT().GetCreatureByEngName(gomock.Any(), "unknown", false).Return(nil, repoErr)
+				bestiary.EXPECT().GetCreatureByEngName(gomock.Any(), "unknown", true).Return(nil, repoErr)
+			},
+			wantErr: repoErr,
+		},
+		{
+			name:     "character modifier is computed from dex score",
+			kind:     models.CharacterCombatant,
+			sourceID: "char-1",
+			setup: func(_ *bestiarymocks.MockBestiaryRepository, characters *charactermocks.MockCharacterRepository) {
+				characters.EXPECT().GetCharacterByMongoId(gomock.Any(), "char-1").
+					Return(characterWithDex(16, 0), nil)
+			},
+			want: 3,
+		},
+		{
+			name:     "character stored modifier is used without score",
+			kind:     models.CharacterCombatant,
+			sourceID: "char-2",
+			setup: func(_ *bestiarymocks.MockBestiaryRepository, characters *charactermocks.MockCharacterRepository) {
+				characters.EXPECT().GetCharacterByMongoId(gomock.Any(), "char-2").
+					Return(characterWithDex(0, 1), nil)
+			},
+			want: 1,
+		},
+		{
+			name:     "character repo error is propagated",
+			kind:     models.CharacterCombatant,
+			sourceID: "char-3",
+			setup: func(_ *bestiarymocks.MockBestiaryRepository, characters *charactermocks.MockCharacterRepository) {
+				characters.EXPECT().GetCharacterByMongoId(gomock.Any(), "char-3").Return(nil, repoErr)
+			},
+			wantErr: repoErr,
+		},
+		{
+			name:  "custom combatant has no modifier",
+			kind:  models.CustomCombatant,
+			setup: func(_ *bestiarymocks.MockBestiaryRepository, _ *charactermocks.MockCharacterRepository) {},
+			want:  0,
+		},
+		{
+			name:    "unknown kind is rejected",
+			kind:    "vehicle",
+			setup:   func(_ *bestiarymocks.MockBestiaryRepository, _ *charactermocks.MockCharacterRepository) {},
+			wantErr: apperrors.InvalidInitiativeErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			bestiary := bestiarymocks.NewMockBestiaryRepository(ctrl)
+			characters := charactermocks.NewMockCharacterRepository(ctrl)
+			tt.setup(bestiary, characters)
+
+			provider := NewCombatantStatsProvider(bestiary, characters)
+			got, err := provider.GetDexModifier(context.Background(), tt.kind, tt.sourceID)
+
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "expected %v, got %v", tt.wantErr, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
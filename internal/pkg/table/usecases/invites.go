@@ -0,0 +1,89 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table/invite"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultInviteTTL = 24 * time.Hour
+	maxInviteTTL     = 7 * 24 * time.Hour
+	maxInviteUses    = 100
+)
+
+func (uc *tableUsecases) CreateInvite(ctx context.Context, sessionID string, userID int,
+	req *models.CreateInviteRequest) (*models.InviteResponse, error) {
+	l := logger.FromContext(ctx)
+
+	ttl := time.Duration(req.ExpiresIn) * time.Second
+	if ttl == 0 {
+		ttl = defaultInviteTTL
+	}
+
+	if ttl < 0 || ttl > maxInviteTTL || req.MaxUses < 0 || req.MaxUses > maxInviteUses ||
+		(req.Role != "" && req.Role != models.Player && req.Role != models.Spectator) {
+		l.UsecasesWarn(apperrors.InvalidInviteParamsErr, userID, map[string]any{"session_id": sessionID,
+			"expires_in": req.ExpiresIn, "max_uses": req.MaxUses, "role": req.Role})
+		return nil, apperrors.InvalidInviteParamsErr
+	}
+
+	adminID, err := uc.tableManager.GetSessionAdmin(ctx, sessionID)
+	if err != nil {
+		l.UsecasesWarn(err, userID, map[string]any{"session_id": sessionID})
+		return nil, err
+	}
+
+	if adminID != userID {
+		l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"session_id": sessionID})
+		return nil, apperrors.PermissionDeniedError
+	}
+
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second)
+
+	token, err := uc.inviteSigner.Sign(&models.InviteClaims{
+		ID:          uuid.NewString(),
+		SessionID:   sessionID,
+		ExpiresAt:   expiresAt.Unix(),
+		MaxUses:     req.MaxUses,
+		Role:        req.Role,
+		CharacterID: req.CharacterID,
+	})
+	if err != nil {
+		l.UsecasesError(err, userID, map[string]any{"session_id": sessionID})
+		return nil, err
+	}
+
+	return &models.InviteResponse{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+func (uc *tableUsecases) VerifyInvite(ctx context.Context, sessionID, token string) (*models.InviteClaims, error) {
+	l := logger.FromContext(ctx)
+
+	claims, err := uc.inviteSigner.Verify(token)
+	if err != nil {
+		if errors.Is(err, invite.ErrTokenExpired) {
+			err = apperrors.InviteExpiredErr
+		} else {
+			err = apperrors.InvalidInviteErr
+		}
+
+		l.UsecasesWarn(err, 0, map[string]any{"session_id": sessionID})
+
+		return nil, err
+	}
+
+	if claims.SessionID != sessionID {
+		l.UsecasesWarn(apperrors.InvalidInviteErr, 0, map[string]any{"session_id": sessionID,
+			"invite_session_id": claims.SessionID})
+		return nil, apperrors.InvalidInviteErr
+	}
+
+	return claims, nil
+}
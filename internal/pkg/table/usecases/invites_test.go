@@ -0,0 +1,168 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
+	encmocks "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/mocks"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table/invite"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table/mocks"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+)
+
+func TestCreateInvite(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		req     *models.CreateInviteRequest
+		setup   func(mgr *mocks.MockTableManager, signer *mocks.MockInviteSigner)
+		wantTTL time.Duration
+		wantErr error
+	}{
+		{
+			name: "defaults to a day",
+			req:  &models.CreateInviteRequest{},
+			setup: func(mgr *mocks.MockTableManager, signer *mocks.MockInviteSigner) {
+				mgr.EXPECT().GetSessionAdmin(gomock.Any(), "session-1").Return(1, nil)
+				signer.EXPECT().Sign(gomock.Any()).Return("token", nil)
+			},
+			wantTTL: defaultInviteTTL,
+		},
+		{
+			name: "spectator invite with uses limit",
+			req:  &models.CreateInviteRequest{ExpiresIn: 3600, MaxUses: 3, Role: models.Spectator},
+			setup: func(mgr *mocks.MockTableManager, signer *mocks.MockInviteSigner) {
+				mgr.EXPECT().GetSessionAdmin(gomock.Any(), "session-1").Return(1, nil)
+				signer.EXPECT().Sign(gomock.Any()).DoAndReturn(func(claims *models.InviteClaims) (string, error) {
+					assert.Equal(t, "session-1", claims.SessionID)
+					assert.Equal(t, 3, claims.MaxUses)
+					assert.Equal(t, models.Spectator, claims.Role)
+					assert.NotEmpty(t, claims.ID)
+
+					return "token", nil
+				})
+			},
+			wantTTL: time.Hour,
+		},
+		{
+			name:    "admin role returns InvalidInviteParamsErr",
+			req:     &models.CreateInviteRequest{Role: models.Admin},
+			setup:   func(_ *mocks.MockTableManager, _ *mocks.MockInviteSigner) {},
+			wantErr: apperrors.InvalidInviteParamsErr,
+		},
+		{
+			name:    "too long expiry returns InvalidInviteParamsErr",
+			req:     &models.CreateInviteRequest{ExpiresIn: int(maxInviteTTL/time.Second) + 1},
+			setup:   func(_ *mocks.MockTableManager, _ *mocks.MockInviteSigner) {},
+			wantErr: apperrors.InvalidInviteParamsErr,
+		},
+		{
+			name:    "too many uses returns InvalidInviteParamsErr",
+			req:     &models.CreateInviteRequest{MaxUses: maxInviteUses + 1},
+			setup:   func(_ *mocks.MockTableManager, _ *mocks.MockInviteSigner) {},
+			wantErr: apperrors.InvalidInviteParamsErr,
+		},
+		{
+			name: "not admin returns PermissionDeniedError",
+			req:  &models.CreateInviteRequest{},
+			setup: func(mgr *mocks.MockTableManager, _ *mocks.MockInviteSigner) {
+				mgr.EXPECT().GetSessionAdmin(gomock.Any(), "session-1").Return(2, nil)
+			},
+			wantErr: apperrors.PermissionDeniedError,
+		},
+		{
+			name: "unknown session is propagated",
+			req:  &models.CreateInviteRequest{},
+			setup: func(mgr *mocks.MockTableManager, _ *mocks.MockInviteSigner) {
+				mgr.EXPECT().GetSessionAdmin(gomock.Any(), "session-1").Return(0, apperrors.TableNotFoundErr)
+			},
+			wantErr: apperrors.TableNotFoundErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			repo := encmocks.NewMockEncounterRepository(ctrl)
+			mgr := mocks.NewMockTableManager(ctrl)
+			idGen := mocks.NewMockSessionIDGenerator(ctrl)
+			tf := mocks.NewMockTimerFactory(ctrl)
+			signer := mocks.NewMockInviteSigner(ctrl)
+			tt.setup(mgr, signer)
+
+			uc := NewTableUsecases(repo, nil, mgr, idGen, tf, signer)
+
+			before := time.Now()
+			resp, err := uc.CreateInvite(context.Background(), "session-1", 1, tt.req)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "token", resp.Token)
+			assert.WithinDuration(t, before.Add(tt.wantTTL), resp.ExpiresAt, 2*time.Second)
+		})
+	}
+}
+
+func TestVerifyInvite(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		claims    *models.InviteClaims
+		verifyErr error
+		wantErr   error
+	}{
+		{
+			name:   "valid invite returns claims",
+			claims: &models.InviteClaims{ID: "invite-1", SessionID: "session-1"},
+		},
+		{
+			name:    "invite for another session returns InvalidInviteErr",
+			claims:  &models.InviteClaims{ID: "invite-1", SessionID: "session-2"},
+			wantErr: apperrors.InvalidInviteErr,
+		},
+		{
+			name:      "expired token returns InviteExpiredErr",
+			verifyErr: invite.ErrTokenExpired,
+			wantErr:   apperrors.InviteExpiredErr,
+		},
+		{
+			name:      "forged token returns InvalidInviteErr",
+			verifyErr: errors.New("bad signature"),
+			wantErr:   apperrors.InvalidInviteErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			signer := mocks.NewMockInviteSigner(ctrl)
+			signer.EXPECT().Verify("token").Return(tt.claims, tt.verifyErr)
+
+			uc := NewTableUsecases(nil, nil, mocks.NewMockTableManager(ctrl), nil, nil, signer)
+			claims, err := uc.VerifyInvite(context.Background(), "session-1", "token")
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.claims, claims)
+		})
+	}
+}
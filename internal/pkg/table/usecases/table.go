@@ -2,13 +2,16 @@ package usecases
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
 	encounterinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/schema"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/logger"
 	tableinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table"
 	"github.com/gorilla/websocket"
@@ -16,87 +19,453 @@ import (
 
 const (
 	sessionDuration = 15 * time.Minute
+	flushRetryDelay = time.Minute
+	maxPlayersLimit = 20
 )
 
 type tableUsecases struct {
 	tableManager  tableinterfaces.TableManager
 	encounterRepo encounterinterfaces.EncounterRepository
+	logRepo       tableinterfaces.TableLogRepository
 
 	idGen        tableinterfaces.SessionIDGenerator
 	timerFactory tableinterfaces.TimerFactory
+	inviteSigner tableinterfaces.InviteSigner
 
 	sessionWatcher map[string]tableinterfaces.SessionTimer
+	paused         map[string]struct{} // Сессии, таймер которых остановлен ведущим
 	mu             sync.RWMutex
+
+	lifecycle tableinterfaces.SessionLifecycle
+}
+
+// sessionLifecycle связывает команды сессии с таймерами бездействия в usecases
+type sessionLifecycle struct {
+	uc *tableUsecases
 }
 
+func (sl *sessionLifecycle) Refresh(sessionID string) { sl.uc.refreshSession(sessionID) }
+func (sl *sessionLifecycle) Pause(sessionID string)   { sl.uc.pauseTimer(sessionID) }
+func (sl *sessionLifecycle) Resume(sessionID string)  { sl.uc.resumeTimer(sessionID) }
+func (sl *sessionLifecycle) End(sessionID string)     { sl.uc.endSession(sessionID) }
+
 func NewTableUsecases(encounterRepo encounterinterfaces.EncounterRepository,
+	logRepo tableinterfaces.TableLogRepository,
 	manager tableinterfaces.TableManager,
 	idGen tableinterfaces.SessionIDGenerator,
-	timerFactory tableinterfaces.TimerFactory) tableinterfaces.TableUsecases {
-	return &tableUsecases{
+	timerFactory tableinterfaces.TimerFactory,
+	inviteSigner tableinterfaces.InviteSigner) tableinterfaces.TableUsecases {
+	uc := &tableUsecases{
 		tableManager:   manager,
 		encounterRepo:  encounterRepo,
+		logRepo:        logRepo,
 		idGen:          idGen,
 		timerFactory:   timerFactory,
+		inviteSigner:   inviteSigner,
 		sessionWatcher: make(map[string]tableinterfaces.SessionTimer),
+		paused:         make(map[string]struct{}),
 	}
+	uc.lifecycle = &sessionLifecycle{uc: uc}
+
+	return uc
 }
 
-func (uc *tableUsecases) CreateSession(ctx context.Context, admin *models.User, encounterID string) (string, error) {
+func (uc *tableUsecases) CreateSession(ctx context.Context, admin *models.User, encounterID string,
+	maxPlayers int) (string, error) {
 	l := logger.FromContext(ctx)
 
+	if maxPlayers < 0 || maxPlayers > maxPlayersLimit {
+		l.UsecasesWarn(apperrors.InvalidMaxPlayersErr, admin.ID, map[string]any{"max_players": maxPlayers})
+		return "", apperrors.InvalidMaxPlayersErr
+	}
+
+	// Сессию может запустить любой, кому выдано право редактировать энкаунтер
+	hasPermission := uc.encounterRepo.CheckPermission(ctx, encounterID, admin.ID, models.EditorEncounterRole)
+	if !hasPermission {
+		l.UsecasesWarn(apperrors.PermissionDeniedError, admin.ID, map[string]any{"id": encounterID})
+		return "", apperrors.PermissionDeniedError
+	}
+
 	encounterData, err := uc.encounterRepo.GetEncounterByID(ctx, encounterID)
 	if err != nil {
 		l.UsecasesError(err, admin.ID, map[string]any{"id": encounterID})
 		return "", err
 	}
 
-	if encounterData.UserID != admin.ID {
-		l.UsecasesWarn(apperrors.PermissionDeniedError, admin.ID, map[string]any{"id": encounterID})
-		return "", apperrors.PermissionDeniedError
+	if _, err := uc.tableManager.FindEncounterSession(ctx, encounterID); err == nil {
+		l.UsecasesWarn(apperrors.EncounterSessionErr, admin.ID, map[string]any{"id": encounterID})
+		return "", apperrors.EncounterSessionErr
+	}
+
+	// Сессия работает с данными в текущей версии схемы, при сбросе они перезапишут старые
+	data, _, err := schema.Migrate(encounterData.Data)
+	if err != nil {
+		l.UsecasesWarn(err, admin.ID, map[string]any{"id": encounterID})
+		return "", apperrors.InvalidEncounterDataErr
 	}
 
+	encounterData.Data = data
 	sessionID := uc.idGen.NewSessionID()
 
-	uc.tableManager.CreateSession(ctx, admin, encounterData, sessionID, uc.refreshSession)
+	err = uc.tableManager.CreateSession(ctx, admin, encounterData, sessionID, maxPlayers, uc.lifecycle)
+	if err != nil {
+		l.UsecasesError(err, admin.ID, map[string]any{"id": encounterID})
+		return "", err
+	}
+
+	uc.startTimer(ctx, sessionID, encounterID, admin.ID)
 
-	uc.mu.Lock()
+	return sessionID, nil
+}
 
-	uc.sessionWatcher[sessionID] = uc.timerFactory.AfterFunc(sessionDuration, func() {
-		uc.stopTimer(ctx, sessionID, encounterID)
-		l.UsecasesInfo(fmt.Sprintf("session timer stopped, sessionID: %s", sessionID), admin.ID)
+func (uc *tableUsecases) RestoreSessions(ctx context.Context) {
+	l := logger.FromContext(ctx)
+
+	restored := uc.tableManager.RestoreSessions(ctx, uc.lifecycle)
+	for _, snapshot := range restored {
+		uc.startSnapshotTimer(ctx, snapshot)
+		l.UsecasesInfo(fmt.Sprintf("session restored, sessionID: %s", snapshot.SessionID), snapshot.AdminID)
+	}
+}
+
+// RunRecovery периодически подхватывает сессии, оставшиеся без владельца после падения другой реплики.
+// Блокируется до отмены контекста
+func (uc *tableUsecases) RunRecovery(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			uc.RestoreSessions(ctx)
+		}
+	}
+}
+
+func (uc *tableUsecases) GetTableData(ctx context.Context, sessionID string,
+	userID int) (*models.TableData, error) {
+	return uc.tableManager.GetTableData(ctx, sessionID, userID)
+}
+
+func (uc *tableUsecases) GetOperationLog(ctx context.Context, sessionID string,
+	userID int) (*models.TableOperationLog, error) {
+	l := logger.FromContext(ctx)
+
+	// Журнал идущей сессии берётся из памяти, завершённой — из базы
+	opLog, err := uc.tableManager.GetOperationLog(ctx, sessionID)
+	live := err == nil
+
+	if !live {
+		opLog, err = uc.logRepo.GetOperationLog(ctx, sessionID)
+		if err != nil {
+			l.UsecasesWarn(err, userID, map[string]any{"session_id": sessionID})
+			return nil, err
+		}
+	}
+
+	if opLog.AdminID != userID {
+		l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"session_id": sessionID})
+		return nil, apperrors.PermissionDeniedError
+	}
+
+	if live {
+		opLog, err = uc.withArchivedOperations(ctx, opLog)
+		if err != nil {
+			l.UsecasesError(err, userID, map[string]any{"session_id": sessionID})
+			return nil, err
+		}
+	}
+
+	return opLog, nil
+}
+
+// withArchivedOperations дополняет журнал идущей сессии операциями, вытесненными из памяти в базу.
+// Исходные данные тоже берутся из базы: в памяти они уже включают вытесненные операции
+func (uc *tableUsecases) withArchivedOperations(ctx context.Context,
+	opLog *models.TableOperationLog) (*models.TableOperationLog, error) {
+	archived, err := uc.logRepo.GetOperationLog(ctx, opLog.SessionID)
+	if errors.Is(err, apperrors.TableNotFoundErr) {
+		return opLog, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	operations := make([]models.TableOperation, 0, len(archived.Operations)+len(opLog.Operations))
+	for _, op := range archived.Operations {
+		if len(opLog.Operations) == 0 || op.Seq < opLog.Operations[0].Seq {
+			operations = append(operations, op)
+		}
+	}
+
+	full := *opLog
+	full.InitialData = archived.InitialData
+	full.Operations = append(operations, opLog.Operations...)
+
+	return &full, nil
+}
+
+func (uc *tableUsecases) ListSessions(ctx context.Context, userID int) ([]*models.TableSessionSummary, error) {
+	l := logger.FromContext(ctx)
+
+	snapshots, err := uc.tableManager.ListSessions(ctx, userID)
+	if err != nil {
+		l.UsecasesError(err, userID, nil)
+		return nil, err
+	}
+
+	now := time.Now()
+
+	summaries := make([]*models.TableSessionSummary, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		summaries = append(summaries, sessionSummary(snapshot, userID, now))
+	}
+
+	slices.SortFunc(summaries, func(a, b *models.TableSessionSummary) int {
+		return b.StartedAt.Compare(a.StartedAt)
 	})
 
-	uc.mu.Unlock()
+	return summaries, nil
+}
 
-	return sessionID, nil
+// GetEncounterSession возвращает сессию, идущую по энкаунтеру. Её видят ведущий, участники сессии
+// и все, кому доступен сам энкаунтер
+func (uc *tableUsecases) GetEncounterSession(ctx context.Context, encounterID string,
+	userID int) (*models.TableSessionSummary, error) {
+	l := logger.FromContext(ctx)
+
+	snapshot, err := uc.tableManager.FindEncounterSession(ctx, encounterID)
+	if err != nil {
+		l.UsecasesWarn(err, userID, map[string]any{"id": encounterID})
+		return nil, err
+	}
+
+	if snapshot.AdminID != userID && !slices.Contains(snapshot.Members, userID) {
+		hasPermission := uc.encounterRepo.CheckPermission(ctx, encounterID, userID, models.ViewerEncounterRole)
+		if !hasPermission {
+			l.UsecasesWarn(apperrors.PermissionDeniedError, userID, map[string]any{"id": encounterID})
+			return nil, apperrors.PermissionDeniedError
+		}
+	}
+
+	return sessionSummary(snapshot, userID, time.Now()), nil
 }
 
-func (uc *tableUsecases) GetTableData(ctx context.Context, sessionID string) (*models.TableData, error) {
-	return uc.tableManager.GetTableData(ctx, sessionID)
+func sessionSummary(snapshot *models.TableSessionSnapshot, userID int, now time.Time) *models.TableSessionSummary {
+	role := models.Player
+	if snapshot.AdminID == userID {
+		role = models.Admin
+	}
+
+	summary := &models.TableSessionSummary{
+		SessionID:       snapshot.SessionID,
+		EncounterID:     snapshot.EncounterID,
+		EncounterName:   snapshot.EncounterName,
+		AdminName:       snapshot.AdminName,
+		Role:            role,
+		ParticipantsNum: snapshot.ParticipantsNum,
+		StartedAt:       snapshot.StartedAt,
+		Paused:          snapshot.Paused,
+	}
+
+	if snapshot.Paused {
+		return summary
+	}
+
+	lastActivity := snapshot.LastActivityAt
+	if lastActivity.IsZero() {
+		lastActivity = snapshot.StartedAt
+	}
+
+	idleLeft := int(max(sessionDuration-now.Sub(lastActivity), 0) / time.Second)
+	summary.IdleSecondsLeft = &idleLeft
+
+	return summary
 }
 
 func (uc *tableUsecases) AddNewConnection(ctx context.Context, user *models.User, sessionID string,
-	conn *websocket.Conn) {
-	uc.tableManager.AddNewConnection(ctx, user, sessionID, conn)
+	params models.ConnectionParams, conn *websocket.Conn) {
+	l := logger.FromContext(ctx)
+
+	// Сессия могла остаться без владельца, тогда её подхватывает реплика, к которой пришёл клиент
+	snapshot, adopted := uc.tableManager.AdoptSession(ctx, sessionID, uc.lifecycle)
+	if adopted {
+		uc.startSnapshotTimer(ctx, snapshot)
+		l.UsecasesInfo(fmt.Sprintf("session adopted, sessionID: %s", sessionID), snapshot.AdminID)
+	}
+
+	uc.tableManager.AddNewConnection(ctx, user, sessionID, params, conn)
+}
+
+func (uc *tableUsecases) ControlSession(ctx context.Context, sessionID string, userID int,
+	req *models.SessionControlRequest) error {
+	l := logger.FromContext(ctx)
+
+	switch req.Action {
+	case models.EndSession, models.PauseSession, models.ResumeSession:
+	case models.KickParticipant, models.BanParticipant, models.TransferAdmin:
+		if req.UserID <= 0 || req.UserID == userID {
+			l.UsecasesWarn(apperrors.InvalidControlErr, userID, map[string]any{"session_id": sessionID,
+				"action": req.Action, "target_id": req.UserID})
+			return apperrors.InvalidControlErr
+		}
+	default:
+		l.UsecasesWarn(apperrors.InvalidControlErr, userID, map[string]any{"session_id": sessionID,
+			"action": req.Action})
+		return apperrors.InvalidControlErr
+	}
+
+	if err := uc.tableManager.ControlSession(ctx, sessionID, userID, req); err != nil {
+		l.UsecasesWarn(err, userID, map[string]any{"session_id": sessionID, "action": req.Action})
+		return err
+	}
+
+	return nil
+}
+
+// startSnapshotTimer запускает таймер подхваченной сессии. Таймер сессии на паузе сразу останавливается
+func (uc *tableUsecases) startSnapshotTimer(ctx context.Context, snapshot *models.TableSessionSnapshot) {
+	uc.startTimer(ctx, snapshot.SessionID, snapshot.EncounterID, snapshot.AdminID)
+
+	if snapshot.Paused {
+		uc.pauseTimer(snapshot.SessionID)
+	}
+}
+
+func (uc *tableUsecases) startTimer(ctx context.Context, sessionID, encounterID string, adminID int) {
+	l := logger.FromContext(ctx)
+	ctx = context.WithoutCancel(ctx)
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if timer, ok := uc.sessionWatcher[sessionID]; ok {
+		timer.Stop()
+	}
+
+	uc.sessionWatcher[sessionID] = uc.timerFactory.AfterFunc(sessionDuration, func() {
+		uc.stopTimer(ctx, sessionID, encounterID, adminID)
+		l.UsecasesInfo(fmt.Sprintf("session timer stopped, sessionID: %s", sessionID), adminID)
+	})
 }
 
 func (uc *tableUsecases) refreshSession(sessionID string) {
 	uc.mu.Lock()
 	defer uc.mu.Unlock()
 
-	uc.sessionWatcher[sessionID].Stop()
-	uc.sessionWatcher[sessionID].Reset(sessionDuration)
+	timer, ok := uc.sessionWatcher[sessionID]
+	if !ok {
+		return
+	}
+
+	if _, paused := uc.paused[sessionID]; paused {
+		return
+	}
+
+	timer.Stop()
+	timer.Reset(sessionDuration)
 }
 
-func (uc *tableUsecases) stopTimer(ctx context.Context, sessionID, encounterID string) {
-	data, _ := uc.tableManager.GetEncounterData(ctx, sessionID)
+func (uc *tableUsecases) pauseTimer(sessionID string) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	timer, ok := uc.sessionWatcher[sessionID]
+	if !ok {
+		return
+	}
+
+	timer.Stop()
+	uc.paused[sessionID] = struct{}{}
+}
 
+func (uc *tableUsecases) resumeTimer(sessionID string) {
 	uc.mu.Lock()
-	uc.sessionWatcher[sessionID].Stop()
-	delete(uc.sessionWatcher, sessionID)
-	uc.mu.Unlock()
+	defer uc.mu.Unlock()
+
+	timer, ok := uc.sessionWatcher[sessionID]
+	if !ok {
+		return
+	}
+
+	delete(uc.paused, sessionID)
+	timer.Reset(sessionDuration)
+}
+
+// endSession завершает сессию по команде ведущего: таймер срабатывает немедленно, поэтому состояние
+// сохраняется так же, как при истечении времени бездействия
+func (uc *tableUsecases) endSession(sessionID string) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	timer, ok := uc.sessionWatcher[sessionID]
+	if !ok {
+		return
+	}
+
+	delete(uc.paused, sessionID)
+	timer.Reset(0)
+}
+
+// stopTimer сохраняет энкаунтер и завершает сессию. Если сохранить не удалось, сессия остаётся,
+// а сохранение повторяется через flushRetryDelay
+func (uc *tableUsecases) stopTimer(ctx context.Context, sessionID, encounterID string, adminID int) {
+	l := logger.FromContext(ctx)
+
+	data, err := uc.tableManager.GetEncounterData(ctx, sessionID)
+
+	// Сессией уже владеет другая реплика, она и сохранит результат
+	if err != nil {
+		uc.forgetTimer(sessionID)
+		return
+	}
+
+	// Версию записывает текущий ведущий: за время сессии права могли быть переданы
+	if currentAdmin, err := uc.tableManager.GetSessionAdmin(ctx, sessionID); err == nil {
+		adminID = currentAdmin
+	}
+
+	change := &models.EncounterChange{AuthorID: adminID, Source: models.TableVersionSource}
+
+	err = uc.encounterRepo.UpdateEncounter(ctx, data, encounterID, change)
+	if err != nil {
+		l.UsecasesError(err, adminID, map[string]any{"session_id": sessionID, "encounter_id": encounterID})
+		uc.retryFlush(sessionID)
+
+		return
+	}
+
+	uc.forgetTimer(sessionID)
+
+	if opLog, err := uc.tableManager.GetOperationLog(ctx, sessionID); err == nil {
+		finishedAt := time.Now()
+		opLog.FinishedAt = &finishedAt
+
+		uc.logRepo.SaveOperationLog(ctx, opLog)
+	}
 
-	uc.encounterRepo.UpdateEncounter(context.Background(), data, encounterID)
 	uc.tableManager.RemoveSession(ctx, sessionID)
 }
+
+func (uc *tableUsecases) forgetTimer(sessionID string) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if timer, ok := uc.sessionWatcher[sessionID]; ok {
+		timer.Stop()
+		delete(uc.sessionWatcher, sessionID)
+	}
+
+	delete(uc.paused, sessionID)
+}
+
+// retryFlush снова запускает сработавший таймер, чтобы повторить сохранение сессии
+func (uc *tableUsecases) retryFlush(sessionID string) {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if timer, ok := uc.sessionWatcher[sessionID]; ok {
+		timer.Reset(flushRetryDelay)
+	}
+}
@@ -2,6 +2,7 @@ package usecases
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"testing"
 	"time"
@@ -9,6 +10,7 @@ import (
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/apperrors"
 	encmocks "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/encounter/mocks"
+	tableinterfaces "github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table"
 	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/table/mocks"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
@@ -20,10 +22,11 @@ func TestCreateSession(t *testing.T) {
 	repoErr := errors.New("db failure")
 
 	tests := []struct {
-		name  string
-		admin *models.User
-		encID string
-		setup func(repo *encmocks.MockEncounterRepository, mgr *mocks.MockTableManager,
+		name       string
+		admin      *models.User
+		encID      string
+		maxPlayers int
+		setup      func(repo *encmocks.MockEncounterRepository, mgr *mocks.MockTableManager,
 			idGen *mocks.MockSessionIDGenerator, tf *mocks.MockTimerFactory, timer *mocks.MockSessionTimer)
 		wantErr error
 		wantID  string
@@ -34,35 +37,124 @@ func TestCreateSession(t *testing.T) {
 			encID: "enc-1",
 			setup: func(repo *encmocks.MockEncounterRepository, _ *mocks.MockTableManager,
 				_ *mocks.MockSessionIDGenerator, _ *mocks.MockTimerFactory, _ *mocks.MockSessionTimer) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(true)
 				repo.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").Return(nil, repoErr)
 			},
 			wantErr: repoErr,
 		},
 		{
-			name:  "wrong user returns PermissionDeniedError",
+			name:  "user without editor grant returns PermissionDeniedError",
 			admin: &models.User{ID: 1, DisplayName: "Admin"},
 			encID: "enc-1",
 			setup: func(repo *encmocks.MockEncounterRepository, _ *mocks.MockTableManager,
 				_ *mocks.MockSessionIDGenerator, _ *mocks.MockTimerFactory, _ *mocks.MockSessionTimer) {
-				repo.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").
-					Return(&models.Encounter{UserID: 999, UUID: "enc-1"}, nil)
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(false)
 			},
 			wantErr: apperrors.PermissionDeniedError,
 		},
+		{
+			name:  "session owned by another replica returns TableLockErr",
+			admin: &models.User{ID: 1, DisplayName: "Admin"},
+			encID: "enc-1",
+			setup: func(repo *encmocks.MockEncounterRepository, mgr *mocks.MockTableManager,
+				idGen *mocks.MockSessionIDGenerator, _ *mocks.MockTimerFactory, _ *mocks.MockSessionTimer) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(true)
+				repo.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").
+					Return(&models.Encounter{UserID: 1, UUID: "enc-1", Name: "Battle"}, nil)
+				mgr.EXPECT().FindEncounterSession(gomock.Any(), gomock.Any()).Return(nil, apperrors.TableNotFoundErr)
+				idGen.EXPECT().NewSessionID().Return("test-session-abc")
+				mgr.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any(), "test-session-abc", 0,
+					gomock.Any()).Return(apperrors.TableLockErr)
+			},
+			wantErr: apperrors.TableLockErr,
+		},
+		{
+			name:  "broken encounter data returns InvalidEncounterDataErr",
+			admin: &models.User{ID: 1, DisplayName: "Admin"},
+			encID: "enc-1",
+			setup: func(repo *encmocks.MockEncounterRepository, mgr *mocks.MockTableManager,
+				_ *mocks.MockSessionIDGenerator, _ *mocks.MockTimerFactory, _ *mocks.MockSessionTimer) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(true)
+				repo.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").
+					Return(&models.Encounter{UserID: 1, UUID: "enc-1", Data: []byte(`[1,2]`)}, nil)
+				mgr.EXPECT().FindEncounterSession(gomock.Any(), gomock.Any()).Return(nil, apperrors.TableNotFoundErr)
+			},
+			wantErr: apperrors.InvalidEncounterDataErr,
+		},
+		{
+			name:  "legacy encounter data is upgraded for the session",
+			admin: &models.User{ID: 1, DisplayName: "Admin"},
+			encID: "enc-1",
+			setup: func(repo *encmocks.MockEncounterRepository, mgr *mocks.MockTableManager,
+				idGen *mocks.MockSessionIDGenerator, tf *mocks.MockTimerFactory, timer *mocks.MockSessionTimer) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(true)
+				repo.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").
+					Return(&models.Encounter{UserID: 1, UUID: "enc-1", Data: []byte(`{"monsters":[]}`)}, nil)
+				mgr.EXPECT().FindEncounterSession(gomock.Any(), gomock.Any()).Return(nil, apperrors.TableNotFoundErr)
+				idGen.EXPECT().NewSessionID().Return("test-session-abc")
+				mgr.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any(), "test-session-abc", 0, gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ *models.User, encounter *models.Encounter, _ string, _ int,
+						_ tableinterfaces.SessionLifecycle) error {
+						assert.JSONEq(t, `{"monsters":[],"schemaVersion":1,"participants":[]}`, string(encounter.Data))
+						return nil
+					})
+				tf.EXPECT().AfterFunc(gomock.Any(), gomock.Any()).Return(timer)
+			},
+			wantID: "test-session-abc",
+		},
 		{
 			name:  "happy path returns session ID",
 			admin: &models.User{ID: 1, DisplayName: "Admin"},
 			encID: "enc-1",
 			setup: func(repo *encmocks.MockEncounterRepository, mgr *mocks.MockTableManager,
 				idGen *mocks.MockSessionIDGenerator, tf *mocks.MockTimerFactory, timer *mocks.MockSessionTimer) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(true)
+				repo.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").
+					Return(&models.Encounter{UserID: 1, UUID: "enc-1", Name: "Battle"}, nil)
+				mgr.EXPECT().FindEncounterSession(gomock.Any(), gomock.Any()).Return(nil, apperrors.TableNotFoundErr)
+				idGen.EXPECT().NewSessionID().Return("test-session-abc")
+				mgr.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any(), "test-session-abc", 0, gomock.Any())
+				tf.EXPECT().AfterFunc(gomock.Any(), gomock.Any()).Return(timer)
+			},
+			wantID: "test-session-abc",
+		},
+		{
+			name:       "custom player cap is passed to manager",
+			admin:      &models.User{ID: 1, DisplayName: "Admin"},
+			encID:      "enc-1",
+			maxPlayers: 8,
+			setup: func(repo *encmocks.MockEncounterRepository, mgr *mocks.MockTableManager,
+				idGen *mocks.MockSessionIDGenerator, tf *mocks.MockTimerFactory, timer *mocks.MockSessionTimer) {
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(true)
 				repo.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").
 					Return(&models.Encounter{UserID: 1, UUID: "enc-1", Name: "Battle"}, nil)
+				mgr.EXPECT().FindEncounterSession(gomock.Any(), gomock.Any()).Return(nil, apperrors.TableNotFoundErr)
 				idGen.EXPECT().NewSessionID().Return("test-session-abc")
-				mgr.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any(), "test-session-abc", gomock.Any())
+				mgr.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any(), "test-session-abc", 8, gomock.Any())
 				tf.EXPECT().AfterFunc(gomock.Any(), gomock.Any()).Return(timer)
 			},
 			wantID: "test-session-abc",
 		},
+		{
+			name:       "negative player cap returns InvalidMaxPlayersErr",
+			admin:      &models.User{ID: 1, DisplayName: "Admin"},
+			encID:      "enc-1",
+			maxPlayers: -1,
+			setup: func(_ *encmocks.MockEncounterRepository, _ *mocks.MockTableManager,
+				_ *mocks.MockSessionIDGenerator, _ *mocks.MockTimerFactory, _ *mocks.MockSessionTimer) {
+			},
+			wantErr: apperrors.InvalidMaxPlayersErr,
+		},
+		{
+			name:       "player cap above limit returns InvalidMaxPlayersErr",
+			admin:      &models.User{ID: 1, DisplayName: "Admin"},
+			encID:      "enc-1",
+			maxPlayers: 21,
+			setup: func(_ *encmocks.MockEncounterRepository, _ *mocks.MockTableManager,
+				_ *mocks.MockSessionIDGenerator, _ *mocks.MockTimerFactory, _ *mocks.MockSessionTimer) {
+			},
+			wantErr: apperrors.InvalidMaxPlayersErr,
+		},
 	}
 
 	for _, tt := range tests {
@@ -77,8 +169,8 @@ func TestCreateSession(t *testing.T) {
 			timer := mocks.NewMockSessionTimer(ctrl)
 			tt.setup(repo, mgr, idGen, tf, timer)
 
-			uc := NewTableUsecases(repo, mgr, idGen, tf)
-			id, err := uc.CreateSession(context.Background(), tt.admin, tt.encID)
+			uc := NewTableUsecases(repo, nil, mgr, idGen, tf, nil)
+			id, err := uc.CreateSession(context.Background(), tt.admin, tt.encID, tt.maxPlayers)
 
 			if tt.wantErr != nil {
 				assert.True(t, errors.Is(err, tt.wantErr), "expected %v, got %v", tt.wantErr, err)
@@ -102,14 +194,16 @@ func TestCreateSession_TimerIsRegistered(t *testing.T) {
 	tf := mocks.NewMockTimerFactory(ctrl)
 	timer := mocks.NewMockSessionTimer(ctrl)
 
+	repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(true)
 	repo.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").
 		Return(&models.Encounter{UserID: 1, UUID: "enc-1", Name: "Battle"}, nil)
+	mgr.EXPECT().FindEncounterSession(gomock.Any(), gomock.Any()).Return(nil, apperrors.TableNotFoundErr)
 	idGen.EXPECT().NewSessionID().Return("sid-1")
-	mgr.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any(), "sid-1", gomock.Any())
+	mgr.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any(), "sid-1", 0, gomock.Any())
 	tf.EXPECT().AfterFunc(gomock.Any(), gomock.Any()).Return(timer)
 
-	uc := NewTableUsecases(repo, mgr, idGen, tf)
-	id, err := uc.CreateSession(context.Background(), &models.User{ID: 1, DisplayName: "Admin"}, "enc-1")
+	uc := NewTableUsecases(repo, nil, mgr, idGen, tf, nil)
+	id, err := uc.CreateSession(context.Background(), &models.User{ID: 1, DisplayName: "Admin"}, "enc-1", 0)
 	assert.NoError(t, err)
 	assert.Equal(t, "sid-1", id)
 }
@@ -131,13 +225,13 @@ func TestGetTableData(t *testing.T) {
 		{
 			name: "happy path returns table data",
 			setup: func(mgr *mocks.MockTableManager) {
-				mgr.EXPECT().GetTableData(gomock.Any(), "session-1").Return(expected, nil)
+				mgr.EXPECT().GetTableData(gomock.Any(), "session-1", 1).Return(expected, nil)
 			},
 		},
 		{
 			name: "manager error is propagated",
 			setup: func(mgr *mocks.MockTableManager) {
-				mgr.EXPECT().GetTableData(gomock.Any(), "session-1").Return(nil, managerErr)
+				mgr.EXPECT().GetTableData(gomock.Any(), "session-1", 1).Return(nil, managerErr)
 			},
 			wantErr: true,
 		},
@@ -154,8 +248,8 @@ func TestGetTableData(t *testing.T) {
 			tf := mocks.NewMockTimerFactory(ctrl)
 			tt.setup(mgr)
 
-			uc := NewTableUsecases(repo, mgr, idGen, tf)
-			result, err := uc.GetTableData(context.Background(), "session-1")
+			uc := NewTableUsecases(repo, nil, mgr, idGen, tf, nil)
+			result, err := uc.GetTableData(context.Background(), "session-1", 1)
 
 			if tt.wantErr {
 				assert.Error(t, err)
@@ -182,13 +276,15 @@ func TestCreateSession_MultipleSessionsGetUniqueIDs(t *testing.T) {
 	tf1 := mocks.NewMockTimerFactory(ctrl1)
 	timer1 := mocks.NewMockSessionTimer(ctrl1)
 
+	repo1.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(true)
 	repo1.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").Return(encounter, nil)
+	mgr1.EXPECT().FindEncounterSession(gomock.Any(), gomock.Any()).Return(nil, apperrors.TableNotFoundErr)
 	idGen1.EXPECT().NewSessionID().Return("session-A")
-	mgr1.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any(), "session-A", gomock.Any())
+	mgr1.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any(), "session-A", 0, gomock.Any())
 	tf1.EXPECT().AfterFunc(gomock.Any(), gomock.Any()).Return(timer1)
 
-	uc1 := NewTableUsecases(repo1, mgr1, idGen1, tf1)
-	id1, err1 := uc1.CreateSession(context.Background(), admin, "enc-1")
+	uc1 := NewTableUsecases(repo1, nil, mgr1, idGen1, tf1, nil)
+	id1, err1 := uc1.CreateSession(context.Background(), admin, "enc-1", 0)
 	assert.NoError(t, err1)
 	assert.Equal(t, "session-A", id1)
 
@@ -200,13 +296,15 @@ func TestCreateSession_MultipleSessionsGetUniqueIDs(t *testing.T) {
 	tf2 := mocks.NewMockTimerFactory(ctrl2)
 	timer2 := mocks.NewMockSessionTimer(ctrl2)
 
+	repo2.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(true)
 	repo2.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").Return(encounter, nil)
+	mgr2.EXPECT().FindEncounterSession(gomock.Any(), gomock.Any()).Return(nil, apperrors.TableNotFoundErr)
 	idGen2.EXPECT().NewSessionID().Return("session-B")
-	mgr2.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any(), "session-B", gomock.Any())
+	mgr2.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any(), "session-B", 0, gomock.Any())
 	tf2.EXPECT().AfterFunc(gomock.Any(), gomock.Any()).Return(timer2)
 
-	uc2 := NewTableUsecases(repo2, mgr2, idGen2, tf2)
-	id2, err2 := uc2.CreateSession(context.Background(), admin, "enc-1")
+	uc2 := NewTableUsecases(repo2, nil, mgr2, idGen2, tf2, nil)
+	id2, err2 := uc2.CreateSession(context.Background(), admin, "enc-1", 0)
 	assert.NoError(t, err2)
 	assert.Equal(t, "session-B", id2)
 	assert.NotEqual(t, id1, id2)
@@ -223,10 +321,12 @@ func TestCreateSession_TimerDuration(t *testing.T) {
 	tf := mocks.NewMockTimerFactory(ctrl)
 	timer := mocks.NewMockSessionTimer(ctrl)
 
+	repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(true)
 	repo.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").
 		Return(&models.Encounter{UserID: 1, UUID: "enc-1"}, nil)
+	mgr.EXPECT().FindEncounterSession(gomock.Any(), gomock.Any()).Return(nil, apperrors.TableNotFoundErr)
 	idGen.EXPECT().NewSessionID().Return("sid-dur")
-	mgr.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any(), "sid-dur", gomock.Any())
+	mgr.EXPECT().CreateSession(gomock.Any(), gomock.Any(), gomock.Any(), "sid-dur", 0, gomock.Any())
 
 	var capturedDuration time.Duration
 	tf.EXPECT().AfterFunc(gomock.Any(), gomock.Any()).DoAndReturn(
@@ -235,8 +335,553 @@ func TestCreateSession_TimerDuration(t *testing.T) {
 			return timer
 		})
 
-	uc := NewTableUsecases(repo, mgr, idGen, tf)
-	_, err := uc.CreateSession(context.Background(), &models.User{ID: 1}, "enc-1")
+	uc := NewTableUsecases(repo, nil, mgr, idGen, tf, nil)
+	_, err := uc.CreateSession(context.Background(), &models.User{ID: 1}, "enc-1", 0)
 	assert.NoError(t, err)
 	assert.True(t, capturedDuration > 0, "timer duration should be positive")
 }
+
+func TestRestoreSessions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		restored   []*models.TableSessionSnapshot
+		wantTimers int
+	}{
+		{
+			name:       "nothing to restore",
+			restored:   nil,
+			wantTimers: 0,
+		},
+		{
+			name: "timer is registered for every restored session",
+			restored: []*models.TableSessionSnapshot{
+				{SessionID: "sid-1", EncounterID: "enc-1", AdminID: 1},
+				{SessionID: "sid-2", EncounterID: "enc-2", AdminID: 2},
+			},
+			wantTimers: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			repo := encmocks.NewMockEncounterRepository(ctrl)
+			mgr := mocks.NewMockTableManager(ctrl)
+			idGen := mocks.NewMockSessionIDGenerator(ctrl)
+			tf := mocks.NewMockTimerFactory(ctrl)
+			timer := mocks.NewMockSessionTimer(ctrl)
+
+			mgr.EXPECT().RestoreSessions(gomock.Any(), gomock.Any()).Return(tt.restored)
+			tf.EXPECT().AfterFunc(sessionDuration, gomock.Any()).Return(timer).Times(tt.wantTimers)
+
+			uc := NewTableUsecases(repo, nil, mgr, idGen, tf, nil).(*tableUsecases)
+			uc.RestoreSessions(context.Background())
+
+			assert.Len(t, uc.sessionWatcher, tt.wantTimers)
+		})
+	}
+}
+
+func TestAddNewConnection(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		adopted   bool
+		snapshot  *models.TableSessionSnapshot
+		wantTimer bool
+	}{
+		{
+			name:    "session owned elsewhere is not adopted",
+			adopted: false,
+		},
+		{
+			name:      "orphaned session is adopted and gets a timer",
+			adopted:   true,
+			snapshot:  &models.TableSessionSnapshot{SessionID: "sid-1", EncounterID: "enc-1", AdminID: 1},
+			wantTimer: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			repo := encmocks.NewMockEncounterRepository(ctrl)
+			mgr := mocks.NewMockTableManager(ctrl)
+			idGen := mocks.NewMockSessionIDGenerator(ctrl)
+			tf := mocks.NewMockTimerFactory(ctrl)
+			timer := mocks.NewMockSessionTimer(ctrl)
+
+			user := &models.User{ID: 2, DisplayName: "Player"}
+
+			mgr.EXPECT().AdoptSession(gomock.Any(), "sid-1", gomock.Any()).Return(tt.snapshot, tt.adopted)
+			if tt.wantTimer {
+				tf.EXPECT().AfterFunc(sessionDuration, gomock.Any()).Return(timer)
+			}
+			mgr.EXPECT().AddNewConnection(gomock.Any(), user, "sid-1", models.ConnectionParams{Role: models.Player}, nil)
+
+			uc := NewTableUsecases(repo, nil, mgr, idGen, tf, nil).(*tableUsecases)
+			uc.AddNewConnection(context.Background(), user, "sid-1", models.ConnectionParams{Role: models.Player}, nil)
+
+			_, hasTimer := uc.sessionWatcher["sid-1"]
+			assert.Equal(t, tt.wantTimer, hasTimer)
+		})
+	}
+}
+
+func TestGetOperationLog(t *testing.T) {
+	t.Parallel()
+
+	liveLog := &models.TableOperationLog{SessionID: "session-1", AdminID: 1}
+	storedLog := &models.TableOperationLog{SessionID: "session-1", AdminID: 1, Operations: []models.TableOperation{
+		{Seq: 1, AuthorID: 1, Kind: models.PatchOperation},
+	}}
+
+	// A long live session keeps only the latest operations in memory, older ones are in the repository.
+	// Operation 3 was written to the repository but is still in memory, the live copy wins
+	compactedLog := &models.TableOperationLog{SessionID: "session-1", AdminID: 1,
+		InitialData: json.RawMessage(`{"hp":2}`), Operations: []models.TableOperation{
+			{Seq: 3, Kind: models.PatchOperation, Undone: true},
+			{Seq: 4, Kind: models.PatchOperation},
+		}}
+	archivedLog := &models.TableOperationLog{SessionID: "session-1", AdminID: 1,
+		InitialData: json.RawMessage(`{"hp":0}`), Operations: []models.TableOperation{
+			{Seq: 1, Kind: models.PatchOperation},
+			{Seq: 2, Kind: models.PatchOperation},
+			{Seq: 3, Kind: models.PatchOperation},
+		}}
+	fullLog := &models.TableOperationLog{SessionID: "session-1", AdminID: 1,
+		InitialData: json.RawMessage(`{"hp":0}`), Operations: []models.TableOperation{
+			{Seq: 1, Kind: models.PatchOperation},
+			{Seq: 2, Kind: models.PatchOperation},
+			{Seq: 3, Kind: models.PatchOperation, Undone: true},
+			{Seq: 4, Kind: models.PatchOperation},
+		}}
+
+	tests := []struct {
+		name    string
+		userID  int
+		setup   func(mgr *mocks.MockTableManager, logRepo *mocks.MockTableLogRepository)
+		want    *models.TableOperationLog
+		wantErr error
+	}{
+		{
+			name:   "live session log is returned from manager",
+			userID: 1,
+			setup: func(mgr *mocks.MockTableManager, logRepo *mocks.MockTableLogRepository) {
+				mgr.EXPECT().GetOperationLog(gomock.Any(), "session-1").Return(liveLog, nil)
+				logRepo.EXPECT().GetOperationLog(gomock.Any(), "session-1").Return(nil, apperrors.TableNotFoundErr)
+			},
+			want: liveLog,
+		},
+		{
+			name:   "archived operations of live session are prepended",
+			userID: 1,
+			setup: func(mgr *mocks.MockTableManager, logRepo *mocks.MockTableLogRepository) {
+				mgr.EXPECT().GetOperationLog(gomock.Any(), "session-1").Return(compactedLog, nil)
+				logRepo.EXPECT().GetOperationLog(gomock.Any(), "session-1").Return(archivedLog, nil)
+			},
+			want: fullLog,
+		},
+		{
+			name:   "archive read error of live session is returned",
+			userID: 1,
+			setup: func(mgr *mocks.MockTableManager, logRepo *mocks.MockTableLogRepository) {
+				mgr.EXPECT().GetOperationLog(gomock.Any(), "session-1").Return(liveLog, nil)
+				logRepo.EXPECT().GetOperationLog(gomock.Any(), "session-1").Return(nil, apperrors.ScanError)
+			},
+			wantErr: apperrors.ScanError,
+		},
+		{
+			name:   "finished session log is read from repository",
+			userID: 1,
+			setup: func(mgr *mocks.MockTableManager, logRepo *mocks.MockTableLogRepository) {
+				mgr.EXPECT().GetOperationLog(gomock.Any(), "session-1").Return(nil, apperrors.TableNotFoundErr)
+				logRepo.EXPECT().GetOperationLog(gomock.Any(), "session-1").Return(storedLog, nil)
+			},
+			want: storedLog,
+		},
+		{
+			name:   "unknown session returns TableNotFoundErr",
+			userID: 1,
+			setup: func(mgr *mocks.MockTableManager, logRepo *mocks.MockTableLogRepository) {
+				mgr.EXPECT().GetOperationLog(gomock.Any(), "session-1").Return(nil, apperrors.TableNotFoundErr)
+				logRepo.EXPECT().GetOperationLog(gomock.Any(), "session-1").Return(nil, apperrors.TableNotFoundErr)
+			},
+			wantErr: apperrors.TableNotFoundErr,
+		},
+		{
+			name:   "non-admin returns PermissionDeniedError",
+			userID: 2,
+			setup: func(mgr *mocks.MockTableManager, _ *mocks.MockTableLogRepository) {
+				mgr.EXPECT().GetOperationLog(gomock.Any(), "session-1").Return(liveLog, nil)
+			},
+			wantErr: apperrors.PermissionDeniedError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			repo := encmocks.NewMockEncounterRepository(ctrl)
+			logRepo := mocks.NewMockTableLogRepository(ctrl)
+			mgr := mocks.NewMockTableManager(ctrl)
+			idGen := mocks.NewMockSessionIDGenerator(ctrl)
+			tf := mocks.NewMockTimerFactory(ctrl)
+			tt.setup(mgr, logRepo)
+
+			uc := NewTableUsecases(repo, logRepo, mgr, idGen, tf, nil)
+			got, err := uc.GetOperationLog(context.Background(), "session-1", tt.userID)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, got)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestControlSession(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		req     *models.SessionControlRequest
+		setup   func(mgr *mocks.MockTableManager)
+		wantErr error
+	}{
+		{
+			name: "end is passed to manager",
+			req:  &models.SessionControlRequest{Action: models.EndSession},
+			setup: func(mgr *mocks.MockTableManager) {
+				mgr.EXPECT().ControlSession(gomock.Any(), "session-1", 1,
+					&models.SessionControlRequest{Action: models.EndSession}).Return(nil)
+			},
+		},
+		{
+			name: "kick of another participant is passed to manager",
+			req:  &models.SessionControlRequest{Action: models.KickParticipant, UserID: 2},
+			setup: func(mgr *mocks.MockTableManager) {
+				mgr.EXPECT().ControlSession(gomock.Any(), "session-1", 1, gomock.Any()).Return(nil)
+			},
+		},
+		{
+			name:    "unknown action returns InvalidControlErr",
+			req:     &models.SessionControlRequest{Action: "explode"},
+			setup:   func(_ *mocks.MockTableManager) {},
+			wantErr: apperrors.InvalidControlErr,
+		},
+		{
+			name:    "ban without target returns InvalidControlErr",
+			req:     &models.SessionControlRequest{Action: models.BanParticipant},
+			setup:   func(_ *mocks.MockTableManager) {},
+			wantErr: apperrors.InvalidControlErr,
+		},
+		{
+			name:    "transfer to self returns InvalidControlErr",
+			req:     &models.SessionControlRequest{Action: models.TransferAdmin, UserID: 1},
+			setup:   func(_ *mocks.MockTableManager) {},
+			wantErr: apperrors.InvalidControlErr,
+		},
+		{
+			name: "manager error is propagated",
+			req:  &models.SessionControlRequest{Action: models.PauseSession},
+			setup: func(mgr *mocks.MockTableManager) {
+				mgr.EXPECT().ControlSession(gomock.Any(), "session-1", 1, gomock.Any()).
+					Return(apperrors.PermissionDeniedError)
+			},
+			wantErr: apperrors.PermissionDeniedError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			repo := encmocks.NewMockEncounterRepository(ctrl)
+			mgr := mocks.NewMockTableManager(ctrl)
+			idGen := mocks.NewMockSessionIDGenerator(ctrl)
+			tf := mocks.NewMockTimerFactory(ctrl)
+			tt.setup(mgr)
+
+			uc := NewTableUsecases(repo, nil, mgr, idGen, tf, nil)
+			err := uc.ControlSession(context.Background(), "session-1", 1, tt.req)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestSessionLifecycle_PauseStopsRefresh(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	repo := encmocks.NewMockEncounterRepository(ctrl)
+	mgr := mocks.NewMockTableManager(ctrl)
+	idGen := mocks.NewMockSessionIDGenerator(ctrl)
+	tf := mocks.NewMockTimerFactory(ctrl)
+	timer := mocks.NewMockSessionTimer(ctrl)
+
+	mgr.EXPECT().RestoreSessions(gomock.Any(), gomock.Any()).Return([]*models.TableSessionSnapshot{
+		{SessionID: "sid-1", EncounterID: "enc-1", AdminID: 1, Paused: true},
+	})
+	tf.EXPECT().AfterFunc(sessionDuration, gomock.Any()).Return(timer)
+
+	// Пауза из снапшота останавливает таймер, активность участников его не перезапускает
+	timer.EXPECT().Stop().Return(true)
+
+	uc := NewTableUsecases(repo, nil, mgr, idGen, tf, nil).(*tableUsecases)
+	uc.RestoreSessions(context.Background())
+	uc.lifecycle.Refresh("sid-1")
+
+	// После снятия паузы таймер отсчитывает время заново
+	timer.EXPECT().Reset(sessionDuration).Return(false)
+	uc.lifecycle.Resume("sid-1")
+
+	// Завершение сессии ведущим запускает сохранение немедленно
+	timer.EXPECT().Reset(time.Duration(0)).Return(false)
+	uc.lifecycle.End("sid-1")
+}
+
+func TestCreateSession_EncounterAlreadyHasSession(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	repo := encmocks.NewMockEncounterRepository(ctrl)
+	mgr := mocks.NewMockTableManager(ctrl)
+	idGen := mocks.NewMockSessionIDGenerator(ctrl)
+	tf := mocks.NewMockTimerFactory(ctrl)
+
+	repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 1, models.EditorEncounterRole).Return(true)
+	repo.EXPECT().GetEncounterByID(gomock.Any(), "enc-1").
+		Return(&models.Encounter{UserID: 1, UUID: "enc-1"}, nil)
+	mgr.EXPECT().FindEncounterSession(gomock.Any(), "enc-1").
+		Return(&models.TableSessionSnapshot{SessionID: "running", EncounterID: "enc-1", AdminID: 1}, nil)
+
+	uc := NewTableUsecases(repo, nil, mgr, idGen, tf, nil)
+	id, err := uc.CreateSession(context.Background(), &models.User{ID: 1}, "enc-1", 0)
+
+	assert.ErrorIs(t, err, apperrors.EncounterSessionErr)
+	assert.Empty(t, id)
+}
+
+func TestListSessions(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	ctrl := gomock.NewController(t)
+	repo := encmocks.NewMockEncounterRepository(ctrl)
+	mgr := mocks.NewMockTableManager(ctrl)
+	idGen := mocks.NewMockSessionIDGenerator(ctrl)
+	tf := mocks.NewMockTimerFactory(ctrl)
+
+	mgr.EXPECT().ListSessions(gomock.Any(), 2).Return([]*models.TableSessionSnapshot{
+		{SessionID: "older", AdminID: 2, StartedAt: now.Add(-time.Hour), LastActivityAt: now.Add(-5 * time.Minute),
+			ParticipantsNum: 3},
+		{SessionID: "newer", AdminID: 1, Members: []int{2}, StartedAt: now.Add(-time.Minute), Paused: true},
+	}, nil)
+
+	uc := NewTableUsecases(repo, nil, mgr, idGen, tf, nil)
+	got, err := uc.ListSessions(context.Background(), 2)
+
+	assert.NoError(t, err)
+	if !assert.Len(t, got, 2) {
+		return
+	}
+
+	assert.Equal(t, "newer", got[0].SessionID)
+	assert.Equal(t, models.Player, got[0].Role)
+	assert.Nil(t, got[0].IdleSecondsLeft)
+
+	assert.Equal(t, "older", got[1].SessionID)
+	assert.Equal(t, models.Admin, got[1].Role)
+	assert.Equal(t, 3, got[1].ParticipantsNum)
+	if assert.NotNil(t, got[1].IdleSecondsLeft) {
+		assert.InDelta(t, (sessionDuration - 5*time.Minute).Seconds(), *got[1].IdleSecondsLeft, 2)
+	}
+}
+
+func TestGetEncounterSession(t *testing.T) {
+	t.Parallel()
+
+	snapshot := &models.TableSessionSnapshot{SessionID: "sid-1", EncounterID: "enc-1", AdminID: 1, Members: []int{2}}
+
+	tests := []struct {
+		name    string
+		userID  int
+		setup   func(repo *encmocks.MockEncounterRepository, mgr *mocks.MockTableManager)
+		wantErr error
+	}{
+		{
+			name:   "session member sees the session",
+			userID: 2,
+			setup: func(_ *encmocks.MockEncounterRepository, mgr *mocks.MockTableManager) {
+				mgr.EXPECT().FindEncounterSession(gomock.Any(), "enc-1").Return(snapshot, nil)
+			},
+		},
+		{
+			name:   "encounter owner sees the session after admin transfer",
+			userID: 3,
+			setup: func(repo *encmocks.MockEncounterRepository, mgr *mocks.MockTableManager) {
+				mgr.EXPECT().FindEncounterSession(gomock.Any(), "enc-1").Return(snapshot, nil)
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 3, models.ViewerEncounterRole).Return(true)
+			},
+		},
+		{
+			name:   "encounter viewer sees the session",
+			userID: 5,
+			setup: func(repo *encmocks.MockEncounterRepository, mgr *mocks.MockTableManager) {
+				mgr.EXPECT().FindEncounterSession(gomock.Any(), "enc-1").Return(snapshot, nil)
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 5, models.ViewerEncounterRole).Return(true)
+			},
+		},
+		{
+			name:   "stranger returns PermissionDeniedError",
+			userID: 4,
+			setup: func(repo *encmocks.MockEncounterRepository, mgr *mocks.MockTableManager) {
+				mgr.EXPECT().FindEncounterSession(gomock.Any(), "enc-1").Return(snapshot, nil)
+				repo.EXPECT().CheckPermission(gomock.Any(), "enc-1", 4, models.ViewerEncounterRole).Return(false)
+			},
+			wantErr: apperrors.PermissionDeniedError,
+		},
+		{
+			name:   "encounter without session returns TableNotFoundErr",
+			userID: 1,
+			setup: func(_ *encmocks.MockEncounterRepository, mgr *mocks.MockTableManager) {
+				mgr.EXPECT().FindEncounterSession(gomock.Any(), "enc-1").Return(nil, apperrors.TableNotFoundErr)
+			},
+			wantErr: apperrors.TableNotFoundErr,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctrl := gomock.NewController(t)
+			repo := encmocks.NewMockEncounterRepository(ctrl)
+			mgr := mocks.NewMockTableManager(ctrl)
+			idGen := mocks.NewMockSessionIDGenerator(ctrl)
+			tf := mocks.NewMockTimerFactory(ctrl)
+			tt.setup(repo, mgr)
+
+			uc := NewTableUsecases(repo, nil, mgr, idGen, tf, nil)
+			got, err := uc.GetEncounterSession(context.Background(), "enc-1", tt.userID)
+
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				assert.Nil(t, got)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "sid-1", got.SessionID)
+		})
+	}
+}
+
+func TestSessionTimer_FlushRecordsTableVersion(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	repo := encmocks.NewMockEncounterRepository(ctrl)
+	mgr := mocks.NewMockTableManager(ctrl)
+	tf := mocks.NewMockTimerFactory(ctrl)
+	timer := mocks.NewMockSessionTimer(ctrl)
+
+	var flush func()
+
+	mgr.EXPECT().RestoreSessions(gomock.Any(), gomock.Any()).Return([]*models.TableSessionSnapshot{
+		{SessionID: "sid-1", EncounterID: "enc-1", AdminID: 1},
+	})
+	tf.EXPECT().AfterFunc(sessionDuration, gomock.Any()).DoAndReturn(
+		func(_ time.Duration, f func()) *mocks.MockSessionTimer {
+			flush = f
+			return timer
+		})
+
+	uc := NewTableUsecases(repo, nil, mgr, nil, tf, nil)
+	uc.RestoreSessions(context.Background())
+
+	// Admin rights were transferred during the session, so the new admin authors the version
+	data := []byte(`{"schemaVersion":1,"participants":[]}`)
+	mgr.EXPECT().GetEncounterData(gomock.Any(), "sid-1").Return(data, nil)
+	timer.EXPECT().Stop().Return(false)
+	mgr.EXPECT().GetSessionAdmin(gomock.Any(), "sid-1").Return(2, nil)
+	repo.EXPECT().UpdateEncounter(gomock.Any(), data, "enc-1",
+		&models.EncounterChange{AuthorID: 2, Source: models.TableVersionSource}).Return(nil)
+	mgr.EXPECT().GetOperationLog(gomock.Any(), "sid-1").Return(nil, apperrors.TableNotFoundErr)
+	mgr.EXPECT().RemoveSession(gomock.Any(), "sid-1")
+
+	flush()
+}
+
+func TestSessionTimer_FlushErrorKeepsSession(t *testing.T) {
+	t.Parallel()
+
+	ctrl := gomock.NewController(t)
+	repo := encmocks.NewMockEncounterRepository(ctrl)
+	mgr := mocks.NewMockTableManager(ctrl)
+	tf := mocks.NewMockTimerFactory(ctrl)
+	timer := mocks.NewMockSessionTimer(ctrl)
+
+	var flush func()
+
+	mgr.EXPECT().RestoreSessions(gomock.Any(), gomock.Any()).Return([]*models.TableSessionSnapshot{
+		{SessionID: "sid-1", EncounterID: "enc-1", AdminID: 1},
+	})
+	tf.EXPECT().AfterFunc(sessionDuration, gomock.Any()).DoAndReturn(
+		func(_ time.Duration, f func()) *mocks.MockSessionTimer {
+			flush = f
+			return timer
+		})
+
+	uc := NewTableUsecases(repo, nil, mgr, nil, tf, nil)
+	uc.RestoreSessions(context.Background())
+
+	data := []byte(`{"schemaVersion":1,"participants":[]}`)
+	dbErr := errors.New("db failure")
+
+	// The first flush fails: the session stays and the timer is re-armed for a retry
+	gomock.InOrder(
+		mgr.EXPECT().GetEncounterData(gomock.Any(), "sid-1").Return(data, nil),
+		mgr.EXPECT().GetSessionAdmin(gomock.Any(), "sid-1").Return(1, nil),
+		repo.EXPECT().UpdateEncounter(gomock.Any(), data, "enc-1",
+			&models.EncounterChange{AuthorID: 1, Source: models.TableVersionSource}).Return(dbErr),
+		timer.EXPECT().Reset(flushRetryDelay).Return(false),
+	)
+
+	flush()
+
+	// The retry succeeds and removes the session
+	gomock.InOrder(
+		mgr.EXPECT().GetEncounterData(gomock.Any(), "sid-1").Return(data, nil),
+		mgr.EXPECT().GetSessionAdmin(gomock.Any(), "sid-1").Return(1, nil),
+		repo.EXPECT().UpdateEncounter(gomock.Any(), data, "enc-1",
+			&models.EncounterChange{AuthorID: 1, Source: models.TableVersionSource}).Return(nil),
+		timer.EXPECT().Stop().Return(false),
+		mgr.EXPECT().GetOperationLog(gomock.Any(), "sid-1").Return(nil, apperrors.TableNotFoundErr),
+		mgr.EXPECT().RemoveSession(gomock.Any(), "sid-1"),
+	)
+
+	flush()
+}
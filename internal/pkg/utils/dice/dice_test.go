@@ -0,0 +1,140 @@
+package dice_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils/dice"
+	"github.com/stretchr/testify/assert"
+)
+
+// sequenceRNG возвращает заранее заданные значения по кругу
+func sequenceRNG(values ...int) dice.RNG {
+	i := 0
+
+	return func(sides int) (int, error) {
+		v := values[i%len(values)]
+		i++
+
+		return v, nil
+	}
+}
+
+func TestRoll(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		formula   string
+		rolls     []int
+		wantTotal int
+		wantTerms []models.DiceTerm
+	}{
+		{
+			name:      "must roll russian notation with modifier",
+			formula:   "3к8 + 5",
+			rolls:     []int{1, 4, 8},
+			wantTotal: 18,
+			wantTerms: []models.DiceTerm{
+				{Sign: 1, Dice: models.D8, Count: 3, Subtotal: 13,
+					Rolls: []models.DieResult{{Value: 1}, {Value: 4}, {Value: 8}}},
+				{Sign: 1, Constant: 5, Subtotal: 5},
+			},
+		},
+		{
+			name:      "must keep highest for advantage",
+			formula:   "2d20kh1",
+			rolls:     []int{7, 15},
+			wantTotal: 15,
+			wantTerms: []models.DiceTerm{
+				{Sign: 1, Dice: models.D20, Count: 2, Subtotal: 15,
+					Rolls: []models.DieResult{{Value: 7, Dropped: true}, {Value: 15}}},
+			},
+		},
+		{
+			name:      "must keep lowest for disadvantage",
+			formula:   "2d20kl1",
+			rolls:     []int{7, 15},
+			wantTotal: 7,
+			wantTerms: []models.DiceTerm{
+				{Sign: 1, Dice: models.D20, Count: 2, Subtotal: 7,
+					Rolls: []models.DieResult{{Value: 7}, {Value: 15, Dropped: true}}},
+			},
+		},
+		{
+			name:      "must drop lowest",
+			formula:   "4d6dl1",
+			rolls:     []int{3, 1, 6, 1},
+			wantTotal: 10,
+			wantTerms: []models.DiceTerm{
+				{Sign: 1, Dice: models.D6, Count: 4, Subtotal: 10,
+					Rolls: []models.DieResult{{Value: 3}, {Value: 1, Dropped: true}, {Value: 6}, {Value: 1}}},
+			},
+		},
+		{
+			name:      "must explode on max value",
+			formula:   "2d6!",
+			rolls:     []int{6, 6, 2, 3},
+			wantTotal: 17,
+			wantTerms: []models.DiceTerm{
+				{Sign: 1, Dice: models.D6, Count: 2, Subtotal: 17,
+					Rolls: []models.DieResult{{Value: 6, Exploded: true}, {Value: 6, Exploded: true},
+						{Value: 2}, {Value: 3}}},
+			},
+		},
+		{
+			name:      "must support d100 and subtraction",
+			formula:   "d100 - 2",
+			rolls:     []int{42},
+			wantTotal: 40,
+			wantTerms: []models.DiceTerm{
+				{Sign: 1, Dice: models.D100, Count: 1, Subtotal: 42, Rolls: []models.DieResult{{Value: 42}}},
+				{Sign: -1, Constant: 2, Subtotal: 2},
+			},
+		},
+		{
+			name:      "must support percentile notation",
+			formula:   "к%",
+			rolls:     []int{99},
+			wantTotal: 99,
+			wantTerms: []models.DiceTerm{
+				{Sign: 1, Dice: models.D100, Count: 1, Subtotal: 99, Rolls: []models.DieResult{{Value: 99}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			expr, err := dice.Parse(tt.formula)
+			assert.NoError(t, err)
+
+			result, err := expr.Roll(sequenceRNG(tt.rolls...))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.formula, result.Formula)
+			assert.Equal(t, tt.wantTotal, result.Total)
+			assert.Equal(t, tt.wantTerms, result.Terms)
+		})
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	t.Parallel()
+
+	for _, formula := range []string{"", "d", "3к", "2d20kh3", "d1", "1000d6", "2d6+", "2d6*3", "4d6dx1", "2d20kh1kl1"} {
+		_, err := dice.Parse(formula)
+		assert.True(t, errors.Is(err, dice.ErrInvalidFormula), "formula %q: %v", formula, err)
+	}
+}
+
+func TestRoll_CryptoRNGInRange(t *testing.T) {
+	t.Parallel()
+
+	for i := 0; i < 100; i++ {
+		result, err := dice.Roll("d20")
+		assert.NoError(t, err)
+		assert.True(t, result.Total >= 1 && result.Total <= 20, "total %d", result.Total)
+	}
+}
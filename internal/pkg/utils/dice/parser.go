@@ -0,0 +1,228 @@
+package dice
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	maxTerms = 20
+	maxCount = 100
+	maxSides = 1000
+)
+
+var ErrInvalidFormula = errors.New("invalid dice formula")
+
+type keepMode int
+
+const (
+	keepAll keepMode = iota
+	keepHighest
+	keepLowest
+	dropHighest
+	dropLowest
+)
+
+type term struct {
+	sign     int
+	constant int
+
+	count   int
+	sides   int
+	mode    keepMode
+	modeN   int
+	explode bool
+}
+
+func (t *term) isDice() bool {
+	return t.sides > 0
+}
+
+// Expression — разобранная формула броска
+type Expression struct {
+	formula string
+	terms   []term
+}
+
+// Parse разбирает формулу броска. Поддерживаются кости в записи "d" и "к" (3к8, 2d20, d100, d%),
+// модификаторы kh/kl/dh/dl (k — то же, что kh), взрывающиеся кости "!" и целые константы
+func Parse(formula string) (*Expression, error) {
+	src := []rune(strings.ToLower(strings.Join(strings.Fields(formula), "")))
+	if len(src) == 0 {
+		return nil, fmt.Errorf("%w: empty formula", ErrInvalidFormula)
+	}
+
+	p := &parser{src: src}
+	expr := &Expression{formula: strings.TrimSpace(formula)}
+
+	sign := 1
+	if p.peek() == '+' || p.peek() == '-' {
+		sign = p.sign()
+	}
+
+	for {
+		t, err := p.term()
+		if err != nil {
+			return nil, err
+		}
+
+		t.sign = sign
+		expr.terms = append(expr.terms, t)
+
+		if len(expr.terms) > maxTerms {
+			return nil, fmt.Errorf("%w: too many terms", ErrInvalidFormula)
+		}
+
+		if p.done() {
+			return expr, nil
+		}
+
+		if p.peek() != '+' && p.peek() != '-' {
+			return nil, fmt.Errorf("%w: unexpected %q", ErrInvalidFormula, p.peek())
+		}
+
+		sign = p.sign()
+	}
+}
+
+type parser struct {
+	src []rune
+	pos int
+}
+
+func (p *parser) done() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *parser) peek() rune {
+	if p.done() {
+		return 0
+	}
+
+	return p.src[p.pos]
+}
+
+func (p *parser) sign() int {
+	r := p.src[p.pos]
+	p.pos++
+
+	if r == '-' {
+		return -1
+	}
+
+	return 1
+}
+
+func (p *parser) number() (int, bool) {
+	start := p.pos
+	for !p.done() && unicode.IsDigit(p.peek()) {
+		p.pos++
+	}
+
+	if start == p.pos {
+		return 0, false
+	}
+
+	n, err := strconv.Atoi(string(p.src[start:p.pos]))
+	if err != nil {
+		return 0, false
+	}
+
+	return n, true
+}
+
+func (p *parser) term() (term, error) {
+	count, hasCount := p.number()
+
+	if r := p.peek(); r != 'd' && r != 'к' {
+		if !hasCount {
+			return term{}, fmt.Errorf("%w: expected number or dice at position %d", ErrInvalidFormula, p.pos)
+		}
+
+		return term{constant: count}, nil
+	}
+
+	p.pos++
+
+	if !hasCount {
+		count = 1
+	}
+
+	var sides int
+	if p.peek() == '%' {
+		p.pos++
+		sides = 100
+	} else {
+		var ok bool
+		if sides, ok = p.number(); !ok {
+			return term{}, fmt.Errorf("%w: expected dice sides at position %d", ErrInvalidFormula, p.pos)
+		}
+	}
+
+	if count < 1 || count > maxCount || sides < 2 || sides > maxSides {
+		return term{}, fmt.Errorf("%w: dice out of range", ErrInvalidFormula)
+	}
+
+	t := term{count: count, sides: sides}
+
+	if err := p.modifiers(&t); err != nil {
+		return term{}, err
+	}
+
+	return t, nil
+}
+
+func (p *parser) modifiers(t *term) error {
+	for !p.done() {
+		switch p.peek() {
+		case '!':
+			p.pos++
+			t.explode = true
+		case 'k', 'd':
+			if t.mode != keepAll {
+				return fmt.Errorf("%w: multiple keep/drop modifiers", ErrInvalidFormula)
+			}
+
+			kind := p.src[p.pos]
+			p.pos++
+
+			direction := p.peek()
+			if direction == 'h' || direction == 'l' {
+				p.pos++
+			} else if kind == 'k' {
+				direction = 'h'
+			} else {
+				return fmt.Errorf("%w: expected h or l after d at position %d", ErrInvalidFormula, p.pos)
+			}
+
+			n, ok := p.number()
+			if !ok {
+				n = 1
+			}
+
+			if n < 0 || n > t.count {
+				return fmt.Errorf("%w: keep/drop count out of range", ErrInvalidFormula)
+			}
+
+			t.modeN = n
+
+			switch {
+			case kind == 'k' && direction == 'h':
+				t.mode = keepHighest
+			case kind == 'k':
+				t.mode = keepLowest
+			case direction == 'h':
+				t.mode = dropHighest
+			default:
+				t.mode = dropLowest
+			}
+		default:
+			return nil
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package dice
+
+import (
+	"crypto/rand"
+	"math/big"
+	"slices"
+	"strconv"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+)
+
+// maxExplosions ограничивает число дополнительных костей у взрывающейся группы
+const maxExplosions = 100
+
+// RNG возвращает случайное число от 1 до sides включительно
+type RNG func(sides int) (int, error)
+
+// CryptoRNG — генератор на основе crypto/rand, используется для всех бросков на сервере
+func CryptoRNG(sides int) (int, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(int64(sides)))
+	if err != nil {
+		return 0, err
+	}
+
+	return int(n.Int64()) + 1, nil
+}
+
+// Roll разбирает формулу и бросает кости с помощью CryptoRNG
+func Roll(formula string) (*models.DiceRoll, error) {
+	expr, err := Parse(formula)
+	if err != nil {
+		return nil, err
+	}
+
+	return expr.Roll(CryptoRNG)
+}
+
+func (e *Expression) Roll(rng RNG) (*models.DiceRoll, error) {
+	result := &models.DiceRoll{
+		Formula: e.formula,
+		Terms:   make([]models.DiceTerm, 0, len(e.terms)),
+	}
+
+	for _, t := range e.terms {
+		rolled, err := t.roll(rng)
+		if err != nil {
+			return nil, err
+		}
+
+		result.Total += rolled.Sign * rolled.Subtotal
+		result.Terms = append(result.Terms, rolled)
+	}
+
+	return result, nil
+}
+
+func (t *term) roll(rng RNG) (models.DiceTerm, error) {
+	if !t.isDice() {
+		return models.DiceTerm{Sign: t.sign, Constant: t.constant, Subtotal: t.constant}, nil
+	}
+
+	rolls := make([]models.DieResult, 0, t.count)
+	explosions := 0
+
+	for i := 0; i < t.count; i++ {
+		for {
+			value, err := rng(t.sides)
+			if err != nil {
+				return models.DiceTerm{}, err
+			}
+
+			exploded := t.explode && value == t.sides && explosions < maxExplosions
+			rolls = append(rolls, models.DieResult{Value: value, Exploded: exploded})
+
+			if !exploded {
+				break
+			}
+
+			explosions++
+		}
+	}
+
+	t.markDropped(rolls)
+
+	subtotal := 0
+	for _, r := range rolls {
+		if !r.Dropped {
+			subtotal += r.Value
+		}
+	}
+
+	return models.DiceTerm{
+		Sign:     t.sign,
+		Dice:     models.DiceType("d" + strconv.Itoa(t.sides)),
+		Count:    t.count,
+		Rolls:    rolls,
+		Subtotal: subtotal,
+	}, nil
+}
+
+// markDropped помечает кости, отброшенные модификаторами kh/kl/dh/dl
+func (t *term) markDropped(rolls []models.DieResult) {
+	if t.mode == keepAll {
+		return
+	}
+
+	// Индексы костей по возрастанию значения, при равенстве — в порядке броска
+	order := make([]int, len(rolls))
+	for i := range order {
+		order[i] = i
+	}
+
+	slices.SortStableFunc(order, func(a, b int) int {
+		return rolls[a].Value - rolls[b].Value
+	})
+
+	var dropped []int
+
+	switch t.mode {
+	case keepHighest:
+		dropped = order[:max(len(order)-t.modeN, 0)]
+	case keepLowest:
+		dropped = order[min(t.modeN, len(order)):]
+	case dropHighest:
+		dropped = order[max(len(order)-t.modeN, 0):]
+	case dropLowest:
+		dropped = order[:min(t.modeN, len(order))]
+	}
+
+	for _, i := range dropped {
+		rolls[i].Dropped = true
+	}
+}
@@ -0,0 +1,166 @@
+package merger
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type diffOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Diff возвращает JSON Patch (RFC 6902), который переводит первый документ во второй. Изменяются
+// только отличающиеся значения: объекты сравниваются по ключам, у массивов общие начало и конец
+// пропускаются, а остальные элементы сравниваются попарно
+func Diff(oldBuf, newBuf []byte) ([]byte, error) {
+	var oldData, newData interface{}
+
+	err := unmarshalJSON(oldBuf, &oldData)
+	if err != nil {
+		err = fmt.Errorf("something went wrong while unmarshalling old JSON: %v", err)
+
+		return nil, err
+	}
+
+	err = unmarshalJSON(newBuf, &newData)
+	if err != nil {
+		err = fmt.Errorf("something went wrong while unmarshalling new JSON: %v", err)
+
+		return nil, err
+	}
+
+	ops := make([]diffOperation, 0)
+
+	err = diffValues(oldData, newData, "", &ops)
+	if err != nil {
+		err = fmt.Errorf("something went wrong while diffing JSON: %v", err)
+
+		return nil, err
+	}
+
+	diffBuf, err := json.Marshal(ops)
+	if err != nil {
+		err = fmt.Errorf("something went wrong while marshalling diff JSON: %v", err)
+
+		return nil, err
+	}
+
+	return diffBuf, nil
+}
+
+func diffValues(oldValue, newValue interface{}, pointer string, ops *[]diffOperation) error {
+	switch oldNode := oldValue.(type) {
+	case map[string]interface{}:
+		if newNode, ok := newValue.(map[string]interface{}); ok {
+			return diffObjects(oldNode, newNode, pointer, ops)
+		}
+	case []interface{}:
+		if newNode, ok := newValue.([]interface{}); ok {
+			return diffArrays(oldNode, newNode, pointer, ops)
+		}
+	}
+
+	if jsonEqual(oldValue, newValue) {
+		return nil
+	}
+
+	return appendDiffOperation(ops, "replace", pointer, newValue)
+}
+
+func diffObjects(oldNode, newNode map[string]interface{}, pointer string, ops *[]diffOperation) error {
+	keys := make([]string, 0, len(oldNode))
+	for k := range oldNode {
+		keys = append(keys, k)
+	}
+
+	slices.Sort(keys)
+
+	for _, k := range keys {
+		newValue, ok := newNode[k]
+		if !ok {
+			*ops = append(*ops, diffOperation{Op: "remove", Path: pointer + "/" + escapePointerToken(k)})
+			continue
+		}
+
+		if err := diffValues(oldNode[k], newValue, pointer+"/"+escapePointerToken(k), ops); err != nil {
+			return err
+		}
+	}
+
+	added := make([]string, 0)
+	for k := range newNode {
+		if _, ok := oldNode[k]; !ok {
+			added = append(added, k)
+		}
+	}
+
+	slices.Sort(added)
+
+	for _, k := range added {
+		if err := appendDiffOperation(ops, "add", pointer+"/"+escapePointerToken(k), newNode[k]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func diffArrays(oldNode, newNode []interface{}, pointer string, ops *[]diffOperation) error {
+	common := min(len(oldNode), len(newNode))
+
+	prefix := 0
+	for prefix < common && jsonEqual(oldNode[prefix], newNode[prefix]) {
+		prefix++
+	}
+
+	suffix := 0
+	for suffix < common-prefix &&
+		jsonEqual(oldNode[len(oldNode)-1-suffix], newNode[len(newNode)-1-suffix]) {
+		suffix++
+	}
+
+	oldMiddle := oldNode[prefix : len(oldNode)-suffix]
+	newMiddle := newNode[prefix : len(newNode)-suffix]
+	paired := min(len(oldMiddle), len(newMiddle))
+
+	for i := 0; i < paired; i++ {
+		err := diffValues(oldMiddle[i], newMiddle[i], pointer+"/"+strconv.Itoa(prefix+i), ops)
+		if err != nil {
+			return err
+		}
+	}
+
+	// Лишние элементы удаляются с одного и того же индекса, следующие за ними сдвигаются на их место
+	for i := paired; i < len(oldMiddle); i++ {
+		*ops = append(*ops, diffOperation{Op: "remove", Path: pointer + "/" + strconv.Itoa(prefix+paired)})
+	}
+
+	for i := paired; i < len(newMiddle); i++ {
+		err := appendDiffOperation(ops, "add", pointer+"/"+strconv.Itoa(prefix+i), newMiddle[i])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func appendDiffOperation(ops *[]diffOperation, op, pointer string, value interface{}) error {
+	valueBuf, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	*ops = append(*ops, diffOperation{Op: op, Path: pointer, Value: valueBuf})
+
+	return nil
+}
+
+func escapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
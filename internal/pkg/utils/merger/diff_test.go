@@ -0,0 +1,83 @@
+package merger_test
+
+import (
+	"testing"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils/merger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		expected string
+	}{
+		{
+			name:     "must return empty patch for equal documents",
+			old:      `{"a": 1, "b": [1, 2]}`,
+			new:      `{"b": [1, 2], "a": 1.0}`,
+			expected: `[]`,
+		},
+		{
+			name: "must replace, remove and add object members",
+			old:  `{"a": 1, "b": {"c": 2, "d": 3}}`,
+			new:  `{"b": {"c": 4, "d": 3}, "e": null}`,
+			expected: `[{"op":"remove","path":"/a"},{"op":"replace","path":"/b/c","value":4},` +
+				`{"op":"add","path":"/e","value":null}]`,
+		},
+		{
+			name:     "must patch only changed array elements",
+			old:      `{"p": [{"hp": 10}, {"hp": 5}, {"hp": 7}]}`,
+			new:      `{"p": [{"hp": 10}, {"hp": 0}, {"hp": 7}]}`,
+			expected: `[{"op":"replace","path":"/p/1/hp","value":0}]`,
+		},
+		{
+			name:     "must insert into the middle of array",
+			old:      `{"p": [1, 2, 3]}`,
+			new:      `{"p": [1, 9, 2, 3]}`,
+			expected: `[{"op":"add","path":"/p/1","value":9}]`,
+		},
+		{
+			name:     "must remove from the start of array",
+			old:      `{"p": [1, 2, 3]}`,
+			new:      `{"p": [3]}`,
+			expected: `[{"op":"remove","path":"/p/0"},{"op":"remove","path":"/p/0"}]`,
+		},
+		{
+			name:     "must escape pointer tokens",
+			old:      `{"a/b": 1, "m~n": 2}`,
+			new:      `{"a/b": 2, "m~n": 2}`,
+			expected: `[{"op":"replace","path":"/a~1b","value":2}]`,
+		},
+		{
+			name:     "must replace values of different types",
+			old:      `{"a": [1]}`,
+			new:      `{"a": {"0": 1}}`,
+			expected: `[{"op":"replace","path":"/a","value":{"0":1}}]`,
+		},
+		{
+			name:     "must replace the whole document",
+			old:      `{"a": 1}`,
+			new:      `[1]`,
+			expected: `[{"op":"replace","path":"","value":[1]}]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			diff, err := merger.Diff([]byte(tt.old), []byte(tt.new))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(diff))
+
+			patched, err := merger.ApplyJSONPatch([]byte(tt.old), diff)
+			assert.NoError(t, err)
+			assert.JSONEq(t, tt.new, string(patched))
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package merger
+
+import (
+	"fmt"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+)
+
+// Apply применяет патч в указанном формате. Пустой формат означает собственный формат Merge
+func Apply(format models.PatchFormat, dataBuf, patchBuf []byte) ([]byte, error) {
+	switch format {
+	case models.LegacyPatch:
+		return Merge(dataBuf, patchBuf)
+	case models.JSONPatch:
+		return ApplyJSONPatch(dataBuf, patchBuf)
+	case models.MergePatch:
+		return ApplyMergePatch(dataBuf, patchBuf)
+	default:
+		return nil, fmt.Errorf("unknown patch format \"%v\"", format)
+	}
+}
+
+// PatchPaths возвращает отсортированный список путей, которые изменяет патч в указанном формате.
+// Пустая строка означает весь документ
+func PatchPaths(format models.PatchFormat, patchBuf []byte) ([]string, error) {
+	switch format {
+	case models.LegacyPatch:
+		return Paths(patchBuf)
+	case models.JSONPatch:
+		return jsonPatchPaths(patchBuf)
+	case models.MergePatch:
+		return mergePatchPaths(patchBuf)
+	default:
+		return nil, fmt.Errorf("unknown patch format \"%v\"", format)
+	}
+}
+
+// ReadPaths возвращает отсортированный список путей, значения которых патч читает, не изменяя их.
+// Такие пути есть только у JSON Patch: источник copy и путь test
+func ReadPaths(format models.PatchFormat, patchBuf []byte) ([]string, error) {
+	switch format {
+	case models.LegacyPatch, models.MergePatch:
+		return nil, nil
+	case models.JSONPatch:
+		return jsonPatchReadPaths(patchBuf)
+	default:
+		return nil, fmt.Errorf("unknown patch format \"%v\"", format)
+	}
+}
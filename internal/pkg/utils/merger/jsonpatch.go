@@ -0,0 +1,499 @@
+package merger
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// jsonPatchOperation — операция JSON Patch. Value остаётся необработанным, чтобы отличать
+// отсутствующее значение от null
+type jsonPatchOperation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+
+	value interface{}
+}
+
+// ApplyJSONPatch применяет JSON Patch (RFC 6902): операции add, remove, replace, move, copy и test
+// выполняются по порядку, и если хотя бы одна из них не удалась, документ не изменяется
+func ApplyJSONPatch(dataBuf, patchBuf []byte) ([]byte, error) {
+	var data interface{}
+
+	err := unmarshalJSON(dataBuf, &data)
+	if err != nil {
+		err = fmt.Errorf("something went wrong while unmarshalling data JSON: %v", err)
+
+		return nil, err
+	}
+
+	ops, err := parseJSONPatch(patchBuf)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, op := range ops {
+		data, err = applyJSONPatchOperation(data, op)
+		if err != nil {
+			err = fmt.Errorf("something went wrong while applying operation %d (%v): %v", i, op.Op, err)
+
+			return nil, err
+		}
+	}
+
+	patchedBuf, err := json.Marshal(data)
+	if err != nil {
+		err = fmt.Errorf("something went wrong while marshalling patched JSON: %v", err)
+
+		return nil, err
+	}
+
+	return patchedBuf, nil
+}
+
+func parseJSONPatch(patchBuf []byte) ([]jsonPatchOperation, error) {
+	var ops []jsonPatchOperation
+
+	err := unmarshalJSON(patchBuf, &ops)
+	if err != nil {
+		err = fmt.Errorf("something went wrong while unmarshalling patch JSON: %v", err)
+
+		return nil, err
+	}
+
+	for i := range ops {
+		op := &ops[i]
+
+		if op.Path == nil {
+			return nil, fmt.Errorf("operation %d has no path", i)
+		}
+
+		switch op.Op {
+		case "add", "replace", "test":
+			if op.Value == nil {
+				return nil, fmt.Errorf("operation %d (%v) has no value", i, op.Op)
+			}
+
+			if err := unmarshalJSON(op.Value, &op.value); err != nil {
+				return nil, fmt.Errorf("operation %d (%v) has invalid value: %v", i, op.Op, err)
+			}
+		case "move", "copy":
+			if op.From == nil {
+				return nil, fmt.Errorf("operation %d (%v) has no from", i, op.Op)
+			}
+		case "remove":
+		default:
+			return nil, fmt.Errorf("operation %d has unknown op \"%v\"", i, op.Op)
+		}
+	}
+
+	return ops, nil
+}
+
+func applyJSONPatchOperation(data interface{}, op jsonPatchOperation) (interface{}, error) {
+	path, err := parsePointer(*op.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Op {
+	case "add":
+		return addValue(data, path, op.value)
+	case "remove":
+		data, _, err = removeValue(data, path)
+
+		return data, err
+	case "replace":
+		if _, err := getValue(data, path); err != nil {
+			return nil, err
+		}
+
+		return replaceValue(data, path, op.value)
+	case "test":
+		value, err := getValue(data, path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !jsonEqual(value, op.value) {
+			return nil, fmt.Errorf("test failed for path \"%v\"", *op.Path)
+		}
+
+		return data, nil
+	}
+
+	from, err := parsePointer(*op.From)
+	if err != nil {
+		return nil, err
+	}
+
+	if op.Op == "copy" {
+		value, err := getValue(data, from)
+		if err != nil {
+			return nil, err
+		}
+
+		return addValue(data, path, cloneValue(value))
+	}
+
+	// move
+	if len(from) < len(path) && slices.Equal(from, path[:len(from)]) {
+		return nil, fmt.Errorf("cannot move \"%v\" into its own child", *op.From)
+	}
+
+	data, value, err := removeValue(data, from)
+	if err != nil {
+		return nil, err
+	}
+
+	return addValue(data, path, value)
+}
+
+// parsePointer разбирает JSON Pointer (RFC 6901) на ключи
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return []string{}, nil
+	}
+
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON pointer \"%v\"", pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	for i, token := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+	}
+
+	return tokens, nil
+}
+
+func getValue(data interface{}, path []string) (interface{}, error) {
+	for i, key := range path {
+		switch node := data.(type) {
+		case map[string]interface{}:
+			value, ok := node[key]
+			if !ok {
+				return nil, fmt.Errorf("path \"%v\" does not exist", strings.Join(path[:i+1], "."))
+			}
+
+			data = value
+		case []interface{}:
+			idx, err := arrayIndex(key, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+
+			data = node[idx]
+		default:
+			return nil, fmt.Errorf("path \"%v\" does not exist", strings.Join(path[:i+1], "."))
+		}
+	}
+
+	return data, nil
+}
+
+// updateParent находит контейнер, в котором лежит последний ключ пути, и заменяет его результатом update
+func updateParent(data interface{}, path []string,
+	update func(parent interface{}, key string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return update(data, path[0])
+	}
+
+	switch node := data.(type) {
+	case map[string]interface{}:
+		child, ok := node[path[0]]
+		if !ok {
+			return nil, fmt.Errorf("key \"%v\" does not exist", path[0])
+		}
+
+		updated, err := updateParent(child, path[1:], update)
+		if err != nil {
+			return nil, err
+		}
+
+		node[path[0]] = updated
+
+		return node, nil
+	case []interface{}:
+		idx, err := arrayIndex(path[0], len(node)-1)
+		if err != nil {
+			return nil, err
+		}
+
+		updated, err := updateParent(node[idx], path[1:], update)
+		if err != nil {
+			return nil, err
+		}
+
+		node[idx] = updated
+
+		return node, nil
+	default:
+		return nil, fmt.Errorf("key \"%v\" does not exist", path[0])
+	}
+}
+
+func addValue(data interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return updateParent(data, path, func(parent interface{}, key string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			node[key] = value
+
+			return node, nil
+		case []interface{}:
+			if key == "-" {
+				return append(node, value), nil
+			}
+
+			idx, err := arrayIndex(key, len(node))
+			if err != nil {
+				return nil, err
+			}
+
+			return slices.Insert(node, idx, value), nil
+		default:
+			return nil, fmt.Errorf("cannot add key \"%v\" to a scalar value", key)
+		}
+	})
+}
+
+func replaceValue(data interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return updateParent(data, path, func(parent interface{}, key string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			node[key] = value
+
+			return node, nil
+		case []interface{}:
+			idx, err := arrayIndex(key, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+
+			node[idx] = value
+
+			return node, nil
+		default:
+			return nil, fmt.Errorf("cannot replace key \"%v\" in a scalar value", key)
+		}
+	})
+}
+
+// removeValue удаляет значение по пути и возвращает его
+func removeValue(data interface{}, path []string) (interface{}, interface{}, error) {
+	if len(path) == 0 {
+		return nil, nil, fmt.Errorf("cannot remove the whole document")
+	}
+
+	var removed interface{}
+
+	data, err := updateParent(data, path, func(parent interface{}, key string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			value, ok := node[key]
+			if !ok {
+				return nil, fmt.Errorf("key \"%v\" does not exist", key)
+			}
+
+			removed = value
+			delete(node, key)
+
+			return node, nil
+		case []interface{}:
+			idx, err := arrayIndex(key, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+
+			removed = node[idx]
+
+			return slices.Delete(node, idx, idx+1), nil
+		default:
+			return nil, fmt.Errorf("cannot remove key \"%v\" from a scalar value", key)
+		}
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, removed, nil
+}
+
+// arrayIndex разбирает индекс массива без ведущих нулей, не превышающий maxIdx
+func arrayIndex(key string, maxIdx int) (int, error) {
+	idx, err := strconv.Atoi(key)
+	if err != nil || idx < 0 || (len(key) > 1 && key[0] == '0') || key[0] == '+' {
+		return 0, fmt.Errorf("invalid array index \"%v\"", key)
+	}
+
+	if idx > maxIdx {
+		return 0, fmt.Errorf("array index %d is out of range", idx)
+	}
+
+	return idx, nil
+}
+
+func cloneValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		ret := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			ret[k] = cloneValue(item)
+		}
+
+		return ret
+	case []interface{}:
+		ret := make([]interface{}, len(v))
+		for i, item := range v {
+			ret[i] = cloneValue(item)
+		}
+
+		return ret
+	default:
+		return v
+	}
+}
+
+// jsonEqual сравнивает значения по правилам операции test: числа сравниваются по значению,
+// объекты — без учёта порядка ключей
+func jsonEqual(a, b interface{}) bool {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+
+		for k, v := range av {
+			other, ok := bv[k]
+			if !ok || !jsonEqual(v, other) {
+				return false
+			}
+		}
+
+		return true
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+
+		for i := range av {
+			if !jsonEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+
+		return true
+	case json.Number:
+		bv, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+
+		x, okX := new(big.Rat).SetString(av.String())
+		y, okY := new(big.Rat).SetString(bv.String())
+
+		return okX && okY && x.Cmp(y) == 0
+	default:
+		return a == b
+	}
+}
+
+// jsonPatchPaths строит пути так же, как Paths. Операции, вставляющие или удаляющие элементы
+// массива, сдвигают индексы следующих элементов, поэтому для них изменённым считается весь массив.
+// Операция test ничего не изменяет
+func jsonPatchPaths(patchBuf []byte) ([]string, error) {
+	ops, err := parseJSONPatch(patchBuf)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(ops))
+
+	for _, op := range ops {
+		path, err := parsePointer(*op.Path)
+		if err != nil {
+			return nil, err
+		}
+
+		switch op.Op {
+		case "add", "remove", "copy":
+			paths = append(paths, changedPath(path, true))
+		case "replace":
+			paths = append(paths, changedPath(path, false))
+		case "move":
+			from, err := parsePointer(*op.From)
+			if err != nil {
+				return nil, err
+			}
+
+			paths = append(paths, changedPath(from, true), changedPath(path, true))
+		}
+	}
+
+	slices.Sort(paths)
+
+	return slices.Compact(paths), nil
+}
+
+// jsonPatchReadPaths возвращает пути, которые операции copy и test читают: copy переносит значение
+// из from в другое место документа, а test по результату раскрывает значение по path
+func jsonPatchReadPaths(patchBuf []byte) ([]string, error) {
+	ops, err := parseJSONPatch(patchBuf)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0)
+
+	for _, op := range ops {
+		var pointer string
+
+		switch op.Op {
+		case "copy":
+			pointer = *op.From
+		case "test":
+			pointer = *op.Path
+		default:
+			continue
+		}
+
+		path, err := parsePointer(pointer)
+		if err != nil {
+			return nil, err
+		}
+
+		paths = append(paths, changedPath(path, false))
+	}
+
+	slices.Sort(paths)
+
+	return slices.Compact(paths), nil
+}
+
+// changedPath переводит путь JSON Pointer в путь через точку. Если shifts и последний ключ
+// похож на индекс массива, изменённым считается родительский путь
+func changedPath(path []string, shifts bool) string {
+	if shifts && len(path) > 0 {
+		last := path[len(path)-1]
+		if _, err := strconv.Atoi(last); err == nil || last == "-" {
+			path = path[:len(path)-1]
+		}
+	}
+
+	return strings.Join(path, ".")
+}
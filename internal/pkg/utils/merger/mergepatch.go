@@ -0,0 +1,81 @@
+package merger
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// ApplyMergePatch применяет JSON Merge Patch (RFC 7386): null удаляет поле, объекты сливаются
+// рекурсивно, остальные значения, включая массивы, заменяются целиком
+func ApplyMergePatch(dataBuf, patchBuf []byte) ([]byte, error) {
+	var data, patch interface{}
+
+	err := unmarshalJSON(dataBuf, &data)
+	if err != nil {
+		err = fmt.Errorf("something went wrong while unmarshalling data JSON: %v", err)
+
+		return nil, err
+	}
+
+	err = unmarshalJSON(patchBuf, &patch)
+	if err != nil {
+		err = fmt.Errorf("something went wrong while unmarshalling patch JSON: %v", err)
+
+		return nil, err
+	}
+
+	mergedBuf, err := json.Marshal(mergePatch(data, patch))
+	if err != nil {
+		err = fmt.Errorf("something went wrong while marshalling merged JSON: %v", err)
+
+		return nil, err
+	}
+
+	return mergedBuf, nil
+}
+
+func mergePatch(data, patch interface{}) interface{} {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	dataObject, ok := data.(map[string]interface{})
+	if !ok {
+		dataObject = make(map[string]interface{})
+	}
+
+	for k, v := range patchObject {
+		if v == nil {
+			delete(dataObject, k)
+			continue
+		}
+
+		dataObject[k] = mergePatch(dataObject[k], v)
+	}
+
+	return dataObject
+}
+
+// mergePatchPaths строит пути так же, как Paths. Патч, не являющийся объектом, заменяет весь документ
+func mergePatchPaths(patchBuf []byte) ([]string, error) {
+	var patch interface{}
+
+	err := unmarshalJSON(patchBuf, &patch)
+	if err != nil {
+		err = fmt.Errorf("something went wrong while unmarshalling patch JSON: %v", err)
+
+		return nil, err
+	}
+
+	if _, ok := patch.(map[string]interface{}); !ok {
+		return []string{""}, nil
+	}
+
+	paths := make([]string, 0)
+	collectPaths(patch, nil, &paths)
+	slices.Sort(paths)
+
+	return paths, nil
+}
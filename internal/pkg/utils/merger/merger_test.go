@@ -71,3 +71,55 @@ func TestMerge(t *testing.T) {
 		})
 	}
 }
+
+func TestPaths(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		patch    []byte
+		expected []string
+	}{
+		{
+			name:     "must return top-level keys",
+			patch:    []byte(`{"b": 1, "a": "x"}`),
+			expected: []string{"a", "b"},
+		},
+		{
+			name:     "must descend into objects",
+			patch:    []byte(`{"obj": {"a": 1, "b": {"c": 2}}}`),
+			expected: []string{"obj.a", "obj.b.c"},
+		},
+		{
+			name:     "must treat arrays and empty objects as leaves",
+			patch:    []byte(`{"arr": [1, 2], "empty": {}, "objArr": {"0": {"hp": 3}}}`),
+			expected: []string{"arr", "empty", "objArr.0.hp"},
+		},
+		{
+			name:     "must return nothing for empty patch",
+			patch:    []byte(`{}`),
+			expected: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			paths, err := merger.Paths(tt.patch)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, paths)
+		})
+	}
+}
+
+func TestOverlaps(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, merger.Overlaps("a.b", "a.b"))
+	assert.True(t, merger.Overlaps("a", "a.b.c"))
+	assert.True(t, merger.Overlaps("a.b.c", "a.b"))
+	assert.False(t, merger.Overlaps("a.b", "a.bc"))
+	assert.False(t, merger.Overlaps("a.b", "a.c"))
+	assert.True(t, merger.Overlaps("", "a.b"))
+}
@@ -0,0 +1,207 @@
+package merger_test
+
+import (
+	"testing"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/models"
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils/merger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyJSONPatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		data     string
+		patch    string
+		expected string
+	}{
+		{
+			name:     "must add object member and replace existing one",
+			data:     `{"a": 1}`,
+			patch:    `[{"op": "add", "path": "/b", "value": {"c": null}}, {"op": "add", "path": "/a", "value": 2}]`,
+			expected: `{"a":2,"b":{"c":null}}`,
+		},
+		{
+			name:     "must insert and append array elements",
+			data:     `{"arr": [1, 3]}`,
+			patch:    `[{"op": "add", "path": "/arr/1", "value": 2}, {"op": "add", "path": "/arr/-", "value": 4}]`,
+			expected: `{"arr":[1,2,3,4]}`,
+		},
+		{
+			name:     "must remove object members and array elements",
+			data:     `{"a": 1, "arr": [1, 2, 3]}`,
+			patch:    `[{"op": "remove", "path": "/a"}, {"op": "remove", "path": "/arr/0"}]`,
+			expected: `{"arr":[2,3]}`,
+		},
+		{
+			name:     "must replace nested values",
+			data:     `{"p": [{"hp": 10}, {"hp": 5}]}`,
+			patch:    `[{"op": "replace", "path": "/p/1/hp", "value": 0}]`,
+			expected: `{"p":[{"hp":10},{"hp":0}]}`,
+		},
+		{
+			name:     "must move array elements",
+			data:     `{"order": ["a", "b", "c"]}`,
+			patch:    `[{"op": "move", "from": "/order/0", "path": "/order/2"}]`,
+			expected: `{"order":["b","c","a"]}`,
+		},
+		{
+			name:     "must copy values without aliasing",
+			data:     `{"src": {"x": 1}}`,
+			patch:    `[{"op": "copy", "from": "/src", "path": "/dst"}, {"op": "replace", "path": "/dst/x", "value": 2}]`,
+			expected: `{"dst":{"x":2},"src":{"x":1}}`,
+		},
+		{
+			name:     "must pass test with equal numbers and unordered keys",
+			data:     `{"a": {"x": 1, "y": [1.0]}}`,
+			patch:    `[{"op": "test", "path": "/a", "value": {"y": [1], "x": 1.00}}]`,
+			expected: `{"a":{"x":1,"y":[1.0]}}`,
+		},
+		{
+			name:     "must unescape pointer tokens",
+			data:     `{"a/b": 1, "m~n": 2}`,
+			patch:    `[{"op": "replace", "path": "/a~1b", "value": 3}, {"op": "remove", "path": "/m~0n"}]`,
+			expected: `{"a/b":3}`,
+		},
+		{
+			name:     "must replace the whole document",
+			data:     `{"a": 1}`,
+			patch:    `[{"op": "replace", "path": "", "value": [1]}]`,
+			expected: `[1]`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := merger.ApplyJSONPatch([]byte(tt.data), []byte(tt.patch))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(result))
+		})
+	}
+}
+
+func TestApplyJSONPatch_Errors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		data  string
+		patch string
+	}{
+		{"failed test", `{"a": 1}`, `[{"op": "test", "path": "/a", "value": 2}]`},
+		{"missing value", `{"a": 1}`, `[{"op": "add", "path": "/b"}]`},
+		{"unknown op", `{"a": 1}`, `[{"op": "merge", "path": "/a", "value": 2}]`},
+		{"replace of missing key", `{"a": 1}`, `[{"op": "replace", "path": "/b", "value": 2}]`},
+		{"remove of missing key", `{"a": 1}`, `[{"op": "remove", "path": "/b"}]`},
+		{"index out of range", `{"arr": [1]}`, `[{"op": "add", "path": "/arr/2", "value": 2}]`},
+		{"index with leading zero", `{"arr": [1, 2]}`, `[{"op": "remove", "path": "/arr/01"}]`},
+		{"move into own child", `{"a": {"b": 1}}`, `[{"op": "move", "from": "/a", "path": "/a/b/c"}]`},
+		{"pointer without slash", `{"a": 1}`, `[{"op": "remove", "path": "a"}]`},
+		{"patch is not an array", `{"a": 1}`, `{"a": 2}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := merger.ApplyJSONPatch([]byte(tt.data), []byte(tt.patch))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestApplyMergePatch(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		data     string
+		patch    string
+		expected string
+	}{
+		{"must merge objects and delete nulls", `{"a": {"b": 1, "c": 2}, "d": 3}`, `{"a": {"c": null, "e": 4}, "d": null}`,
+			`{"a":{"b":1,"e":4}}`},
+		{"must replace arrays", `{"a": [1, 2]}`, `{"a": [3]}`, `{"a":[3]}`},
+		{"must replace scalar with object", `{"a": 1}`, `{"a": {"b": null, "c": 1}}`, `{"a":{"c":1}}`},
+		{"must replace the whole document", `{"a": 1}`, `["x"]`, `["x"]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := merger.ApplyMergePatch([]byte(tt.data), []byte(tt.patch))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, string(result))
+		})
+	}
+}
+
+func TestPatchPaths(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		format   models.PatchFormat
+		patch    string
+		expected []string
+	}{
+		{"legacy uses Paths", models.LegacyPatch, `{"a": {"b": 1}}`, []string{"a.b"}},
+		{"merge patch includes deleted keys", models.MergePatch, `{"a": null, "b": {"c": 1}}`,
+			[]string{"a", "b.c"}},
+		{"merge patch replacing document", models.MergePatch, `[1]`, []string{""}},
+		{"json patch array inserts change the array", models.JSONPatch,
+			`[{"op": "add", "path": "/p/0", "value": 1}, {"op": "replace", "path": "/p/1/hp", "value": 2}]`,
+			[]string{"p", "p.1.hp"}},
+		{"json patch move changes both ends", models.JSONPatch,
+			`[{"op": "move", "from": "/a/x", "path": "/b/y"}, {"op": "test", "path": "/c", "value": 1}]`,
+			[]string{"a.x", "b.y"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			paths, err := merger.PatchPaths(tt.format, []byte(tt.patch))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, paths)
+		})
+	}
+}
+
+func TestReadPaths(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		format   models.PatchFormat
+		patch    string
+		expected []string
+	}{
+		{"legacy reads nothing", models.LegacyPatch, `{"a": {"b": 1}}`, nil},
+		{"merge patch reads nothing", models.MergePatch, `{"a": null}`, nil},
+		{"json patch copy reads its source", models.JSONPatch,
+			`[{"op": "copy", "from": "/_hidden/0/name", "path": "/notes"}]`,
+			[]string{"_hidden.0.name"}},
+		{"json patch test reads its path", models.JSONPatch,
+			`[{"op": "test", "path": "/_dmOnly/trap", "value": "pit"}]`,
+			[]string{"_dmOnly.trap"}},
+		{"json patch writes read nothing", models.JSONPatch,
+			`[{"op": "add", "path": "/a", "value": 1}, {"op": "move", "from": "/b", "path": "/c"}]`,
+			[]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			paths, err := merger.ReadPaths(tt.format, []byte(tt.patch))
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, paths)
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package merger
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Paths возвращает отсортированный список путей, которые изменяет патч. Путь строится так же,
+// как в сообщениях об ошибках Merge: ключи через точку, индексы массивов — как ключи
+func Paths(patchBuf []byte) ([]string, error) {
+	var patch interface{}
+
+	err := unmarshalJSON(patchBuf, &patch)
+	if err != nil {
+		err = fmt.Errorf("something went wrong while unmarshalling patch JSON: %v", err)
+
+		return nil, err
+	}
+
+	paths := make([]string, 0)
+	collectPaths(patch, nil, &paths)
+	slices.Sort(paths)
+
+	return paths, nil
+}
+
+func collectPaths(patch interface{}, path []string, paths *[]string) {
+	patchObject, ok := patch.(map[string]interface{})
+	if !ok || len(patchObject) == 0 {
+		if len(path) > 0 {
+			*paths = append(*paths, strings.Join(path, "."))
+		}
+
+		return
+	}
+
+	for k, v := range patchObject {
+		collectPaths(v, append(slices.Clone(path), k), paths)
+	}
+}
+
+// Overlaps сообщает, затрагивают ли два пути одно и то же значение: пути совпадают
+// или один из них вложен в другой. Пустой путь означает весь документ
+func Overlaps(a, b string) bool {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+
+	return a == "" || a == b || strings.HasPrefix(b, a+".")
+}
@@ -0,0 +1,150 @@
+package redactor
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// HiddenKey — ключ в корне энкаунтера со списком путей, скрытых от игроков. Пути записываются
+	// так же, как в merger: ключи через точку, индексы массивов — как ключи
+	HiddenKey = "_hidden"
+
+	// DMOnlyKey помечает объект, скрытый от игроков целиком: {"_dmOnly": true, ...}
+	DMOnlyKey = "_dmOnly"
+)
+
+// Redact возвращает версию энкаунтера для игроков. Скрытые поля объектов удаляются,
+// скрытые элементы массивов заменяются на null, чтобы не сдвигать индексы в путях патчей
+func Redact(dataBuf []byte) ([]byte, error) {
+	var data interface{}
+
+	err := unmarshalJSON(dataBuf, &data)
+	if err != nil {
+		err = fmt.Errorf("something went wrong while unmarshalling data JSON: %v", err)
+
+		return nil, err
+	}
+
+	hidden := hiddenList(data)
+	if root, ok := data.(map[string]interface{}); ok {
+		delete(root, HiddenKey)
+	}
+
+	redacted, _ := redactValue(data, nil, hidden)
+
+	redactedBuf, err := json.Marshal(redacted)
+	if err != nil {
+		err = fmt.Errorf("something went wrong while marshalling redacted JSON: %v", err)
+
+		return nil, err
+	}
+
+	return redactedBuf, nil
+}
+
+// HiddenPaths возвращает все пути энкаунтера, скрытые от игроков, включая сам HiddenKey
+func HiddenPaths(dataBuf []byte) ([]string, error) {
+	var data interface{}
+
+	err := unmarshalJSON(dataBuf, &data)
+	if err != nil {
+		err = fmt.Errorf("something went wrong while unmarshalling data JSON: %v", err)
+
+		return nil, err
+	}
+
+	paths := append([]string{HiddenKey}, hiddenList(data)...)
+	collectDMOnly(data, nil, &paths)
+	slices.Sort(paths)
+
+	return slices.Compact(paths), nil
+}
+
+func hiddenList(data interface{}) []string {
+	root, ok := data.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	list, ok := root[HiddenKey].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	paths := make([]string, 0, len(list))
+	for _, v := range list {
+		if path, ok := v.(string); ok && path != "" {
+			paths = append(paths, path)
+		}
+	}
+
+	return paths
+}
+
+// redactValue возвращает значение без скрытых частей и false, если значение скрыто целиком
+func redactValue(value interface{}, path []string, hidden []string) (interface{}, bool) {
+	if len(path) > 0 && slices.Contains(hidden, strings.Join(path, ".")) {
+		return nil, false
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if isDMOnly(v) {
+			return nil, false
+		}
+
+		ret := make(map[string]interface{}, len(v))
+		for k, child := range v {
+			if redacted, ok := redactValue(child, append(path, k), hidden); ok {
+				ret[k] = redacted
+			}
+		}
+
+		return ret, true
+	case []interface{}:
+		ret := make([]interface{}, len(v))
+		for i, child := range v {
+			ret[i], _ = redactValue(child, append(path, strconv.Itoa(i)), hidden)
+		}
+
+		return ret, true
+	}
+
+	return value, true
+}
+
+func collectDMOnly(value interface{}, path []string, paths *[]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		if isDMOnly(v) && len(path) > 0 {
+			*paths = append(*paths, strings.Join(path, "."))
+			return
+		}
+
+		for k, child := range v {
+			collectDMOnly(child, append(slices.Clone(path), k), paths)
+		}
+	case []interface{}:
+		for i, child := range v {
+			collectDMOnly(child, append(slices.Clone(path), strconv.Itoa(i)), paths)
+		}
+	}
+}
+
+func isDMOnly(object map[string]interface{}) bool {
+	dmOnly, ok := object[DMOnlyKey].(bool)
+
+	return ok && dmOnly
+}
+
+func unmarshalJSON(buff []byte, data interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(buff))
+	decoder.UseNumber()
+
+	return decoder.Decode(data)
+}
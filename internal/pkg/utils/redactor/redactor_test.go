@@ -0,0 +1,64 @@
+package redactor_test
+
+import (
+	"testing"
+
+	"github.com/IlyaChgn/2025_IMAO_DnD_Assistant_backend/internal/pkg/utils/redactor"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		data     []byte
+		expected []byte
+	}{
+		{
+			name:     "must keep data without hidden fields",
+			data:     []byte(`{"b": 1, "a": {"c": "x"}}`),
+			expected: []byte(`{"a":{"c":"x"},"b":1}`),
+		},
+		{
+			name:     "must remove listed paths and the list itself",
+			data:     []byte(`{"_hidden": ["notes", "monsters.0.hp"], "notes": "trap", "monsters": [{"hp": 30, "name": "orc"}]}`),
+			expected: []byte(`{"monsters":[{"name":"orc"}]}`),
+		},
+		{
+			name:     "must replace hidden array elements with null",
+			data:     []byte(`{"monsters": [{"name": "orc"}, {"_dmOnly": true, "name": "assassin"}, {"name": "goblin"}]}`),
+			expected: []byte(`{"monsters":[{"name":"orc"},null,{"name":"goblin"}]}`),
+		},
+		{
+			name:     "must remove hidden object subtrees",
+			data:     []byte(`{"secret": {"_dmOnly": true, "plan": "ambush"}, "visible": {"_dmOnly": false, "a": 1}}`),
+			expected: []byte(`{"visible":{"_dmOnly":false,"a":1}}`),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			redacted, err := redactor.Redact(tt.data)
+			assert.NoError(t, err)
+			assert.JSONEq(t, string(tt.expected), string(redacted))
+		})
+	}
+}
+
+func TestRedact_InvalidJSON(t *testing.T) {
+	t.Parallel()
+
+	_, err := redactor.Redact([]byte(`{"a":`))
+	assert.Error(t, err)
+}
+
+func TestHiddenPaths(t *testing.T) {
+	t.Parallel()
+
+	paths, err := redactor.HiddenPaths([]byte(`{"_hidden": ["notes"], "monsters": [{"hp": 1}, {"_dmOnly": true}]}`))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"_hidden", "monsters.1", "notes"}, paths)
+}